@@ -0,0 +1,163 @@
+// Команда linkmigrate - импорт и выгрузка ссылок напрямую в хранилище сервиса, минуя HTTP API.
+//
+// Хранилище настраивается так же, как у сервиса: configs/app/.env, переменные окружения и флаги
+// сервиса (-d, -f, -dedup и остальные). Импорт сохраняет владельцев из колонки user_id,
+// строки без нее получают владельца -owner:
+//
+//	linkmigrate -d postgres://... -import links.csv -owner migrated
+//	linkmigrate -d postgres://... -export - -format jsonl > links.jsonl
+//
+// С файловым хранилищем команду нужно запускать при остановленном сервисе: он читает файл
+// только при запуске. Адреса при импорте на вредоносность не проверяются - это сделает
+// плановая перепроверка ссылок сервиса, если она настроена.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	lg "log"
+	"os"
+	"time"
+
+	cfg "github.com/sonikq/url-shortener/configs/app"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+const envFile = "configs/app/.env"
+
+func main() {
+	importPath := flag.String("import", "", "file to import links from, - for stdin")
+	exportPath := flag.String("export", "", "file to export links to, - for stdout")
+	format := flag.String("format", "", "csv or jsonl, by default from the file extension")
+	owner := flag.String("owner", "", "import: owner of rows without user_id; export: only links of this user")
+
+	if _, err := os.Stat(envFile); err == nil {
+		if _, err = cfg.Load(envFile); err != nil {
+			lg.Fatalf("failed to load %s: %s", envFile, err)
+		}
+	}
+	var config cfg.Config
+	cfg.ParseConfig(&config)
+
+	if (*importPath == "") == (*exportPath == "") {
+		lg.Fatal("exactly one of -import and -export is required")
+	}
+
+	if err := run(config, *importPath, *exportPath, *format, *owner); err != nil {
+		lg.Fatal(err)
+	}
+}
+
+func run(config cfg.Config, importPath, exportPath, format, owner string) error {
+	store, err := initStorage(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer store.Close()
+
+	repo := repositories.NewUserRepo(store)
+	if importPath != "" {
+		return importLinks(context.Background(), repo, config.BaseURL, importPath, format, owner)
+	}
+	return exportLinks(context.Background(), repo, exportPath, format, owner)
+}
+
+func importLinks(ctx context.Context, repo *repositories.UserRepo, baseURL, path, format, owner string) error {
+	format, err := linkio.FileFormat(path, format)
+	if err != nil {
+		return err
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		file, errOpen := os.Open(path)
+		if errOpen != nil {
+			return errOpen
+		}
+		defer file.Close()
+		in = file
+	}
+
+	rows, err := linkio.NewReader(in, format)
+	if err != nil {
+		return err
+	}
+
+	result := repo.ImportLinks(ctx, user.ImportLinksRequest{
+		UserID:     owner,
+		KeepOwners: true,
+		BaseURL:    baseURL,
+		Rows:       rows,
+	})
+	if err = printReport(os.Stdout, result.Response); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
+
+func exportLinks(ctx context.Context, repo *repositories.UserRepo, path, format, owner string) error {
+	format, err := linkio.FileFormat(path, format)
+	if err != nil {
+		return err
+	}
+
+	// Отчет не должен смешиваться с выгрузкой в stdout.
+	out, report := io.Writer(os.Stdout), os.Stderr
+	if path != "-" {
+		file, errCreate := os.Create(path)
+		if errCreate != nil {
+			return errCreate
+		}
+		defer file.Close()
+		out, report = file, os.Stdout
+	}
+
+	rows, err := linkio.NewWriter(out, format)
+	if err != nil {
+		return err
+	}
+
+	result := repo.ExportLinks(ctx, user.ExportLinksRequest{
+		UserID: owner,
+		Rows:   rows,
+	})
+	if err = printReport(report, result.Response); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
+
+func printReport(w io.Writer, report any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func initStorage(config cfg.Config) (*storage.Storage, error) {
+	storageOptions := []storage.OptionsStorage{storage.WithDedupScope(storage.DedupScope(config.DedupScope))}
+	if config.DatabaseDSN != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		storageOptions = append(storageOptions, storage.WithDB(ctx, config.DatabaseDSN, config.DBPoolWorkers))
+	}
+
+	if config.FileStoragePath != "" {
+		storageOptions = append(storageOptions, storage.RestoreFile(context.Background(), config.FileStoragePath))
+		storageOptions = append(storageOptions, storage.WithFileStorage(config.FileStoragePath))
+	}
+
+	return storage.NewStorage(storageOptions...)
+}
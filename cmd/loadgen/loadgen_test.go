@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cfg "github.com/sonikq/url-shortener/configs/app"
+	"github.com/sonikq/url-shortener/internal/app/handlers"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/sonikq/url-shortener/internal/app/services"
+	"github.com/sonikq/url-shortener/internal/app/workers"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    mix
+		wantErr bool
+	}{
+		{name: "default", value: defaultMix, want: mix{30, 5, 60, 5}},
+		{name: "partial", value: " redirect = 70, shorten=30 ", want: mix{30, 0, 70, 0}},
+		{name: "unknown operation", value: "update=10", wantErr: true},
+		{name: "no weight", value: "shorten", wantErr: true},
+		{name: "negative weight", value: "shorten=-1", wantErr: true},
+		{name: "empty", value: "shorten=0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMix(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMix_Pick(t *testing.T) {
+	m, err := parseMix("shorten=1,redirect=3")
+	require.NoError(t, err)
+
+	rnd := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		counts[m.pick(rnd)]++
+	}
+	assert.Zero(t, counts[opBatch])
+	assert.Zero(t, counts[opDelete])
+	assert.InDelta(t, 7500, counts[opRedirect], 300)
+}
+
+func TestSummarize(t *testing.T) {
+	latencies := make([]time.Duration, 0, 100)
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	got := summarize(latencies)
+	assert.Equal(t, latency{Mean: 50.5, P50: 50, P90: 90, P95: 95, P99: 99, Max: 100}, got)
+	assert.Equal(t, latency{}, summarize(nil))
+}
+
+func TestRun(t *testing.T) {
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	log := logger.New(logger.LevelError, "loadgen-test")
+	repo := repositories.NewRepository(store)
+	worker := workers.NewWorker(store, repo, log, 1, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker.Run(ctx)
+
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Config.Handler = handlers.NewRouter(handlers.Option{
+		Conf:      cfg.Config{BaseURL: "http://" + srv.Listener.Addr().String()},
+		Service:   services.NewService(repo),
+		Logger:    log,
+		Cache:     store,
+		Worker:    worker,
+		Scheduler: workers.NewScheduler(store, log),
+	})
+	srv.Start()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	opts := options{
+		target:      srv.URL,
+		proto:       protoHTTP,
+		duration:    time.Minute,
+		requests:    200,
+		concurrency: 4,
+		mix:         defaultMix,
+		batchSize:   5,
+		seed:        20,
+		timeout:     5 * time.Second,
+		output:      outputJSON,
+		heapProfile: filepath.Join(dir, "heap.pprof"),
+	}
+	m, err := validate(&opts)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL, opts.pprofURL, "pprof is served by the target")
+
+	tgt, err := newTarget(opts)
+	require.NoError(t, err)
+	r := newRunner(tgt, m, opts)
+	require.NoError(t, r.seedLinks(ctx))
+
+	got := r.run(ctx)
+	assert.LessOrEqual(t, got.Requests, 200)
+	assert.Greater(t, got.Requests, 0)
+	for _, op := range got.Operations {
+		// Переход может начаться до того, как ссылку удалит другой воркер.
+		if op.Operation == opRedirect {
+			assert.Equal(t, op.Errors, op.ByKind["HTTP 410"], op.ByKind)
+		} else {
+			assert.Zero(t, op.Errors, op.ByKind)
+		}
+		assert.Greater(t, op.Requests, 0, op.Operation)
+		assert.Greater(t, op.Latency.Max, 0.0, op.Operation)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, printReport(&buf, opts.output, got))
+	var decoded report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, got.Requests, decoded.Requests)
+
+	profiles := newProfiler(opts.pprofURL, opts.timeout)
+	require.NoError(t, profiles.finish(ctx, opts.heapProfile))
+	info, err := os.Stat(opts.heapProfile)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+}
+
+func TestValidate(t *testing.T) {
+	opts := options{
+		target: "localhost:3200", proto: protoGRPC, duration: time.Second, concurrency: 1,
+		batchSize: 1, output: outputTable, mix: defaultMix,
+	}
+	_, err := validate(&opts)
+	assert.Error(t, err, "delete is not supported by gRPC")
+
+	opts.mix = "shorten=1,redirect=1"
+	opts.cpuProfile = "cpu.pprof"
+	_, err = validate(&opts)
+	assert.Error(t, err, "gRPC needs -pprof for profiles")
+
+	opts.pprofURL = "http://localhost:8080"
+	_, err = validate(&opts)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,168 @@
+// Команда loadgen - нагрузочный тест сервиса сокращения ссылок через HTTP API или gRPC.
+//
+// Несколько воркеров выполняют операции в заданной пропорции (-mix), пока не истечет -duration
+// или не будет выполнено -requests операций:
+//
+//	shorten   POST /api/shorten, уникальный адрес на каждый запрос
+//	batch     POST /api/shorten/batch по -batch-size адресов
+//	redirect  GET /:id по ранее созданной ссылке
+//	delete    DELETE /api/user/urls одной ранее созданной ссылки (только HTTP)
+//
+// Перед запуском создается -seed ссылок, чтобы redirect и delete было с чем работать.
+// Удаленная ссылка больше не выбирается, но переход, начатый до удаления, может получить 410.
+// Все запросы идут от одного пользователя. Повторы клиента отключены, поэтому каждый ответ
+// 5xx или 429 попадает в отчет как ошибка. В отчете - количество операций, доля ошибок,
+// пропускная способность и перцентили задержки по каждому виду операций.
+//
+// С -cpuprofile и -heapprofile в конце прогона снимаются профили с /debug/pprof сервиса:
+// CPU - за последние -cpu-seconds нагрузки, heap - после ее окончания. Для gRPC адрес
+// HTTP-сервера с pprof задается флагом -pprof. Профили сравниваются обычным образом:
+//
+//	loadgen -target http://localhost:8080 -duration 1m -cpuprofile profiles/result.pprof
+//	go tool pprof -diff_base profiles/base.pprof profiles/result.pprof
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	lg "log"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// Протоколы, через которые подается нагрузка.
+const (
+	protoHTTP = "http"
+	protoGRPC = "grpc"
+)
+
+// Форматы отчета.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// options - параметры прогона
+type options struct {
+	target      string
+	proto       string
+	duration    time.Duration
+	requests    int64
+	concurrency int
+	rate        float64
+	mix         string
+	batchSize   int
+	seed        int
+	timeout     time.Duration
+	output      string
+
+	pprofURL    string
+	cpuProfile  string
+	heapProfile string
+	cpuSeconds  time.Duration
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.target, "target", "http://localhost:8080", "HTTP base URL or gRPC address (host:port) of the service")
+	flag.StringVar(&opts.proto, "proto", protoHTTP, "http or grpc")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long to run")
+	flag.Int64Var(&opts.requests, "requests", 0, "stop after this many operations, 0 - run for -duration")
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "number of concurrent workers")
+	flag.Float64Var(&opts.rate, "rate", 0, "operations per second across all workers, 0 - as fast as possible")
+	flag.StringVar(&opts.mix, "mix", defaultMix, "weights of operations: shorten, batch, redirect, delete")
+	flag.IntVar(&opts.batchSize, "batch-size", 10, "links per batch operation")
+	flag.IntVar(&opts.seed, "seed", 100, "links created before the run for redirect and delete")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of a single request")
+	flag.StringVar(&opts.output, "o", outputTable, "report format: table or json")
+	flag.StringVar(&opts.pprofURL, "pprof", "", "base URL of the HTTP server with /debug/pprof, by default -target for http")
+	flag.StringVar(&opts.cpuProfile, "cpuprofile", "", "write the server CPU profile to this file")
+	flag.StringVar(&opts.heapProfile, "heapprofile", "", "write the server heap profile to this file")
+	flag.DurationVar(&opts.cpuSeconds, "cpu-seconds", 10*time.Second, "CPU profile covers this last part of the run")
+	flag.Parse()
+
+	m, err := validate(&opts)
+	if err != nil {
+		fmt.Fprintln(flag.CommandLine.Output(), err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err = run(ctx, opts, m); err != nil {
+		lg.Fatal(err)
+	}
+}
+
+// validate проверяет флаги и разбирает пропорцию операций.
+func validate(opts *options) (mix, error) {
+	switch {
+	case opts.proto != protoHTTP && opts.proto != protoGRPC:
+		return nil, fmt.Errorf("unknown protocol %q, expected http or grpc", opts.proto)
+	case opts.output != outputTable && opts.output != outputJSON:
+		return nil, fmt.Errorf("unknown output format %q, expected table or json", opts.output)
+	case opts.duration <= 0:
+		return nil, errors.New("-duration must be positive")
+	case opts.concurrency <= 0:
+		return nil, errors.New("-concurrency must be positive")
+	case opts.batchSize <= 0:
+		return nil, errors.New("-batch-size must be positive")
+	case opts.requests < 0 || opts.rate < 0 || opts.seed < 0:
+		return nil, errors.New("-requests, -rate and -seed must not be negative")
+	}
+
+	m, err := parseMix(opts.mix)
+	if err != nil {
+		return nil, err
+	}
+	if opts.proto == protoGRPC && m.weight(opDelete) > 0 {
+		return nil, errors.New("gRPC API has no delete operation, remove it from -mix")
+	}
+
+	if opts.pprofURL == "" && opts.proto == protoHTTP {
+		opts.pprofURL = opts.target
+	}
+	if (opts.cpuProfile != "" || opts.heapProfile != "") && opts.pprofURL == "" {
+		return nil, errors.New("-pprof is required to capture profiles of a gRPC server")
+	}
+	return m, nil
+}
+
+func run(ctx context.Context, opts options, m mix) error {
+	t, err := newTarget(opts)
+	if err != nil {
+		return err
+	}
+	defer t.close()
+
+	r := newRunner(t, m, opts)
+	lg.Printf("seeding %d links", opts.seed)
+	if err = r.seedLinks(ctx); err != nil {
+		return fmt.Errorf("failed to seed links: %w", err)
+	}
+
+	done := make(chan struct{})
+	var profiles *profiler
+	if opts.cpuProfile != "" || opts.heapProfile != "" {
+		profiles = newProfiler(opts.pprofURL, opts.timeout)
+		profiles.startCPU(opts.cpuProfile, opts.duration, opts.cpuSeconds, done)
+	}
+
+	lg.Printf("running %s load against %s with %d workers for %s", opts.proto, opts.target, opts.concurrency, opts.duration)
+	report := r.run(ctx)
+	close(done)
+
+	if profiles != nil {
+		// Прерванный прогон все равно дописывает профили, поэтому ctx здесь не используется.
+		if err = profiles.finish(context.Background(), opts.heapProfile); err != nil {
+			lg.Printf("failed to capture profiles: %s", err)
+		}
+	}
+
+	return printReport(os.Stdout, opts.output, report)
+}
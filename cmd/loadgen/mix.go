@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Операции нагрузки.
+const (
+	opShorten  = "shorten"
+	opBatch    = "batch"
+	opRedirect = "redirect"
+	opDelete   = "delete"
+)
+
+// operations - операции в порядке вывода отчета
+var operations = []string{opShorten, opBatch, opRedirect, opDelete}
+
+// defaultMix - пропорция операций по умолчанию: сервис в основном отдает редиректы
+const defaultMix = "shorten=30,batch=5,redirect=60,delete=5"
+
+// mix - веса операций в порядке operations
+type mix []int
+
+// parseMix разбирает пропорцию вида shorten=30,redirect=70; операции без веса не выполняются.
+func parseMix(value string) (mix, error) {
+	m := make(mix, len(operations))
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, rawWeight, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid mix entry %q, expected operation=weight", part)
+		}
+		i := operationIndex(strings.TrimSpace(name))
+		if i < 0 {
+			return nil, fmt.Errorf("unknown operation %q in mix, expected one of %s", name, strings.Join(operations, ", "))
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(rawWeight))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("weight of %s must be a non-negative integer", name)
+		}
+		m[i] = weight
+	}
+
+	if m.total() == 0 {
+		return nil, fmt.Errorf("mix %q has no operations", value)
+	}
+	return m, nil
+}
+
+func operationIndex(name string) int {
+	for i, op := range operations {
+		if op == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m mix) weight(op string) int {
+	return m[operationIndex(op)]
+}
+
+func (m mix) total() int {
+	var total int
+	for _, weight := range m {
+		total += weight
+	}
+	return total
+}
+
+// pick выбирает операцию с вероятностью, пропорциональной ее весу.
+func (m mix) pick(rnd *rand.Rand) string {
+	n := rnd.Intn(m.total())
+	for i, weight := range m {
+		if n < weight {
+			return operations[i]
+		}
+		n -= weight
+	}
+	return operations[len(operations)-1]
+}
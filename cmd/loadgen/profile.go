@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	lg "log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// profiler снимает профили с /debug/pprof сервиса
+type profiler struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+
+	cpuDone chan error
+}
+
+func newProfiler(baseURL string, timeout time.Duration) *profiler {
+	return &profiler{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{},
+		timeout: timeout,
+	}
+}
+
+// startCPU снимает CPU-профиль за последние window из duration прогона.
+// Если прогон закончился раньше (-requests или прерывание), профиль не снимается.
+func (p *profiler) startCPU(path string, duration, window time.Duration, done <-chan struct{}) {
+	if path == "" {
+		return
+	}
+
+	window = min(window, duration)
+	seconds := max(int(window/time.Second), 1)
+	p.cpuDone = make(chan error, 1)
+	go func() {
+		timer := time.NewTimer(duration - window)
+		defer timer.Stop()
+		select {
+		case <-done:
+			p.cpuDone <- errors.New("run finished before CPU profiling started")
+			return
+		case <-timer.C:
+		}
+
+		// Сервер отвечает только по истечении seconds, к ним добавляется обычный таймаут запроса.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(seconds)*time.Second+p.timeout)
+		defer cancel()
+		p.cpuDone <- p.fetch(ctx, "/debug/pprof/profile?seconds="+strconv.Itoa(seconds), path)
+	}()
+}
+
+// finish дожидается CPU-профиля и снимает heap-профиль.
+func (p *profiler) finish(ctx context.Context, heapPath string) error {
+	var errs []error
+	if p.cpuDone != nil {
+		errs = append(errs, <-p.cpuDone)
+	}
+
+	if heapPath != "" {
+		ctx, cancel := context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+		errs = append(errs, p.fetch(ctx, "/debug/pprof/heap?gc=1", heapPath))
+	}
+	return errors.Join(errs...)
+}
+
+func (p *profiler) fetch(ctx context.Context, endpoint, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", endpoint, resp.Status)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	lg.Printf("profile %s written to %s", endpoint, path)
+	return nil
+}
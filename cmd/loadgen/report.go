@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/client"
+	"google.golang.org/grpc/status"
+)
+
+// recorder - результаты операций прогона
+type recorder struct {
+	mu  sync.Mutex
+	ops map[string]*opResults
+}
+
+type opResults struct {
+	latencies []time.Duration // только успешных операций
+	errors    map[string]int  // вид ошибки - количество
+	skipped   int
+}
+
+func newRecorder() *recorder {
+	ops := make(map[string]*opResults, len(operations))
+	for _, op := range operations {
+		ops[op] = &opResults{errors: make(map[string]int)}
+	}
+	return &recorder{ops: ops}
+}
+
+func (r *recorder) record(op string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.ops[op].errors[errorKind(err)]++
+		return
+	}
+	r.ops[op].latencies = append(r.ops[op].latencies, latency)
+}
+
+// skip отмечает операцию, для которой не нашлось ссылки.
+func (r *recorder) skip(op string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops[op].skipped++
+}
+
+// errorKind - вид ошибки для отчета: код ответа, а не текст с подробностями конкретного запроса.
+func errorKind(err error) string {
+	var apiErr *client.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &apiErr):
+		return "HTTP " + strconv.Itoa(apiErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "network error"
+	}
+	if st, ok := status.FromError(err); ok {
+		return "gRPC " + st.Code().String()
+	}
+	return err.Error()
+}
+
+// report - итог прогона
+type report struct {
+	Duration   string     `json:"duration"`
+	Requests   int        `json:"requests"`
+	Errors     int        `json:"errors"`
+	ErrorRate  float64    `json:"error_rate"`
+	RPS        float64    `json:"rps"`
+	Operations []opReport `json:"operations"`
+}
+
+// opReport - итог по виду операций, задержки в миллисекундах
+type opReport struct {
+	Operation string         `json:"operation"`
+	Requests  int            `json:"requests"`
+	Errors    int            `json:"errors"`
+	ErrorRate float64        `json:"error_rate"`
+	Skipped   int            `json:"skipped,omitempty"` // не нашлось ссылки для redirect или delete
+	RPS       float64        `json:"rps"`
+	Latency   latency        `json:"latency_ms"`
+	ByKind    map[string]int `json:"errors_by_kind,omitempty"`
+}
+
+type latency struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+func (r *recorder) report(elapsed time.Duration) report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := report{Duration: elapsed.Round(time.Millisecond).String()}
+	for _, op := range operations {
+		results := r.ops[op]
+
+		var errs int
+		for _, n := range results.errors {
+			errs += n
+		}
+		requests := len(results.latencies) + errs
+		if requests == 0 && results.skipped == 0 {
+			continue
+		}
+
+		item := opReport{
+			Operation: op,
+			Requests:  requests,
+			Errors:    errs,
+			ErrorRate: ratio(errs, requests),
+			Skipped:   results.skipped,
+			RPS:       float64(requests) / elapsed.Seconds(),
+			Latency:   summarize(results.latencies),
+		}
+		if errs > 0 {
+			item.ByKind = results.errors
+		}
+		result.Operations = append(result.Operations, item)
+		result.Requests += requests
+		result.Errors += errs
+	}
+	result.ErrorRate = ratio(result.Errors, result.Requests)
+	result.RPS = float64(result.Requests) / elapsed.Seconds()
+	return result
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// summarize считает среднее и перцентили задержки по методу ближайшего ранга.
+func summarize(latencies []time.Duration) latency {
+	if len(latencies) == 0 {
+		return latency{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return milliseconds(sorted[max(rank-1, 0)])
+	}
+
+	return latency{
+		Mean: milliseconds(sum / time.Duration(len(sorted))),
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P95:  percentile(95),
+		P99:  percentile(99),
+		Max:  milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*1000) / 1000
+}
+
+func printReport(w io.Writer, output string, r report) error {
+	if output == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OPERATION\tREQUESTS\tERRORS\tERROR %\tRPS\tMEAN ms\tP50 ms\tP90 ms\tP95 ms\tP99 ms\tMAX ms\t")
+	for _, op := range r.Operations {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			op.Operation, op.Requests, op.Errors, op.ErrorRate*100, op.RPS,
+			op.Latency.Mean, op.Latency.P50, op.Latency.P90, op.Latency.P95, op.Latency.P99, op.Latency.Max)
+	}
+	fmt.Fprintf(tw, "total\t%d\t%d\t%.2f\t%.1f\t\t\t\t\t\t\t\n", r.Requests, r.Errors, r.ErrorRate*100, r.RPS)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nduration: %s\n", r.Duration)
+	for _, op := range r.Operations {
+		if op.Skipped > 0 {
+			fmt.Fprintf(w, "%s: %d skipped, no links left\n", op.Operation, op.Skipped)
+		}
+		kinds := make([]string, 0, len(op.ByKind))
+		for kind := range op.ByKind {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Fprintf(w, "%s: %d x %s\n", op.Operation, op.ByKind[kind], kind)
+		}
+	}
+	return nil
+}
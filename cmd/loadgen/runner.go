@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// seedBatchSize - ссылок в одном запросе при подготовке прогона
+const seedBatchSize = 100
+
+// runner - воркеры, подающие нагрузку
+type runner struct {
+	target   target
+	mix      mix
+	opts     options
+	links    *linkPool
+	recorder *recorder
+
+	runID string // отличает адреса этого прогона от созданных раньше
+	seq   atomic.Int64
+}
+
+func newRunner(t target, m mix, opts options) *runner {
+	return &runner{
+		target:   t,
+		mix:      m,
+		opts:     opts,
+		links:    &linkPool{},
+		recorder: newRecorder(),
+		runID:    strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+// nextURL - новый адрес: повторный адрес сервис не сокращает, а возвращает прежнюю ссылку.
+func (r *runner) nextURL() string {
+	return fmt.Sprintf("https://loadgen.example.com/%s/%d", r.runID, r.seq.Add(1))
+}
+
+// seedLinks создает ссылки, по которым пойдут redirect и delete.
+func (r *runner) seedLinks(ctx context.Context) error {
+	for created := 0; created < r.opts.seed; {
+		urls := make([]string, min(seedBatchSize, r.opts.seed-created))
+		for i := range urls {
+			urls[i] = r.nextURL()
+		}
+
+		shortURLs, err := r.target.batch(ctx, urls)
+		if err != nil {
+			return err
+		}
+		r.links.add(shortURLs...)
+		created += len(urls)
+	}
+	return nil
+}
+
+// run подает нагрузку до истечения -duration, выполнения -requests операций или отмены ctx.
+func (r *runner) run(ctx context.Context) report {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.duration)
+	defer cancel()
+
+	tokens := limiter(ctx, r.opts.rate)
+	var issued atomic.Int64
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.concurrency; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(seed))
+			for ctx.Err() == nil {
+				if r.opts.requests > 0 && issued.Add(1) > r.opts.requests {
+					cancel()
+					return
+				}
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tokens:
+					}
+				}
+				r.do(ctx, rnd, r.mix.pick(rnd))
+			}
+		}(start.UnixNano() + int64(i))
+	}
+	wg.Wait()
+
+	return r.recorder.report(time.Since(start))
+}
+
+// do выполняет операцию и записывает ее результат.
+func (r *runner) do(ctx context.Context, rnd *rand.Rand, op string) {
+	var (
+		start = time.Now()
+		err   error
+	)
+	switch op {
+	case opShorten:
+		var shortURL string
+		if shortURL, err = r.target.shorten(ctx, r.nextURL()); err == nil {
+			r.links.add(shortURL)
+		}
+	case opBatch:
+		urls := make([]string, r.opts.batchSize)
+		for i := range urls {
+			urls[i] = r.nextURL()
+		}
+		var shortURLs []string
+		if shortURLs, err = r.target.batch(ctx, urls); err == nil {
+			r.links.add(shortURLs...)
+		}
+	case opRedirect:
+		shortURL, ok := r.links.random(rnd)
+		if !ok {
+			r.recorder.skip(op)
+			return
+		}
+		start = time.Now()
+		err = r.target.redirect(ctx, shortURL)
+	case opDelete:
+		shortURL, ok := r.links.take(rnd)
+		if !ok {
+			r.recorder.skip(op)
+			return
+		}
+		start = time.Now()
+		err = r.target.remove(ctx, shortURL)
+	}
+
+	// Запрос, оборванный окончанием прогона, в отчет не попадает.
+	if ctx.Err() != nil {
+		return
+	}
+	r.recorder.record(op, time.Since(start), err)
+}
+
+// limiter - канал, из которого можно читать не чаще rate раз в секунду; nil - без ограничения.
+func limiter(ctx context.Context, rate float64) <-chan struct{} {
+	if rate <= 0 {
+		return nil
+	}
+
+	interval := time.Duration(float64(time.Second) / rate)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+
+	tokens := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case tokens <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return tokens
+}
+
+// linkPool - созданные за прогон ссылки
+type linkPool struct {
+	mu    sync.Mutex
+	links []string
+}
+
+func (p *linkPool) add(links ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.links = append(p.links, links...)
+}
+
+// random - случайная ссылка из пула.
+func (p *linkPool) random(rnd *rand.Rand) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.links) == 0 {
+		return "", false
+	}
+	return p.links[rnd.Intn(len(p.links))], true
+}
+
+// take забирает случайную ссылку из пула, чтобы по удаленной ссылке не было переходов.
+func (p *linkPool) take(rnd *rand.Rand) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.links) == 0 {
+		return "", false
+	}
+
+	i := rnd.Intn(len(p.links))
+	link := p.links[i]
+	last := len(p.links) - 1
+	p.links[i] = p.links[last]
+	p.links = p.links[:last]
+	return link, true
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/client"
+)
+
+// target - сервис под нагрузкой
+type target interface {
+	shorten(ctx context.Context, originalURL string) (string, error)
+	batch(ctx context.Context, originalURLs []string) ([]string, error)
+	redirect(ctx context.Context, shortURL string) error
+	remove(ctx context.Context, shortURL string) error
+	close() error
+}
+
+// errDeleteUnsupported - в gRPC API нет удаления ссылок
+var errDeleteUnsupported = errors.New("delete is not supported by gRPC API")
+
+func newTarget(opts options) (target, error) {
+	if opts.proto == protoGRPC {
+		c, err := client.DialGRPC(opts.target, nil, client.WithGRPCRetries(0, 0, 0))
+		if err != nil {
+			return nil, err
+		}
+		return &grpcTarget{c: c, timeout: opts.timeout}, nil
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	// Соединения переиспользуются всеми воркерами, иначе замер включит установку TCP.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = opts.concurrency
+	transport.MaxIdleConnsPerHost = opts.concurrency
+
+	return &httpTarget{
+		c: client.New(opts.target,
+			client.WithHTTPClient(&http.Client{Jar: jar, Transport: transport}),
+			client.WithTimeout(opts.timeout),
+			client.WithRetries(0, 0, 0),
+		),
+	}, nil
+}
+
+type httpTarget struct {
+	c *client.Client
+}
+
+func (h *httpTarget) shorten(ctx context.Context, originalURL string) (string, error) {
+	return h.c.ShortenJSON(ctx, client.ShortenRequest{URL: originalURL})
+}
+
+func (h *httpTarget) batch(ctx context.Context, originalURLs []string) ([]string, error) {
+	result, err := h.c.ShortenBatch(ctx, batchRequest(originalURLs))
+	if err != nil {
+		return nil, err
+	}
+	return batchShortURLs(result), nil
+}
+
+func (h *httpTarget) redirect(ctx context.Context, shortURL string) error {
+	_, err := h.c.Expand(ctx, shortURL)
+	return err
+}
+
+func (h *httpTarget) remove(ctx context.Context, shortURL string) error {
+	_, err := h.c.DeleteLinks(ctx, shortURL)
+	return err
+}
+
+func (h *httpTarget) close() error {
+	return nil
+}
+
+type grpcTarget struct {
+	c       *client.GRPCClient
+	timeout time.Duration
+}
+
+func (g *grpcTarget) shorten(ctx context.Context, originalURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	return g.c.Shorten(ctx, client.ShortenRequest{URL: originalURL})
+}
+
+func (g *grpcTarget) batch(ctx context.Context, originalURLs []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	result, err := g.c.ShortenBatch(ctx, batchRequest(originalURLs))
+	if err != nil {
+		return nil, err
+	}
+	return batchShortURLs(result), nil
+}
+
+func (g *grpcTarget) redirect(ctx context.Context, shortURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	_, err := g.c.Expand(ctx, shortURL)
+	return err
+}
+
+func (g *grpcTarget) remove(context.Context, string) error {
+	return errDeleteUnsupported
+}
+
+func (g *grpcTarget) close() error {
+	return g.c.Close()
+}
+
+func batchRequest(originalURLs []string) []client.BatchRequest {
+	links := make([]client.BatchRequest, 0, len(originalURLs))
+	for i, originalURL := range originalURLs {
+		links = append(links, client.BatchRequest{
+			CorrelationID: strconv.Itoa(i),
+			OriginalURL:   originalURL,
+		})
+	}
+	return links
+}
+
+func batchShortURLs(result []client.BatchResult) []string {
+	shortURLs := make([]string, 0, len(result))
+	for _, row := range result {
+		shortURLs = append(shortURLs, row.ShortURL)
+	}
+	return shortURLs
+}
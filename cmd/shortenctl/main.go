@@ -0,0 +1,349 @@
+// Команда shortenctl - утилита администратора сервиса сокращения ссылок.
+//
+// Конфигурация загружается так же, как у сервиса: configs/app/.env, переменные окружения и флаги
+// сервиса (-d, -f, -dedup и остальные). Флаги утилиты и сервиса указываются до команды:
+//
+//	shortenctl [флаги] <команда> [аргументы]
+//
+// Команды:
+//
+//	lookup <alias>                                  ссылка в любом состоянии
+//	disable [-reason text] <alias>                  заблокировать ссылку
+//	enable <alias>                                  снять блокировку
+//	delete <alias>                                  удалить ссылку от имени владельца
+//	transfer -from user -to user [alias...]         передать ссылки другому владельцу, без alias - все
+//	stats                                           количество ссылок и пользователей
+//	migrate                                         создать или обновить схему БД
+//	import [-owner user] [-format f] <file|->       импорт ссылок из CSV или JSON Lines
+//	export [-owner user] [-format f] <file|->       выгрузка ссылок, -owner - только ссылки пользователя
+//	compact                                         сжать файл хранилища
+//
+// По умолчанию утилита работает напрямую с хранилищем из конфигурации. С флагом -server она
+// обращается к служебному API запущенного сервиса; так доступны только lookup и stats
+// (запрос должен прийти из доверенной подсети, адрес задается флагом -real-ip).
+//
+// Файловое хранилище сервис читает только при запуске, поэтому изменять его утилитой
+// нужно при остановленном сервисе.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	lg "log"
+	"os"
+	"os/signal"
+	"time"
+
+	cfg "github.com/sonikq/url-shortener/configs/app"
+	"github.com/sonikq/url-shortener/internal/app/admin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+const envFile = "configs/app/.env"
+
+// dbConnectTimeout - ограничение времени подключения к БД
+const dbConnectTimeout = 5 * time.Second
+
+// errUsage - команда вызвана с неверными аргументами, подсказка уже выведена
+var errUsage = errors.New("invalid usage")
+
+// options - флаги утилиты
+type options struct {
+	output string
+	server string
+	realIP string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.output, "o", outputTable, "output format: table or json")
+	flag.StringVar(&opts.server, "server", "", "address of a running server, e.g. http://localhost:8080")
+	flag.StringVar(&opts.realIP, "real-ip", "", "X-Real-IP sent to the server for trusted endpoints")
+
+	if _, err := os.Stat(envFile); err == nil {
+		if _, err = cfg.Load(envFile); err != nil {
+			lg.Fatalf("failed to load %s: %s", envFile, err)
+		}
+	}
+	var config cfg.Config
+	cfg.ParseConfig(&config)
+
+	if opts.output != outputTable && opts.output != outputJSON {
+		lg.Fatalf("unknown output format %q, expected table or json", opts.output)
+	}
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := run(ctx, config, opts, flag.Arg(0), flag.Args()[1:])
+	switch {
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		lg.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <command> [args]\n\n", os.Args[0])
+	fmt.Fprintln(flag.CommandLine.Output(), "commands: lookup, disable, enable, delete, transfer, stats, migrate, import, export, compact")
+	fmt.Fprintln(flag.CommandLine.Output(), "\nflags:")
+	flag.PrintDefaults()
+}
+
+func run(ctx context.Context, config cfg.Config, opts options, command string, args []string) error {
+	if opts.server != "" {
+		return runRemote(ctx, newRemote(opts.server, opts.realIP), opts.output, command, args)
+	}
+
+	switch command {
+	case "compact":
+		return compact(config, opts.output, args)
+	case "migrate":
+		return migrate(ctx, config, opts.output)
+	case "lookup", "disable", "enable", "delete", "transfer", "stats", "import", "export":
+	default:
+		usage()
+		return errUsage
+	}
+
+	store, err := initStorage(config)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer store.Close()
+
+	a := admin.New(store)
+	switch command {
+	case "lookup", "enable", "delete":
+		alias, errArgs := aliasArg(command, args)
+		if errArgs != nil {
+			return errArgs
+		}
+
+		action := a.Lookup
+		switch command {
+		case "enable":
+			action = a.Enable
+		case "delete":
+			action = a.Delete
+		}
+		return printLink(ctx, opts.output, alias, action)
+	case "disable":
+		return disable(ctx, a, opts.output, args)
+	case "transfer":
+		return transfer(ctx, a, opts.output, args)
+	case "stats":
+		stats, errStats := a.Stats(ctx)
+		if errStats != nil {
+			return errStats
+		}
+		return printResult(os.Stdout, opts.output, statsResult(stats))
+	case "import":
+		return importLinks(ctx, repositories.NewUserRepo(store), config.BaseURL, opts.output, args)
+	default:
+		return exportLinks(ctx, repositories.NewUserRepo(store), opts.output, args)
+	}
+}
+
+// migrate создает или обновляет схему БД, не дожидаясь запуска сервиса; данные сохраняются.
+func migrate(ctx context.Context, config cfg.Config, output string) error {
+	if config.DatabaseDSN == "" {
+		return errors.New("database is not configured, set -d or DATABASE_DSN")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dbConnectTimeout)
+	defer cancel()
+
+	migration, err := storage.Migrate(ctx, config.DatabaseDSN, storage.DedupScope(config.DedupScope))
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return printResult(os.Stdout, output, newMigrateResult(migration))
+}
+
+// aliasArg - единственный аргумент команды, сокращение ссылки.
+func aliasArg(command string, args []string) (string, error) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s %s <alias>\n", os.Args[0], command)
+		return "", errUsage
+	}
+	return args[0], nil
+}
+
+func printLink(ctx context.Context, output, alias string, action func(context.Context, string) (admin.Link, error)) error {
+	link, err := action(ctx, alias)
+	if err != nil {
+		return err
+	}
+	return printResult(os.Stdout, output, linkResult(link))
+}
+
+func disable(ctx context.Context, a *admin.Admin, output string, args []string) error {
+	flags := flag.NewFlagSet("disable", flag.ContinueOnError)
+	reason := flags.String("reason", "", "reason shown to visitors, by default \""+admin.DisabledReason+"\"")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	alias, err := aliasArg("disable", flags.Args())
+	if err != nil {
+		return err
+	}
+	return printLink(ctx, output, alias, func(ctx context.Context, alias string) (admin.Link, error) {
+		return a.Disable(ctx, alias, *reason)
+	})
+}
+
+func transfer(ctx context.Context, a *admin.Admin, output string, args []string) error {
+	flags := flag.NewFlagSet("transfer", flag.ContinueOnError)
+	from := flags.String("from", "", "current owner")
+	to := flags.String("to", "", "new owner")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if *from == "" || *to == "" {
+		fmt.Fprintf(os.Stderr, "usage: %s transfer -from user -to user [alias...]\n", os.Args[0])
+		return errUsage
+	}
+
+	moved, err := a.Transfer(ctx, *from, *to, flags.Args())
+	if err != nil {
+		return err
+	}
+	if moved == nil {
+		moved = []string{}
+	}
+	return printResult(os.Stdout, output, transferResult{Transferred: moved})
+}
+
+func compact(config cfg.Config, output string, args []string) error {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "usage: %s compact\n", os.Args[0])
+		return errUsage
+	}
+	if config.FileStoragePath == "" {
+		return errors.New("file storage is not configured, set -f or FILE_STORAGE_PATH")
+	}
+
+	result, err := storage.CompactFile(config.FileStoragePath)
+	if err != nil {
+		return err
+	}
+	return printResult(os.Stdout, output, compactResult(result))
+}
+
+// fileArgs разбирает флаги import и export и возвращает путь к файлу, формат и владельца.
+func fileArgs(command string, args []string) (path, format, owner string, err error) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.StringVar(&format, "format", "", "csv or jsonl, by default from the file extension")
+	flags.StringVar(&owner, "owner", "", "import: owner of rows without user_id; export: only links of this user")
+	if err = flags.Parse(args); err != nil {
+		return "", "", "", errUsage
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s %s [-owner user] [-format csv|jsonl] <file|->\n", os.Args[0], command)
+		return "", "", "", errUsage
+	}
+
+	path = flags.Arg(0)
+	format, err = linkio.FileFormat(path, format)
+	return path, format, owner, err
+}
+
+func importLinks(ctx context.Context, repo *repositories.UserRepo, baseURL, output string, args []string) error {
+	path, format, owner, err := fileArgs("import", args)
+	if err != nil {
+		return err
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		file, errOpen := os.Open(path)
+		if errOpen != nil {
+			return errOpen
+		}
+		defer file.Close()
+		in = file
+	}
+
+	rows, err := linkio.NewReader(in, format)
+	if err != nil {
+		return err
+	}
+
+	result := repo.ImportLinks(ctx, user.ImportLinksRequest{
+		UserID:     owner,
+		KeepOwners: true,
+		BaseURL:    baseURL,
+		Rows:       rows,
+	})
+	if err = printResult(os.Stdout, output, (*importResult)(result.Response)); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
+
+func exportLinks(ctx context.Context, repo *repositories.UserRepo, output string, args []string) error {
+	path, format, owner, err := fileArgs("export", args)
+	if err != nil {
+		return err
+	}
+
+	// Отчет не должен смешиваться с выгрузкой в stdout.
+	out, report := io.Writer(os.Stdout), os.Stderr
+	if path != "-" {
+		file, errCreate := os.Create(path)
+		if errCreate != nil {
+			return errCreate
+		}
+		defer file.Close()
+		out, report = file, os.Stdout
+	}
+
+	rows, err := linkio.NewWriter(out, format)
+	if err != nil {
+		return err
+	}
+
+	result := repo.ExportLinks(ctx, user.ExportLinksRequest{
+		UserID: owner,
+		Rows:   rows,
+	})
+	if err = printResult(report, output, (*exportResult)(result.Response)); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return errors.New(result.Error.Message)
+	}
+	return nil
+}
+
+func initStorage(config cfg.Config) (*storage.Storage, error) {
+	storageOptions := []storage.OptionsStorage{storage.WithDedupScope(storage.DedupScope(config.DedupScope))}
+	if config.DatabaseDSN != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), dbConnectTimeout)
+		defer cancel()
+		storageOptions = append(storageOptions, storage.WithDB(ctx, config.DatabaseDSN, config.DBPoolWorkers))
+	}
+
+	if config.FileStoragePath != "" {
+		storageOptions = append(storageOptions, storage.RestoreFile(context.Background(), config.FileStoragePath))
+		storageOptions = append(storageOptions, storage.WithFileStorage(config.FileStoragePath))
+	}
+
+	return storage.NewStorage(storageOptions...)
+}
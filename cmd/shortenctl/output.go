@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/admin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Форматы вывода результата команды.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// tabular - результат команды, который можно вывести таблицей
+type tabular interface {
+	table() (header []string, rows [][]string)
+}
+
+// summarizer - результат с итоговой строкой, которая выводится после таблицы
+type summarizer interface {
+	summary() string
+}
+
+func printResult(w io.Writer, output string, result tabular) error {
+	if output == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	header, rows := result.table()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if s, ok := result.(summarizer); ok {
+		_, err := fmt.Fprintln(w, s.summary())
+		return err
+	}
+	return nil
+}
+
+type linkResult admin.Link
+
+func (l linkResult) table() ([]string, [][]string) {
+	rows := [][]string{
+		{"alias", l.Alias},
+		{"original_url", l.OriginalURL},
+		{"user_id", l.UserID},
+		{"state", l.State},
+	}
+	if l.BlockedReason != "" {
+		rows = append(rows, []string{"blocked_reason", l.BlockedReason})
+	}
+	if !l.CreatedAt.IsZero() {
+		clicks := strconv.Itoa(l.Clicks)
+		if l.MaxClicks > 0 {
+			clicks += " of " + strconv.Itoa(l.MaxClicks)
+		}
+		rows = append(rows,
+			[]string{"clicks", clicks},
+			[]string{"created_at", l.CreatedAt.Format(time.RFC3339)},
+			[]string{"updated_at", l.UpdatedAt.Format(time.RFC3339)},
+		)
+	}
+	if l.DeletedAt != nil {
+		rows = append(rows, []string{"deleted_at", l.DeletedAt.Format(time.RFC3339)})
+	}
+	return []string{"FIELD", "VALUE"}, rows
+}
+
+type transferResult struct {
+	Transferred []string `json:"transferred"`
+}
+
+func (t transferResult) table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(t.Transferred))
+	for _, alias := range t.Transferred {
+		rows = append(rows, []string{alias})
+	}
+	return []string{"TRANSFERRED"}, rows
+}
+
+type statsResult admin.Stats
+
+func (s statsResult) table() ([]string, [][]string) {
+	return []string{"URLS", "USERS"}, [][]string{{
+		strconv.FormatInt(s.URLs, 10),
+		strconv.FormatInt(s.Users, 10),
+	}}
+}
+
+type migrateResult struct {
+	From   int    `json:"from_version"`
+	To     int    `json:"to_version"`
+	Status string `json:"status"`
+}
+
+func newMigrateResult(migration storage.Migration) migrateResult {
+	result := migrateResult{From: migration.From, To: migration.To, Status: "schema is up to date"}
+	if migration.Applied() {
+		result.Status = "schema migrated"
+	}
+	return result
+}
+
+func (m migrateResult) table() ([]string, [][]string) {
+	return []string{"FROM", "TO", "STATUS"}, [][]string{{strconv.Itoa(m.From), strconv.Itoa(m.To), m.Status}}
+}
+
+type compactResult storage.CompactResult
+
+func (c compactResult) table() ([]string, [][]string) {
+	return []string{"ENTRIES", "LINKS"}, [][]string{{strconv.Itoa(c.Entries), strconv.Itoa(c.Links)}}
+}
+
+type importResult user.ImportReport
+
+func (i *importResult) table() ([]string, [][]string) {
+	rows := make([][]string, 0, len(i.Rows))
+	for _, row := range i.Rows {
+		rows = append(rows, []string{strconv.Itoa(row.Line), row.Alias, row.ShortURL, row.Error})
+	}
+	if i.RowsTruncated {
+		rows = append(rows, []string{"...", "", "", ""})
+	}
+	return []string{"LINE", "ALIAS", "SHORT_URL", "ERROR"}, rows
+}
+
+func (i *importResult) summary() string {
+	return fmt.Sprintf("imported: %d, renamed: %d, failed: %d", i.Imported, i.Renamed, i.Failed)
+}
+
+type exportResult user.ExportReport
+
+func (e *exportResult) table() ([]string, [][]string) {
+	return []string{"EXPORTED"}, [][]string{{strconv.Itoa(e.Exported)}}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/admin"
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// remoteTimeout - ограничение времени запроса к запущенному сервису
+const remoteTimeout = 10 * time.Second
+
+// remote - обращение к запущенному сервису через его HTTP API
+type remote struct {
+	baseURL string
+	realIP  string
+	client  *http.Client
+}
+
+func newRemote(baseURL, realIP string) *remote {
+	return &remote{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		realIP:  realIP,
+		client:  &http.Client{Timeout: remoteTimeout},
+	}
+}
+
+func runRemote(ctx context.Context, r *remote, output, command string, args []string) error {
+	switch command {
+	case "lookup":
+		alias, err := aliasArg(command, args)
+		if err != nil {
+			return err
+		}
+		link, err := r.lookup(ctx, alias)
+		if err != nil {
+			return err
+		}
+		return printResult(os.Stdout, output, linkResult(link))
+	case "stats":
+		stats, err := r.stats(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(os.Stdout, output, statsResult(stats))
+	case "disable", "enable", "delete", "transfer", "migrate", "import", "export", "compact":
+		return fmt.Errorf("%s needs direct access to the storage, run it without -server", command)
+	default:
+		usage()
+		return errUsage
+	}
+}
+
+// lookup запрашивает ссылку у служебного API: переход по ссылке не выполняется, поэтому
+// счетчики, варианты A/B-теста и события не изменяются, а состояние ссылки определяет сервис.
+func (r *remote) lookup(ctx context.Context, alias string) (admin.Link, error) {
+	resp, err := r.do(ctx, "/api/internal/links/"+url.PathEscape(alias))
+	if err != nil {
+		return admin.Link{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return admin.Link{}, models.ErrLinkNotFound
+	default:
+		return admin.Link{}, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	var link admin.Link
+	if err = json.NewDecoder(resp.Body).Decode(&link); err != nil {
+		return admin.Link{}, errors.Join(errors.New("cant decode link"), err)
+	}
+	return link, nil
+}
+
+func (r *remote) stats(ctx context.Context) (admin.Stats, error) {
+	resp, err := r.do(ctx, "/api/internal/stats")
+	if err != nil {
+		return admin.Stats{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return admin.Stats{}, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	var stats admin.Stats
+	if err = json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return admin.Stats{}, errors.Join(errors.New("cant decode stats"), err)
+	}
+	return stats, nil
+}
+
+func (r *remote) do(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.realIP != "" {
+		req.Header.Set("X-Real-IP", r.realIP)
+	}
+	return r.client.Do(req)
+}
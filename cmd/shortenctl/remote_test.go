@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/admin"
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/internal/stats":
+			if r.Header.Get("X-Real-IP") != "10.0.0.1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"urls":3,"users":2}`))
+		case "/api/internal/links/active", "/api/internal/links/later":
+			if r.Header.Get("X-Real-IP") != "10.0.0.1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			state := admin.StateActive
+			if r.URL.Path == "/api/internal/links/later" {
+				state = admin.StatePending
+			}
+			_, _ = w.Write([]byte(`{"alias":"active","original_url":"https://ya.ru","user_id":"u1","state":"` + state + `"}`))
+		case "/active":
+			t.Error("lookup must not follow the link")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	r := newRemote(server.URL+"/", "10.0.0.1")
+
+	stats, err := r.stats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, admin.Stats{URLs: 3, Users: 2}, stats)
+
+	link, err := r.lookup(ctx, "active")
+	require.NoError(t, err)
+	require.Equal(t, admin.Link{Alias: "active", OriginalURL: "https://ya.ru", UserID: "u1", State: admin.StateActive}, link)
+
+	link, err = r.lookup(ctx, "later")
+	require.NoError(t, err)
+	require.Equal(t, admin.StatePending, link.State)
+
+	_, err = r.lookup(ctx, "missing")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+
+	_, err = newRemote(server.URL, "").stats(ctx)
+	require.Error(t, err)
+
+	_, err = newRemote(server.URL, "").lookup(ctx, "active")
+	require.Error(t, err)
+}
@@ -14,7 +14,9 @@ import (
 
 	cfg "github.com/sonikq/url-shortener/configs/app"
 	"github.com/sonikq/url-shortener/internal/app/handlers"
+	"github.com/sonikq/url-shortener/internal/app/pkg/events"
 	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlcheck"
 	"github.com/sonikq/url-shortener/internal/app/repositories"
 	http2 "github.com/sonikq/url-shortener/internal/app/servers/http"
 	"github.com/sonikq/url-shortener/internal/app/services"
@@ -22,6 +24,28 @@ import (
 	"github.com/sonikq/url-shortener/pkg/storage"
 )
 
+// Параметры проверки адресов, не вынесенные в конфигурацию.
+const (
+	blocklistReloadInterval = 10 * time.Second
+	safeBrowsingTimeout     = 2 * time.Second
+)
+
+// Параметры публикации событий, не вынесенные в конфигурацию.
+const (
+	outboxRelayInterval = time.Second
+	webhookSendInterval = 5 * time.Second
+	brokerTimeout       = 5 * time.Second
+)
+
+// Ограничения времени фоновых задач планировщика.
+const (
+	purgeJobTimeout  = 10 * time.Minute
+	rescanJobTimeout = time.Hour
+)
+
+// deleteQueueInterval - как часто очередь удаления проверяет отложенные повторы
+const deleteQueueInterval = time.Second
+
 var buildVersion = "N/A"
 var buildDate = "N/A"
 var buildCommit = "N/A"
@@ -60,22 +84,55 @@ func main() {
 		log.Fatal("failed to initialize storage", logger.Error(err))
 	}
 
-	repo := repositories.NewRepository(store)
+	checker, err := initURLChecker(config)
+	if err != nil {
+		log.Fatal("failed to initialize url checker", logger.Error(err))
+	}
+
+	var repoOptions []repositories.OptionsUserRepo
+	if checker != nil {
+		repoOptions = append(repoOptions, repositories.WithURLChecker(checker))
+	}
+	repo := repositories.NewRepository(store, repoOptions...)
 
 	service := services.NewService(repo)
 
-	pool := make(chan workers.Pool)
-	defer close(pool)
+	// Очередь удаления останавливается после сервера, чтобы обработчики дописали начатые задания.
+	ctxWorker, cancelWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+
+	worker := workers.NewWorker(store, repo, log, config.DeleteWorkers, deleteQueueInterval)
+	go func() {
+		worker.Run(ctxWorker)
+		close(workerDone)
+	}()
 
-	worker := workers.NewWorker(pool, store)
-	go worker.Run()
+	ctxPurge, cancelPurge := context.WithCancel(context.Background())
+	defer cancelPurge()
+
+	scheduler, err := initScheduler(config, store, checker, log)
+	if err != nil {
+		log.Fatal("failed to initialize scheduler", logger.Error(err))
+	}
+	go scheduler.Run(ctxPurge)
+
+	sinks, err := initEventSinks(config, store, log)
+	if err != nil {
+		log.Fatal("failed to initialize event sinks", logger.Error(err))
+	}
+	relay := workers.NewRelay(store, log, outboxRelayInterval, sinks...)
+	go relay.Run(ctxPurge)
+
+	sender := workers.NewWebhookSender(store, log, webhookSendInterval)
+	go sender.Run(ctxPurge)
 
 	router := handlers.NewRouter(handlers.Option{
-		Conf:    config,
-		Cache:   store,
-		Logger:  log,
-		Service: service,
-		Worker:  worker,
+		Conf:      config,
+		Cache:     store,
+		Logger:    log,
+		Service:   service,
+		Worker:    worker,
+		Scheduler: scheduler,
 	})
 
 	if config.UseGRPC {
@@ -132,10 +189,96 @@ func main() {
 
 		log.Info("http-server shutdown gracefully")
 	}
+
+	cancelWorker()
+	<-workerDone
+	log.Info("delete queue stopped")
+}
+
+// initURLChecker собирает проверки адресов из конфигурации, nil - проверки не настроены.
+func initURLChecker(cfg cfg.Config) (urlcheck.URLChecker, error) {
+	var chain urlcheck.Chain
+	if cfg.BlocklistPath != "" {
+		blocklist, err := urlcheck.NewBlocklist(cfg.BlocklistPath, blocklistReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, blocklist)
+	}
+
+	if cfg.SafeBrowsingURL != "" || cfg.SafeBrowsingAPIKey != "" {
+		endpoint := cfg.SafeBrowsingURL
+		if endpoint == "" {
+			endpoint = urlcheck.DefaultSafeBrowsingURL
+		}
+		chain = append(chain, urlcheck.NewSafeBrowsing(endpoint, cfg.SafeBrowsingAPIKey, safeBrowsingTimeout))
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// initEventSinks - приемники событий outbox: шина внутри процесса, вебхуки пользователей
+// и брокеры, если они настроены.
+func initEventSinks(cfg cfg.Config, store *storage.Storage, log logger.Logger) ([]events.Sink, error) {
+	bus := events.NewBus()
+	bus.Subscribe(func(_ context.Context, event storage.OutboxEvent) error {
+		log.Debug("link event", logger.String("type", event.Type), logger.String("alias", event.Link.Alias))
+		return nil
+	})
+
+	sinks := []events.Sink{bus, events.NewWebhookSink(store)}
+	if cfg.NATSURL != "" {
+		nats, err := events.NewNATS(cfg.NATSURL, cfg.NATSSubject, brokerTimeout)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, nats)
+	}
+	if cfg.KafkaRESTURL != "" {
+		sinks = append(sinks, events.NewKafkaREST(cfg.KafkaRESTURL, cfg.KafkaTopic, brokerTimeout))
+	}
+
+	return sinks, nil
+}
+
+// initScheduler - фоновые задачи по расписанию: очистка удаленных ссылок и, если проверки адресов
+// настроены, перепроверка существующих ссылок.
+func initScheduler(cfg cfg.Config, store *storage.Storage, checker urlcheck.URLChecker,
+	log logger.Logger) (*workers.Scheduler, error) {
+	scheduler := workers.NewScheduler(store, log)
+
+	purger := workers.NewPurger(store, cfg.DeleteRetention)
+	err := scheduler.Register("purge-deleted", jobSchedule(cfg.PurgeSchedule, cfg.PurgeInterval), purgeJobTimeout,
+		purger.Purge)
+	if err != nil {
+		return nil, err
+	}
+
+	if checker != nil {
+		rescanner := workers.NewRescanner(store, checker, log)
+		err = scheduler.Register("rescan-links", jobSchedule(cfg.RescanSchedule, cfg.RescanInterval), rescanJobTimeout,
+			rescanner.Rescan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scheduler, nil
+}
+
+// jobSchedule - расписание cron из конфигурации или, если оно не задано, запуск через interval.
+func jobSchedule(spec string, interval time.Duration) string {
+	if spec != "" {
+		return spec
+	}
+	return "@every " + interval.String()
 }
 
 func initStorage(cfg cfg.Config) (*storage.Storage, error) {
-	var storageOptions []storage.OptionsStorage
+	storageOptions := []storage.OptionsStorage{storage.WithDedupScope(storage.DedupScope(cfg.DedupScope))}
 	if cfg.DatabaseDSN != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"flag"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
@@ -23,6 +25,29 @@ type Config struct {
 	FileStoragePath string `json:"file_storage_path"`
 	DatabaseDSN     string `json:"database_dsn"`
 	DBPoolWorkers   int
+	DedupScope      string `json:"dedup_scope"`
+
+	DeleteGracePeriod time.Duration
+	DeleteRetention   time.Duration
+	PurgeInterval     time.Duration
+	PurgeSchedule     string `json:"purge_schedule"` // cron, пустое - каждые PurgeInterval
+	DeleteWorkers     int    `json:"delete_workers"`
+
+	DefaultRedirectCode int `json:"default_redirect_code"`
+	RedirectCacheMaxAge time.Duration
+
+	QRLogoPath string `json:"qr_logo_path"`
+
+	BlocklistPath      string `json:"blocklist_path"`
+	SafeBrowsingURL    string `json:"safe_browsing_url"`
+	SafeBrowsingAPIKey string `json:"safe_browsing_api_key"`
+	RescanInterval     time.Duration
+	RescanSchedule     string `json:"rescan_schedule"` // cron, пустое - каждые RescanInterval
+
+	NATSURL      string `json:"nats_url"`
+	NATSSubject  string `json:"nats_subject"`
+	KafkaRESTURL string `json:"kafka_rest_url"`
+	KafkaTopic   string `json:"kafka_topic"`
 
 	TrustedSubnet string `json:"trusted_subnet"`
 	UseGRPC       bool
@@ -62,6 +87,26 @@ func Load(envFiles ...string) (Config, error) {
 	cfg.FileStoragePath = cast.ToString(os.Getenv("FILE_STORAGE_PATH"))
 
 	cfg.DBPoolWorkers = cast.ToInt(os.Getenv("DB_POOL_WORKERS"))
+	cfg.DedupScope = cast.ToString(os.Getenv("DEDUP_SCOPE"))
+
+	cfg.DeleteGracePeriod = cast.ToDuration(os.Getenv("DELETE_GRACE_PERIOD"))
+	cfg.DeleteRetention = cast.ToDuration(os.Getenv("DELETE_RETENTION"))
+	cfg.PurgeInterval = cast.ToDuration(os.Getenv("PURGE_INTERVAL"))
+	cfg.PurgeSchedule = cast.ToString(os.Getenv("PURGE_SCHEDULE"))
+	cfg.DeleteWorkers = cast.ToInt(os.Getenv("DELETE_WORKERS"))
+
+	cfg.DefaultRedirectCode = cast.ToInt(os.Getenv("DEFAULT_REDIRECT_CODE"))
+	cfg.RedirectCacheMaxAge = cast.ToDuration(os.Getenv("REDIRECT_CACHE_MAX_AGE"))
+	cfg.QRLogoPath = cast.ToString(os.Getenv("QR_LOGO_PATH"))
+	cfg.BlocklistPath = cast.ToString(os.Getenv("URL_BLOCKLIST_PATH"))
+	cfg.SafeBrowsingURL = cast.ToString(os.Getenv("SAFE_BROWSING_URL"))
+	cfg.SafeBrowsingAPIKey = cast.ToString(os.Getenv("SAFE_BROWSING_API_KEY"))
+	cfg.RescanInterval = cast.ToDuration(os.Getenv("URL_RESCAN_INTERVAL"))
+	cfg.RescanSchedule = cast.ToString(os.Getenv("URL_RESCAN_SCHEDULE"))
+	cfg.NATSURL = cast.ToString(os.Getenv("EVENTS_NATS_URL"))
+	cfg.NATSSubject = cast.ToString(os.Getenv("EVENTS_NATS_SUBJECT"))
+	cfg.KafkaRESTURL = cast.ToString(os.Getenv("EVENTS_KAFKA_REST_URL"))
+	cfg.KafkaTopic = cast.ToString(os.Getenv("EVENTS_KAFKA_TOPIC"))
 
 	cfg.LogLevel = cast.ToString(os.Getenv("LOG_LEVEL"))
 	cfg.ServiceName = cast.ToString(os.Getenv("SERVICE_NAME"))
@@ -81,6 +126,24 @@ const (
 	defaultFileStoragePath = "/tmp/short-url-storage.json"
 	defaultDatabaseDSN     = ""
 	defaultDBPoolWorkers   = 250
+	defaultDedupScope      = "global"
+	defaultDeleteGrace     = 7 * 24 * time.Hour
+	defaultDeleteRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval   = time.Hour
+	defaultPurgeSchedule   = ""
+	defaultDeleteWorkers   = 4
+	defaultRedirectCode    = http.StatusTemporaryRedirect
+	defaultRedirectMaxAge  = 5 * time.Minute
+	defaultQRLogoPath      = ""
+	defaultBlocklistPath   = ""
+	defaultSafeBrowsingURL = ""
+	defaultSafeBrowsingKey = ""
+	defaultRescanInterval  = 24 * time.Hour
+	defaultRescanSchedule  = ""
+	defaultNATSURL         = ""
+	defaultNATSSubject     = "shortener"
+	defaultKafkaRESTURL    = ""
+	defaultKafkaTopic      = "link-events"
 	defaultTLSRequire      = ""
 	defaultConfigPath      = ""
 	defaultTrustedSubnet   = ""
@@ -94,6 +157,24 @@ func ParseConfig(cfg *Config) {
 	fileStoragePath := flag.String("f", defaultFileStoragePath, "determines where the data will be saved")
 	databaseDSN := flag.String("d", defaultDatabaseDSN, "defines the database connection address")
 	dbPoolWorkers := flag.Int("p", defaultDBPoolWorkers, "defines count of pool workers for db")
+	dedupScope := flag.String("dedup", defaultDedupScope, "scope of original url deduplication: global, user or none")
+	deleteGrace := flag.Duration("delete-grace", defaultDeleteGrace, "how long the owner can restore a deleted link")
+	deleteRetention := flag.Duration("delete-retention", defaultDeleteRetention, "how long deleted links are kept before purge")
+	purgeInterval := flag.Duration("purge-interval", defaultPurgeInterval, "how often deleted links are purged")
+	purgeSchedule := flag.String("purge-schedule", defaultPurgeSchedule, "cron schedule of deleted links purge, overrides purge-interval")
+	deleteWorkers := flag.Int("delete-workers", defaultDeleteWorkers, "how many workers process the link deletion queue")
+	redirectCode := flag.Int("redirect-code", defaultRedirectCode, "default redirect status for links without their own: 301, 302, 307 or 308")
+	redirectMaxAge := flag.Duration("redirect-max-age", defaultRedirectMaxAge, "how long clients may cache permanent redirects; a link edit reaches clients that cached the redirect only after it")
+	qrLogoPath := flag.String("qr-logo", defaultQRLogoPath, "path to png logo that can be placed in the center of qr codes")
+	blocklistPath := flag.String("blocklist", defaultBlocklistPath, "path to file with blocked domains and regex: patterns, reloaded on change")
+	safeBrowsingURL := flag.String("safe-browsing-url", defaultSafeBrowsingURL, "safe browsing api v4 compatible endpoint, google api if only key is set")
+	safeBrowsingKey := flag.String("safe-browsing-key", defaultSafeBrowsingKey, "safe browsing api key")
+	rescanInterval := flag.Duration("rescan-interval", defaultRescanInterval, "how often existing links are checked again for malicious urls")
+	rescanSchedule := flag.String("rescan-schedule", defaultRescanSchedule, "cron schedule of links rescan, overrides rescan-interval")
+	natsURL := flag.String("nats-url", defaultNATSURL, "nats server for link events, nats://host:port")
+	natsSubject := flag.String("nats-subject", defaultNATSSubject, "nats subject prefix, events go to <prefix>.<event type>")
+	kafkaRESTURL := flag.String("kafka-rest-url", defaultKafkaRESTURL, "kafka rest proxy for link events")
+	kafkaTopic := flag.String("kafka-topic", defaultKafkaTopic, "kafka topic for link events")
 	tlsRequire := flag.String("s", defaultTLSRequire, "server would be run on TLS")
 	configPath := flag.String("c", defaultConfigPath, "path to config file")
 	configPath = flag.String("config", *configPath, "path to config file")
@@ -124,6 +205,32 @@ func ParseConfig(cfg *Config) {
 	cfg.FileStoragePath = getEnvString("FILE_STORAGE_PATH", fileStoragePath)
 	cfg.DatabaseDSN = getEnvString("DATABASE_DSN", databaseDSN)
 	cfg.DBPoolWorkers = getEnvInt("DB_POOL_WORKERS", dbPoolWorkers)
+	cfg.DedupScope = getEnvString("DEDUP_SCOPE", dedupScope)
+	cfg.DeleteGracePeriod = getEnvDuration("DELETE_GRACE_PERIOD", deleteGrace)
+	cfg.DeleteRetention = getEnvDuration("DELETE_RETENTION", deleteRetention)
+	cfg.PurgeInterval = getEnvDuration("PURGE_INTERVAL", purgeInterval)
+	cfg.PurgeSchedule = getEnvString("PURGE_SCHEDULE", purgeSchedule)
+	cfg.DeleteWorkers = getEnvInt("DELETE_WORKERS", deleteWorkers)
+	if cfg.DeleteWorkers < 1 {
+		log.Fatalf("delete workers must be positive: %d", cfg.DeleteWorkers)
+	}
+	cfg.DefaultRedirectCode = getEnvInt("DEFAULT_REDIRECT_CODE", redirectCode)
+	cfg.RedirectCacheMaxAge = getEnvDuration("REDIRECT_CACHE_MAX_AGE", redirectMaxAge)
+	switch cfg.DefaultRedirectCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		log.Fatalf("unsupported default redirect code: %d", cfg.DefaultRedirectCode)
+	}
+	cfg.QRLogoPath = getEnvString("QR_LOGO_PATH", qrLogoPath)
+	cfg.BlocklistPath = getEnvString("URL_BLOCKLIST_PATH", blocklistPath)
+	cfg.SafeBrowsingURL = getEnvString("SAFE_BROWSING_URL", safeBrowsingURL)
+	cfg.SafeBrowsingAPIKey = getEnvString("SAFE_BROWSING_API_KEY", safeBrowsingKey)
+	cfg.RescanInterval = getEnvDuration("URL_RESCAN_INTERVAL", rescanInterval)
+	cfg.RescanSchedule = getEnvString("URL_RESCAN_SCHEDULE", rescanSchedule)
+	cfg.NATSURL = getEnvString("EVENTS_NATS_URL", natsURL)
+	cfg.NATSSubject = getEnvString("EVENTS_NATS_SUBJECT", natsSubject)
+	cfg.KafkaRESTURL = getEnvString("EVENTS_KAFKA_REST_URL", kafkaRESTURL)
+	cfg.KafkaTopic = getEnvString("EVENTS_KAFKA_TOPIC", kafkaTopic)
 	cfg.HTTP.EnableHTTPS = getEnvString("ENABLE_HTTPS", tlsRequire)
 	cfg.LogLevel = defaultLogLevel
 	cfg.ServiceName = defaultServiceName
@@ -153,6 +260,14 @@ func getEnvBool(key string, argumentValue *bool) bool {
 	return *argumentValue
 }
 
+func getEnvDuration(key string, argumentValue *time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err == nil {
+		return value
+	}
+	return *argumentValue
+}
+
 func loadConfigFromFile(configPath string) (*Config, error) {
 	f, err := os.Open(configPath)
 	if err != nil {
@@ -171,9 +286,29 @@ func loadConfigFromFile(configPath string) (*Config, error) {
 		FileStoragePath: defaultFileStoragePath,
 		DatabaseDSN:     defaultDatabaseDSN,
 		DBPoolWorkers:   defaultDBPoolWorkers,
-		ConfigPath:      defaultConfigPath,
-		LogLevel:        defaultLogLevel,
-		ServiceName:     defaultServiceName,
+		DedupScope:      defaultDedupScope,
+
+		DeleteGracePeriod: defaultDeleteGrace,
+		DeleteRetention:   defaultDeleteRetention,
+		PurgeInterval:     defaultPurgeInterval,
+		DeleteWorkers:     defaultDeleteWorkers,
+
+		DefaultRedirectCode: defaultRedirectCode,
+		RedirectCacheMaxAge: defaultRedirectMaxAge,
+
+		QRLogoPath: defaultQRLogoPath,
+
+		BlocklistPath:      defaultBlocklistPath,
+		SafeBrowsingURL:    defaultSafeBrowsingURL,
+		SafeBrowsingAPIKey: defaultSafeBrowsingKey,
+		RescanInterval:     defaultRescanInterval,
+
+		NATSSubject: defaultNATSSubject,
+		KafkaTopic:  defaultKafkaTopic,
+
+		ConfigPath:  defaultConfigPath,
+		LogLevel:    defaultLogLevel,
+		ServiceName: defaultServiceName,
 	}
 
 	if err = json.NewDecoder(f).Decode(&fileConfig); err != nil {
@@ -0,0 +1,189 @@
+// Package admin - операции администратора над ссылками в обход API пользователей:
+// поиск ссылки в любом состоянии, отключение, удаление и передача другому владельцу.
+// Используется утилитой cmd/shortenctl.
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/pkg/utils"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// DisabledReason - причина блокировки ссылки, отключенной администратором без указания причины
+const DisabledReason = "disabled by administrator"
+
+// Состояния ссылки.
+const (
+	StateActive  = "active"
+	StatePending = "pending" // еще не наступил active_from
+	StateExpired = "expired" // истек срок или исчерпан лимит переходов
+	StateBlocked = "blocked"
+	StateDeleted = "deleted"
+)
+
+// Link - ссылка в представлении администратора
+type Link struct {
+	Alias         string     `json:"alias"`
+	OriginalURL   string     `json:"original_url"`
+	UserID        string     `json:"user_id"`
+	State         string     `json:"state"`
+	BlockedReason string     `json:"blocked_reason,omitempty"`
+	Clicks        int        `json:"clicks"`
+	MaxClicks     int        `json:"max_clicks,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Stats - количество ссылок и пользователей
+type Stats struct {
+	URLs  int64 `json:"urls"`
+	Users int64 `json:"users"`
+}
+
+// Admin -
+type Admin struct {
+	store *storage.Storage
+}
+
+// New -
+func New(store *storage.Storage) *Admin {
+	return &Admin{
+		store: store,
+	}
+}
+
+// Lookup - ссылка в любом состоянии, в том числе удаленная
+func (a *Admin) Lookup(ctx context.Context, alias string) (Link, error) {
+	item, err := a.store.Lookup(ctx, alias)
+	if err != nil {
+		return Link{}, err
+	}
+	return newLink(alias, item), nil
+}
+
+// Disable - блокирует ссылку: вместо редиректа посетитель увидит страницу блокировки.
+// Пустая причина заменяется на DisabledReason.
+func (a *Admin) Disable(ctx context.Context, alias, reason string) (Link, error) {
+	if reason == "" {
+		reason = DisabledReason
+	}
+	return a.setBlocked(ctx, alias, reason)
+}
+
+// Enable - снимает блокировку, выставленную администратором или проверкой адресов
+func (a *Admin) Enable(ctx context.Context, alias string) (Link, error) {
+	return a.setBlocked(ctx, alias, "")
+}
+
+func (a *Admin) setBlocked(ctx context.Context, alias, reason string) (Link, error) {
+	item, err := a.store.SetBlocked(ctx, alias, reason)
+	if err != nil {
+		return Link{}, err
+	}
+	if err = a.save(map[string]storage.Item{alias: item}); err != nil {
+		return Link{}, err
+	}
+	return newLink(alias, item), nil
+}
+
+// Delete - помечает ссылку удаленной от имени владельца: владелец может восстановить ее
+// в течение льготного периода, затем ее окончательно удалит плановая очистка.
+func (a *Admin) Delete(ctx context.Context, alias string) (Link, error) {
+	item, err := a.store.Lookup(ctx, alias)
+	if err != nil {
+		return Link{}, err
+	}
+	if item.IsDeleted {
+		return newLink(alias, item), nil
+	}
+
+	if _, err = a.store.DeleteBatch(ctx, []string{alias}, item.UserID); err != nil {
+		return Link{}, err
+	}
+
+	item, err = a.store.Lookup(ctx, alias)
+	if err != nil {
+		return Link{}, err
+	}
+	if err = a.save(map[string]storage.Item{alias: item}); err != nil {
+		return Link{}, err
+	}
+	return newLink(alias, item), nil
+}
+
+// Transfer - передает ссылки пользователя from пользователю to: перечисленные в aliases
+// или все, если список пуст. Возвращает переданные сокращения.
+func (a *Admin) Transfer(ctx context.Context, from, to string, aliases []string) ([]string, error) {
+	moved, err := a.store.TransferLinks(ctx, from, to, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.store.File != nil && len(moved) > 0 {
+		items := make(map[string]storage.Item, len(moved))
+		for _, alias := range moved {
+			if items[alias], err = a.store.Lookup(ctx, alias); err != nil {
+				return nil, err
+			}
+		}
+		if err = a.save(items); err != nil {
+			return nil, err
+		}
+	}
+
+	return moved, nil
+}
+
+// Stats -
+func (a *Admin) Stats(ctx context.Context) (Stats, error) {
+	urls, users, err := a.store.GetStats(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{URLs: urls, Users: users}, nil
+}
+
+// save дописывает измененные ссылки в файл хранилища, если он используется.
+func (a *Admin) save(items map[string]storage.Item) error {
+	if a.store.File == nil {
+		return nil
+	}
+	return a.store.File.SaveToFile(items)
+}
+
+func newLink(alias string, item storage.Item) Link {
+	link := Link{
+		Alias:         alias,
+		OriginalURL:   item.Object,
+		UserID:        item.UserID,
+		State:         state(item),
+		BlockedReason: item.BlockedReason,
+		Clicks:        item.Clicks,
+		MaxClicks:     item.MaxClicks,
+		CreatedAt:     item.CreatedAt,
+		UpdatedAt:     item.UpdatedAt,
+	}
+	if item.IsDeleted && !item.DeletedAt.IsZero() {
+		link.DeletedAt = utils.Ptr(item.DeletedAt)
+	}
+	return link
+}
+
+// state - состояние ссылки с точки зрения посетителя.
+func state(item storage.Item) string {
+	switch {
+	case item.IsDeleted:
+		return StateDeleted
+	case item.BlockedReason != "":
+		return StateBlocked
+	case item.Expired() || item.ClicksExhausted():
+		return StateExpired
+	case item.ActiveFrom.After(time.Now()):
+		return StatePending
+	default:
+		return StateActive
+	}
+}
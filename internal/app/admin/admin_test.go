@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store, err := storage.NewStorage(storage.WithFileStorage(path))
+	require.NoError(t, err)
+	require.NoError(t, store.Set(ctx, map[string]storage.Item{
+		"aaaaaa": {Object: "https://ya.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://google.com", UserID: "user-a", MaxClicks: 1, Clicks: 1},
+	}))
+	a := New(store)
+
+	link, err := a.Lookup(ctx, "bbbbbb")
+	require.NoError(t, err)
+	require.Equal(t, StateExpired, link.State)
+
+	_, err = a.Lookup(ctx, "zzzzzz")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+
+	link, err = a.Disable(ctx, "aaaaaa", "")
+	require.NoError(t, err)
+	require.Equal(t, StateBlocked, link.State)
+	require.Equal(t, DisabledReason, link.BlockedReason)
+
+	link, err = a.Enable(ctx, "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, StateActive, link.State)
+
+	moved, err := a.Transfer(ctx, "user-a", "user-b", []string{"aaaaaa"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaaaaa"}, moved)
+
+	link, err = a.Delete(ctx, "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, StateDeleted, link.State)
+	require.Equal(t, "user-b", link.UserID)
+	require.NotNil(t, link.DeletedAt)
+	store.Close()
+
+	// Изменения попадают в файл и переживают перезапуск.
+	store, err = storage.NewStorage(storage.RestoreFile(ctx, path))
+	require.NoError(t, err)
+	defer store.Close()
+
+	link, err = New(store).Lookup(ctx, "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, StateDeleted, link.State)
+	require.Equal(t, "user-b", link.UserID)
+}
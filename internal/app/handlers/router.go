@@ -9,6 +9,7 @@ import (
 	"github.com/sonikq/url-shortener/internal/app/handlers/user"
 	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
 	"github.com/sonikq/url-shortener/internal/app/pkg/middlewares"
+	"github.com/sonikq/url-shortener/internal/app/pkg/openapi"
 	"github.com/sonikq/url-shortener/internal/app/services"
 	"github.com/sonikq/url-shortener/internal/app/workers"
 	"github.com/sonikq/url-shortener/pkg/storage"
@@ -21,11 +22,12 @@ type Handlers struct {
 
 // Option -
 type Option struct {
-	Conf    cfg.Config
-	Service *services.Service
-	Logger  logger.Logger
-	Cache   *storage.Storage
-	Worker  *workers.Worker
+	Conf      cfg.Config
+	Service   *services.Service
+	Logger    logger.Logger
+	Cache     *storage.Storage
+	Worker    *workers.Worker
+	Scheduler *workers.Scheduler
 }
 
 // NewRouter -
@@ -38,14 +40,19 @@ func NewRouter(option Option) *gin.Engine {
 	router.Use(middlewares.RequestResponseLogger(option.Logger))
 	router.Use(middlewares.CompressResponse(), middlewares.DecompressRequest())
 
+	// Каждый маршрут ниже описан в openapi.json, запросы проверяются по нему до обработчиков.
+	spec := openapi.MustLoad()
+	router.Use(middlewares.ValidateRequest(spec))
+
 	router.MaxMultipartMemory = 8 << 20
 
 	h := &Handlers{
 		UserHandler: user.New(&user.HandlerConfig{
-			Service: option.Service,
-			Logger:  option.Logger,
-			Conf:    option.Conf,
-			Worker:  option.Worker,
+			Service:   option.Service,
+			Logger:    option.Logger,
+			Conf:      option.Conf,
+			Worker:    option.Worker,
+			Scheduler: option.Scheduler,
 		}),
 	}
 
@@ -58,18 +65,52 @@ func NewRouter(option Option) *gin.Engine {
 	trusted := router.Group("/api/internal")
 	trusted.Use(middlewares.Truster(option.Conf))
 	trusted.GET("/stats", h.UserHandler.GetStats)
+	trusted.GET("/links/:id", h.UserHandler.LookupLink)
+	trusted.GET("/jobs", h.UserHandler.GetScheduledJobs)
+	trusted.GET("/jobs/:name/runs", h.UserHandler.GetScheduledJobRuns)
+	trusted.POST("/jobs/:name/run", h.UserHandler.RunScheduledJob)
 
 	router.POST("/", h.UserHandler.ShorteningLink)
 	router.POST("/api/shorten", h.UserHandler.ShorteningLinkJSON)
 	router.POST("/api/shorten/batch", h.UserHandler.ShorteningBatchLinks)
 
 	router.GET("/:id", h.UserHandler.GetFullLinkByID)
+	router.GET("/:id/*rest", h.UserHandler.GetFullLinkByID)
+	router.POST("/:id", h.UserHandler.GetFullLinkByID)
+	router.POST("/:id/*rest", h.UserHandler.GetFullLinkByID)
 	router.GET("/api/user/urls", h.UserHandler.GetBatchByUserID)
 
 	router.DELETE("/api/user/urls", h.UserHandler.DeleteBatchLinks)
+	router.GET("/api/user/jobs/:id", h.UserHandler.GetDeleteJob)
+
+	router.PATCH("/api/user/urls/:id", h.UserHandler.UpdateLink)
+	router.GET("/api/user/urls/:id/revisions", h.UserHandler.GetLinkRevisions)
+	router.GET("/api/user/urls/:id/stats", h.UserHandler.GetLinkStats)
+	router.GET("/api/user/urls/:id/qr", h.UserHandler.GetLinkQR)
+	router.POST("/api/user/urls/:id/revisions/:revision/restore", h.UserHandler.RestoreLinkRevision)
+
+	router.POST("/api/user/import", h.UserHandler.ImportLinks)
+	router.GET("/api/user/export", h.UserHandler.ExportLinks)
+
+	router.GET("/api/user/urls/deleted", h.UserHandler.GetDeletedLinks)
+	router.POST("/api/user/urls/:id/restore", h.UserHandler.RestoreLink)
+
+	router.POST("/api/user/collections", h.UserHandler.CreateCollection)
+	router.GET("/api/user/collections", h.UserHandler.GetCollections)
+	router.DELETE("/api/user/collections/:name", h.UserHandler.DeleteCollection)
+	router.GET("/api/user/collections/:name/export", h.UserHandler.ExportCollection)
+
+	router.POST("/api/user/webhooks", h.UserHandler.CreateWebhook)
+	router.GET("/api/user/webhooks", h.UserHandler.GetWebhooks)
+	router.DELETE("/api/user/webhooks/:id", h.UserHandler.DeleteWebhook)
+	router.GET("/api/user/webhooks/:id/deliveries", h.UserHandler.GetWebhookDeliveries)
+	router.POST("/api/user/webhooks/:id/deliveries/:delivery/retry", h.UserHandler.RetryWebhookDelivery)
 
 	router.GET("/ping", h.UserHandler.PingDB)
 
+	router.GET("/api/openapi.json", gin.WrapF(spec.ServeJSON))
+	router.GET("/api/docs", gin.WrapF(spec.ServeDocs))
+
 	router.GET("/debug/pprof/", gin.WrapF(pprof.Index))
 	router.GET("/debug/pprof/heap", gin.WrapF(pprof.Handler("heap").ServeHTTP))
 	router.GET("/debug/pprof/cmdline", gin.WrapF(pprof.Cmdline))
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	cfg "github.com/sonikq/url-shortener/configs/app"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/openapi"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/sonikq/url-shortener/internal/app/services"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	return NewRouter(Option{
+		Conf:    cfg.Config{BaseURL: "http://localhost:8080"},
+		Service: services.NewService(repositories.NewRepository(store)),
+		Logger:  logger.New(logger.LevelError, "router-test"),
+		Cache:   store,
+	})
+}
+
+// TestNewRouter_OpenAPI - спецификация описывает ровно те маршруты, что есть в роутере.
+func TestNewRouter_OpenAPI(t *testing.T) {
+	spec := openapi.MustLoad()
+
+	routes := NewRouter(Option{Logger: logger.New(logger.LevelError, "router-test")}).Routes()
+	registered := make(map[openapi.Route]bool, len(routes))
+	for _, route := range routes {
+		r := openapi.Route{Method: route.Method, Path: openapi.TemplatePath(route.Path)}
+		registered[r] = true
+
+		_, ok := spec.Operation(r.Method, r.Path)
+		assert.True(t, ok, "%s %s is not described in openapi.json", r.Method, r.Path)
+	}
+
+	for _, route := range spec.Routes() {
+		assert.True(t, registered[route], "%s %s from openapi.json is not registered in the router", route.Method, route.Path)
+	}
+}
+
+func TestNewRouter_Docs(t *testing.T) {
+	router := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "/api/shorten/batch")
+}
+
+func TestNewRouter_ValidateRequest(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		want    int
+		message string
+	}{
+		{
+			name: "valid body", method: http.MethodPost, target: "/api/shorten",
+			body: `{"url": "https://example.com/valid"}`, want: http.StatusCreated,
+		},
+		{
+			name: "missing field", method: http.MethodPost, target: "/api/shorten",
+			body: `{"title": "no url"}`, want: http.StatusBadRequest, message: "body.url is required",
+		},
+		{
+			name: "wrong item type", method: http.MethodPost, target: "/api/shorten/batch",
+			body: `[{"correlation_id": 1, "original_url": "https://example.com"}]`, want: http.StatusBadRequest,
+			message: "body[0].correlation_id must be a string",
+		},
+		{
+			name: "invalid query before auth", method: http.MethodGet, target: "/api/user/urls?limit=0",
+			want: http.StatusBadRequest, message: `query parameter "limit" must be at least 1`,
+		},
+		{
+			name: "valid query reaches handler", method: http.MethodGet, target: "/api/user/urls?limit=10",
+			want: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+			if tt.message == "" {
+				return
+			}
+
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, map[string]string{
+				"статус":          "fail",
+				"источник ошибки": "request",
+				"описание ошибки": tt.message,
+			}, body)
+		})
+	}
+}
+
+// TestNewRouter_ValidationReadError - ошибка чтения тела отдается в том же формате, что и ошибки обработчиков.
+func TestNewRouter_ValidationReadError(t *testing.T) {
+	router := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/shorten", iotest.ErrReader(errors.New("connection reset")))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]string{
+		"статус":          "fail",
+		"источник ошибки": "request",
+		"описание ошибки": "Error in reading body",
+	}, body)
+}
@@ -1,10 +1,27 @@
 package user
 
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
 // Константы ошибок и вспомогательные константы
 const (
-	StatusKey          = "статус"
-	ErrSourceKey       = "источник ошибки"
-	ErrMsgKey          = "описание ошибки"
+	StatusKey          = models.StatusKey
+	StatusFail         = models.StatusFail
+	ErrSourceKey       = models.ErrSourceKey
+	ErrMsgKey          = models.ErrMsgKey
 	TimeLimitExceedErr = "превышен лимит времени"
 	CtxTimeout         = 5
+	BulkCtxTimeout     = 30 * 60 // импорт и выгрузка всех ссылок пользователя
+
+	PasswordHeader = "X-Link-Password"
+	PasswordField  = "password"
+
+	VariantCookie       = "ab_variant"
+	VariantCookieMaxAge = 30 * 24 * time.Hour
+
+	// PreviewSuffix - суффикс сокращения, открывающий страницу предпросмотра вместо редиректа.
+	PreviewSuffix = "+"
 )
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/reader"
+)
+
+// CreateCollection создание пустой коллекции ссылок пользователя.
+//
+// POST /api/user/collections
+//
+// Content-Type: application/json.
+//
+// В запросе - имя коллекции {"name": string}.
+func (h *Handler) CreateCollection(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	bodyBytes, err := reader.GetBody(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error in reading body"})
+		h.log.Error("Invalid request data", logger.Error(err))
+		return
+	}
+
+	var reqBody user.CreateCollectionRequestBody
+
+	unmarshalErr := json.Unmarshal(bodyBytes, &reqBody)
+	if unmarshalErr != nil {
+		h.invalidJSON(ctx, unmarshalErr)
+		return
+	}
+
+	request := user.CreateCollectionRequest{
+		UserID: userID,
+		Body:   reqBody,
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.CreateCollection(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusCreated:
+			ctx.JSON(result.Code, result.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
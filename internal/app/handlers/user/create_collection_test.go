@@ -0,0 +1,73 @@
+package user
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CreateCollection(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.POST("/api/user/collections", handler.CreateCollection)
+
+	tests := []struct {
+		name         string
+		body         string
+		mockSetup    func()
+		expectedCode int
+	}{
+		{
+			name:         "invalid json",
+			body:         `{"name":`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "created",
+			body: `{"name":"spring"}`,
+			mockSetup: func() {
+				mockServiceManager.On("CreateCollection", mock.Anything, mock.Anything).Return(user.CreateCollectionResponse{
+					Code:     http.StatusCreated,
+					Status:   "success",
+					Response: &user.Collection{Name: "spring"},
+				})
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "already exists",
+			body: `{"name":"spring"}`,
+			mockSetup: func() {
+				mockServiceManager.On("CreateCollection", mock.Anything, mock.Anything).Return(user.CreateCollectionResponse{
+					Code:   http.StatusConflict,
+					Status: "fail",
+					Error:  &models.Err{Source: "storage", Message: models.ErrAlreadyExists.Error()},
+				})
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServiceManager.ExpectedCalls = nil
+			tc.mockSetup()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/user/collections", bytes.NewBufferString(tc.body))
+			cookies := httptest.NewRecorder()
+			require.NoError(t, auth.SetUserCookie(cookies))
+			req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
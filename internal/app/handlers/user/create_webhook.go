@@ -0,0 +1,75 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/reader"
+)
+
+// CreateWebhook подписка на события ссылок пользователя: link.created, link.clicked,
+// link.expired и link.deleted. Доставки подписываются HMAC-SHA256 ключом secret,
+// который отдается только в ответе на этот запрос.
+//
+// POST /api/user/webhooks
+//
+// Content-Type: application/json.
+//
+// В запросе - {"url": string, "events": [string]}, без events - все события.
+// Адрес подписки должен вести на публичный хост: loopback, частные и link-local адреса,
+// а также локальные имена (localhost, *.internal) отклоняются. Редиректы подписчика не выполняются.
+func (h *Handler) CreateWebhook(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	bodyBytes, err := reader.GetBody(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error in reading body"})
+		h.log.Error("Invalid request data", logger.Error(err))
+		return
+	}
+
+	var reqBody user.CreateWebhookRequestBody
+
+	unmarshalErr := json.Unmarshal(bodyBytes, &reqBody)
+	if unmarshalErr != nil {
+		h.invalidJSON(ctx, unmarshalErr)
+		return
+	}
+
+	request := user.CreateWebhookRequest{
+		UserID: userID,
+		Body:   reqBody,
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.CreateWebhook(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusCreated:
+			ctx.JSON(result.Code, result.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
 	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
 	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
 	"github.com/sonikq/url-shortener/internal/app/pkg/reader"
@@ -16,7 +19,8 @@ import (
 //
 // Content-Type: application/json.
 //
-// В запросе - массив строк(сокращенных ссылок) [string].
+// В запросе - массив строк(сокращенных ссылок) [string]. Удаление ставится в очередь,
+// в ответе 202 - задание, за статусом которого можно следить по GET /api/user/jobs/:id.
 func (h *Handler) DeleteBatchLinks(ctx *gin.Context) {
 	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
 	if err != nil {
@@ -36,17 +40,40 @@ func (h *Handler) DeleteBatchLinks(ctx *gin.Context) {
 
 	unmarshalErr := json.Unmarshal(bodyBytes, &reqBody)
 	if unmarshalErr != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "invalid request json data"})
-		h.log.Error("Invalid request data", logger.Error(err))
+		h.invalidJSON(ctx, unmarshalErr)
 		return
 	}
 
-	err = h.worker.DeleteURLs(reqBody, userID)
-	ctx.Status(http.StatusAccepted)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error while deleting links"})
-		h.log.Error("worker.DeleteURLs", logger.Error(err))
-		return
+	request := user.DeleteBatchLinksRequest{
+		UserID: userID,
+		Body:   []user.DeleteBatchBody{{URLS: reqBody}},
 	}
 
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.EnqueueDeleteLinks(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusAccepted:
+			h.worker.Notify()
+			ctx.Header("Location", jobLocation(result.Response.ID))
+			ctx.JSON(result.Code, result.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
+
+// jobLocation - адрес статуса задания на удаление.
+func jobLocation(id string) string {
+	return "/api/user/jobs/" + id
 }
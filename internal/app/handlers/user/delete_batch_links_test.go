@@ -1,9 +1,78 @@
 package user
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandler_DeleteBatchLinks(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.DELETE("/api/user/urls", handler.DeleteBatchLinks)
+
+	tests := []struct {
+		name             string
+		body             string
+		mockSetup        func()
+		expectedCode     int
+		expectedLocation string
+	}{
+		{
+			name:         "invalid json",
+			body:         `{"aaaaaa"`,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "job created",
+			body: `["aaaaaa","bbbbbb"]`,
+			mockSetup: func() {
+				mockServiceManager.On("EnqueueDeleteLinks", mock.Anything, mock.MatchedBy(func(request user.DeleteBatchLinksRequest) bool {
+					return len(request.Body) == 1 && len(request.Body[0].URLS) == 2
+				})).Return(user.DeleteJobResponse{
+					Code:     http.StatusAccepted,
+					Status:   "success",
+					Response: &user.DeleteJob{ID: "job", Status: "pending", URLs: 2},
+				})
+			},
+			expectedCode:     http.StatusAccepted,
+			expectedLocation: "/api/user/jobs/job",
+		},
+		{
+			name: "empty list",
+			body: `[]`,
+			mockSetup: func() {
+				mockServiceManager.On("EnqueueDeleteLinks", mock.Anything, mock.Anything).Return(user.DeleteJobResponse{
+					Code:   http.StatusBadRequest,
+					Status: "fail",
+					Error:  &models.Err{Source: "request", Message: "no links to delete"},
+				})
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServiceManager.ExpectedCalls = nil
+			tc.mockSetup()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/api/user/urls", strings.NewReader(tc.body))
+			cookies := httptest.NewRecorder()
+			require.NoError(t, auth.SetUserCookie(cookies))
+			req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+			r.ServeHTTP(w, req)
 
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedLocation, w.Header().Get("Location"))
+		})
+	}
 }
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// DeleteCollection удаляет коллекцию вместе со всеми ее ссылками.
+//
+// DELETE /api/user/collections/:name
+//
+// Content-Type: text/plain.
+//
+// Ссылки удаляются через ту же очередь, что и в DeleteBatchLinks, и могут быть восстановлены
+// в течение льготного периода. Если в коллекции были ссылки, в ответе - задание на их удаление.
+func (h *Handler) DeleteCollection(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	request := user.DeleteCollectionRequest{
+		UserID: userID,
+		Name:   ctx.Param("name"),
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.DeleteCollection(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusAccepted:
+			if len(result.Response) == 0 {
+				ctx.Status(result.Code)
+				return
+			}
+
+			job := h.service.IUserService.EnqueueDeleteLinks(c, user.DeleteBatchLinksRequest{
+				UserID: userID,
+				Body:   []user.DeleteBatchBody{{URLS: result.Response}},
+			})
+			if job.Code != http.StatusAccepted {
+				ctx.JSON(job.Code, gin.H{
+					StatusKey: job.Status,
+					ErrMsgKey: job.Error.Message,
+				})
+				return
+			}
+			h.worker.Notify()
+			ctx.Header("Location", jobLocation(job.Response.ID))
+			ctx.JSON(job.Code, job.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// DeleteWebhook удаление подписки вместе с журналом ее доставок.
+//
+// DELETE /api/user/webhooks/:id
+//
+// Content-Type: text/plain.
+func (h *Handler) DeleteWebhook(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	request := user.DeleteWebhookRequest{
+		UserID: userID,
+		ID:     ctx.Param("id"),
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.DeleteWebhook(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusNoContent:
+			ctx.Status(result.Code)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// ExportCollection выгрузка всех неудаленных ссылок коллекции одним JSON-файлом.
+//
+// GET /api/user/collections/:name/export
+//
+// Content-Type: text/plain.
+func (h *Handler) ExportCollection(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	request := user.ExportCollectionRequest{
+		UserID:  userID,
+		Name:    ctx.Param("name"),
+		BaseURL: h.config.BaseURL,
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.ExportCollection(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusOK:
+			ctx.Header("Content-Disposition", `attachment; filename="`+request.Name+`.json"`)
+			ctx.JSON(result.Code, result.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// ExportLinks потоковая выгрузка всех неудаленных ссылок пользователя в формате импорта.
+//
+// GET /api/user/export?format=
+//
+// Content-Type: text/plain.
+//
+// format - csv или jsonl; без него формат берется из Accept, по умолчанию jsonl.
+func (h *Handler) ExportLinks(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	format := linkio.FormatJSONL
+	if rawFormat := ctx.Query("format"); rawFormat != "" {
+		format, err = linkio.ParseFormat(rawFormat)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				StatusKey: StatusFail,
+				ErrMsgKey: err.Error(),
+			})
+			return
+		}
+	} else if accepted, errAccept := linkio.ParseFormat(ctx.GetHeader("Accept")); errAccept == nil {
+		format = accepted
+	}
+
+	rows, err := linkio.NewWriter(ctx.Writer, format)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			StatusKey: StatusFail,
+			ErrMsgKey: err.Error(),
+		})
+		return
+	}
+
+	ctx.Header("Content-Type", linkio.ContentType(format))
+	ctx.Header("Content-Disposition", `attachment; filename="links.`+format+`"`)
+
+	c, cancel := context.WithTimeout(ctx, BulkCtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.ExportLinks(c, user.ExportLinksRequest{
+		UserID: userID,
+		Rows:   rows,
+	})
+	if result.Code == http.StatusOK {
+		ctx.Status(result.Code)
+		return
+	}
+
+	// Начатую выгрузку уже не заменить ответом с ошибкой, клиент получит оборванный файл.
+	h.log.Error("failed to export links", logger.String("user", userID), logger.String("error", result.Error.Message))
+	if ctx.Writer.Written() {
+		return
+	}
+
+	ctx.Writer.Header().Del("Content-Type")
+	ctx.Writer.Header().Del("Content-Disposition")
+	if c.Err() != nil {
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+		return
+	}
+	ctx.JSON(result.Code, gin.H{
+		StatusKey: result.Status,
+		ErrMsgKey: result.Error.Message,
+	})
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,11 +12,16 @@ import (
 	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
 )
 
-// GetBatchByUserID получение всех ссылок для пользователя, пользователь определяется по bearer токену.
+// GetBatchByUserID получение ссылок пользователя постранично, пользователь определяется по bearer токену.
 //
-// GET /api/user/urls
+// GET /api/user/urls?limit=&cursor=&sort=&q=&tag=&collection=&deleted=
 //
 // Content-Type: text/plain.
+//
+// limit - размер страницы (по умолчанию 100), sort - created_at или -created_at (по умолчанию),
+// q - подстрока original_url, tag - тег ссылки, collection - имя коллекции,
+// deleted - all (по умолчанию), active или deleted.
+// Курсор следующей страницы возвращается в заголовке X-Next-Cursor и в Link с rel="next".
 func (h *Handler) GetBatchByUserID(ctx *gin.Context) {
 	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
 	if err != nil {
@@ -24,9 +30,25 @@ func (h *Handler) GetBatchByUserID(ctx *gin.Context) {
 		return
 	}
 
+	var limit int
+	if rawLimit := ctx.Query("limit"); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+	}
+
 	request := user.GetBatchByUserIDRequest{
-		UserID:  userID,
-		BaseURL: h.config.BaseURL,
+		UserID:     userID,
+		BaseURL:    h.config.BaseURL,
+		Limit:      limit,
+		Cursor:     ctx.Query("cursor"),
+		Sort:       ctx.Query("sort"),
+		Search:     ctx.Query("q"),
+		Tag:        ctx.Query("tag"),
+		Collection: ctx.Query("collection"),
+		Deleted:    ctx.Query("deleted"),
 	}
 
 	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
@@ -46,6 +68,15 @@ func (h *Handler) GetBatchByUserID(ctx *gin.Context) {
 				ErrMsgKey: "no content found",
 			})
 		case http.StatusOK:
+			if result.NextCursor != "" {
+				next := *ctx.Request.URL
+				query := next.Query()
+				query.Set("cursor", result.NextCursor)
+				next.RawQuery = query.Encode()
+
+				ctx.Header("X-Next-Cursor", result.NextCursor)
+				ctx.Header("Link", "<"+next.RequestURI()+`>; rel="next"`)
+			}
 			ctx.JSON(result.Code, result.Response)
 		default:
 			ctx.JSON(result.Code, gin.H{
@@ -1,8 +1,65 @@
 package user
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandler_GetBatchByUserID(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/api/user/urls", handler.GetBatchByUserID)
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedCode   int
+		expectedCursor string
+	}{
+		{
+			name:         "invalid limit",
+			query:        "?limit=abc",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "page with next cursor",
+			query: "?limit=1&sort=created_at",
+			mockSetup: func() {
+				mockServiceManager.On("GetBatchByUserID", mock.Anything, mock.MatchedBy(func(request user.GetBatchByUserIDRequest) bool {
+					return request.Limit == 1 && request.Sort == "created_at"
+				})).Return(user.GetBatchByUserIDResponse{
+					Code:       http.StatusOK,
+					Status:     "success",
+					Response:   []user.BatchByUserID{{ShortURL: "http://localhost:8080/abcdef", OriginalURL: "https://ya.ru"}},
+					NextCursor: "next",
+				})
+			},
+			expectedCode:   http.StatusOK,
+			expectedCursor: "next",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServiceManager.ExpectedCalls = nil
+			tc.mockSetup()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/user/urls"+tc.query, nil)
+			cookies := httptest.NewRecorder()
+			require.NoError(t, auth.SetUserCookie(cookies))
+			req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedCursor, w.Header().Get("X-Next-Cursor"))
+		})
+	}
 }
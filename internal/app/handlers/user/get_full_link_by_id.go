@@ -3,6 +3,8 @@ package user
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,12 +15,57 @@ import (
 //
 // GET /:id
 //
+// GET /:id/*rest
 // Content-Type: text/plain.
+//
+// Код редиректа (301, 302, 307 или 308) задается ссылкой, иначе берется из конфигурации.
+// Постоянные редиректы разрешено кэшировать на RedirectCacheMaxAge, временные - нет.
+// Владелец может изменить адрес ссылки (PATCH /api/user/urls/:id), а клиент, закэшировавший
+// редирект, узнает об этом только по истечении RedirectCacheMaxAge, поэтому по умолчанию срок короткий.
+// Ссылки с лимитом переходов (max_clicks) не кэшируются вовсе: после исчерпания лимита
+// отдается 410, до момента активации (active_from) - 403.
+// Правила маршрутизации ссылки выбирают адрес по User-Agent, Accept-Language и Referer.
+// Ссылка с вариантами A/B-теста выбирает адрес по весам и не кэшируется; закрепленный
+// вариант запоминается в cookie ab_variant на VariantCookieMaxAge.
+// Хвост пути (rest) и query запроса дописываются к адресу, только если это разрешено ссылкой.
+//
+// GET /:id+
+//
+// Content-Type: text/html.
+//
+// Страница предпросмотра: адрес назначения, название и дата создания ссылки вместо редиректа,
+// переход при этом не засчитывается. Ссылка с флагом interstitial всегда открывается
+// промежуточной страницей "Вы покидаете сайт".
+//
+// POST /:id
+//
+// POST /:id/*rest
+// Content-Type: application/x-www-form-urlencoded.
+//
+// Для ссылки с паролем без верного пароля отдается HTML-форма ввода (401).
+// Пароль передается заголовком X-Link-Password или полем password формы,
+// после отправки формы выполняется редирект 303. Частые ошибки ввода блокируют ссылку (429).
 func (h *Handler) GetFullLinkByID(ctx *gin.Context) {
-	linkID := ctx.Param("id")
+	linkID, preview := strings.CutSuffix(ctx.Param("id"), PreviewSuffix)
 
 	request := user.GetFullLinkByIDRequest{
-		ShortLinkID: linkID,
+		ShortLinkID:         linkID,
+		DefaultRedirectCode: h.config.DefaultRedirectCode,
+		PathSuffix:          ctx.Param("rest"),
+		RawQuery:            ctx.Request.URL.RawQuery,
+		Password:            ctx.GetHeader(PasswordHeader),
+		UserAgent:           ctx.GetHeader("User-Agent"),
+		AcceptLanguage:      ctx.GetHeader("Accept-Language"),
+		Referrer:            ctx.GetHeader("Referer"),
+		Preview:             preview,
+	}
+	if ctx.Request.Method == http.MethodPost && request.Password == "" {
+		request.Password = ctx.PostForm(PasswordField)
+	}
+	if cookie, err := ctx.Cookie(VariantCookie); err == nil {
+		if variant, err := strconv.Atoi(cookie); err == nil {
+			request.Variant = &variant
+		}
 	}
 
 	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
@@ -31,9 +78,48 @@ func (h *Handler) GetFullLinkByID(ctx *gin.Context) {
 			StatusKey: TimeLimitExceedErr,
 		})
 	default:
+		if result.Routed {
+			ctx.Header("Vary", "User-Agent, Accept-Language, Referer")
+		}
+		if result.Sticky {
+			http.SetCookie(ctx.Writer, &http.Cookie{
+				Name:     VariantCookie,
+				Value:    strconv.Itoa(*result.Variant),
+				Path:     "/" + linkID,
+				MaxAge:   int(VariantCookieMaxAge.Seconds()),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		if isRedirect(result.Code) && (preview || result.Interstitial) {
+			renderLinkPage(ctx, linkPage{
+				ShortURL:    h.config.BaseURL + "/" + linkID,
+				Destination: *result.Response,
+				Title:       result.Title,
+				CreatedAt:   result.CreatedAt,
+				Preview:     preview,
+			})
+			return
+		}
+		if result.Protected {
+			h.protectedLink(ctx, request, result)
+			return
+		}
+
 		switch result.Code {
-		case http.StatusTemporaryRedirect:
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+			ctx.Header("Location", *result.Response)
+			ctx.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(h.config.RedirectCacheMaxAge.Seconds())))
+			if result.Limited || result.Variant != nil {
+				ctx.Header("Cache-Control", "private, no-store")
+			}
+			ctx.Status(result.Code)
+		case http.StatusFound, http.StatusTemporaryRedirect:
 			ctx.Header("Location", *result.Response)
+			ctx.Header("Cache-Control", "private, no-cache")
+			if result.Limited || result.Variant != nil {
+				ctx.Header("Cache-Control", "private, no-store")
+			}
 			ctx.Status(result.Code)
 		case http.StatusGone:
 			ctx.Status(result.Code)
@@ -45,3 +131,40 @@ func (h *Handler) GetFullLinkByID(ctx *gin.Context) {
 		}
 	}
 }
+
+// protectedLink отвечает на запрос защищенной паролем ссылки: редиректы не кэшируются.
+func (h *Handler) protectedLink(ctx *gin.Context, request user.GetFullLinkByIDRequest, result user.GetFullLinkByIDResponse) {
+	switch {
+	case isRedirect(result.Code):
+		code := result.Code
+		if ctx.Request.Method == http.MethodPost {
+			code = http.StatusSeeOther
+		}
+		ctx.Header("Location", *result.Response)
+		ctx.Header("Cache-Control", "private, no-store")
+		ctx.Status(code)
+	case result.Code == http.StatusUnauthorized:
+		var message string
+		if request.Password != "" {
+			message = "Неверный пароль."
+		}
+		renderPasswordForm(ctx, result.Code, message)
+	case result.Code == http.StatusTooManyRequests:
+		ctx.Header("Retry-After", retryAfter(result.RetryAfter))
+		renderPasswordForm(ctx, result.Code, "Слишком много неудачных попыток, повторите позже.")
+	default:
+		ctx.JSON(result.Code, gin.H{
+			StatusKey: result.Status,
+			ErrMsgKey: result.Error.Message,
+		})
+	}
+}
+
+// isRedirect - код ответа ссылки является кодом редиректа.
+func isRedirect(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
@@ -1,8 +1,271 @@
 package user
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandler_GetFullLinkByID(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+	handler.config.DefaultRedirectCode = http.StatusTemporaryRedirect
+	handler.config.RedirectCacheMaxAge = time.Hour
+
+	r.GET("/:id", handler.GetFullLinkByID)
+
+	destination := "https://yandex.ru"
+
+	tests := []struct {
+		name                 string
+		code                 int
+		expectedCacheControl string
+	}{
+		{
+			name:                 "temporary redirect",
+			code:                 http.StatusTemporaryRedirect,
+			expectedCacheControl: "private, no-cache",
+		},
+		{
+			name:                 "found",
+			code:                 http.StatusFound,
+			expectedCacheControl: "private, no-cache",
+		},
+		{
+			name:                 "moved permanently",
+			code:                 http.StatusMovedPermanently,
+			expectedCacheControl: "public, max-age=3600",
+		},
+		{
+			name:                 "permanent redirect",
+			code:                 http.StatusPermanentRedirect,
+			expectedCacheControl: "public, max-age=3600",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServiceManager.ExpectedCalls = nil
+			mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+				ShortLinkID:         "abcdef",
+				DefaultRedirectCode: http.StatusTemporaryRedirect,
+			}).Return(user.GetFullLinkByIDResponse{
+				Code:     tc.code,
+				Status:   "success",
+				Response: &destination,
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/abcdef", nil)
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, destination, w.Header().Get("Location"))
+			require.Equal(t, tc.expectedCacheControl, w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestHandler_GetFullLinkByIDPassthrough(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/:id", handler.GetFullLinkByID)
+	r.GET("/:id/*rest", handler.GetFullLinkByID)
+
+	destination := "https://example.com/docs/guide?utm_source=x"
+	mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+		ShortLinkID: "abcdef",
+		PathSuffix:  "/guide",
+		RawQuery:    "utm_source=x",
+	}).Return(user.GetFullLinkByIDResponse{
+		Code:     http.StatusTemporaryRedirect,
+		Status:   "success",
+		Response: &destination,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/abcdef/guide?utm_source=x", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	require.Equal(t, destination, w.Header().Get("Location"))
+}
+
+func TestHandler_GetFullLinkByIDProtected(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/:id", handler.GetFullLinkByID)
+	r.POST("/:id", handler.GetFullLinkByID)
+
+	destination := "https://example.com/internal"
+	mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+		ShortLinkID: "abcdef",
+	}).Return(user.GetFullLinkByIDResponse{
+		Code:      http.StatusUnauthorized,
+		Status:    "fail",
+		Error:     &models.Err{Message: models.ErrPasswordRequired.Error()},
+		Protected: true,
+	})
+	mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+		ShortLinkID: "abcdef",
+		Password:    "secret",
+	}).Return(user.GetFullLinkByIDResponse{
+		Code:      http.StatusTemporaryRedirect,
+		Status:    "success",
+		Response:  &destination,
+		Protected: true,
+	})
+	mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+		ShortLinkID: "abcdef",
+		Password:    "guess",
+	}).Return(user.GetFullLinkByIDResponse{
+		Code:       http.StatusTooManyRequests,
+		Status:     "fail",
+		Error:      &models.Err{Message: models.ErrTooManyAttempts.Error()},
+		Protected:  true,
+		RetryAfter: 1500 * time.Millisecond,
+	})
+
+	t.Run("password form", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/abcdef", nil)
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		require.Contains(t, w.Body.String(), `name="password"`)
+	})
+
+	t.Run("password header", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/abcdef", nil)
+		req.Header.Set(PasswordHeader, "secret")
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+		require.Equal(t, destination, w.Header().Get("Location"))
+		require.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("password form submitted", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/abcdef", strings.NewReader("password=secret"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, destination, w.Header().Get("Location"))
+	})
+
+	t.Run("too many attempts", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/abcdef", strings.NewReader("password=guess"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "2", w.Header().Get("Retry-After"))
+	})
+}
+
+func TestHandler_GetFullLinkByIDStickyVariant(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/:id", handler.GetFullLinkByID)
+
+	destination := "https://b.example.com"
+	variant := 1
+	mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+		ShortLinkID: "abcdef",
+		Variant:     &variant,
+	}).Return(user.GetFullLinkByIDResponse{
+		Code:     http.StatusPermanentRedirect,
+		Status:   "success",
+		Response: &destination,
+		Variant:  &variant,
+		Sticky:   true,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/abcdef", nil)
+	req.AddCookie(&http.Cookie{Name: VariantCookie, Value: "1"})
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusPermanentRedirect, w.Code)
+	require.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, VariantCookie, cookies[0].Name)
+	require.Equal(t, "1", cookies[0].Value)
+	require.Equal(t, "/abcdef", cookies[0].Path)
+}
+
+func TestHandler_GetFullLinkByIDPreview(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/:id", handler.GetFullLinkByID)
+
+	destination := `https://example.com/?q="><script>alert(1)</script>`
+	createdAt := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+	mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+		ShortLinkID: "abcdef",
+		Preview:     true,
+	}).Return(user.GetFullLinkByIDResponse{
+		Code:      http.StatusPermanentRedirect,
+		Status:    "success",
+		Response:  &destination,
+		Title:     "<b>Отчет</b>",
+		CreatedAt: createdAt,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/abcdef+", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Location"))
+	require.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+	require.Contains(t, w.Header().Get("Content-Type"), "text/html")
+
+	body := w.Body.String()
+	require.Contains(t, body, "Предпросмотр ссылки")
+	require.Contains(t, body, "05.03.2024")
+	require.Contains(t, body, "&lt;b&gt;Отчет&lt;/b&gt;")
+	require.NotContains(t, body, "<script>")
+	require.NotContains(t, body, "<b>")
+}
+
+func TestHandler_GetFullLinkByIDInterstitial(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/:id", handler.GetFullLinkByID)
+
+	destination := "https://example.com/docs"
+	mockServiceManager.On("GetFullLinkByID", mock.Anything, user.GetFullLinkByIDRequest{
+		ShortLinkID: "abcdef",
+	}).Return(user.GetFullLinkByIDResponse{
+		Code:         http.StatusFound,
+		Status:       "success",
+		Response:     &destination,
+		Interstitial: true,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/abcdef", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Location"))
+
+	body := w.Body.String()
+	require.Contains(t, body, "Вы покидаете")
+	require.Contains(t, body, `href="https://example.com/docs"`)
+	require.NotContains(t, body, "Создана")
 }
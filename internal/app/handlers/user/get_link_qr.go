@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// GetLinkQR получение QR-кода сокращенной ссылки для печати.
+//
+// GET /api/user/urls/:id/qr?format=&size=&margin=&level=&logo=
+//
+// Content-Type: text/plain.
+//
+// format - png (по умолчанию) или svg, size - сторона изображения в пикселях (по умолчанию 256),
+// margin - белая рамка в модулях кода (по умолчанию 4), level - коррекция ошибок L, M (по умолчанию), Q или H,
+// logo=true - логотип из конфигурации (QR_LOGO_PATH) в центре кода.
+func (h *Handler) GetLinkQR(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	request := user.GetLinkQRRequest{
+		UserID:      userID,
+		ShortLinkID: ctx.Param("id"),
+		BaseURL:     h.config.BaseURL,
+		LogoPath:    h.config.QRLogoPath,
+		Format:      ctx.Query("format"),
+		Level:       ctx.Query("level"),
+	}
+
+	if rawSize := ctx.Query("size"); rawSize != "" {
+		request.Size, err = strconv.Atoi(rawSize)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "size must be an integer"})
+			return
+		}
+	}
+	if rawMargin := ctx.Query("margin"); rawMargin != "" {
+		margin, errMargin := strconv.Atoi(rawMargin)
+		if errMargin != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "margin must be an integer"})
+			return
+		}
+		request.Margin = &margin
+	}
+	if rawLogo := ctx.Query("logo"); rawLogo != "" {
+		request.Logo, err = strconv.ParseBool(rawLogo)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "logo must be a boolean"})
+			return
+		}
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.GetLinkQR(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusOK:
+			ctx.Data(result.Code, result.Response.ContentType, result.Response.Image)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetLinkQR(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/api/user/urls/:id/qr", handler.GetLinkQR)
+
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+	margin := 2
+	mockServiceManager.On("GetLinkQR", mock.Anything, mock.MatchedBy(func(request user.GetLinkQRRequest) bool {
+		return request.ShortLinkID == "abcdef" && request.Format == "svg" && request.Size == 512 &&
+			request.Margin != nil && *request.Margin == margin && request.Level == "H" && request.Logo
+	})).Return(user.GetLinkQRResponse{
+		Code:     http.StatusOK,
+		Status:   "success",
+		Response: &user.LinkQR{ContentType: "image/svg+xml", Image: svg},
+	})
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{
+			name:         "svg",
+			query:        "?format=svg&size=512&margin=2&level=H&logo=true",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid size",
+			query:        "?size=big",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid logo",
+			query:        "?logo=maybe",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/user/urls/abcdef/qr"+tc.query, nil)
+			cookies := httptest.NewRecorder()
+			require.NoError(t, auth.SetUserCookie(cookies))
+			req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				require.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+				require.Equal(t, svg, w.Body.Bytes())
+			}
+		})
+	}
+}
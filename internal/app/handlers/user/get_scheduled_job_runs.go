@@ -0,0 +1,59 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/workers"
+)
+
+// Размер выборки истории запусков фоновой задачи.
+const (
+	defaultJobRunsLimit = 20
+	maxJobRunsLimit     = 100
+)
+
+// GetScheduledJobRuns история запусков фоновой задачи от новых к старым.
+//
+// GET /api/internal/jobs/:name/runs?limit=
+//
+// Content-Type: text/plain.
+//
+// limit - размер выборки (по умолчанию 20, не больше 100).
+func (h *Handler) GetScheduledJobRuns(ctx *gin.Context) {
+	if h.scheduler == nil {
+		h.schedulerError(ctx, workers.ErrSchedulerStopped)
+		return
+	}
+
+	limit := defaultJobRunsLimit
+	if rawLimit := ctx.Query("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(limit, maxJobRunsLimit)
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	runs, err := h.scheduler.Runs(c, ctx.Param("name"), limit)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		if err != nil {
+			h.schedulerError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, runs)
+	}
+}
@@ -0,0 +1,59 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/workers"
+)
+
+// GetScheduledJobs список фоновых задач планировщика с расписанием и последним запуском.
+//
+// GET /api/internal/jobs
+//
+// Content-Type: text/plain.
+func (h *Handler) GetScheduledJobs(ctx *gin.Context) {
+	if h.scheduler == nil {
+		h.schedulerError(ctx, workers.ErrSchedulerStopped)
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	jobs, err := h.scheduler.Jobs(c)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		if err != nil {
+			h.schedulerError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, jobs)
+	}
+}
+
+// schedulerError отвечает на ошибку планировщика подходящим кодом.
+func (h *Handler) schedulerError(ctx *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, models.ErrJobNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, models.ErrJobRunning):
+		code = http.StatusConflict
+	case errors.Is(err, workers.ErrSchedulerStopped):
+		code = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(code, gin.H{
+		StatusKey: StatusFail,
+		ErrMsgKey: err.Error(),
+	})
+}
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_GetWebhookDeliveries(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.GET("/api/user/webhooks/:id/deliveries", handler.GetWebhookDeliveries)
+
+	tests := []struct {
+		name         string
+		query        string
+		mockSetup    func()
+		expectedCode int
+	}{
+		{
+			name:         "invalid limit",
+			query:        "?limit=-1",
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "dead letters",
+			query: "?status=dead&limit=10",
+			mockSetup: func() {
+				mockServiceManager.On("GetWebhookDeliveries", mock.Anything, mock.MatchedBy(func(request user.GetWebhookDeliveriesRequest) bool {
+					return request.WebhookID == "hook" && request.Status == "dead" && request.Limit == 10
+				})).Return(user.GetWebhookDeliveriesResponse{
+					Code:     http.StatusOK,
+					Status:   "success",
+					Response: []user.WebhookDelivery{{ID: 1, Event: "link.created", Status: "dead", Attempts: 8}},
+				})
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "webhook not found",
+			mockSetup: func() {
+				mockServiceManager.On("GetWebhookDeliveries", mock.Anything, mock.Anything).Return(user.GetWebhookDeliveriesResponse{
+					Code:   http.StatusNotFound,
+					Status: "fail",
+					Error:  &models.Err{Source: "storage", Message: models.ErrWebhookNotFound.Error()},
+				})
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServiceManager.ExpectedCalls = nil
+			tc.mockSetup()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/user/webhooks/hook/deliveries"+tc.query, nil)
+			cookies := httptest.NewRecorder()
+			require.NoError(t, auth.SetUserCookie(cookies))
+			req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
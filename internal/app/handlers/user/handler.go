@@ -9,26 +9,29 @@ import (
 
 // HandlerConfig -
 type HandlerConfig struct {
-	Conf    cfg.Config
-	Logger  logger.Logger
-	Service *services.Service
-	Worker  *workers.Worker
+	Conf      cfg.Config
+	Logger    logger.Logger
+	Service   *services.Service
+	Worker    *workers.Worker
+	Scheduler *workers.Scheduler
 }
 
 // Handler -
 type Handler struct {
-	config  cfg.Config
-	log     logger.Logger
-	service *services.Service
-	worker  *workers.Worker
+	config    cfg.Config
+	log       logger.Logger
+	service   *services.Service
+	worker    *workers.Worker
+	scheduler *workers.Scheduler
 }
 
 // New -
 func New(cfg *HandlerConfig) *Handler {
 	return &Handler{
-		config:  cfg.Conf,
-		log:     cfg.Logger,
-		service: cfg.Service,
-		worker:  cfg.Worker,
+		config:    cfg.Conf,
+		log:       cfg.Logger,
+		service:   cfg.Service,
+		worker:    cfg.Worker,
+		scheduler: cfg.Scheduler,
 	}
 }
@@ -0,0 +1,85 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// ImportLinks массовый импорт ссылок пользователя, например при переезде с другого сервиса.
+//
+// POST /api/user/import?format=
+//
+// Content-Type: text/csv или application/x-ndjson.
+//
+// Тело читается потоком: CSV с заголовком из колонок alias, original_url, title, note, tags,
+// collection, redirect_code, max_clicks, clicks, created_at (обязательна только original_url)
+// или JSON Lines с теми же полями. format (csv или jsonl) задает формат, если Content-Type другой.
+// Сокращение из файла сохраняется, если оно свободно, иначе выдается новое; владелец всех ссылок -
+// пользователь запроса. В ответе - количество импортированных, переименованных и ошибочных строк
+// и строки с ошибками и новыми сокращениями.
+func (h *Handler) ImportLinks(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	format := ctx.Query("format")
+	if format == "" {
+		format = ctx.ContentType()
+	}
+	format, err = linkio.ParseFormat(format)
+	if err != nil {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{
+			StatusKey: StatusFail,
+			ErrMsgKey: err.Error(),
+		})
+		return
+	}
+
+	rows, err := linkio.NewReader(ctx.Request.Body, format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			StatusKey:    StatusFail,
+			ErrSourceKey: "request",
+			ErrMsgKey:    err.Error(),
+		})
+		return
+	}
+
+	request := user.ImportLinksRequest{
+		UserID:  userID,
+		BaseURL: h.config.BaseURL,
+		Rows:    rows,
+	}
+
+	c, cancel := context.WithTimeout(ctx, BulkCtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.ImportLinks(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusOK:
+			ctx.JSON(result.Code, result.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey:  result.Status,
+				ErrMsgKey:  result.Error.Message,
+				"response": result.Response,
+			})
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ImportLinks(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+	r.POST("/api/user/import", handler.ImportLinks)
+
+	mockServiceManager.On("ImportLinks", mock.Anything, mock.MatchedBy(func(request user.ImportLinksRequest) bool {
+		return request.UserID != "" && !request.KeepOwners
+	})).Return(user.ImportLinksResponse{
+		Code:     http.StatusOK,
+		Status:   "success",
+		Response: &user.ImportReport{Imported: 1, Rows: []user.ImportedRow{}},
+	})
+
+	tests := []struct {
+		name         string
+		target       string
+		contentType  string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "csv",
+			target:       "/api/user/import",
+			contentType:  "text/csv",
+			body:         "original_url\nhttps://ya.ru\n",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "format in query",
+			target:       "/api/user/import?format=jsonl",
+			contentType:  "text/plain",
+			body:         `{"original_url":"https://ya.ru"}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unsupported content type",
+			target:       "/api/user/import",
+			contentType:  "application/json",
+			body:         `[]`,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "invalid csv header",
+			target:       "/api/user/import",
+			contentType:  "text/csv",
+			body:         "url\nhttps://ya.ru\n",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			cookies := httptest.NewRecorder()
+			require.NoError(t, auth.SetUserCookie(cookies))
+			req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_ExportLinks(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+	r.GET("/api/user/export", handler.ExportLinks)
+
+	mockServiceManager.On("ExportLinks", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		rows := args.Get(1).(user.ExportLinksRequest).Rows
+		require.NoError(t, rows.Write(linkio.Row{Alias: "abc", OriginalURL: "https://ya.ru"}))
+		require.NoError(t, rows.Flush())
+	}).Return(user.ExportLinksResponse{
+		Code:     http.StatusOK,
+		Status:   "success",
+		Response: &user.ExportReport{Exported: 1},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/export", nil)
+	req.Header.Set("Accept", "text/csv")
+	cookies := httptest.NewRecorder()
+	require.NoError(t, auth.SetUserCookie(cookies))
+	req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), "abc,https://ya.ru,")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/user/export?format=xml", nil)
+	req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
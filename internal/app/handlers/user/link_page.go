@@ -0,0 +1,50 @@
+package user
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// linkPage - данные страницы предпросмотра и промежуточной страницы перехода.
+type linkPage struct {
+	ShortURL    string
+	Destination string
+	Title       string
+	CreatedAt   time.Time
+	Preview     bool // предпросмотр по суффиксу "+", иначе страница "Вы покидаете сайт"
+}
+
+// linkPageTemplate - все значения экранируются html/template, в том числе адрес в href.
+var linkPageTemplate = template.Must(template.New("link").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta name="referrer" content="no-referrer">
+<title>{{if .Preview}}Предпросмотр ссылки{{else}}Переход по ссылке{{end}}</title>
+</head>
+<body>
+{{if .Preview}}<h1>Предпросмотр ссылки</h1>
+<p>Сокращенная ссылка <code>{{.ShortURL}}</code> ведет на адрес:</p>
+{{else}}<h1>Вы покидаете {{.ShortURL}}</h1>
+<p>Ссылка ведет на внешний адрес:</p>
+{{end}}<p><a href="{{.Destination}}" rel="noopener noreferrer nofollow">{{.Destination}}</a></p>
+{{if .Title}}<p>Название: {{.Title}}</p>
+{{end}}{{if not .CreatedAt.IsZero}}<p>Создана: <time datetime="{{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.CreatedAt.Format "02.01.2006"}}</time></p>
+{{end}}<p><a href="{{.Destination}}" rel="noopener noreferrer nofollow">Перейти</a></p>
+</body>
+</html>
+`))
+
+// renderLinkPage отдает страницу ссылки вместо редиректа, страница не кэшируется.
+func renderLinkPage(ctx *gin.Context, page linkPage) {
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := linkPageTemplate.Execute(ctx.Writer, page); err != nil {
+		_ = ctx.Error(err)
+	}
+}
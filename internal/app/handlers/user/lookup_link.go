@@ -0,0 +1,45 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+)
+
+// LookupLink получение ссылки в любом состоянии для администратора.
+//
+// GET /api/internal/links/:id
+//
+// Content-Type: text/plain.
+//
+// Состояние ссылки (active, pending, expired, blocked, deleted) определяется сервисом,
+// переход по ссылке при этом не засчитывается и события не пишутся.
+func (h *Handler) LookupLink(ctx *gin.Context) {
+	request := user.LookupLinkRequest{
+		ShortLinkID: ctx.Param("id"),
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.LookupLink(c, request)
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusOK:
+			ctx.JSON(result.Code, result.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
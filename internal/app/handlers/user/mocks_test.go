@@ -56,12 +56,122 @@ func (m *MockServiceManager) GetBatchByUserID(ctx context.Context, request user.
 	return args.Get(0).(user.GetBatchByUserIDResponse)
 }
 
+func (m *MockServiceManager) LookupLink(ctx context.Context, request user.LookupLinkRequest) user.LookupLinkResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.LookupLinkResponse)
+}
+
 func (m *MockServiceManager) GetStats(ctx context.Context) user.GetStatsResponse {
 	args := m.Called(ctx)
 	return args.Get(0).(user.GetStatsResponse)
 }
 
+func (m *MockServiceManager) UpdateLink(ctx context.Context, request user.UpdateLinkRequest) user.UpdateLinkResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.UpdateLinkResponse)
+}
+
+func (m *MockServiceManager) GetLinkRevisions(ctx context.Context, request user.GetLinkRevisionsRequest) user.GetLinkRevisionsResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.GetLinkRevisionsResponse)
+}
+
+func (m *MockServiceManager) GetLinkStats(ctx context.Context, request user.GetLinkStatsRequest) user.GetLinkStatsResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.GetLinkStatsResponse)
+}
+
+func (m *MockServiceManager) GetLinkQR(ctx context.Context, request user.GetLinkQRRequest) user.GetLinkQRResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.GetLinkQRResponse)
+}
+
+func (m *MockServiceManager) RestoreLinkRevision(ctx context.Context, request user.RestoreLinkRevisionRequest) user.UpdateLinkResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.UpdateLinkResponse)
+}
+
+func (m *MockServiceManager) RestoreLink(ctx context.Context, request user.RestoreLinkRequest) user.UpdateLinkResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.UpdateLinkResponse)
+}
+
+func (m *MockServiceManager) GetDeletedLinks(ctx context.Context, request user.GetDeletedLinksRequest) user.GetDeletedLinksResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.GetDeletedLinksResponse)
+}
+
 func (m *MockServiceManager) PingDB(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
+
+func (m *MockServiceManager) CreateCollection(ctx context.Context, request user.CreateCollectionRequest) user.CreateCollectionResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.CreateCollectionResponse)
+}
+
+func (m *MockServiceManager) GetCollections(ctx context.Context, request user.GetCollectionsRequest) user.GetCollectionsResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.GetCollectionsResponse)
+}
+
+func (m *MockServiceManager) DeleteCollection(ctx context.Context, request user.DeleteCollectionRequest) user.DeleteCollectionResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.DeleteCollectionResponse)
+}
+
+func (m *MockServiceManager) ExportCollection(ctx context.Context, request user.ExportCollectionRequest) user.ExportCollectionResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.ExportCollectionResponse)
+}
+
+func (m *MockServiceManager) DeleteBatchLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteBatchLinksResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.DeleteBatchLinksResponse)
+}
+
+func (m *MockServiceManager) EnqueueDeleteLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteJobResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.DeleteJobResponse)
+}
+
+func (m *MockServiceManager) GetDeleteJob(ctx context.Context, request user.GetDeleteJobRequest) user.DeleteJobResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.DeleteJobResponse)
+}
+
+func (m *MockServiceManager) ImportLinks(ctx context.Context, request user.ImportLinksRequest) user.ImportLinksResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.ImportLinksResponse)
+}
+
+func (m *MockServiceManager) ExportLinks(ctx context.Context, request user.ExportLinksRequest) user.ExportLinksResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.ExportLinksResponse)
+}
+
+func (m *MockServiceManager) CreateWebhook(ctx context.Context, request user.CreateWebhookRequest) user.CreateWebhookResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.CreateWebhookResponse)
+}
+
+func (m *MockServiceManager) GetWebhooks(ctx context.Context, request user.GetWebhooksRequest) user.GetWebhooksResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.GetWebhooksResponse)
+}
+
+func (m *MockServiceManager) DeleteWebhook(ctx context.Context, request user.DeleteWebhookRequest) user.DeleteWebhookResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.DeleteWebhookResponse)
+}
+
+func (m *MockServiceManager) GetWebhookDeliveries(ctx context.Context, request user.GetWebhookDeliveriesRequest) user.GetWebhookDeliveriesResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.GetWebhookDeliveriesResponse)
+}
+
+func (m *MockServiceManager) RetryWebhookDelivery(ctx context.Context, request user.RetryWebhookDeliveryRequest) user.RetryWebhookDeliveryResponse {
+	args := m.Called(ctx, request)
+	return args.Get(0).(user.RetryWebhookDeliveryResponse)
+}
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"html/template"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// passwordForm - страница ввода пароля защищенной ссылки, отправляется POST-запросом на тот же адрес.
+var passwordForm = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Ссылка защищена паролем</title>
+</head>
+<body>
+<form method="post">
+<p>Ссылка защищена паролем.</p>
+{{if .}}<p role="alert">{{.}}</p>{{end}}
+<input type="password" name="` + PasswordField + `" autofocus required>
+<button type="submit">Перейти</button>
+</form>
+</body>
+</html>
+`))
+
+// renderPasswordForm отдает форму ввода пароля с кодом code и сообщением об ошибке, если оно есть.
+func renderPasswordForm(ctx *gin.Context, code int, message string) {
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(code)
+	if err := passwordForm.Execute(ctx.Writer, message); err != nil {
+		_ = ctx.Error(err)
+	}
+}
+
+// retryAfter - значение заголовка Retry-After в целых секундах.
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
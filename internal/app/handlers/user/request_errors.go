@@ -0,0 +1,37 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// invalidJSON отвечает 400 с описанием, что именно не так с JSON в теле запроса.
+func (h *Handler) invalidJSON(ctx *gin.Context, err error) {
+	h.log.Error("Invalid request data", logger.Error(err))
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		StatusKey:    StatusFail,
+		ErrSourceKey: "request",
+		ErrMsgKey:    jsonErrorMessage(err),
+	})
+}
+
+// jsonErrorMessage - понятное клиенту описание ошибки json.Unmarshal.
+func jsonErrorMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed json at offset %d: %s", syntaxErr.Offset, syntaxErr)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("field %q must not be json %s", typeErr.Field, typeErr.Value)
+	case errors.As(err, &typeErr):
+		return fmt.Sprintf("request body must not be json %s", typeErr.Value)
+	default:
+		return "invalid request json data: " + err.Error()
+	}
+}
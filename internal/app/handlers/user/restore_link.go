@@ -0,0 +1,39 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// RestoreLink отмена удаления ссылки владельцем в пределах льготного периода.
+//
+// POST /api/user/urls/:id/restore
+//
+// Content-Type: text/plain.
+func (h *Handler) RestoreLink(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	request := user.RestoreLinkRequest{
+		UserID:      userID,
+		ShortLinkID: ctx.Param("id"),
+		BaseURL:     h.config.BaseURL,
+		GracePeriod: h.config.DeleteGracePeriod,
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.RestoreLink(c, request)
+	h.writeUpdateLinkResult(c, ctx, result)
+}
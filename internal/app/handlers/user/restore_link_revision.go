@@ -0,0 +1,47 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+)
+
+// RestoreLinkRevision возврат ссылки к одному из прежних original_url.
+// Текущее значение при этом само попадает в историю.
+//
+// POST /api/user/urls/:id/revisions/:revision/restore
+//
+// Content-Type: text/plain.
+func (h *Handler) RestoreLinkRevision(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	revisionID, err := strconv.ParseInt(ctx.Param("revision"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision id"})
+		return
+	}
+
+	request := user.RestoreLinkRevisionRequest{
+		UserID:      userID,
+		ShortLinkID: ctx.Param("id"),
+		BaseURL:     h.config.BaseURL,
+		RevisionID:  revisionID,
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.RestoreLinkRevision(c, request)
+	h.writeUpdateLinkResult(c, ctx, result)
+}
@@ -0,0 +1,34 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/workers"
+)
+
+// RunScheduledJob внеочередной запуск фоновой задачи. Ответ 202 не дожидается завершения,
+// результат появится в истории запусков; 409 - задача уже выполняется.
+//
+// POST /api/internal/jobs/:name/run
+//
+// Content-Type: text/plain.
+func (h *Handler) RunScheduledJob(ctx *gin.Context) {
+	if h.scheduler == nil {
+		h.schedulerError(ctx, workers.ErrSchedulerStopped)
+		return
+	}
+
+	name := ctx.Param("name")
+	if err := h.scheduler.Trigger(name); err != nil {
+		h.schedulerError(ctx, err)
+		return
+	}
+
+	h.log.Info("scheduled job triggered manually", logger.String("job", name))
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"job":     name,
+		"trigger": workers.TriggerManual,
+	})
+}
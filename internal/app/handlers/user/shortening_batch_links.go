@@ -37,8 +37,7 @@ func (h *Handler) ShorteningBatchLinks(ctx *gin.Context) {
 
 	unmarshalErr := json.Unmarshal(bodyBytes, &reqBody)
 	if unmarshalErr != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "invalid request json data, cannot unmarshal into Go-struct"})
-		h.log.Error("Invalid request data", logger.Error(err))
+		h.invalidJSON(ctx, unmarshalErr)
 		return
 	}
 
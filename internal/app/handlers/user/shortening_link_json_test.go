@@ -1,9 +1,103 @@
 package user
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandler_ShorteningLinkJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		result       *user.ShorteningLinkJSONResponse
+		expectedCode int
+		expectedMsg  string
+	}{
+		{
+			name:         "truncated json",
+			body:         `{"url": "https://example.com"`,
+			expectedCode: http.StatusBadRequest,
+			expectedMsg:  "unexpected end of JSON input",
+		},
+		{
+			name:         "syntax error",
+			body:         `{"url" "https://example.com"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedMsg:  "malformed json at offset 8",
+		},
+		{
+			name:         "wrong field type",
+			body:         `{"url": 5}`,
+			expectedCode: http.StatusBadRequest,
+			expectedMsg:  `field "url" must not be json number`,
+		},
+		{
+			name:         "wrong body type",
+			body:         `["https://example.com"]`,
+			expectedCode: http.StatusBadRequest,
+			expectedMsg:  "request body must not be json array",
+		},
+		{
+			name: "invalid url",
+			body: `{"url": "javascript:alert(1)"}`,
+			result: &user.ShorteningLinkJSONResponse{
+				Code:   http.StatusBadRequest,
+				Status: "fail",
+				Error:  &models.Err{Source: "request", Message: "url scheme must be http or https"},
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedMsg:  "url scheme must be http or https",
+		},
+		{
+			name: "created",
+			body: `{"url": "https://example.com"}`,
+			result: &user.ShorteningLinkJSONResponse{
+				Code:     http.StatusCreated,
+				Status:   "success",
+				Response: user.ShortenLinkJSONResponseBody{Result: "http://localhost:8080/abcdef"},
+			},
+			expectedCode: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mockServiceManager, handler := NewTestHandler()
+
+			r.POST("/api/shorten", handler.ShorteningLinkJSON)
+
+			if tt.result != nil {
+				mockServiceManager.On("ShorteningLinkJSON", mock.Anything, mock.Anything).Return(*tt.result)
+			}
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedCode, w.Code)
+			if tt.result == nil {
+				mockServiceManager.AssertNotCalled(t, "ShorteningLinkJSON", mock.Anything, mock.Anything)
+			}
+			if tt.expectedMsg == "" {
+				return
+			}
 
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Equal(t, "fail", body[StatusKey])
+			require.Contains(t, body[ErrMsgKey], tt.expectedMsg)
+			if tt.result == nil {
+				require.Equal(t, "request", body[ErrSourceKey])
+			}
+		})
+	}
 }
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/reader"
+)
+
+// UpdateLink изменение атрибутов сокращенной ссылки ее владельцем.
+//
+// PATCH /api/user/urls/:id
+//
+// Content-Type: application/json.
+//
+// В запросе - изменяемые поля ссылки {"url": string, "title": string, "note": string,
+// "tags": [string], "collection": string}, отсутствующие поля не меняются.
+// Пустое имя коллекции убирает ссылку из коллекции.
+func (h *Handler) UpdateLink(ctx *gin.Context) {
+	userID, err := auth.VerifyUserToken(ctx.Writer, ctx.Request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "cant get cookie"})
+		h.log.Error("userID not found, or invalid", logger.Error(err))
+		return
+	}
+
+	bodyBytes, err := reader.GetBody(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error in reading body"})
+		h.log.Error("Invalid request data", logger.Error(err))
+		return
+	}
+
+	var reqBody user.UpdateLinkRequestBody
+
+	unmarshalErr := json.Unmarshal(bodyBytes, &reqBody)
+	if unmarshalErr != nil {
+		h.invalidJSON(ctx, unmarshalErr)
+		return
+	}
+
+	request := user.UpdateLinkRequest{
+		UserID:      userID,
+		ShortLinkID: ctx.Param("id"),
+		BaseURL:     h.config.BaseURL,
+		Body:        reqBody,
+	}
+
+	c, cancel := context.WithTimeout(ctx, CtxTimeout*time.Second)
+	defer cancel()
+
+	result := h.service.IUserService.UpdateLink(c, request)
+	h.writeUpdateLinkResult(c, ctx, result)
+}
+
+func (h *Handler) writeUpdateLinkResult(c context.Context, ctx *gin.Context, result user.UpdateLinkResponse) {
+	select {
+	case <-c.Done():
+		ctx.JSON(http.StatusRequestTimeout, gin.H{
+			StatusKey: TimeLimitExceedErr,
+		})
+	default:
+		switch result.Code {
+		case http.StatusOK:
+			ctx.JSON(result.Code, result.Response)
+		default:
+			ctx.JSON(result.Code, gin.H{
+				StatusKey: result.Status,
+				ErrMsgKey: result.Error.Message,
+			})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_UpdateLink(t *testing.T) {
+	r, mockServiceManager, handler := NewTestHandler()
+
+	r.PATCH("/api/user/urls/:id", handler.UpdateLink)
+
+	tests := []struct {
+		name         string
+		body         string
+		withCookie   bool
+		mockSetup    func()
+		expectedCode int
+	}{
+		{
+			name:         "without cookie",
+			body:         `{"url":"https://ya.ru"}`,
+			withCookie:   false,
+			mockSetup:    func() {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid json",
+			body:         `{"url":`,
+			withCookie:   true,
+			mockSetup:    func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:       "successful update",
+			body:       `{"url":"https://ya.ru"}`,
+			withCookie: true,
+			mockSetup: func() {
+				mockServiceManager.On("UpdateLink", mock.Anything, mock.Anything).Return(user.UpdateLinkResponse{
+					Code:     http.StatusOK,
+					Status:   "success",
+					Response: &user.UpdatedLink{ShortURL: "http://localhost:8080/abcdef", OriginalURL: "https://ya.ru"},
+				})
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "foreign link",
+			body:       `{"url":"https://ya.ru"}`,
+			withCookie: true,
+			mockSetup: func() {
+				mockServiceManager.On("UpdateLink", mock.Anything, mock.Anything).Return(user.UpdateLinkResponse{
+					Code:   http.StatusForbidden,
+					Status: "fail",
+					Error:  &models.Err{Source: "storage", Message: models.ErrNotOwner.Error()},
+				})
+			},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServiceManager.ExpectedCalls = nil
+			tc.mockSetup()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPatch, "/api/user/urls/abcdef", strings.NewReader(tc.body))
+			if tc.withCookie {
+				cookies := httptest.NewRecorder()
+				require.NoError(t, auth.SetUserCookie(cookies))
+				req.Header.Set("Cookie", cookies.Header().Get("Set-Cookie"))
+			}
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
@@ -2,6 +2,14 @@ package models
 
 import "errors"
 
+// Ключи JSON-ответа с ошибкой, общие для обработчиков и middleware
+const (
+	StatusKey    = "статус"
+	StatusFail   = "fail"
+	ErrSourceKey = "источник ошибки"
+	ErrMsgKey    = "описание ошибки"
+)
+
 // Err - структур для представления ошибок
 type Err struct {
 	Source  string
@@ -13,4 +21,27 @@ var (
 	ErrAlreadyExists  = errors.New("URL already exists")
 	ErrGetDeletedLink = errors.New("deleted Link cant be retrieved")
 	ErrGenerateCookie = errors.New("cant generate cookie")
+	ErrLinkNotFound   = errors.New("link not found")
+	ErrAliasTaken     = errors.New("short link is already taken")
+	ErrNotOwner       = errors.New("link belongs to another user")
+
+	ErrRestorePeriodExpired = errors.New("deleted link can no longer be restored")
+	ErrCollectionNotFound   = errors.New("collection not found")
+
+	ErrPasswordRequired = errors.New("link is protected by password")
+	ErrWrongPassword    = errors.New("wrong link password")
+	ErrTooManyAttempts  = errors.New("too many password attempts")
+
+	ErrClicksExhausted = errors.New("link click limit reached")
+	ErrLinkNotActive   = errors.New("link is not active yet")
+	ErrLinkExpired     = errors.New("link has expired")
+
+	ErrMaliciousURL = errors.New("url is considered malicious")
+	ErrLinkBlocked  = errors.New("link is disabled as malicious")
+
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
 )
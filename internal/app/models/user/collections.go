@@ -0,0 +1,75 @@
+package user
+
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// CreateCollectionRequest -
+type CreateCollectionRequest struct {
+	UserID string
+	Body   CreateCollectionRequestBody
+}
+
+// CreateCollectionRequestBody -
+type CreateCollectionRequestBody struct {
+	Name string `json:"name"`
+}
+
+// CreateCollectionResponse -
+type CreateCollectionResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *Collection
+}
+
+// GetCollectionsRequest -
+type GetCollectionsRequest struct {
+	UserID string
+}
+
+// GetCollectionsResponse -
+type GetCollectionsResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response []Collection
+}
+
+// Collection - именованная группа ссылок пользователя
+type Collection struct {
+	Name      string    `json:"name"`
+	Links     int       `json:"links"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeleteCollectionRequest -
+type DeleteCollectionRequest struct {
+	UserID string
+	Name   string
+}
+
+// DeleteCollectionResponse -
+type DeleteCollectionResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response []string    // сокращения ссылок коллекции, которые нужно удалить
+}
+
+// ExportCollectionRequest -
+type ExportCollectionRequest struct {
+	UserID  string
+	Name    string
+	BaseURL string
+}
+
+// ExportCollectionResponse -
+type ExportCollectionResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response []BatchByUserID
+}
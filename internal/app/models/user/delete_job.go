@@ -0,0 +1,32 @@
+package user
+
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// GetDeleteJobRequest -
+type GetDeleteJobRequest struct {
+	UserID string
+	JobID  string
+}
+
+// DeleteJobResponse -
+type DeleteJobResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *DeleteJob  `json:"response"`
+}
+
+// DeleteJob - задание на удаление ссылок
+type DeleteJob struct {
+	ID        string    `json:"job_id"`
+	Status    string    `json:"status"` // pending, done или failed
+	URLs      int       `json:"urls"`   // количество ссылок в задании
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// RestoreLinkRequest -
+type RestoreLinkRequest struct {
+	UserID      string
+	ShortLinkID string
+	BaseURL     string
+	GracePeriod time.Duration
+}
+
+// GetDeletedLinksRequest -
+type GetDeletedLinksRequest struct {
+	UserID      string
+	BaseURL     string
+	GracePeriod time.Duration
+}
+
+// GetDeletedLinksResponse -
+type GetDeletedLinksResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response []DeletedLink
+}
+
+// DeletedLink - удаленная ссылка, которую еще не вычистили из хранилища
+type DeletedLink struct {
+	ShortURL        string    `json:"short_url"`
+	OriginalURL     string    `json:"original_url"`
+	Title           string    `json:"title,omitempty"`
+	DeletedAt       time.Time `json:"deleted_at"`
+	RestorableUntil time.Time `json:"restorable_until"`
+}
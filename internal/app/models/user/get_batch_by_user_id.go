@@ -1,23 +1,56 @@
 package user
 
-import "github.com/sonikq/url-shortener/internal/app/models"
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
 
 // GetBatchByUserIDRequest -
 type GetBatchByUserIDRequest struct {
 	BaseURL string
 	UserID  string
+
+	Limit      int    // размер страницы, 0 - по умолчанию
+	Cursor     string // непрозрачный курсор из NextCursor предыдущей страницы
+	Sort       string // created_at или -created_at
+	Search     string // подстрока original_url
+	Tag        string // тег ссылки
+	Collection string // имя коллекции
+	Deleted    string // all, active или deleted
 }
 
 // GetBatchByUserIDResponse -
 type GetBatchByUserIDResponse struct {
-	Code     int
-	Status   string      `json:"status"`
-	Error    *models.Err `json:"error"`
-	Response []BatchByUserID
+	Code       int
+	Status     string      `json:"status"`
+	Error      *models.Err `json:"error"`
+	Response   []BatchByUserID
+	NextCursor string
 }
 
 // BatchByUserID -
 type BatchByUserID struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
+	ShortURL     string        `json:"short_url"`
+	OriginalURL  string        `json:"original_url"`
+	Title        string        `json:"title,omitempty"`
+	Note         string        `json:"note,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Collection   string        `json:"collection,omitempty"`
+	RedirectCode int           `json:"redirect_code,omitempty"`
+	ForwardQuery bool          `json:"forward_query,omitempty"`
+	ForwardPath  bool          `json:"forward_path,omitempty"`
+	Protected    bool          `json:"protected,omitempty"`
+	MaxClicks    int           `json:"max_clicks,omitempty"`
+	Clicks       int           `json:"clicks,omitempty"`
+	ActiveFrom   *time.Time    `json:"active_from,omitempty"`
+	Rules        []RoutingRule `json:"rules,omitempty"`
+	Variants     []Variant     `json:"variants,omitempty"`
+	Sticky       bool          `json:"sticky,omitempty"`
+	Interstitial bool          `json:"interstitial,omitempty"`
+	Blocked      string        `json:"blocked,omitempty"` // причина блокировки ссылки как вредоносной
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	IsDeleted    bool          `json:"is_deleted,omitempty"`
+	DeletedAt    *time.Time    `json:"deleted_at,omitempty"`
 }
@@ -1,16 +1,44 @@
 package user
 
-import "github.com/sonikq/url-shortener/internal/app/models"
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
 
 // GetFullLinkByIDRequest -
 type GetFullLinkByIDRequest struct {
-	ShortLinkID string
+	ShortLinkID         string
+	DefaultRedirectCode int    // для ссылок без собственного кода редиректа
+	PathSuffix          string // хвост пути после сокращения, например /docs
+	RawQuery            string // query входящего запроса без '?'
+	Password            string // пароль защищенной ссылки, если передан
+
+	// Заголовки запроса для правил маршрутизации.
+	UserAgent      string
+	AcceptLanguage string
+	Referrer       string
+
+	Variant *int // вариант A/B-теста, ранее закрепленный за посетителем
+	Preview bool // предпросмотр: адрес не учитывается в счетчиках переходов
 }
 
-// GetFullLinkByIDResponse -
+// GetFullLinkByIDResponse - при успехе Code содержит код редиректа ссылки
 type GetFullLinkByIDResponse struct {
 	Code     int
 	Status   string      `json:"status"`
 	Error    *models.Err `json:"error"`
 	Response *string     `json:"response"`
+
+	Protected  bool          // ссылка защищена паролем
+	Limited    bool          // число переходов ограничено, редирект нельзя кэшировать
+	Routed     bool          // адрес зависит от правил маршрутизации по заголовкам запроса
+	Variant    *int          // выбранный вариант A/B-теста
+	Sticky     bool          // вариант нужно закрепить за посетителем
+	RetryAfter time.Duration // при 429 - через сколько можно повторить попытку
+
+	// Для страницы предпросмотра и страницы перехода.
+	Interstitial bool
+	Title        string
+	CreatedAt    time.Time
 }
@@ -0,0 +1,61 @@
+package user
+
+import (
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+)
+
+// ImportLinksRequest -
+type ImportLinksRequest struct {
+	UserID     string // владелец ссылок, для которых он не взят из файла
+	KeepOwners bool   // брать владельца из user_id строки; только для импорта с сервера
+	BaseURL    string
+	Rows       linkio.Reader
+}
+
+// ImportLinksResponse -
+type ImportLinksResponse struct {
+	Code     int
+	Status   string        `json:"status"`
+	Error    *models.Err   `json:"error"`
+	Response *ImportReport `json:"response"`
+}
+
+// ImportReport - итог импорта
+type ImportReport struct {
+	Imported int `json:"imported"`
+	Renamed  int `json:"renamed"` // сокращение из файла занято или некорректно, выдано новое
+	Failed   int `json:"failed"`
+
+	// Rows - строки с ошибкой и строки, получившие не то сокращение, что в файле,
+	// не больше maxImportReportRows; остальные учтены только в счетчиках.
+	Rows          []ImportedRow `json:"rows"`
+	RowsTruncated bool          `json:"rows_truncated,omitempty"`
+}
+
+// ImportedRow - результат импорта строки файла
+type ImportedRow struct {
+	Line     int    `json:"line"`
+	Alias    string `json:"alias,omitempty"`     // сокращение из файла
+	ShortURL string `json:"short_url,omitempty"` // выданное сокращение
+	Error    string `json:"error,omitempty"`
+}
+
+// ExportLinksRequest -
+type ExportLinksRequest struct {
+	UserID string // пустой - ссылки всех пользователей; только для выгрузки с сервера
+	Rows   linkio.Writer
+}
+
+// ExportLinksResponse -
+type ExportLinksResponse struct {
+	Code     int
+	Status   string        `json:"status"`
+	Error    *models.Err   `json:"error"`
+	Response *ExportReport `json:"response"`
+}
+
+// ExportReport - итог выгрузки
+type ExportReport struct {
+	Exported int `json:"exported"`
+}
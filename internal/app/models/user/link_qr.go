@@ -0,0 +1,31 @@
+package user
+
+import "github.com/sonikq/url-shortener/internal/app/models"
+
+// GetLinkQRRequest - пустые значения параметров заменяются значениями по умолчанию
+type GetLinkQRRequest struct {
+	UserID      string
+	ShortLinkID string
+	BaseURL     string
+	LogoPath    string // PNG-логотип из конфигурации
+
+	Format string // png или svg
+	Size   int    // в пикселях
+	Margin *int   // в модулях кода
+	Level  string // коррекция ошибок: L, M, Q или H
+	Logo   bool
+}
+
+// GetLinkQRResponse -
+type GetLinkQRResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *LinkQR
+}
+
+// LinkQR - изображение QR-кода сокращенной ссылки
+type LinkQR struct {
+	ContentType string
+	Image       []byte
+}
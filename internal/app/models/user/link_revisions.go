@@ -0,0 +1,36 @@
+package user
+
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// GetLinkRevisionsRequest -
+type GetLinkRevisionsRequest struct {
+	UserID      string
+	ShortLinkID string
+}
+
+// GetLinkRevisionsResponse -
+type GetLinkRevisionsResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response []LinkRevision
+}
+
+// LinkRevision - прежнее значение original_url ссылки
+type LinkRevision struct {
+	ID          int64     `json:"id"`
+	OriginalURL string    `json:"original_url"`
+	ReplacedAt  time.Time `json:"replaced_at"`
+}
+
+// RestoreLinkRevisionRequest -
+type RestoreLinkRevisionRequest struct {
+	UserID      string
+	ShortLinkID string
+	BaseURL     string
+	RevisionID  int64
+}
@@ -0,0 +1,33 @@
+package user
+
+import "github.com/sonikq/url-shortener/internal/app/models"
+
+// Variant - вариант адреса A/B-теста; Clicks заполняется только в ответах
+type Variant struct {
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+	Clicks      int64  `json:"clicks"`
+}
+
+// GetLinkStatsRequest -
+type GetLinkStatsRequest struct {
+	UserID      string
+	ShortLinkID string
+	BaseURL     string
+}
+
+// GetLinkStatsResponse -
+type GetLinkStatsResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *LinkStats
+}
+
+// LinkStats - счетчики переходов по ссылке
+type LinkStats struct {
+	ShortURL  string    `json:"short_url"`
+	MaxClicks int       `json:"max_clicks,omitempty"`
+	Clicks    int       `json:"clicks,omitempty"` // ведется только для ссылок с max_clicks
+	Variants  []Variant `json:"variants,omitempty"`
+}
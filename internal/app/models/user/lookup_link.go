@@ -0,0 +1,19 @@
+package user
+
+import (
+	"github.com/sonikq/url-shortener/internal/app/admin"
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// LookupLinkRequest -
+type LookupLinkRequest struct {
+	ShortLinkID string
+}
+
+// LookupLinkResponse -
+type LookupLinkResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *admin.Link
+}
@@ -0,0 +1,10 @@
+package user
+
+// RoutingRule - правило выбора адреса редиректа по платформе, языку и источнику перехода.
+// Условие без значения не проверяется, правило без условий недопустимо.
+type RoutingRule struct {
+	Platform    string `json:"platform,omitempty"` // ios, android, windows, macos или linux
+	Language    string `json:"language,omitempty"` // языковой тег из Accept-Language, например ru или pt-BR
+	Referrer    string `json:"referrer,omitempty"` // домен источника перехода, поддомены тоже подходят
+	Destination string `json:"destination"`
+}
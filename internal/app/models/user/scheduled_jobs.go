@@ -0,0 +1,25 @@
+package user
+
+import "time"
+
+// ScheduledJob - фоновая задача планировщика
+type ScheduledJob struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Timeout  string     `json:"timeout"`
+	Running  bool       `json:"running"` // выполняется в этом экземпляре сервиса
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *JobRun    `json:"last_run,omitempty"`
+}
+
+// JobRun - запуск фоновой задачи
+type JobRun struct {
+	ID         int64     `json:"id"`
+	Trigger    string    `json:"trigger"` // schedule или manual
+	Status     string    `json:"status"`  // succeeded или failed
+	Processed  int       `json:"processed"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   string    `json:"duration"`
+}
@@ -12,7 +12,27 @@ type ShorteningLinkJSONRequest struct {
 
 // ShortenLinkJSONRequestBody -
 type ShortenLinkJSONRequestBody struct {
-	URL string `json:"url"`
+	URL        string   `json:"url"`
+	Title      string   `json:"title,omitempty"`
+	Note       string   `json:"note,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Collection string   `json:"collection,omitempty"`
+
+	RedirectCode int  `json:"redirect_code,omitempty"` // 301, 302, 307 или 308
+	ForwardQuery bool `json:"forward_query,omitempty"`
+	ForwardPath  bool `json:"forward_path,omitempty"`
+
+	Password string `json:"password,omitempty"` // хранится только bcrypt-хэш
+
+	MaxClicks  int    `json:"max_clicks,omitempty"`  // 0 - без ограничения
+	ActiveFrom string `json:"active_from,omitempty"` // RFC 3339
+
+	Rules []RoutingRule `json:"rules,omitempty"` // без совпадений редирект ведет на url
+
+	Variants []Variant `json:"variants,omitempty"` // A/B-тест вместо url, если правила не сработали
+	Sticky   bool      `json:"sticky,omitempty"`   // закреплять вариант за посетителем через cookie
+
+	Interstitial bool `json:"interstitial,omitempty"` // показывать страницу перехода вместо редиректа
 }
 
 // ShorteningLinkJSONResponse -
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// UpdateLinkRequest -
+type UpdateLinkRequest struct {
+	UserID      string
+	ShortLinkID string
+	BaseURL     string
+	Body        UpdateLinkRequestBody
+}
+
+// UpdateLinkRequestBody - изменяемые атрибуты ссылки, отсутствующее поле не меняется
+type UpdateLinkRequestBody struct {
+	URL        *string   `json:"url"`
+	Title      *string   `json:"title"`
+	Note       *string   `json:"note"`
+	Tags       *[]string `json:"tags"`
+	Collection *string   `json:"collection"`
+
+	RedirectCode *int  `json:"redirect_code"` // 0 - код по умолчанию
+	ForwardQuery *bool `json:"forward_query"`
+	ForwardPath  *bool `json:"forward_path"`
+
+	Password *string `json:"password"` // пустая строка снимает защиту
+
+	MaxClicks  *int    `json:"max_clicks"`  // 0 снимает ограничение
+	ActiveFrom *string `json:"active_from"` // RFC 3339, пустая строка делает ссылку активной сразу
+
+	Rules *[]RoutingRule `json:"rules"` // заменяет все правила, пустой список удаляет их
+
+	Variants *[]Variant `json:"variants"` // заменяет варианты и обнуляет их счетчики
+	Sticky   *bool      `json:"sticky"`
+
+	Interstitial *bool `json:"interstitial"`
+}
+
+// UpdateLinkResponse -
+type UpdateLinkResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *UpdatedLink
+}
+
+// UpdatedLink -
+type UpdatedLink struct {
+	ShortURL     string        `json:"short_url"`
+	OriginalURL  string        `json:"original_url"`
+	Title        string        `json:"title,omitempty"`
+	Note         string        `json:"note,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Collection   string        `json:"collection,omitempty"`
+	RedirectCode int           `json:"redirect_code,omitempty"`
+	ForwardQuery bool          `json:"forward_query,omitempty"`
+	ForwardPath  bool          `json:"forward_path,omitempty"`
+	Protected    bool          `json:"protected,omitempty"`
+	MaxClicks    int           `json:"max_clicks,omitempty"`
+	Clicks       int           `json:"clicks,omitempty"`
+	ActiveFrom   *time.Time    `json:"active_from,omitempty"`
+	Rules        []RoutingRule `json:"rules,omitempty"`
+	Variants     []Variant     `json:"variants,omitempty"`
+	Sticky       bool          `json:"sticky,omitempty"`
+	Interstitial bool          `json:"interstitial,omitempty"`
+	Blocked      string        `json:"blocked,omitempty"` // причина блокировки ссылки как вредоносной
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
@@ -0,0 +1,108 @@
+package user
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// CreateWebhookRequest -
+type CreateWebhookRequest struct {
+	UserID string
+	Body   CreateWebhookRequestBody
+}
+
+// CreateWebhookRequestBody -
+type CreateWebhookRequestBody struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // пустой список - все события
+}
+
+// CreateWebhookResponse -
+type CreateWebhookResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *Webhook
+}
+
+// GetWebhooksRequest -
+type GetWebhooksRequest struct {
+	UserID string
+}
+
+// GetWebhooksResponse -
+type GetWebhooksResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response []Webhook
+}
+
+// Webhook - подписка пользователя на события его ссылок
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"` // ключ подписи, отдается только при создании
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DeleteWebhookRequest -
+type DeleteWebhookRequest struct {
+	UserID string
+	ID     string
+}
+
+// DeleteWebhookResponse -
+type DeleteWebhookResponse struct {
+	Code   int
+	Status string      `json:"status"`
+	Error  *models.Err `json:"error"`
+}
+
+// GetWebhookDeliveriesRequest -
+type GetWebhookDeliveriesRequest struct {
+	UserID    string
+	WebhookID string
+	Status    string // pending, delivered или dead; пустой - все доставки
+	Limit     int
+}
+
+// GetWebhookDeliveriesResponse -
+type GetWebhookDeliveriesResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response []WebhookDelivery
+}
+
+// WebhookDelivery - запись журнала доставок
+type WebhookDelivery struct {
+	ID            int64           `json:"id"`
+	Event         string          `json:"event"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error,omitempty"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"` // только для ожидающих доставок
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// RetryWebhookDeliveryRequest -
+type RetryWebhookDeliveryRequest struct {
+	UserID     string
+	WebhookID  string
+	DeliveryID int64
+}
+
+// RetryWebhookDeliveryResponse -
+type RetryWebhookDeliveryResponse struct {
+	Code     int
+	Status   string      `json:"status"`
+	Error    *models.Err `json:"error"`
+	Response *WebhookDelivery
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/sonikq/url-shortener/internal/app/models"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -59,18 +60,14 @@ func SetUserCookie(w http.ResponseWriter) error {
 
 // VerifyUserToken -
 func VerifyUserToken(w http.ResponseWriter, r *http.Request) (string, error) {
-	var (
-		cookie *http.Cookie
-		err    error
-	)
 	claims := &Claims{}
 
-	cookie, err = r.Cookie(CookieName)
-	if cookie == nil || err != nil {
-		return "", err
+	value := requestToken(r)
+	if value == "" {
+		return "", http.ErrNoCookie
 	}
 
-	token, parseCookieErr := parseCookie(cookie.Value, claims)
+	token, parseCookieErr := parseCookie(value, claims)
 	if parseCookieErr != nil {
 		return "", parseCookieErr
 	}
@@ -94,35 +91,46 @@ func GetUserToken(w http.ResponseWriter, r *http.Request) (string, error) {
 	)
 	claims := &Claims{}
 
-	cookie, err = r.Cookie(CookieName)
-	if cookie == nil || err != nil {
+	value := requestToken(r)
+	if value == "" {
 		cookie, err = generateCookie()
 		if err != nil {
 			return "", err
 		}
 		http.SetCookie(w, cookie)
+		value = cookie.Value
 	}
 
-	token, parseCookieErr := parseCookie(cookie.Value, claims)
-	if parseCookieErr != nil {
+	// Истекший или поддельный токен заменяется новым, как и отсутствующий.
+	token, parseCookieErr := parseCookie(value, claims)
+	if parseCookieErr != nil || !token.Valid {
 		cookie, err = generateCookie()
 		if err != nil {
 			return "", models.ErrGenerateCookie
 		}
 		http.SetCookie(w, cookie)
-	}
 
-	if !token.Valid {
-		cookie, err = generateCookie()
-		if err != nil {
-			return "", models.ErrGenerateCookie
+		claims = &Claims{}
+		if _, err = parseCookie(cookie.Value, claims); err != nil {
+			return "", err
 		}
-		http.SetCookie(w, cookie)
 	}
 
 	return claims.UserID, nil
 }
 
+// requestToken - токен из cookie, а для клиентов без cookie - API-ключ из заголовка
+// Authorization: Bearer; ключом служит ранее выданный сервисом токен.
+func requestToken(r *http.Request) string {
+	if cookie, err := r.Cookie(CookieName); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+	if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
 func parseCookie(value string, claim *Claims) (*jwt.Token, error) {
 	token, err := jwt.ParseWithClaims(value, claim,
 		func(j *jwt.Token) (interface{}, error) {
@@ -0,0 +1,199 @@
+// Package cron - расписания фоновых задач в формате cron.
+//
+// Поддерживаются пять полей "минута час день-месяца месяц день-недели" со значениями
+// *, a-b, */n, a-b/n и списками через запятую, а также @hourly, @daily (@midnight),
+// @weekly, @monthly, @yearly (@annually) и @every <длительность>.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule - расписание запусков
+type Schedule interface {
+	// Next - ближайшее время запуска строго после after
+	Next(after time.Time) time.Time
+}
+
+// Every - запуск через равные промежутки, отсчитываемые от предыдущего запуска
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
+
+// field - допустимые значения поля cron.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7}, // 0 и 7 - воскресенье
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse - разбирает расписание
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if interval, found := strings.CutPrefix(spec, "@every "); found {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %w", interval, err)
+		}
+		if d <= 0 {
+			return nil, errors.New("interval must be positive")
+		}
+		return Every(d), nil
+	}
+	if expanded, found := descriptors[spec]; found {
+		spec = expanded
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(fields), len(parts))
+	}
+
+	var s cronSchedule
+	bits := [5]*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	for i, part := range parts {
+		value, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		*bits[i] = value
+	}
+
+	// Воскресенье можно записать и как 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.anyDOM = parts[2] == "*"
+	s.anyDOW = parts[4] == "*"
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("schedule %q never fires", spec)
+	}
+
+	return s, nil
+}
+
+// parseField переводит поле в битовую маску допустимых значений.
+func parseField(part string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+		}
+
+		from, to := f.min, f.max
+		if rangePart != "*" {
+			low, high, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = parseValue(low, f); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = parseValue(high, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				to = f.max
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, f.name)
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q, expected %d-%d", f.name, value, f.min, f.max)
+	}
+	return v, nil
+}
+
+// cronSchedule - расписание из пяти полей в виде битовых масок.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// Как в cron: если ограничены и день месяца, и день недели, подходит любой из них.
+	anyDOM, anyDOW bool
+}
+
+// maxSearch - дальше расписание считается невыполнимым (например, 30 февраля).
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+func (s cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.anyDOM || s.anyDOW {
+		return dom && dow
+	}
+	return dom || dow
+}
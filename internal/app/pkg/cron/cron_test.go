@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	base := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.UTC) // пятница
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{spec: "* * * * *", next: time.Date(2024, time.March, 15, 10, 8, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", next: time.Date(2024, time.March, 15, 10, 15, 0, 0, time.UTC)},
+		{spec: "5,50 9-11 * * *", next: time.Date(2024, time.March, 15, 10, 50, 0, 0, time.UTC)},
+		{spec: "30 3 * * *", next: time.Date(2024, time.March, 16, 3, 30, 0, 0, time.UTC)},
+		{spec: "0 0 1 * *", next: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 12 * * 1-5", next: time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		{spec: "0 12 * * 7", next: time.Date(2024, time.March, 17, 12, 0, 0, 0, time.UTC)},
+		// Ограничены оба дня - подходит любой.
+		{spec: "0 0 20 * 6", next: time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", next: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", next: time.Date(2024, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "@weekly", next: time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{spec: "@every 90s", next: base.Add(90 * time.Second)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := Parse(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.next, schedule.Next(base))
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"0 0 30 2 *",
+		"@every -1m",
+		"@every soon",
+	} {
+		_, err := Parse(spec)
+		require.Error(t, err, spec)
+	}
+}
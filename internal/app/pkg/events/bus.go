@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Handler - обработчик события внутри процесса
+type Handler func(ctx context.Context, event storage.OutboxEvent) error
+
+// Bus - шина событий внутри процесса, вызывает обработчики синхронно в порядке подписки
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus -
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe - добавляет обработчик всех событий
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish - передает событие всем обработчикам, ошибка любого из них вернет событие в outbox
+func (b *Bus) Publish(ctx context.Context, event storage.OutboxEvent) error {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
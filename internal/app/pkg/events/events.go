@@ -0,0 +1,39 @@
+// Package events - приемники событий жизненного цикла ссылок, которые публикует
+// релей outbox (см. workers.Relay).
+//
+// Доставка - "хотя бы один раз": при сбое событие публикуется повторно во все приемники,
+// поэтому получатели должны отбрасывать повторы по id события.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Sink - приемник событий
+type Sink interface {
+	Publish(ctx context.Context, event storage.OutboxEvent) error
+}
+
+// Event - тело события во всех приемниках
+type Event struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	UserID     string            `json:"user_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Link       storage.EventLink `json:"link"`
+}
+
+// Encode - JSON события из outbox
+func Encode(event storage.OutboxEvent) ([]byte, error) {
+	return json.Marshal(Event{
+		ID:         event.EventID,
+		Type:       event.Type,
+		UserID:     event.UserID,
+		OccurredAt: event.CreatedAt,
+		Link:       event.Link,
+	})
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// kafkaContentType - формат записей Kafka REST Proxy v2 со значениями в JSON
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaREST - публикация в топик Kafka через REST Proxy (POST /topics/<topic>).
+// Ключ записи - сокращение ссылки, поэтому события одной ссылки попадают в одну партицию по порядку.
+type KafkaREST struct {
+	endpoint string
+	topic    string
+	client   *http.Client
+}
+
+// NewKafkaREST -
+func NewKafkaREST(endpoint, topic string, timeout time.Duration) *KafkaREST {
+	return &KafkaREST{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		topic:    topic,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish -
+func (k *KafkaREST) Publish(ctx context.Context, event storage.OutboxEvent) error {
+	value, err := Encode(event)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(kafkaProduceRequest{Records: []kafkaRecord{{Key: event.Link.Alias, Value: value}}})
+	if err != nil {
+		return err
+	}
+
+	endpoint := k.endpoint + "/topics/" + url.PathEscape(k.topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka rest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka rest: unexpected status %d", resp.StatusCode)
+	}
+
+	// REST Proxy отвечает 200 и при ошибке записи, ошибка - в offsets.
+	var result kafkaProduceResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("kafka rest: %w", err)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rest: error %d: %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+
+	return nil
+}
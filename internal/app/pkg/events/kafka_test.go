@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaREST_Publish(t *testing.T) {
+	var received kafkaProduceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/topics/link-events", r.URL.Path)
+		require.Equal(t, kafkaContentType, r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		if received.Records[0].Key == "broken" {
+			_, _ = w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"Kafka error"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":42,"error_code":null,"error":null}]}`))
+	}))
+	defer server.Close()
+
+	sink := NewKafkaREST(server.URL+"/", "link-events", time.Second)
+	event := storage.NewOutboxEvent(storage.EventLinkDeleted, "user", storage.EventLink{Alias: "abcdef"})
+
+	require.NoError(t, sink.Publish(context.Background(), event))
+	require.Len(t, received.Records, 1)
+	require.Equal(t, "abcdef", received.Records[0].Key)
+
+	var body Event
+	require.NoError(t, json.Unmarshal(received.Records[0].Value, &body))
+	require.Equal(t, storage.EventLinkDeleted, body.Type)
+
+	event.Link.Alias = "broken"
+	require.ErrorContains(t, sink.Publish(context.Background(), event), "50002")
+}
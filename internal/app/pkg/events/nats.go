@@ -0,0 +1,180 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// natsDefaultPort - порт NATS, если в адресе он не указан
+const natsDefaultPort = "4222"
+
+// NATS - публикация в NATS по текстовому протоколу ядра. Событие уходит в тему
+// "<subject>.<тип события>", например events.link.created. После PUB отправляется PING:
+// PONG подтверждает, что сервер обработал публикацию, а -ERR до него - что нет.
+//
+// Соединение одно и открывается при первой публикации, после ошибки - заново.
+type NATS struct {
+	address string
+	subject string
+	user    string
+	pass    string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATS - rawURL вида nats://[user:pass@]host[:port]
+func NewNATS(rawURL, subject string, timeout time.Duration) (*NATS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nats url: %w", err)
+	}
+	if u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, errors.New("nats url must look like nats://host:port")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+
+	n := &NATS{
+		address: net.JoinHostPort(u.Hostname(), port),
+		subject: strings.TrimSuffix(subject, "."),
+		timeout: timeout,
+	}
+	if u.User != nil {
+		n.user = u.User.Username()
+		n.pass, _ = u.User.Password()
+	}
+	return n, nil
+}
+
+// Publish -
+func (n *NATS) Publish(ctx context.Context, event storage.OutboxEvent) error {
+	payload, err := Encode(event)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err = n.publish(ctx, n.subject+"."+event.Type, payload); err != nil {
+		n.closeConn()
+		return fmt.Errorf("nats: %w", err)
+	}
+	return nil
+}
+
+// Close - закрывает соединение
+func (n *NATS) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.closeConn()
+}
+
+func (n *NATS) publish(ctx context.Context, subject string, payload []byte) error {
+	if n.conn == nil {
+		if err := n.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(n.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := n.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := n.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	for {
+		line, err := n.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err = n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK и INFO не влияют на результат публикации.
+	}
+}
+
+// connect открывает соединение: сервер присылает INFO, клиент отвечает CONNECT.
+func (n *NATS) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: n.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", n.address)
+	if err != nil {
+		return err
+	}
+	if err = conn.SetDeadline(time.Now().Add(n.timeout)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	info, err := reader.ReadString('\n')
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(info, "INFO") {
+		_ = conn.Close()
+		return fmt.Errorf("unexpected greeting: %q", strings.TrimSpace(info))
+	}
+
+	options, err := json.Marshal(map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "url-shortener",
+		"lang":     "go",
+		"user":     n.user,
+		"pass":     n.pass,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if _, err = conn.Write([]byte("CONNECT " + string(options) + "\r\n")); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	n.conn, n.reader = conn, reader
+	return nil
+}
+
+func (n *NATS) closeConn() {
+	if n.conn != nil {
+		_ = n.conn.Close()
+		n.conn, n.reader = nil, nil
+	}
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"slices"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// WebhookSink - ставит событие в очередь доставки подписчикам владельца ссылки
+// (доставляет workers.WebhookSender)
+type WebhookSink struct {
+	store *storage.Storage
+}
+
+// NewWebhookSink -
+func NewWebhookSink(store *storage.Storage) *WebhookSink {
+	return &WebhookSink{store: store}
+}
+
+// Publish -
+func (s *WebhookSink) Publish(ctx context.Context, event storage.OutboxEvent) error {
+	webhooks, err := s.store.GetWebhooks(ctx, event.UserID)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	payload, err := Encode(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []storage.Delivery
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.Events, event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, storage.Delivery{
+			WebhookID: webhook.ID,
+			UserID:    event.UserID,
+			Event:     event.Type,
+			Payload:   payload,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.store.AddDeliveries(ctx, deliveries)
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink_Publish(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	require.NoError(t, store.CreateWebhook(ctx, storage.Webhook{ID: "all", UserID: "user", URL: "https://example.com/all",
+		Events: storage.EventTypes}))
+	require.NoError(t, store.CreateWebhook(ctx, storage.Webhook{ID: "deleted", UserID: "user", URL: "https://example.com/deleted",
+		Events: []string{storage.EventLinkDeleted}}))
+
+	sink := NewWebhookSink(store)
+	event := storage.NewOutboxEvent(storage.EventLinkCreated, "user", storage.EventLink{Alias: "abcdef"})
+	require.NoError(t, sink.Publish(ctx, event))
+	require.NoError(t, sink.Publish(ctx, storage.NewOutboxEvent(storage.EventLinkCreated, "other", storage.EventLink{Alias: "ghijkl"})))
+
+	deliveries, err := store.GetDeliveries(ctx, "user", "all", "", 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	var body Event
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &body))
+	require.Equal(t, event.EventID, body.ID)
+	require.Equal(t, "abcdef", body.Link.Alias)
+
+	deliveries, err = store.GetDeliveries(ctx, "user", "deleted", "", 10)
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
@@ -0,0 +1,376 @@
+// Package linkio - чтение и запись ссылок для массового импорта и выгрузки в CSV и JSON Lines.
+//
+// CSV начинается со строки заголовка с именами колонок (см. Columns), обязательна только original_url;
+// теги перечисляются через запятую в одной ячейке. В JSON Lines каждая строка - объект Row.
+package linkio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы файлов.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// maxLineSize - строка JSON Lines длиннее считается ошибочной
+const maxLineSize = 1 << 20
+
+// Columns - колонки CSV в порядке выгрузки
+var Columns = []string{
+	"alias", "original_url", "user_id", "title", "note", "tags", "collection",
+	"redirect_code", "max_clicks", "clicks", "created_at",
+}
+
+// Row - ссылка в файле импорта или выгрузки
+type Row struct {
+	Alias        string     `json:"alias,omitempty"` // пустое - сокращение выдается при импорте
+	OriginalURL  string     `json:"original_url"`
+	UserID       string     `json:"user_id,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Collection   string     `json:"collection,omitempty"`
+	RedirectCode int        `json:"redirect_code,omitempty"`
+	MaxClicks    int        `json:"max_clicks,omitempty"`
+	Clicks       int        `json:"clicks,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+// RowError - ошибка в отдельной строке файла, чтение можно продолжать
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader - последовательное чтение строк файла
+type Reader interface {
+	// Read - следующая строка и номер строки файла, на которой она начинается. В конце файла
+	// возвращает io.EOF, для некорректной строки - *RowError, после которой чтение продолжается.
+	Read() (Row, int, error)
+}
+
+// Writer - последовательная запись строк файла
+type Writer interface {
+	Write(row Row) error
+	// Flush - дописывает буферизованные строки
+	Flush() error
+}
+
+// ParseFormat - формат по явному имени (csv, jsonl, ndjson) или по MIME-типу
+func ParseFormat(value string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(value))
+	}
+
+	switch mediaType {
+	case FormatCSV, "text/csv", "application/csv":
+		return FormatCSV, nil
+	case FormatJSONL, "ndjson", "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected csv or jsonl", value)
+	}
+}
+
+// FileFormat - формат из явного значения format или, если оно пустое, по расширению файла
+func FileFormat(path, format string) (string, error) {
+	if format != "" {
+		return ParseFormat(format)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("cant detect format of %q, set it explicitly", path)
+	}
+}
+
+// ContentType - MIME-тип формата
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// NewReader -
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		return &jsonlReader{r: bufio.NewReader(r)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// NewWriter -
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatJSONL:
+		return &jsonlWriter{w: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // число полей проверяется построчно
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("csv header is missing")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(Columns, name) {
+			return nil, fmt.Errorf("unknown csv column %q", name)
+		}
+		if slices.Contains(columns[:i], name) {
+			return nil, fmt.Errorf("duplicate csv column %q", name)
+		}
+		columns[i] = name
+	}
+	if !slices.Contains(columns, "original_url") {
+		return nil, errors.New("csv column original_url is required")
+	}
+
+	return &csvReader{r: reader, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (Row, int, error) {
+	record, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return Row{}, 0, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Row{}, parseErr.StartLine, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+	}
+	if err != nil {
+		return Row{}, 0, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	if len(record) != len(c.columns) {
+		return Row{}, line, &RowError{
+			Line: line,
+			Err:  fmt.Errorf("expected %d fields, got %d", len(c.columns), len(record)),
+		}
+	}
+
+	var row Row
+	for i, value := range record {
+		if err = row.set(c.columns[i], value); err != nil {
+			return Row{}, line, &RowError{Line: line, Err: err}
+		}
+	}
+	return row, line, nil
+}
+
+// set заполняет поле строки значением из колонки CSV.
+func (r *Row) set(column, value string) error {
+	var err error
+	switch column {
+	case "alias":
+		r.Alias = strings.TrimSpace(value)
+	case "original_url":
+		r.OriginalURL = value
+	case "user_id":
+		r.UserID = strings.TrimSpace(value)
+	case "title":
+		r.Title = value
+	case "note":
+		r.Note = value
+	case "tags":
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				r.Tags = append(r.Tags, tag)
+			}
+		}
+	case "collection":
+		r.Collection = strings.TrimSpace(value)
+	case "redirect_code":
+		r.RedirectCode, err = parseInt(value)
+	case "max_clicks":
+		r.MaxClicks, err = parseInt(value)
+	case "clicks":
+		r.Clicks, err = parseInt(value)
+	case "created_at":
+		if value = strings.TrimSpace(value); value != "" {
+			var createdAt time.Time
+			if createdAt, err = time.Parse(time.RFC3339, value); err == nil {
+				r.CreatedAt = &createdAt
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", column, err)
+	}
+	return nil
+}
+
+func parseInt(value string) (int, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+type jsonlReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (j *jsonlReader) Read() (Row, int, error) {
+	for {
+		data, err := j.readLine()
+		if err != nil {
+			return Row{}, j.line, err
+		}
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var row Row
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err = decoder.Decode(&row); err != nil {
+			return Row{}, j.line, &RowError{Line: j.line, Err: err}
+		}
+		return row, j.line, nil
+	}
+}
+
+// readLine читает следующую строку; слишком длинная строка пропускается с ошибкой.
+func (j *jsonlReader) readLine() ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, isPrefix, err := j.r.ReadLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) && (len(line) > 0 || tooLong) {
+				break
+			}
+			return nil, err
+		}
+		if !tooLong {
+			line = append(line, chunk...)
+			tooLong = len(line) > maxLineSize
+		}
+		if !isPrefix {
+			break
+		}
+	}
+
+	j.line++
+	if tooLong {
+		return nil, &RowError{Line: j.line, Err: fmt.Errorf("line exceeds %d bytes", maxLineSize)}
+	}
+	return line, nil
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (c *csvWriter) Write(row Row) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(Columns); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+
+	var createdAt string
+	if row.CreatedAt != nil {
+		createdAt = row.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return c.w.Write([]string{
+		row.Alias, row.OriginalURL, row.UserID, row.Title, row.Note, strings.Join(row.Tags, ","), row.Collection,
+		formatInt(row.RedirectCode), formatInt(row.MaxClicks), formatInt(row.Clicks), createdAt,
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	// Пустая выгрузка - это файл из одного заголовка.
+	if !c.wroteHeader {
+		if err := c.w.Write(Columns); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+type jsonlWriter struct {
+	w *bufio.Writer
+}
+
+func (j *jsonlWriter) Write(row Row) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if _, err = j.w.Write(data); err != nil {
+		return err
+	}
+	return j.w.WriteByte('\n')
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
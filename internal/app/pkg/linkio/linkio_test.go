@@ -0,0 +1,154 @@
+package linkio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readAll читает файл до конца, ошибки строк собираются по номерам строк.
+func readAll(t *testing.T, r Reader) ([]Row, map[int]string) {
+	var rows []Row
+	failed := make(map[int]string)
+	for {
+		row, line, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, failed
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			require.Equal(t, line, rowErr.Line)
+			failed[line] = rowErr.Err.Error()
+			continue
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	data := "\ufeffAlias,original_url,tags,clicks,created_at\n" +
+		"abc,https://ya.ru,\"go, news\",3,2020-01-02T03:04:05Z\n" +
+		",https://example.com,,,\n" +
+		"bad,https://example.com,,many,\n" +
+		"short,https://example.com\n" +
+		"\"multi\nline\",https://example.org,,,\n"
+
+	reader, err := NewReader(strings.NewReader(data), FormatCSV)
+	require.NoError(t, err)
+
+	rows, failed := readAll(t, reader)
+	require.Len(t, rows, 3)
+	require.Equal(t, "abc", rows[0].Alias)
+	require.Equal(t, []string{"go", "news"}, rows[0].Tags)
+	require.Equal(t, 3, rows[0].Clicks)
+	require.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), *rows[0].CreatedAt)
+	require.Empty(t, rows[1].Alias)
+	require.Nil(t, rows[1].CreatedAt)
+	require.Equal(t, "multi\nline", rows[2].Alias)
+
+	require.Len(t, failed, 2)
+	require.Contains(t, failed[4], "invalid clicks")
+	require.Contains(t, failed[5], "expected 5 fields")
+}
+
+func TestCSVReader_header(t *testing.T) {
+	for _, header := range []string{"", "alias,title\n", "original_url,password\n", "original_url,alias,alias\n"} {
+		_, err := NewReader(strings.NewReader(header), FormatCSV)
+		require.Error(t, err, header)
+	}
+}
+
+func TestJSONLReader(t *testing.T) {
+	data := `{"alias":"abc","original_url":"https://ya.ru","tags":["go"]}` + "\n\n" +
+		`{"original_url":"https://example.com","password":"x"}` + "\n" +
+		`{"original_url":` + "\n" +
+		`{"original_url":"https://example.org","user_id":"u1"}`
+
+	reader, err := NewReader(strings.NewReader(data), FormatJSONL)
+	require.NoError(t, err)
+
+	rows, failed := readAll(t, reader)
+	require.Len(t, rows, 2)
+	require.Equal(t, "abc", rows[0].Alias)
+	require.Equal(t, "u1", rows[1].UserID)
+	require.Len(t, failed, 2)
+	require.Contains(t, failed[3], "unknown field")
+	require.Contains(t, failed, 4)
+
+	long := strings.Repeat("x", maxLineSize+10) + "\n" + `{"original_url":"https://ya.ru"}` + "\n"
+	reader, err = NewReader(strings.NewReader(long), FormatJSONL)
+	require.NoError(t, err)
+	rows, failed = readAll(t, reader)
+	require.Len(t, rows, 1)
+	require.Contains(t, failed[1], "exceeds")
+}
+
+func TestWriter_roundTrip(t *testing.T) {
+	createdAt := time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC)
+	rows := []Row{
+		{Alias: "abc", OriginalURL: "https://ya.ru", UserID: "u1", Title: "Ya, \"quoted\"", Tags: []string{"a", "b"},
+			RedirectCode: 301, MaxClicks: 10, Clicks: 2, CreatedAt: &createdAt},
+		{Alias: "def", OriginalURL: "https://example.com"},
+	}
+
+	for _, format := range []string{FormatCSV, FormatJSONL} {
+		var buf bytes.Buffer
+		writer, err := NewWriter(&buf, format)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, writer.Write(row))
+		}
+		require.NoError(t, writer.Flush())
+
+		reader, err := NewReader(&buf, format)
+		require.NoError(t, err)
+		got, failed := readAll(t, reader)
+		require.Empty(t, failed)
+		require.Equal(t, rows, got, format)
+	}
+
+	// Пустая выгрузка CSV остается корректным файлом импорта.
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, FormatCSV)
+	require.NoError(t, err)
+	require.NoError(t, writer.Flush())
+	_, err = NewReader(&buf, FormatCSV)
+	require.NoError(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]string{
+		"csv":                      FormatCSV,
+		"text/csv; charset=utf-8":  FormatCSV,
+		"JSONL":                    FormatJSONL,
+		"application/x-ndjson":     FormatJSONL,
+		"application/jsonl; q=0.9": FormatJSONL,
+	}
+	for value, want := range tests {
+		got, err := ParseFormat(value)
+		require.NoError(t, err, value)
+		require.Equal(t, want, got, value)
+	}
+
+	_, err := ParseFormat("application/json")
+	require.Error(t, err)
+}
+
+func TestFileFormat(t *testing.T) {
+	format, err := FileFormat("links.CSV", "")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+
+	format, err = FileFormat("links.csv", "ndjson")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSONL, format)
+
+	_, err = FileFormat("-", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/pkg/openapi"
+)
+
+// ValidateRequest middleware для проверки параметров и тела запроса по спецификации OpenAPI.
+// Запрос, не соответствующий спецификации, не доходит до обработчика и получает 400.
+func ValidateRequest(spec *openapi.Spec) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Запросы без маршрута получат 404 от gin.
+		route := ctx.FullPath()
+		if route == "" {
+			ctx.Next()
+			return
+		}
+
+		err := spec.ValidateRequest(ctx.Request, openapi.TemplatePath(route), ctx.Param)
+		var requestErr *openapi.RequestError
+		switch {
+		case errors.As(err, &requestErr):
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				models.StatusKey:    models.StatusFail,
+				models.ErrSourceKey: "request",
+				models.ErrMsgKey:    requestErr.Message,
+			})
+			return
+		case err != nil:
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				models.StatusKey:    models.StatusFail,
+				models.ErrSourceKey: "request",
+				models.ErrMsgKey:    "Error in reading body",
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
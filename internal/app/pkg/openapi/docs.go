@@ -0,0 +1,249 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ServeJSON отдает спецификацию в том виде, в котором она встроена в сервис.
+func (s *Spec) ServeJSON(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(s.raw)))
+	_, _ = w.Write(s.raw)
+}
+
+// ServeDocs отдает HTML-страницу документации, собранную по спецификации на сервере,
+// без внешних скриптов и стилей.
+func (s *Spec) ServeDocs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := docsTemplate.Execute(w, s.docsPage()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// docsPage - данные страницы документации.
+type docsPage struct {
+	Info Info
+	Tags []docsTag
+}
+
+type docsTag struct {
+	Tag
+	Operations []docsOperation
+}
+
+type docsOperation struct {
+	Method     string
+	Path       string
+	Op         *Operation
+	Auth       string // способ передачи токена, пустой - токен не нужен
+	Parameters []docsParameter
+	Body       []string // типы тела запроса
+	Required   bool
+	Fields     []docsField // поля JSON-тела
+	Responses  []docsResponse
+}
+
+type docsParameter struct {
+	*Parameter
+	Type string
+}
+
+type docsField struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+type docsResponse struct {
+	Code        string
+	Description string
+	Content     []string
+}
+
+func (s *Spec) docsPage() docsPage {
+	page := docsPage{Info: s.doc.Info}
+
+	byTag := make(map[string][]docsOperation)
+	for _, route := range s.Routes() {
+		op, _ := s.Operation(route.Method, route.Path)
+		tag := ""
+		if len(op.Tags) > 0 {
+			tag = op.Tags[0]
+		}
+		byTag[tag] = append(byTag[tag], docsOperationOf(op))
+	}
+
+	for _, tag := range s.doc.Tags {
+		if ops := byTag[tag.Name]; len(ops) > 0 {
+			page.Tags = append(page.Tags, docsTag{Tag: tag, Operations: ops})
+		}
+	}
+	return page
+}
+
+func docsOperationOf(op *Operation) docsOperation {
+	item := docsOperation{
+		Method: op.method,
+		Path:   op.path,
+		Op:     op,
+		Auth:   authDescription(op.Security),
+	}
+
+	for _, param := range op.Parameters {
+		item.Parameters = append(item.Parameters, docsParameter{Parameter: param, Type: schemaSummary(param.Schema)})
+	}
+
+	if op.RequestBody != nil {
+		item.Body = sortedKeys(op.RequestBody.Content)
+		item.Required = op.RequestBody.Required
+		if media, ok := op.RequestBody.Content[mediaTypeJSON]; ok {
+			item.Fields = bodyFields(media.Schema)
+		}
+	}
+
+	codes := sortedKeys(op.Responses)
+	for _, code := range codes {
+		response := op.Responses[code]
+		item.Responses = append(item.Responses, docsResponse{
+			Code:        code,
+			Description: response.Description,
+			Content:     sortedKeys(response.Content),
+		})
+	}
+	return item
+}
+
+// bodyFields - поля JSON-тела: объекта или элементов массива объектов.
+func bodyFields(schema *Schema) []docsField {
+	if schema != nil && schema.Type == "array" {
+		schema = schema.Items
+	}
+	if schema == nil || schema.Type != "object" {
+		return nil
+	}
+
+	fields := make([]docsField, 0, len(schema.Properties))
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+		field := docsField{
+			Name:        name,
+			Type:        schemaSummary(property),
+			Description: property.Description,
+		}
+		for _, required := range schema.Required {
+			field.Required = field.Required || required == name
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// authDescription - как передается токен пользователя; пустой список требований значит,
+// что токен необязателен.
+func authDescription(security []map[string][]string) string {
+	if len(security) == 0 {
+		return ""
+	}
+
+	optional := false
+	for _, requirement := range security {
+		if len(requirement) == 0 {
+			optional = true
+		}
+	}
+	if optional {
+		return "необязателен: без токена создается новый пользователь"
+	}
+	return "cookie token или Authorization: Bearer"
+}
+
+// schemaSummary - краткое описание типа параметра: тип, допустимые значения и границы.
+func schemaSummary(schema *Schema) string {
+	if schema == nil {
+		return ""
+	}
+
+	parts := []string{schema.Type}
+	if schema.Type == "array" && schema.Items != nil {
+		parts[0] = "array of " + schema.Items.Type
+	}
+	if len(schema.Enum) > 0 {
+		parts = append(parts, "одно из: "+enumList(schema.Enum))
+	}
+	if schema.Minimum != nil {
+		parts = append(parts, "от "+formatNumber(*schema.Minimum))
+	}
+	if schema.Maximum != nil {
+		parts = append(parts, "до "+formatNumber(*schema.Maximum))
+	}
+	if schema.Default != nil {
+		switch v := schema.Default.(type) {
+		case float64:
+			parts = append(parts, "по умолчанию "+formatNumber(v))
+		case string:
+			parts = append(parts, "по умолчанию "+v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// docsTemplate - все значения экранируются html/template.
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Info.Title}} API</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 1em; }
+section.operation { border-top: 1px solid #ddd; padding: .5em 0; }
+code.method { font-weight: bold; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ddd; padding: .2em .5em; text-align: left; vertical-align: top; }
+pre { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Info.Title}} API <small>{{.Info.Version}}</small></h1>
+<pre>{{.Info.Description}}</pre>
+<p>Спецификация OpenAPI: <a href="/api/openapi.json">/api/openapi.json</a></p>
+<nav><ul>
+{{range .Tags}}<li><a href="#{{.Name}}">{{.Name}}</a> - {{.Description}}</li>
+{{end}}</ul></nav>
+{{range .Tags}}<h2 id="{{.Name}}">{{.Name}}</h2>
+<p>{{.Description}}</p>
+{{range .Operations}}<section class="operation" id="{{.Op.OperationID}}">
+<h3><code class="method">{{.Method}}</code> <code>{{.Path}}</code></h3>
+<p>{{.Op.Summary}}</p>
+{{if .Op.Description}}<p>{{.Op.Description}}</p>
+{{end}}{{if .Auth}}<p>Токен пользователя: {{.Auth}}</p>
+{{end}}{{if .Parameters}}<table>
+<tr><th>Параметр</th><th>Где</th><th>Тип</th><th>Описание</th></tr>
+{{range .Parameters}}<tr><td><code>{{.Name}}</code>{{if .Required}} *{{end}}</td><td>{{.In}}</td><td>{{.Type}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Body}}<p>Тело запроса{{if .Required}} (обязательно){{end}}: {{range $i, $t := .Body}}{{if $i}}, {{end}}<code>{{$t}}</code>{{end}}</p>
+{{if .Fields}}<table>
+<tr><th>Поле</th><th>Тип</th><th>Описание</th></tr>
+{{range .Fields}}<tr><td><code>{{.Name}}</code>{{if .Required}} *{{end}}</td><td>{{.Type}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}<table>
+<tr><th>Ответ</th><th>Описание</th><th>Тип</th></tr>
+{{range .Responses}}<tr><td>{{.Code}}</td><td>{{.Description}}</td><td>{{range $i, $t := .Content}}{{if $i}}, {{end}}<code>{{$t}}</code>{{end}}</td></tr>
+{{end}}</table>
+</section>
+{{end}}{{end}}</body>
+</html>
+`))
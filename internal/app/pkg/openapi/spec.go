@@ -0,0 +1,373 @@
+// Package openapi - спецификация HTTP API сервиса в формате OpenAPI 3 и проверка запросов по ней.
+//
+// Спецификация хранится в openapi.json и встраивается в бинарный файл. Проверка поддерживает
+// ту часть JSON Schema, которая используется в спецификации: type, nullable, enum, minimum,
+// maximum, minLength, maxLength, pattern, minItems, maxItems, items, properties, required
+// и minProperties, а также ссылки $ref на components.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed openapi.json
+var document []byte
+
+// Spec - разобранная спецификация с операциями, готовыми к проверке запросов
+type Spec struct {
+	raw        []byte
+	doc        Document
+	operations map[string]*Operation // метод и путь из спецификации
+}
+
+// Document - документ OpenAPI в объеме, нужном для проверки запросов и страницы документации
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info -
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// Tag -
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PathItem - операции одного пути и их общие параметры
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Post       *Operation   `json:"post"`
+	Put        *Operation   `json:"put"`
+	Patch      *Operation   `json:"patch"`
+	Delete     *Operation   `json:"delete"`
+}
+
+// Operation -
+type Operation struct {
+	Tags        []string              `json:"tags"`
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Security    []map[string][]string `json:"security"`
+	Parameters  []*Parameter          `json:"parameters"`
+	RequestBody *RequestBody          `json:"requestBody"`
+	Responses   map[string]*Response  `json:"responses"`
+
+	method string
+	path   string
+}
+
+// Parameter -
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query, header или cookie
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody -
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response -
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// MediaType -
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema - схема JSON Schema в объеме, который используется в спецификации
+type Schema struct {
+	Ref           string             `json:"$ref"`
+	Type          string             `json:"type"`
+	Format        string             `json:"format"`
+	Description   string             `json:"description"`
+	Nullable      bool               `json:"nullable"`
+	Enum          []any              `json:"enum"`
+	Default       any                `json:"default"`
+	Minimum       *float64           `json:"minimum"`
+	Maximum       *float64           `json:"maximum"`
+	MinLength     *int               `json:"minLength"`
+	MaxLength     *int               `json:"maxLength"`
+	Pattern       string             `json:"pattern"`
+	MinItems      *int               `json:"minItems"`
+	MaxItems      *int               `json:"maxItems"`
+	Items         *Schema            `json:"items"`
+	Properties    map[string]*Schema `json:"properties"`
+	Required      []string           `json:"required"`
+	MinProperties *int               `json:"minProperties"`
+
+	pattern *regexp.Regexp
+}
+
+// Components -
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas"`
+	Parameters map[string]*Parameter `json:"parameters"`
+	Responses  map[string]*Response  `json:"responses"`
+}
+
+// Route - метод и путь операции в форме спецификации, например GET /api/user/urls/{id}
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Load разбирает встроенную спецификацию и подставляет ссылки $ref.
+func Load() (*Spec, error) {
+	return Parse(document)
+}
+
+// MustLoad - Load, паникующий при ошибке; спецификация встроена, поэтому ошибка возможна
+// только в самом openapi.json и проверяется тестами.
+func MustLoad() *Spec {
+	spec, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+// Parse разбирает спецификацию из data.
+func Parse(data []byte) (*Spec, error) {
+	spec := &Spec{
+		raw:        data,
+		operations: make(map[string]*Operation),
+	}
+	if err := json.Unmarshal(data, &spec.doc); err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %w", err)
+	}
+
+	r := resolver{components: spec.doc.Components}
+	for name, schema := range spec.doc.Components.Schemas {
+		if err := r.schemaFields(schema); err != nil {
+			return nil, fmt.Errorf("components.schemas.%s: %w", name, err)
+		}
+	}
+
+	for path, item := range spec.doc.Paths {
+		common, err := r.parameters(item.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for method, op := range item.operations() {
+			op.method, op.path = method, path
+			if op.Parameters, err = r.parameters(op.Parameters); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			op.Parameters = mergeParameters(common, op.Parameters)
+
+			if err = r.operation(op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			spec.operations[method+" "+path] = op
+		}
+	}
+
+	return spec, nil
+}
+
+// Document - разобранный документ, ссылки $ref в нем уже подставлены.
+func (s *Spec) Document() Document {
+	return s.doc
+}
+
+// Operation - операция по методу и пути в форме спецификации.
+func (s *Spec) Operation(method, path string) (*Operation, bool) {
+	op, ok := s.operations[method+" "+path]
+	return op, ok
+}
+
+// Routes - все операции спецификации, отсортированные по пути и методу.
+func (s *Spec) Routes() []Route {
+	routes := make([]Route, 0, len(s.operations))
+	for _, op := range s.operations {
+		routes = append(routes, Route{Method: op.method, Path: op.path})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return methodOrder(routes[i].Method) < methodOrder(routes[j].Method)
+	})
+	return routes
+}
+
+// TemplatePath переводит шаблон маршрута gin (/api/user/urls/:id, /:id/*rest)
+// в путь спецификации (/api/user/urls/{id}, /{id}/{rest}).
+func TemplatePath(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// methods - методы в порядке вывода документации
+var methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+func methodOrder(method string) int {
+	for i, m := range methods {
+		if m == method {
+			return i
+		}
+	}
+	return len(methods)
+}
+
+func (p *PathItem) operations() map[string]*Operation {
+	ops := make(map[string]*Operation)
+	for method, op := range map[string]*Operation{
+		http.MethodGet:    p.Get,
+		http.MethodPost:   p.Post,
+		http.MethodPut:    p.Put,
+		http.MethodPatch:  p.Patch,
+		http.MethodDelete: p.Delete,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+// mergeParameters - общие параметры пути, дополненные и переопределенные параметрами операции.
+func mergeParameters(common, own []*Parameter) []*Parameter {
+	merged := make([]*Parameter, 0, len(common)+len(own))
+	for _, param := range common {
+		overridden := false
+		for _, p := range own {
+			if p.Name == param.Name && p.In == param.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, param)
+		}
+	}
+	return append(merged, own...)
+}
+
+// resolver подставляет ссылки $ref на components. Ссылка заменяется указателем на схему
+// из components, поэтому каждая схема из components разбирается один раз.
+type resolver struct {
+	components Components
+}
+
+const (
+	schemasPrefix    = "#/components/schemas/"
+	parametersPrefix = "#/components/parameters/"
+	responsesPrefix  = "#/components/responses/"
+)
+
+func (r resolver) schema(schema *Schema) (*Schema, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if schema.Ref == "" {
+		return schema, r.schemaFields(schema)
+	}
+
+	name, ok := strings.CutPrefix(schema.Ref, schemasPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q", schema.Ref)
+	}
+	target, ok := r.components.Schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %q", schema.Ref)
+	}
+	return target, nil
+}
+
+// schemaFields подставляет ссылки во вложенных схемах.
+func (r resolver) schemaFields(schema *Schema) error {
+	var err error
+	if schema.Pattern != "" && schema.pattern == nil {
+		if schema.pattern, err = regexp.Compile(schema.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if schema.Items, err = r.schema(schema.Items); err != nil {
+		return fmt.Errorf("items: %w", err)
+	}
+	for name, property := range schema.Properties {
+		if schema.Properties[name], err = r.schema(property); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (r resolver) parameters(params []*Parameter) ([]*Parameter, error) {
+	resolved := make([]*Parameter, 0, len(params))
+	for _, param := range params {
+		if param.Ref != "" {
+			name, ok := strings.CutPrefix(param.Ref, parametersPrefix)
+			target, found := r.components.Parameters[name]
+			if !ok || !found {
+				return nil, fmt.Errorf("unknown parameter %q", param.Ref)
+			}
+			param = target
+		}
+
+		var err error
+		if param.Schema, err = r.schema(param.Schema); err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+		resolved = append(resolved, param)
+	}
+	return resolved, nil
+}
+
+func (r resolver) operation(op *Operation) error {
+	if op.RequestBody != nil {
+		for mediaType, media := range op.RequestBody.Content {
+			var err error
+			if media.Schema, err = r.schema(media.Schema); err != nil {
+				return fmt.Errorf("request body %s: %w", mediaType, err)
+			}
+		}
+	}
+
+	for code, response := range op.Responses {
+		if response.Ref == "" {
+			continue
+		}
+		name, ok := strings.CutPrefix(response.Ref, responsesPrefix)
+		target, found := r.components.Responses[name]
+		if !ok || !found {
+			return fmt.Errorf("response %s: unknown response %q", code, response.Ref)
+		}
+		op.Responses[code] = target
+	}
+	return nil
+}
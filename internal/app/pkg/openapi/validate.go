@@ -0,0 +1,329 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Типы тела запроса, которые проверяются по схеме. Остальные тела (CSV, JSON Lines, формы)
+// читаются обработчиком потоком или через PostForm и не проверяются.
+const (
+	mediaTypeJSON = "application/json"
+	mediaTypeText = "text/plain"
+)
+
+// RequestError - запрос не соответствует спецификации
+type RequestError struct {
+	Message string
+}
+
+// Error -
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+func invalid(format string, args ...any) error {
+	return &RequestError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidateRequest проверяет параметры и тело запроса к операции по ее пути в спецификации.
+// pathParam - значение параметра пути по имени. Проверенное тело читается целиком и
+// возвращается в r.Body. Ошибка несоответствия спецификации - *RequestError,
+// остальные ошибки - ошибки чтения тела. Запрос к пути без операции не проверяется.
+func (s *Spec) ValidateRequest(r *http.Request, path string, pathParam func(string) string) error {
+	op, ok := s.Operation(r.Method, path)
+	if !ok {
+		return nil
+	}
+
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var (
+			value string
+			found bool
+		)
+		switch param.In {
+		case "path":
+			value = pathParam(param.Name)
+			found = true
+		case "query":
+			found = query.Has(param.Name)
+			value = query.Get(param.Name)
+		case "header":
+			value = r.Header.Get(param.Name)
+			found = value != ""
+		default:
+			continue
+		}
+
+		if !found {
+			if param.Required {
+				return invalid("%s parameter %q is required", param.In, param.Name)
+			}
+			continue
+		}
+		if err := validateParameter(param, value); err != nil {
+			return err
+		}
+	}
+
+	return validateBody(op.RequestBody, r)
+}
+
+func validateParameter(param *Parameter, raw string) error {
+	if param.Schema == nil {
+		return nil
+	}
+
+	location := fmt.Sprintf("%s parameter %q", param.In, param.Name)
+	var value any = raw
+	switch param.Schema.Type {
+	case "integer", "number":
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return invalid("%s %s", location, typeProblem(param.Schema.Type))
+		}
+		value = number
+	case "boolean":
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid("%s %s", location, typeProblem(param.Schema.Type))
+		}
+		value = flag
+	}
+	return validateValue(param.Schema, value, location)
+}
+
+func validateBody(body *RequestBody, r *http.Request) error {
+	if body == nil {
+		return nil
+	}
+
+	media, mediaType := requestMediaType(body, r.Header.Get("Content-Type"))
+	if media == nil || (mediaType != mediaTypeJSON && mediaType != mediaTypeText) {
+		return nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if body.Required {
+			return invalid("request body is required")
+		}
+		return nil
+	}
+	if media.Schema == nil {
+		return nil
+	}
+
+	if mediaType == mediaTypeText {
+		return validateValue(media.Schema, string(data), "body")
+	}
+
+	var value any
+	if err = json.Unmarshal(data, &value); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return invalid("malformed json at offset %d: %s", syntaxErr.Offset, syntaxErr)
+		}
+		return invalid("malformed json: %s", err)
+	}
+	return validateValue(media.Schema, value, "body")
+}
+
+// requestMediaType - описание тела по Content-Type запроса. Если операция принимает
+// единственный тип, он используется при любом Content-Type: обработчики исторически
+// не смотрят на заголовок, и клиенты присылают, например, application/x-gzip.
+func requestMediaType(body *RequestBody, contentType string) (*MediaType, string) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if media, ok := body.Content[mediaType]; ok {
+			return media, mediaType
+		}
+	}
+
+	if len(body.Content) == 1 {
+		for mediaType, media := range body.Content {
+			return media, mediaType
+		}
+	}
+	return nil, ""
+}
+
+// validateValue проверяет значение из encoding/json (числа - float64) по схеме.
+func validateValue(schema *Schema, value any, location string) error {
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return invalid("%s must not be null", location)
+	}
+
+	if err := validateType(schema, value, location); err != nil {
+		return err
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		return invalid("%s must be one of %s", location, enumList(schema.Enum))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			return invalid("%s must be at least %s", location, formatNumber(*schema.Minimum))
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			return invalid("%s must be at most %s", location, formatNumber(*schema.Maximum))
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				return invalid("%s must not be empty", location)
+			}
+			return invalid("%s must be at least %d characters long", location, *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return invalid("%s must be at most %d characters long", location, *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(v) {
+			return invalid("%s must match %s", location, schema.Pattern)
+		}
+	case []any:
+		return validateArray(schema, v, location)
+	case map[string]any:
+		return validateObject(schema, v, location)
+	}
+	return nil
+}
+
+func validateType(schema *Schema, value any, location string) error {
+	var ok bool
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = value.(float64)
+	case "integer":
+		var number float64
+		number, ok = value.(float64)
+		ok = ok && number == math.Trunc(number) && !math.IsInf(number, 0)
+	case "array":
+		_, ok = value.([]any)
+	case "object":
+		_, ok = value.(map[string]any)
+	default:
+		return nil
+	}
+
+	if !ok {
+		return invalid("%s %s", location, typeProblem(schema.Type))
+	}
+	return nil
+}
+
+func validateArray(schema *Schema, items []any, location string) error {
+	if schema.MinItems != nil && len(items) < *schema.MinItems {
+		if *schema.MinItems == 1 {
+			return invalid("%s must not be empty", location)
+		}
+		return invalid("%s must contain at least %d items", location, *schema.MinItems)
+	}
+	if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+		return invalid("%s must contain at most %d items", location, *schema.MaxItems)
+	}
+
+	if schema.Items == nil {
+		return nil
+	}
+	for i, item := range items {
+		if err := validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", location, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateObject(schema *Schema, fields map[string]any, location string) error {
+	if schema.MinProperties != nil && len(fields) < *schema.MinProperties {
+		if *schema.MinProperties == 1 {
+			return invalid("%s must not be empty", location)
+		}
+		return invalid("%s must contain at least %d fields", location, *schema.MinProperties)
+	}
+	for _, name := range schema.Required {
+		if _, ok := fields[name]; !ok {
+			return invalid("%s.%s is required", location, name)
+		}
+	}
+
+	// Поля проверяются в порядке имен, чтобы при нескольких ошибках ответ был одинаковым.
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			continue
+		}
+		if err := validateValue(property, fields[name], location+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func typeProblem(schemaType string) string {
+	switch schemaType {
+	case "integer", "array", "object":
+		return "must be an " + schemaType
+	default:
+		return "must be a " + schemaType
+	}
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, item := range enum {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []any) string {
+	values := make([]string, 0, len(enum))
+	for _, item := range enum {
+		switch v := item.(type) {
+		case float64:
+			values = append(values, formatNumber(v))
+		default:
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
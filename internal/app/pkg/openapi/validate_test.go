@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	spec, err := Load()
+	require.NoError(t, err)
+	assert.NotEmpty(t, spec.Routes())
+
+	ids := make(map[string]Route)
+	for _, route := range spec.Routes() {
+		op, ok := spec.Operation(route.Method, route.Path)
+		require.True(t, ok)
+		require.NotEmpty(t, op.OperationID, "%s %s", route.Method, route.Path)
+		require.NotEmpty(t, op.Responses, "%s %s", route.Method, route.Path)
+
+		prev, duplicate := ids[op.OperationID]
+		assert.False(t, duplicate, "operationId %s of %s %s is used by %s %s",
+			op.OperationID, route.Method, route.Path, prev.Method, prev.Path)
+		ids[op.OperationID] = route
+	}
+}
+
+func TestParse_UnknownRef(t *testing.T) {
+	_, err := Parse([]byte(`{"paths": {"/": {"get": {"parameters": [{"$ref": "#/components/parameters/Missing"}]}}}}`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`{"paths": {"/": {"post": {"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}}}}}}`))
+	assert.Error(t, err)
+}
+
+func TestTemplatePath(t *testing.T) {
+	assert.Equal(t, "/api/user/urls/{id}/revisions/{revision}/restore", TemplatePath("/api/user/urls/:id/revisions/:revision/restore"))
+	assert.Equal(t, "/{id}/{rest}", TemplatePath("/:id/*rest"))
+	assert.Equal(t, "/debug/pprof/", TemplatePath("/debug/pprof/"))
+}
+
+func TestSpec_ValidateRequest(t *testing.T) {
+	spec := MustLoad()
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		path        string
+		params      map[string]string
+		contentType string
+		body        string
+		wantErr     string
+	}{
+		{
+			name: "shorten json", method: http.MethodPost, target: "/api/shorten", path: "/api/shorten",
+			contentType: "application/json", body: `{"url": "https://example.com", "redirect_code": 308, "tags": ["a"]}`,
+		},
+		{
+			name: "shorten json without content type", method: http.MethodPost, target: "/api/shorten", path: "/api/shorten",
+			contentType: "application/x-gzip", body: `{"title": "no url"}`,
+			wantErr: "body.url is required",
+		},
+		{
+			name: "wrong field type", method: http.MethodPost, target: "/api/shorten", path: "/api/shorten",
+			contentType: "application/json", body: `{"url": 42}`,
+			wantErr: "body.url must be a string",
+		},
+		{
+			name: "enum", method: http.MethodPost, target: "/api/shorten", path: "/api/shorten",
+			contentType: "application/json", body: `{"url": "https://example.com", "redirect_code": 303}`,
+			wantErr: "body.redirect_code must be one of 0, 301, 302, 307, 308",
+		},
+		{
+			name: "malformed json", method: http.MethodPost, target: "/api/shorten", path: "/api/shorten",
+			contentType: "application/json", body: `{"url": `,
+			wantErr: "malformed json",
+		},
+		{
+			name: "empty body", method: http.MethodPost, target: "/api/shorten", path: "/api/shorten",
+			contentType: "application/json",
+			wantErr:     "request body is required",
+		},
+		{
+			name: "batch item", method: http.MethodPost, target: "/api/shorten/batch", path: "/api/shorten/batch",
+			contentType: "application/json", body: `[{"correlation_id": "1", "original_url": "https://example.com"}, {"correlation_id": "2"}]`,
+			wantErr: "body[1].original_url is required",
+		},
+		{
+			name: "batch must be array", method: http.MethodPost, target: "/api/shorten/batch", path: "/api/shorten/batch",
+			contentType: "application/json", body: `{"correlation_id": "1"}`,
+			wantErr: "body must be an array",
+		},
+		{
+			name: "text body", method: http.MethodPost, target: "/", path: "/",
+			contentType: "text/plain", body: "https://example.com",
+		},
+		{
+			name: "empty text body", method: http.MethodPost, target: "/", path: "/",
+			contentType: "text/plain", body: " ",
+			wantErr: "request body is required",
+		},
+		{
+			name: "delete empty list", method: http.MethodDelete, target: "/api/user/urls", path: "/api/user/urls",
+			contentType: "application/json", body: `[]`,
+			wantErr: "body must not be empty",
+		},
+		{
+			name: "update nullable fields", method: http.MethodPatch, target: "/api/user/urls/abc", path: "/api/user/urls/{id}",
+			params:      map[string]string{"id": "abc"},
+			contentType: "application/json", body: `{"title": null, "note": "note", "rules": [{"platform": "iOS", "destination": "https://example.com"}]}`,
+		},
+		{
+			name: "update nothing", method: http.MethodPatch, target: "/api/user/urls/abc", path: "/api/user/urls/{id}",
+			params:      map[string]string{"id": "abc"},
+			contentType: "application/json", body: `{}`,
+			wantErr: "body must not be empty",
+		},
+		{
+			name: "collection name pattern", method: http.MethodPost, target: "/api/user/collections", path: "/api/user/collections",
+			contentType: "application/json", body: `{"name": "bad name"}`,
+			wantErr: "body.name must match",
+		},
+		{
+			name: "webhook events", method: http.MethodPost, target: "/api/user/webhooks", path: "/api/user/webhooks",
+			contentType: "application/json", body: `{"url": "https://example.com/hook", "events": ["link.created", "link.renamed"]}`,
+			wantErr: "body.events[1] must be one of",
+		},
+		{
+			name: "query parameters", method: http.MethodGet, target: "/api/user/urls?limit=10&sort=created_at&deleted=active&q=x",
+			path: "/api/user/urls",
+		},
+		{
+			name: "query integer", method: http.MethodGet, target: "/api/user/urls?limit=ten", path: "/api/user/urls",
+			wantErr: `query parameter "limit" must be an integer`,
+		},
+		{
+			name: "query maximum", method: http.MethodGet, target: "/api/user/urls?limit=1001", path: "/api/user/urls",
+			wantErr: `query parameter "limit" must be at most 1000`,
+		},
+		{
+			name: "query enum", method: http.MethodGet, target: "/api/user/urls?sort=title", path: "/api/user/urls",
+			wantErr: `query parameter "sort" must be one of created_at, -created_at`,
+		},
+		{
+			name: "query boolean", method: http.MethodGet, target: "/api/user/urls/abc/qr?logo=yes&level=h", path: "/api/user/urls/{id}/qr",
+			params:  map[string]string{"id": "abc"},
+			wantErr: `query parameter "logo" must be a boolean`,
+		},
+		{
+			name: "path integer", method: http.MethodPost, target: "/api/user/urls/abc/revisions/x/restore",
+			path:    "/api/user/urls/{id}/revisions/{revision}/restore",
+			params:  map[string]string{"id": "abc", "revision": "x"},
+			wantErr: `path parameter "revision" must be an integer`,
+		},
+		{
+			name: "import stream is not read", method: http.MethodPost, target: "/api/user/import", path: "/api/user/import",
+			contentType: "text/csv", body: "alias,original_url\n",
+		},
+		{
+			name: "unknown route", method: http.MethodGet, target: "/unknown", path: "/unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			err := spec.ValidateRequest(req, tt.path, func(name string) string { return tt.params[name] })
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				body, readErr := io.ReadAll(req.Body)
+				require.NoError(t, readErr)
+				assert.Equal(t, tt.body, string(body), "body must stay readable for the handler")
+				return
+			}
+
+			var requestErr *RequestError
+			require.ErrorAs(t, err, &requestErr)
+			assert.Contains(t, requestErr.Message, tt.wantErr)
+		})
+	}
+}
+
+func TestSpec_ServeDocs(t *testing.T) {
+	spec := MustLoad()
+
+	w := httptest.NewRecorder()
+	spec.ServeDocs(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<code>/api/shorten</code>")
+	assert.Contains(t, w.Body.String(), "<code>redirect_code</code>")
+
+	w = httptest.NewRecorder()
+	spec.ServeJSON(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, string(document), w.Body.String())
+}
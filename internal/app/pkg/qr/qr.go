@@ -0,0 +1,190 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+	"sync"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Форматы изображения QR-кода.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Options - параметры отрисовки QR-кода
+type Options struct {
+	Size   int                  // ширина и высота изображения в пикселях
+	Margin int                  // белая рамка в модулях (точках) кода
+	Level  qrcode.RecoveryLevel // уровень коррекции ошибок
+	Logo   image.Image          // логотип в центре кода, может отсутствовать
+}
+
+// logoShare - доля ширины кода, которую занимает логотип; при уровне коррекции Q и H
+// такую площадь можно закрыть без потери читаемости.
+const logoShare = 5
+
+// ParseLevel - уровень коррекции ошибок по букве L, M, Q или H
+func ParseLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	default:
+		return 0, fmt.Errorf("unknown error correction level %q", level)
+	}
+}
+
+// Render - QR-код content в формате FormatPNG или FormatSVG
+func Render(content, format string, opts Options) ([]byte, error) {
+	code, err := qrcode.New(content, opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	modules := len(bitmap) + 2*opts.Margin
+	if opts.Size < modules {
+		return nil, fmt.Errorf("size must be at least %d pixels for this link", modules)
+	}
+
+	switch format {
+	case FormatPNG:
+		return renderPNG(bitmap, opts)
+	case FormatSVG:
+		return renderSVG(bitmap, opts)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func renderPNG(bitmap [][]bool, opts Options) ([]byte, error) {
+	modules := len(bitmap) + 2*opts.Margin
+	scale := opts.Size / modules
+	// Остаток от деления делим поровну между сторонами, чтобы код был по центру.
+	offset := (opts.Size-scale*modules)/2 + opts.Margin*scale
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				module := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, module, image.Black, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if opts.Logo != nil {
+		width := len(bitmap) * scale / logoShare
+		box := image.Rect(0, 0, width, width).Add(image.Pt((opts.Size-width)/2, (opts.Size-width)/2))
+		draw.Draw(img, box.Inset(-scale), image.White, image.Point{}, draw.Src)
+		drawScaled(img, box, opts.Logo)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func renderSVG(bitmap [][]bool, opts Options) ([]byte, error) {
+	modules := len(bitmap) + 2*opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, modules, modules)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	if opts.Logo != nil {
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, opts.Logo); err != nil {
+			return nil, err
+		}
+
+		width := float64(len(bitmap)) / logoShare
+		start := (float64(modules) - width) / 2
+		fmt.Fprintf(&buf, `<rect x="%g" y="%g" width="%g" height="%g" fill="#fff"/>`, start-1, start-1, width+2, width+2)
+		fmt.Fprintf(&buf, `<image x="%g" y="%g" width="%g" height="%g" href="data:image/png;base64,%s"/>`,
+			start, start, width, width, base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// drawScaled вписывает src в box с сохранением пропорций (ближайший сосед),
+// прозрачные участки логотипа остаются белыми.
+func drawScaled(dst draw.Image, box image.Rectangle, src image.Image) {
+	bounds := src.Bounds()
+	if bounds.Empty() || box.Empty() {
+		return
+	}
+
+	width, height := box.Dx(), box.Dy()
+	if bounds.Dx() > bounds.Dy() {
+		height = max(1, width*bounds.Dy()/bounds.Dx())
+	} else {
+		width = max(1, height*bounds.Dx()/bounds.Dy())
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			scaled.Set(x, y, src.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+
+	origin := box.Min.Add(image.Pt((box.Dx()-width)/2, (box.Dy()-height)/2))
+	draw.Draw(dst, scaled.Bounds().Add(origin), scaled, image.Point{}, draw.Over)
+}
+
+var logos sync.Map // путь -> image.Image
+
+// LoadLogo читает PNG-логотип; изображение кэшируется, файл читается один раз.
+func LoadLogo(path string) (image.Image, error) {
+	if path == "" {
+		return nil, errors.New("logo is not configured")
+	}
+	if logo, ok := logos.Load(path); ok {
+		return logo.(image.Image), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	logo, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("cant decode logo: %w", err)
+	}
+
+	logos.Store(path, logo)
+	return logo, nil
+}
@@ -0,0 +1,56 @@
+package qr
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	const content = "http://localhost:8080/abcdef"
+
+	t.Run("png", func(t *testing.T) {
+		data, err := Render(content, FormatPNG, Options{Size: 256, Margin: 4, Level: qrcode.Medium})
+		require.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, 256, 256), img.Bounds())
+
+		// Угол изображения приходится на белую рамку.
+		r, _, _, _ := img.At(2, 2).RGBA()
+		require.Equal(t, uint32(0xffff), r)
+	})
+
+	t.Run("png with logo", func(t *testing.T) {
+		logo := image.NewRGBA(image.Rect(0, 0, 10, 5))
+		_, err := Render(content, FormatPNG, Options{Size: 300, Margin: 2, Level: qrcode.Highest, Logo: logo})
+		require.NoError(t, err)
+	})
+
+	t.Run("svg", func(t *testing.T) {
+		data, err := Render(content, FormatSVG, Options{Size: 512, Margin: 0, Level: qrcode.Low})
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(data), `<svg xmlns="http://www.w3.org/2000/svg" width="512" height="512"`))
+		require.Contains(t, string(data), "M0 0h1v1h-1z")
+	})
+
+	t.Run("too small", func(t *testing.T) {
+		_, err := Render(content, FormatPNG, Options{Size: 16, Margin: 4, Level: qrcode.Medium})
+		require.Error(t, err)
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("h")
+	require.NoError(t, err)
+	require.Equal(t, qrcode.Highest, level)
+
+	_, err = ParseLevel("X")
+	require.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter - ограничивает количество неудачных попыток по ключу в скользящем окне
+type Limiter struct {
+	maxFailures int
+	window      time.Duration
+
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	now      func() time.Time
+}
+
+// New -
+func New(maxFailures int, window time.Duration) *Limiter {
+	return &Limiter{
+		maxFailures: maxFailures,
+		window:      window,
+		failures:    make(map[string][]time.Time),
+		now:         time.Now,
+	}
+}
+
+// Blocked - сколько еще ключ заблокирован, 0 если попытки разрешены
+func (l *Limiter) Blocked(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	failures := l.actual(key)
+	if len(failures) < l.maxFailures {
+		return 0
+	}
+
+	return failures[len(failures)-l.maxFailures].Add(l.window).Sub(l.now())
+}
+
+// Fail - учитывает неудачную попытку
+func (l *Limiter) Fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.failures[key] = append(l.actual(key), l.now())
+}
+
+// Reset - забывает неудачные попытки после успешной
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+}
+
+// actual отбрасывает попытки старше окна.
+func (l *Limiter) actual(key string) []time.Time {
+	failures := l.failures[key]
+	since := l.now().Add(-l.window)
+
+	i := 0
+	for i < len(failures) && !failures[i].After(since) {
+		i++
+	}
+	failures = failures[i:]
+
+	if len(failures) == 0 {
+		delete(l.failures, key)
+		return nil
+	}
+	l.failures[key] = failures
+
+	return failures
+}
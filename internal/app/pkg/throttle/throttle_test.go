@@ -0,0 +1,31 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := New(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.Fail("abc")
+	require.Zero(t, l.Blocked("abc"))
+
+	now = now.Add(10 * time.Second)
+	l.Fail("abc")
+	require.Equal(t, 50*time.Second, l.Blocked("abc"))
+	require.Zero(t, l.Blocked("other"))
+
+	now = now.Add(50 * time.Second)
+	require.Zero(t, l.Blocked("abc"))
+
+	l.Fail("abc")
+	require.Equal(t, 10*time.Second, l.Blocked("abc"))
+
+	l.Reset("abc")
+	require.Zero(t, l.Blocked("abc"))
+}
@@ -0,0 +1,167 @@
+package urlcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// regexPrefix - строки файла с этим префиксом задают регулярное выражение для всего адреса
+const regexPrefix = "regex:"
+
+// Blocklist - локальный список запрещенных доменов и регулярных выражений.
+//
+// Формат файла: по одному правилу в строке, пустые строки и строки с # пропускаются.
+// Домен запрещает и все свои поддомены, строка "regex:<выражение>" проверяется по всему адресу.
+// Файл перечитывается при изменении, но не чаще раза в reloadEvery; если новая версия
+// не разбирается, продолжает действовать прежний список.
+type Blocklist struct {
+	path        string
+	reloadEvery time.Duration
+
+	mu        sync.RWMutex
+	domains   map[string]struct{}
+	patterns  []*regexp.Regexp
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+	now       func() time.Time
+}
+
+// NewBlocklist - читает список из файла path, ошибка чтения или разбора возвращается сразу
+func NewBlocklist(path string, reloadEvery time.Duration) (*Blocklist, error) {
+	b := &Blocklist{
+		path:        path,
+		reloadEvery: reloadEvery,
+		now:         time.Now,
+	}
+	if err := b.Reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Check -
+func (b *Blocklist) Check(_ context.Context, rawURL string) (Verdict, error) {
+	b.maybeReload()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Verdict{}, err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	// example.com запрещает и sub.example.com.
+	for domain := host; domain != ""; {
+		if _, found := b.domains[domain]; found {
+			return Verdict{Malicious: true, Reason: "blocklist: " + domain}, nil
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+		domain = parent
+	}
+
+	for _, pattern := range b.patterns {
+		if pattern.MatchString(rawURL) {
+			return Verdict{Malicious: true, Reason: "blocklist: " + regexPrefix + pattern.String()}, nil
+		}
+	}
+
+	return Verdict{}, nil
+}
+
+// Reload - перечитывает файл независимо от того, менялся ли он
+func (b *Blocklist) Reload() error {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return fmt.Errorf("cant stat blocklist: %w", err)
+	}
+
+	domains, patterns, err := parseBlocklist(b.path)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.domains, b.patterns = domains, patterns
+	b.modTime, b.size = info.ModTime(), info.Size()
+	b.checkedAt = b.now()
+
+	return nil
+}
+
+// maybeReload перечитывает файл, если с прошлой проверки прошло reloadEvery и файл изменился.
+func (b *Blocklist) maybeReload() {
+	b.mu.Lock()
+	now := b.now()
+	if now.Sub(b.checkedAt) < b.reloadEvery {
+		b.mu.Unlock()
+		return
+	}
+	b.checkedAt = now
+	modTime, size := b.modTime, b.size
+	b.mu.Unlock()
+
+	info, err := os.Stat(b.path)
+	if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+		return
+	}
+
+	_ = b.Reload()
+}
+
+// parseBlocklist читает правила из файла.
+func parseBlocklist(path string) (map[string]struct{}, []*regexp.Regexp, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cant open blocklist: %w", err)
+	}
+	defer file.Close()
+
+	domains := make(map[string]struct{})
+	var patterns []*regexp.Regexp
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		rule := strings.TrimSpace(scanner.Text())
+		if rule == "" || strings.HasPrefix(rule, "#") {
+			continue
+		}
+
+		if expr, ok := strings.CutPrefix(rule, regexPrefix); ok {
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, nil, fmt.Errorf("blocklist line %d: %w", line, err)
+			}
+			patterns = append(patterns, pattern)
+			continue
+		}
+
+		// Проверяемые адреса уже нормализованы, поэтому IDN в списке тоже переводятся в punycode.
+		domain := strings.TrimSuffix(strings.ToLower(rule), ".")
+		if ascii, err := idna.Lookup.ToASCII(domain); err == nil {
+			domain = ascii
+		}
+		domains[domain] = struct{}{}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("cant read blocklist: %w", err)
+	}
+
+	return domains, patterns, nil
+}
@@ -0,0 +1,76 @@
+package urlcheck
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklist_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte(`# фишинг
+evil.example
+Пример.рф
+
+regex:^https?://[^/]+/wp-login\.php
+`), 0o600))
+
+	b, err := NewBlocklist(path, time.Minute)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	tests := []struct {
+		url       string
+		malicious bool
+	}{
+		{url: "https://evil.example/login", malicious: true},
+		{url: "https://login.evil.example", malicious: true},
+		{url: "https://notevil.example", malicious: false},
+		{url: "https://xn--e1afmkfd.xn--p1ai/path", malicious: true},
+		{url: "https://good.example/wp-login.php", malicious: true},
+		{url: "https://good.example/docs/wp-login.php", malicious: false},
+	}
+	for _, tt := range tests {
+		verdict, err := b.Check(ctx, tt.url)
+		require.NoError(t, err)
+		require.Equal(t, tt.malicious, verdict.Malicious, tt.url)
+		if tt.malicious {
+			require.NotEmpty(t, verdict.Reason)
+		}
+	}
+}
+
+func TestBlocklist_hotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("evil.example\n"), 0o600))
+
+	b, err := NewBlocklist(path, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	check := func(url string) bool {
+		verdict, err := b.Check(ctx, url)
+		require.NoError(t, err)
+		return verdict.Malicious
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte("phishing.example\nevil.example\n"), 0o600))
+	require.False(t, check("https://phishing.example"), "reloaded before reloadEvery")
+
+	now = now.Add(time.Minute)
+	require.True(t, check("https://phishing.example"))
+
+	// Неразбираемая версия файла не отменяет прежний список.
+	require.NoError(t, os.WriteFile(path, []byte("regex:([\n"), 0o600))
+	now = now.Add(time.Minute)
+	require.True(t, check("https://phishing.example"))
+
+	_, err = NewBlocklist(path, time.Minute)
+	require.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package urlcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultSafeBrowsingURL - адрес Google Safe Browsing API v4
+const DefaultSafeBrowsingURL = "https://safebrowsing.googleapis.com"
+
+// safeBrowsingClientID - идентификатор клиента в запросах к Safe Browsing
+const safeBrowsingClientID = "url-shortener"
+
+// threatTypes - категории угроз, при которых адрес признается вредоносным
+var threatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+
+// SafeBrowsing - клиент метода threatMatches:find Safe Browsing API v4.
+// Endpoint можно направить на локальный сервер с тем же протоколом.
+type SafeBrowsing struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewSafeBrowsing -
+func NewSafeBrowsing(endpoint, apiKey string, timeout time.Duration) *SafeBrowsing {
+	return &SafeBrowsing{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+type threatEntry struct {
+	URL string `json:"url"`
+}
+
+type findRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string      `json:"threatTypes"`
+		PlatformTypes    []string      `json:"platformTypes"`
+		ThreatEntryTypes []string      `json:"threatEntryTypes"`
+		ThreatEntries    []threatEntry `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+type findResponse struct {
+	Matches []struct {
+		ThreatType  string      `json:"threatType"`
+		ThreatEntry threatEntry `json:"threat"`
+	} `json:"matches"`
+}
+
+// Check -
+func (s *SafeBrowsing) Check(ctx context.Context, rawURL string) (Verdict, error) {
+	var body findRequest
+	body.Client.ClientID = safeBrowsingClientID
+	body.Client.ClientVersion = "1.0"
+	body.ThreatInfo.ThreatTypes = threatTypes
+	body.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	body.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	body.ThreatInfo.ThreatEntries = []threatEntry{{URL: rawURL}}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	endpoint := s.endpoint + "/v4/threatMatches:find?key=" + url.QueryEscape(s.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("safe browsing: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("safe browsing: unexpected status %d", resp.StatusCode)
+	}
+
+	var result findResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Verdict{}, fmt.Errorf("safe browsing: %w", err)
+	}
+	if len(result.Matches) == 0 {
+		return Verdict{}, nil
+	}
+
+	return Verdict{Malicious: true, Reason: "safe browsing: " + result.Matches[0].ThreatType}, nil
+}
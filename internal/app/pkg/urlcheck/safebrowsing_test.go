@@ -0,0 +1,72 @@
+package urlcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeBrowsing_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v4/threatMatches:find" || r.URL.Query().Get("key") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body findRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.ThreatInfo.ThreatEntries) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch body.ThreatInfo.ThreatEntries[0].URL {
+		case "https://phishing.example":
+			_, _ = w.Write([]byte(`{"matches": [{"threatType": "SOCIAL_ENGINEERING", "platformType": "ANY_PLATFORM",
+				"threat": {"url": "https://phishing.example"}}]}`))
+		case "https://broken.example":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	checker := NewSafeBrowsing(server.URL+"/", "secret", time.Second)
+
+	verdict, err := checker.Check(ctx, "https://phishing.example")
+	require.NoError(t, err)
+	require.True(t, verdict.Malicious)
+	require.Equal(t, "safe browsing: SOCIAL_ENGINEERING", verdict.Reason)
+
+	verdict, err = checker.Check(ctx, "https://example.com")
+	require.NoError(t, err)
+	require.False(t, verdict.Malicious)
+
+	_, err = checker.Check(ctx, "https://broken.example")
+	require.Error(t, err)
+
+	_, err = NewSafeBrowsing(server.URL, "wrong", time.Second).Check(ctx, "https://example.com")
+	require.Error(t, err)
+}
+
+func TestChain_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"matches": [{"threatType": "MALWARE"}]}`))
+	}))
+	defer server.Close()
+
+	chain := Chain{NewSafeBrowsing(server.URL, "", time.Second)}
+	verdict, err := chain.Check(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	require.Equal(t, Verdict{Malicious: true, Reason: "safe browsing: MALWARE"}, verdict)
+
+	verdict, err = Chain{}.Check(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	require.False(t, verdict.Malicious)
+}
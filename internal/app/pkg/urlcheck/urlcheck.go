@@ -0,0 +1,29 @@
+package urlcheck
+
+import "context"
+
+// Verdict - результат проверки адреса
+type Verdict struct {
+	Malicious bool
+	Reason    string // кто и почему признал адрес вредоносным, например "blocklist: example.com"
+}
+
+// URLChecker - проверка адреса перед сокращением.
+// Ошибка означает, что проверку выполнить не удалось, а не что адрес вредоносный.
+type URLChecker interface {
+	Check(ctx context.Context, rawURL string) (Verdict, error)
+}
+
+// Chain - выполняет проверки по порядку до первой, признавшей адрес вредоносным
+type Chain []URLChecker
+
+// Check -
+func (c Chain) Check(ctx context.Context, rawURL string) (Verdict, error) {
+	for _, checker := range c {
+		verdict, err := checker.Check(ctx, rawURL)
+		if err != nil || verdict.Malicious {
+			return verdict, err
+		}
+	}
+	return Verdict{}, nil
+}
@@ -0,0 +1,93 @@
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// MaxLength - максимальная длина адреса до и после нормализации
+const MaxLength = 2048
+
+// Ошибки проверки адреса.
+var (
+	ErrEmpty             = errors.New("url must not be empty")
+	ErrTooLong           = fmt.Errorf("url must not exceed %d characters", MaxLength)
+	ErrInvalid           = errors.New("url is malformed")
+	ErrUnsupportedScheme = errors.New("url scheme must be http or https")
+	ErrMissingHost       = errors.New("url must contain a host")
+)
+
+// defaultPorts - порты, которые не указываются в каноническом адресе
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// hosts - преобразование IDN в punycode; подчеркивания в именах хостов встречаются на практике,
+// поэтому строгая проверка STD3 отключена.
+var hosts = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+// Normalize проверяет адрес и приводит его к канонической форме: схема и хост в нижнем регистре,
+// хост в punycode, без порта по умолчанию и без "/" в качестве единственного пути.
+// Одинаковые по смыслу адреса после нормализации совпадают, на этом основана дедупликация.
+func Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrEmpty
+	}
+	if len(raw) > MaxLength {
+		return "", ErrTooLong
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	// url.Parse уже приводит схему к нижнему регистру.
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return "", ErrUnsupportedScheme
+	}
+	if u.Opaque != "" || u.Hostname() == "" {
+		return "", ErrMissingHost
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", err
+	}
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+
+	if u.Path == "/" {
+		u.Path, u.RawPath = "", ""
+	}
+
+	result := u.String()
+	if len(result) > MaxLength {
+		return "", ErrTooLong
+	}
+	return result, nil
+}
+
+// normalizeHost - IP-адреса остаются как есть, доменные имена переводятся в punycode.
+func normalizeHost(host string) (string, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), nil
+	}
+
+	ascii, err := hosts.ToASCII(host)
+	if err != nil || ascii == "" {
+		return "", fmt.Errorf("url host %q is invalid", host)
+	}
+	return strings.ToLower(ascii), nil
+}
@@ -0,0 +1,67 @@
+package urlnorm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "already canonical", raw: "https://example.com/docs?q=1", want: "https://example.com/docs?q=1"},
+		{name: "spaces trimmed", raw: "  https://example.com/docs\n", want: "https://example.com/docs"},
+		{name: "scheme and host lowercased", raw: "HTTPS://Example.COM/Docs", want: "https://example.com/Docs"},
+		{name: "root slash dropped", raw: "https://example.com/", want: "https://example.com"},
+		{name: "root slash with query", raw: "https://example.com/?q=1", want: "https://example.com?q=1"},
+		{name: "nested trailing slash kept", raw: "https://example.com/docs/", want: "https://example.com/docs/"},
+		{name: "default http port", raw: "http://example.com:80/a", want: "http://example.com/a"},
+		{name: "default https port", raw: "https://example.com:443", want: "https://example.com"},
+		{name: "custom port kept", raw: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{name: "idn to punycode", raw: "https://Пример.рф/путь", want: "https://xn--e1afmkfd.xn--p1ai/%D0%BF%D1%83%D1%82%D1%8C"},
+		{name: "ipv4", raw: "http://127.0.0.1:8080/", want: "http://127.0.0.1:8080"},
+		{name: "ipv6 default port", raw: "http://[::1]:80/a", want: "http://[::1]/a"},
+		{name: "underscore in host", raw: "https://my_host.example.com", want: "https://my_host.example.com"},
+		{name: "fragment kept", raw: "https://example.com/app#/settings", want: "https://example.com/app#/settings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalize_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		err  error
+	}{
+		{name: "empty", raw: "", err: ErrEmpty},
+		{name: "blank", raw: "   ", err: ErrEmpty},
+		{name: "javascript", raw: "javascript:alert(1)", err: ErrUnsupportedScheme},
+		{name: "ftp", raw: "ftp://example.com/file", err: ErrUnsupportedScheme},
+		{name: "no scheme", raw: "example.com", err: ErrUnsupportedScheme},
+		{name: "no host", raw: "https:///path", err: ErrMissingHost},
+		{name: "opaque", raw: "https:example.com", err: ErrMissingHost},
+		{name: "malformed", raw: "https://exa mple.com/%zz", err: ErrInvalid},
+		{name: "too long", raw: "https://example.com/" + strings.Repeat("a", MaxLength), err: ErrTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Normalize(tt.raw)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err := Normalize("https://-invalid-.com")
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenHost - адрес подписки ведет на сам сервис или во внутреннюю сеть
+var ErrForbiddenHost = errors.New("webhook url must point to a public host")
+
+// forbiddenPrefixes - сети, в которые не уходят доставки, помимо loopback, частных,
+// link-local, multicast и неуказанных адресов (их проверяет netip.Addr).
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "этот" хост
+	netip.MustParsePrefix("100.64.0.0/10"),  // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // служебные адреса IETF
+	netip.MustParsePrefix("198.18.0.0/15"),  // стенды
+	netip.MustParsePrefix("240.0.0.0/4"),    // зарезервированные
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64 со встроенным IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"), // локальный NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4 со встроенным IPv4
+	netip.MustParsePrefix("fec0::/10"),      // устаревшие site-local
+}
+
+// forbiddenHostSuffixes - имена, которые по соглашению указывают на локальный хост
+// или внутреннюю сеть и не резолвятся публичным DNS.
+var forbiddenHostSuffixes = []string{"localhost", ".localhost", ".local", ".internal", ".localdomain", ".home.arpa"}
+
+// CheckURL проверяет адрес подписки при регистрации: хост не должен быть внутренним адресом
+// или локальным именем. Имя, которое резолвится во внутренний адрес, отсекает клиент NewClient при подключении.
+func CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, suffix := range forbiddenHostSuffixes {
+		if host == strings.TrimPrefix(suffix, ".") || strings.HasSuffix(host, suffix) {
+			return ErrForbiddenHost
+		}
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !Allowed(addr) {
+		return ErrForbiddenHost
+	}
+	return nil
+}
+
+// Allowed - можно ли отправлять доставку на адрес addr.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewClient - HTTP-клиент доставок. Адрес проверяется после резолвинга, непосредственно
+// перед подключением, поэтому DNS-имя подписчика не может вести во внутреннюю сеть.
+// Редиректы не выполняются (ответ 3xx считается неудачной доставкой), прокси из окружения
+// не используется, чтобы проверка касалась самого подписчика.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			if !Allowed(addr) {
+				return ErrForbiddenHost
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{url: "https://example.com/hook", allowed: true},
+		{url: "https://93.184.216.34/hook", allowed: true},
+		{url: "https://[2606:4700::1111]/hook", allowed: true},
+		{url: "http://127.0.0.1:8080/api", allowed: false},
+		{url: "http://localhost/hook", allowed: false},
+		{url: "http://api.localhost/hook", allowed: false},
+		{url: "http://metadata.google.internal/computeMetadata/v1", allowed: false},
+		{url: "http://10.1.2.3/hook", allowed: false},
+		{url: "http://172.16.0.1/hook", allowed: false},
+		{url: "http://192.168.1.1/hook", allowed: false},
+		{url: "http://169.254.169.254/latest/meta-data", allowed: false},
+		{url: "http://100.64.0.1/hook", allowed: false},
+		{url: "http://0.0.0.0/hook", allowed: false},
+		{url: "http://[::1]/hook", allowed: false},
+		{url: "http://[::ffff:127.0.0.1]/hook", allowed: false},
+		{url: "http://[fd00::1]/hook", allowed: false},
+		{url: "http://[fe80::1]/hook", allowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := CheckURL(tt.url)
+			if tt.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrForbiddenHost)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	// Адрес проверяется при подключении, даже если регистрацию обошли.
+	_, err := NewClient(time.Second).Post(server.URL, "application/json", nil)
+	require.ErrorIs(t, err, ErrForbiddenHost)
+	require.Zero(t, hits)
+
+	redirect := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data", http.StatusFound))
+	defer redirect.Close()
+
+	client := NewClient(time.Second)
+	client.Transport = http.DefaultTransport
+	resp, err := client.Post(redirect.URL, "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+}
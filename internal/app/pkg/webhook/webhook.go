@@ -0,0 +1,81 @@
+// Package webhook - подпись доставок событий подписчикам и проверка адресов подписок.
+//
+// Каждая доставка несет заголовок
+//
+//	X-Webhook-Signature: t=<unix-время>,v1=<hex HMAC-SHA256 от "<unix-время>.<тело>">
+//
+// Подписчик пересчитывает HMAC своим ключом и отбрасывает запросы со старым t,
+// чтобы перехваченную доставку нельзя было повторить.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки доставки.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Ошибки проверки подписи.
+var (
+	ErrMalformedSignature = errors.New("malformed webhook signature")
+	ErrInvalidSignature   = errors.New("webhook signature mismatch")
+	ErrExpiredSignature   = errors.New("webhook signature is too old")
+)
+
+// Sign - значение заголовка SignatureHeader для тела body, отправленного в момент timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify - проверяет подпись header тела body; подписи старше tolerance отклоняются,
+// нулевой tolerance отключает проверку возраста
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return ErrMalformedSignature
+	}
+	signature, err := hex.DecodeString(v1)
+	if err != nil {
+		return ErrMalformedSignature
+	}
+
+	if !hmac.Equal(signature, mac(secret, t, body)) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(unix, 0)) > tolerance {
+		return ErrExpiredSignature
+	}
+
+	return nil
+}
+
+// mac считает HMAC-SHA256 от "t.body".
+func mac(secret, t string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(t))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,23 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"type":"link.created"}`)
+	sentAt := time.Unix(1700000000, 0)
+	header := Sign("secret", sentAt, body)
+
+	require.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+	require.NoError(t, Verify("secret", header, body, 5*time.Minute, sentAt.Add(time.Minute)))
+
+	require.ErrorIs(t, Verify("other", header, body, 0, sentAt), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", header, []byte(`{}`), 0, sentAt), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", header, body, 5*time.Minute, sentAt.Add(time.Hour)), ErrExpiredSignature)
+	require.ErrorIs(t, Verify("secret", "v1=abc", body, 0, sentAt), ErrMalformedSignature)
+	require.ErrorIs(t, Verify("secret", "t=1,v1=zz", body, 0, sentAt), ErrMalformedSignature)
+}
@@ -20,12 +20,14 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	Shortener_Shorten_FullMethodName  = "/shortener.Shortener/Shorten"
-	Shortener_Expand_FullMethodName   = "/shortener.Shortener/Expand"
-	Shortener_GetBatch_FullMethodName = "/shortener.Shortener/GetBatch"
-	Shortener_Batch_FullMethodName    = "/shortener.Shortener/Batch"
-	Shortener_Ping_FullMethodName     = "/shortener.Shortener/Ping"
-	Shortener_GetStats_FullMethodName = "/shortener.Shortener/GetStats"
+	Shortener_Shorten_FullMethodName    = "/shortener.Shortener/Shorten"
+	Shortener_Expand_FullMethodName     = "/shortener.Shortener/Expand"
+	Shortener_GetBatch_FullMethodName   = "/shortener.Shortener/GetBatch"
+	Shortener_Batch_FullMethodName      = "/shortener.Shortener/Batch"
+	Shortener_Ping_FullMethodName       = "/shortener.Shortener/Ping"
+	Shortener_GetStats_FullMethodName   = "/shortener.Shortener/GetStats"
+	Shortener_UpdateLink_FullMethodName = "/shortener.Shortener/UpdateLink"
+	Shortener_GetQR_FullMethodName      = "/shortener.Shortener/GetQR"
 )
 
 // ShortenerClient is the client API for Shortener service.
@@ -38,6 +40,8 @@ type ShortenerClient interface {
 	Batch(ctx context.Context, in *ShortBatchRequest, opts ...grpc.CallOption) (*ShortBatchResponse, error)
 	Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
 	GetStats(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*UpdateLinkResponse, error)
+	GetQR(ctx context.Context, in *GetQRRequest, opts ...grpc.CallOption) (*GetQRResponse, error)
 }
 
 type shortenerClient struct {
@@ -108,6 +112,26 @@ func (c *shortenerClient) GetStats(ctx context.Context, in *empty.Empty, opts ..
 	return out, nil
 }
 
+func (c *shortenerClient) UpdateLink(ctx context.Context, in *UpdateLinkRequest, opts ...grpc.CallOption) (*UpdateLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateLinkResponse)
+	err := c.cc.Invoke(ctx, Shortener_UpdateLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) GetQR(ctx context.Context, in *GetQRRequest, opts ...grpc.CallOption) (*GetQRResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQRResponse)
+	err := c.cc.Invoke(ctx, Shortener_GetQR_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortenerServer is the server API for Shortener service.
 // All implementations must embed UnimplementedShortenerServer
 // for forward compatibility
@@ -118,6 +142,8 @@ type ShortenerServer interface {
 	Batch(context.Context, *ShortBatchRequest) (*ShortBatchResponse, error)
 	Ping(context.Context, *empty.Empty) (*empty.Empty, error)
 	GetStats(context.Context, *empty.Empty) (*GetStatsResponse, error)
+	UpdateLink(context.Context, *UpdateLinkRequest) (*UpdateLinkResponse, error)
+	GetQR(context.Context, *GetQRRequest) (*GetQRResponse, error)
 	mustEmbedUnimplementedShortenerServer()
 }
 
@@ -143,6 +169,12 @@ func (UnimplementedShortenerServer) Ping(context.Context, *empty.Empty) (*empty.
 func (UnimplementedShortenerServer) GetStats(context.Context, *empty.Empty) (*GetStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
+func (UnimplementedShortenerServer) UpdateLink(context.Context, *UpdateLinkRequest) (*UpdateLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLink not implemented")
+}
+func (UnimplementedShortenerServer) GetQR(context.Context, *GetQRRequest) (*GetQRResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQR not implemented")
+}
 func (UnimplementedShortenerServer) mustEmbedUnimplementedShortenerServer() {}
 
 // UnsafeShortenerServer may be embedded to opt out of forward compatibility for this service.
@@ -264,6 +296,42 @@ func _Shortener_GetStats_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Shortener_UpdateLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).UpdateLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_UpdateLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).UpdateLink(ctx, req.(*UpdateLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_GetQR_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQRRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).GetQR(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_GetQR_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).GetQR(ctx, req.(*GetQRRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Shortener_ServiceDesc is the grpc.ServiceDesc for Shortener service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -295,6 +363,14 @@ var Shortener_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetStats",
 			Handler:    _Shortener_GetStats_Handler,
 		},
+		{
+			MethodName: "UpdateLink",
+			Handler:    _Shortener_UpdateLink_Handler,
+		},
+		{
+			MethodName: "GetQR",
+			Handler:    _Shortener_GetQR_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/app/proto/shortener.proto",
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// CreateCollection - создание пустой коллекции пользователя
+func (r *UserRepo) CreateCollection(ctx context.Context, request user.CreateCollectionRequest) user.CreateCollectionResponse {
+	if err := validateGroupName("collection", request.Body.Name); err != nil {
+		return user.CreateCollectionResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	collection := storage.Collection{
+		Name:      request.Body.Name,
+		UserID:    request.UserID,
+		CreatedAt: time.Now(),
+	}
+	if err := r.storage.CreateCollection(ctx, collection); err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, models.ErrAlreadyExists) {
+			code = http.StatusConflict
+		}
+		return user.CreateCollectionResponse{
+			Code:   code,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return user.CreateCollectionResponse{
+		Code:   http.StatusCreated,
+		Status: success,
+		Response: &user.Collection{
+			Name:      collection.Name,
+			CreatedAt: collection.CreatedAt,
+		},
+	}
+}
+
+// GetCollections - коллекции пользователя с количеством ссылок в каждой
+func (r *UserRepo) GetCollections(ctx context.Context, request user.GetCollectionsRequest) user.GetCollectionsResponse {
+	collections, err := r.storage.GetCollections(ctx, request.UserID)
+	if err != nil {
+		return user.GetCollectionsResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	result := make([]user.Collection, 0, len(collections))
+	for _, collection := range collections {
+		result = append(result, user.Collection{
+			Name:      collection.Name,
+			Links:     collection.Links,
+			CreatedAt: collection.CreatedAt,
+		})
+	}
+
+	return user.GetCollectionsResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: result,
+	}
+}
+
+// DeleteCollection - удаление коллекции, возвращает сокращения ее ссылок для удаления воркером
+func (r *UserRepo) DeleteCollection(ctx context.Context, request user.DeleteCollectionRequest) user.DeleteCollectionResponse {
+	batch, err := r.storage.GetBatchByUserID(ctx, request.UserID, storage.BatchQuery{
+		Collection: request.Name,
+		Deleted:    storage.DeletedExclude,
+	})
+	if err != nil {
+		return user.DeleteCollectionResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if err = r.storage.DeleteCollection(ctx, request.UserID, request.Name); err != nil {
+		return user.DeleteCollectionResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	aliases := make([]string, 0, len(batch))
+	for _, record := range batch {
+		aliases = append(aliases, record.Alias)
+	}
+
+	return user.DeleteCollectionResponse{
+		Code:     http.StatusAccepted,
+		Status:   success,
+		Response: aliases,
+	}
+}
+
+// ExportCollection - все неудаленные ссылки коллекции
+func (r *UserRepo) ExportCollection(ctx context.Context, request user.ExportCollectionRequest) user.ExportCollectionResponse {
+	collections, err := r.storage.GetCollections(ctx, request.UserID)
+	if err != nil {
+		return user.ExportCollectionResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	found := false
+	for _, collection := range collections {
+		if collection.Name == request.Name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return user.ExportCollectionResponse{
+			Code:   http.StatusNotFound,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: models.ErrCollectionNotFound.Error(),
+			},
+		}
+	}
+
+	batch, err := r.storage.GetBatchByUserID(ctx, request.UserID, storage.BatchQuery{
+		Collection: request.Name,
+		Deleted:    storage.DeletedExclude,
+	})
+	if err != nil {
+		return user.ExportCollectionResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	result := make([]user.BatchByUserID, 0, len(batch))
+	for _, record := range batch {
+		result = append(result, user.BatchByUserID{
+			ShortURL:     request.BaseURL + "/" + record.Alias,
+			OriginalURL:  record.Object,
+			Title:        record.Title,
+			Note:         record.Note,
+			Tags:         record.Tags,
+			Collection:   record.Collection,
+			RedirectCode: record.RedirectCode,
+			ForwardQuery: record.ForwardQuery,
+			ForwardPath:  record.ForwardPath,
+			Protected:    record.PasswordHash != "",
+			MaxClicks:    record.MaxClicks,
+			Clicks:       record.Clicks,
+			ActiveFrom:   optionalTime(record.ActiveFrom),
+			Rules:        routingRules(record.Rules),
+			Variants:     linkVariants(record.Variants),
+			Sticky:       record.Sticky,
+			Interstitial: record.Interstitial,
+			Blocked:      record.BlockedReason,
+			CreatedAt:    record.CreatedAt,
+			UpdatedAt:    record.UpdatedAt,
+		})
+	}
+
+	return user.ExportCollectionResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: result,
+	}
+}
@@ -1,8 +1,46 @@
 package repositories
 
+import "time"
+
 // Константы для репощитория.
 const (
 	fail        = "fail"
 	success     = "success"
 	sizeOfAlias = 6
+
+	defaultPageSize = 100
+	maxPageSize     = 1000
+
+	maxTitleLength = 255
+	maxNoteLength  = 2000
+
+	maxTagsPerLink     = 20
+	maxGroupNameLength = 64
+	maxRulesPerLink    = 20
+	maxVariantsPerLink = 10
+	maxVariantWeight   = 1000
+
+	minPasswordLength = 4
+	maxPasswordLength = 72 // больше bcrypt не учитывает
+
+	maxPasswordAttempts    = 5
+	passwordAttemptsWindow = 15 * time.Minute
+
+	defaultQRSize   = 256
+	minQRSize       = 64
+	maxQRSize       = 2048
+	defaultQRMargin = 4
+	maxQRMargin     = 16
+
+	maxWebhooksPerUser        = 10
+	webhookSecretSize         = 32
+	defaultDeliveriesPageSize = 50
+	maxDeliveriesPageSize     = 500
+
+	maxURLsPerDeleteJob = 10000
+
+	importBatchSize     = 500
+	maxAliasAttempts    = 5
+	maxImportReportRows = 1000
+	exportPageSize      = 1000
 )
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// EnqueueDeleteLinks - ставит удаление ссылок пользователя в очередь, само удаление выполняет
+// workers.Worker через DeleteBatchLinks
+func (r *UserRepo) EnqueueDeleteLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteJobResponse {
+	var urls []string
+	for _, body := range request.Body {
+		urls = append(urls, body.URLS...)
+	}
+
+	if err := validateDeleteURLs(urls); err != nil {
+		return user.DeleteJobResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	job := storage.DeleteJob{
+		ID:        uuid.NewString(),
+		UserID:    request.UserID,
+		URLs:      urls,
+		Status:    storage.JobPending,
+		CreatedAt: time.Now(),
+	}
+	job.UpdatedAt = job.CreatedAt
+	if err := r.storage.AddDeleteJob(ctx, job); err != nil {
+		return user.DeleteJobResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return user.DeleteJobResponse{
+		Code:     http.StatusAccepted,
+		Status:   success,
+		Response: deleteJobResponse(job),
+	}
+}
+
+// GetDeleteJob - состояние задания на удаление, созданного пользователем
+func (r *UserRepo) GetDeleteJob(ctx context.Context, request user.GetDeleteJobRequest) user.DeleteJobResponse {
+	job, err := r.storage.GetDeleteJob(ctx, request.UserID, request.JobID)
+	if err != nil {
+		return user.DeleteJobResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return user.DeleteJobResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: deleteJobResponse(job),
+	}
+}
+
+// validateDeleteURLs проверяет список сокращений для задания на удаление.
+func validateDeleteURLs(urls []string) error {
+	if len(urls) == 0 {
+		return errors.New("no links to delete")
+	}
+	if len(urls) > maxURLsPerDeleteJob {
+		return fmt.Errorf("at most %d links per request", maxURLsPerDeleteJob)
+	}
+	for _, alias := range urls {
+		if alias == "" {
+			return errors.New("empty short link in request")
+		}
+	}
+	return nil
+}
+
+// deleteJobResponse - задание в виде, в котором его видит пользователь.
+func deleteJobResponse(job storage.DeleteJob) *user.DeleteJob {
+	return &user.DeleteJob{
+		ID:        job.ID,
+		Status:    job.Status,
+		URLs:      len(job.URLs),
+		Attempts:  job.Attempts,
+		LastError: job.LastError,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+}
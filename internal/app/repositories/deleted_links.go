@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// DeleteBatchLinks - мягкое удаление ссылок пользователя, чужие и уже удаленные пропускаются
+func (r *UserRepo) DeleteBatchLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteBatchLinksResponse {
+	var urls []string
+	for _, body := range request.Body {
+		urls = append(urls, body.URLS...)
+	}
+
+	// link.deleted пишет в outbox само хранилище вместе с удалением.
+	deleted, err := r.storage.DeleteBatch(ctx, urls, request.UserID)
+	if err != nil {
+		return user.DeleteBatchLinksResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	// В файл пишется состояние удаленных ссылок, иначе после перезапуска они снова станут
+	// активными и не попадут в очистку по истечении льготного периода.
+	if r.storage.File != nil && len(deleted) > 0 {
+		if err = r.saveDeleted(ctx, deleted); err != nil {
+			return user.DeleteBatchLinksResponse{
+				Code:   http.StatusInternalServerError,
+				Status: fail,
+				Error: &models.Err{
+					Source:  "file_storage",
+					Message: err.Error(),
+				},
+			}
+		}
+	}
+
+	return user.DeleteBatchLinksResponse{
+		Code:   http.StatusAccepted,
+		Status: success,
+	}
+}
+
+// saveDeleted дописывает в файл хранилища удаленные ссылки вместе с отметкой и временем удаления.
+func (r *UserRepo) saveDeleted(ctx context.Context, aliases []string) error {
+	items := make(map[string]storage.Item, len(aliases))
+	for _, alias := range aliases {
+		item, err := r.storage.Lookup(ctx, alias)
+		if err != nil {
+			return err
+		}
+		items[alias] = item
+	}
+	return r.storage.File.SaveToFile(items)
+}
+
+// RestoreLink - отмена удаления ссылки владельцем в пределах льготного периода
+func (r *UserRepo) RestoreLink(ctx context.Context, request user.RestoreLinkRequest) user.UpdateLinkResponse {
+	item, err := r.storage.RestoreDeleted(ctx, request.ShortLinkID, request.UserID, time.Now().Add(-request.GracePeriod))
+	if err != nil {
+		return user.UpdateLinkResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if r.storage.File != nil {
+		err = r.storage.File.SaveToFile(map[string]storage.Item{request.ShortLinkID: item})
+		if err != nil {
+			return user.UpdateLinkResponse{
+				Code:   http.StatusInternalServerError,
+				Status: fail,
+				Error: &models.Err{
+					Source:  "file_storage",
+					Message: err.Error(),
+				},
+			}
+		}
+	}
+
+	return user.UpdateLinkResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: updatedLink(request.BaseURL, request.ShortLinkID, item),
+	}
+}
+
+// GetDeletedLinks - удаленные ссылки пользователя, еще не вычищенные из хранилища
+func (r *UserRepo) GetDeletedLinks(ctx context.Context, request user.GetDeletedLinksRequest) user.GetDeletedLinksResponse {
+	batch, err := r.storage.GetBatchByUserID(ctx, request.UserID, storage.BatchQuery{
+		Desc:    true,
+		Deleted: storage.DeletedOnly,
+	})
+	if err != nil {
+		return user.GetDeletedLinksResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	result := make([]user.DeletedLink, 0, len(batch))
+	for _, record := range batch {
+		result = append(result, user.DeletedLink{
+			ShortURL:        request.BaseURL + "/" + record.Alias,
+			OriginalURL:     record.Object,
+			Title:           record.Title,
+			DeletedAt:       record.DeletedAt,
+			RestorableUntil: record.DeletedAt.Add(request.GracePeriod),
+		})
+	}
+
+	return user.GetDeletedLinksResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: result,
+	}
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_DeleteBatchLinks_file(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "links.json")
+
+	s, err := storage.NewStorage(storage.WithFileStorage(path))
+	require.NoError(t, err)
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"deleted": {Object: "https://ya.ru", UserID: "user"},
+		"kept":    {Object: "https://example.com", UserID: "user"},
+	}))
+	require.NoError(t, s.File.SaveToFile(map[string]storage.Item{
+		"deleted": {Object: "https://ya.ru", UserID: "user"},
+		"kept":    {Object: "https://example.com", UserID: "user"},
+	}))
+
+	result := NewUserRepo(s).DeleteBatchLinks(ctx, user.DeleteBatchLinksRequest{
+		UserID: "user",
+		Body:   []user.DeleteBatchBody{{URLS: []string{"deleted"}}},
+	})
+	require.Equal(t, http.StatusAccepted, result.Code)
+	s.Close()
+
+	// После перезапуска ссылка остается удаленной и видна в корзине владельца.
+	restarted, err := storage.NewStorage(storage.RestoreFile(ctx, path), storage.WithFileStorage(path))
+	require.NoError(t, err)
+	defer restarted.Close()
+
+	item, err := restarted.Lookup(ctx, "deleted")
+	require.NoError(t, err)
+	require.True(t, item.IsDeleted)
+	require.False(t, item.DeletedAt.IsZero())
+
+	item, err = restarted.Lookup(ctx, "kept")
+	require.NoError(t, err)
+	require.False(t, item.IsDeleted)
+
+	deleted := NewUserRepo(restarted).GetDeletedLinks(ctx, user.GetDeletedLinksRequest{UserID: "user", GracePeriod: time.Hour})
+	require.Equal(t, http.StatusOK, deleted.Code)
+	require.Len(t, deleted.Response, 1)
+}
+
+func TestUserRepo_RestoreLink_old(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	// Новые ссылки не получают срока жизни.
+	created := repo.ShorteningLinkJSON(ctx, user.ShorteningLinkJSONRequest{
+		UserID:         "user",
+		ShorteningLink: user.ShortenLinkJSONRequestBody{URL: "https://ya.ru"},
+		BaseURL:        "http://localhost:8080",
+	})
+	require.Equal(t, http.StatusCreated, created.Code)
+	alias := filepath.Base(created.Response.Result)
+	item, err := s.Lookup(ctx, alias)
+	require.NoError(t, err)
+	require.Zero(t, item.Expiration)
+
+	// Ссылка создана час назад и удалена: в пределах льготного периода она восстанавливается и открывается.
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"old": {Object: "https://example.com", UserID: "user", CreatedAt: time.Now().Add(-time.Hour)},
+	}))
+	deleted := repo.DeleteBatchLinks(ctx, user.DeleteBatchLinksRequest{
+		UserID: "user",
+		Body:   []user.DeleteBatchBody{{URLS: []string{"old"}}},
+	})
+	require.Equal(t, http.StatusAccepted, deleted.Code)
+
+	restored := repo.RestoreLink(ctx, user.RestoreLinkRequest{UserID: "user", ShortLinkID: "old", GracePeriod: 24 * time.Hour})
+	require.Equal(t, http.StatusOK, restored.Code)
+
+	redirect := repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: "old", DefaultRedirectCode: http.StatusTemporaryRedirect})
+	require.Equal(t, http.StatusTemporaryRedirect, redirect.Code)
+}
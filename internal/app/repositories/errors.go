@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+)
+
+// linkErrorCode сопоставляет ошибку хранилища при работе со ссылкой пользователя с HTTP-кодом ответа.
+func linkErrorCode(err error) int {
+	switch {
+	case errors.Is(err, models.ErrLinkNotFound), errors.Is(err, models.ErrCollectionNotFound),
+		errors.Is(err, models.ErrWebhookNotFound), errors.Is(err, models.ErrDeliveryNotFound),
+		errors.Is(err, models.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrNotOwner), errors.Is(err, models.ErrLinkNotActive), errors.Is(err, models.ErrLinkBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrGetDeletedLink), errors.Is(err, models.ErrRestorePeriodExpired),
+		errors.Is(err, models.ErrClicksExhausted), errors.Is(err, models.ErrLinkExpired):
+		return http.StatusGone
+	case errors.Is(err, models.ErrAlreadyExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// groupNamePattern - допустимые символы тегов и имен коллекций
+var groupNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_.-]+$`)
+
+// normalizeTags приводит теги к нижнему регистру, убирает повторы и сортирует.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxTagsPerLink {
+		return nil, fmt.Errorf("link can have at most %d tags", maxTagsPerLink)
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if err := validateGroupName("tag", tag); err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized), nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// validateGroupName проверяет тег или имя коллекции.
+func validateGroupName(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s must not be empty", kind)
+	}
+	if utf8.RuneCountInString(name) > maxGroupNameLength {
+		return fmt.Errorf("%s must not exceed %d characters", kind, maxGroupNameLength)
+	}
+	if !groupNamePattern.MatchString(name) {
+		return errors.New(kind + " may contain only letters, digits, '_', '.' and '-'")
+	}
+	return nil
+}
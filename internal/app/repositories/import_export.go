@@ -0,0 +1,313 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlnorm"
+	"github.com/sonikq/url-shortener/internal/app/pkg/utils"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// aliasPattern - допустимое сокращение из файла импорта
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// reservedAliases - первые сегменты путей сервиса, которые перекрыли бы сокращение
+var reservedAliases = []string{"api", "debug", "ping", "ping_url_shortener"}
+
+// errOwnerRequired - при импорте с сервера у строки нет user_id, а владелец по умолчанию не задан
+var errOwnerRequired = errors.New("user_id is required")
+
+// pendingImport - проверенная строка, ожидающая записи в хранилище.
+type pendingImport struct {
+	line   int
+	alias  string // сокращение из файла
+	keep   bool   // сокращение из файла корректно, его можно сохранить
+	record storage.Record
+}
+
+// ImportLinks - потоковый импорт ссылок: строки проверяются по одной и записываются пачками,
+// ошибка в строке не прерывает импорт. Сокращение из файла сохраняется, если оно корректно и свободно,
+// иначе выдается новое. Существующие ссылки не изменяются.
+func (r *UserRepo) ImportLinks(ctx context.Context, request user.ImportLinksRequest) user.ImportLinksResponse {
+	report := &user.ImportReport{Rows: []user.ImportedRow{}}
+	batch := make([]pendingImport, 0, importBatchSize)
+
+	for {
+		row, line, err := request.Rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *linkio.RowError
+		if errors.As(err, &rowErr) {
+			reportFailure(report, line, "", rowErr.Err)
+			continue
+		}
+		if err != nil {
+			return r.importResponse(http.StatusBadRequest, "request", err, report)
+		}
+
+		pending, err := r.importRow(ctx, request, row)
+		if err != nil {
+			reportFailure(report, line, row.Alias, err)
+			continue
+		}
+		pending.line = line
+
+		batch = append(batch, pending)
+		if len(batch) < importBatchSize {
+			continue
+		}
+		if err = r.importBatch(ctx, request.BaseURL, batch, report); err != nil {
+			return r.importResponse(http.StatusInternalServerError, "storage", err, report)
+		}
+		batch = batch[:0]
+	}
+
+	if err := r.importBatch(ctx, request.BaseURL, batch, report); err != nil {
+		return r.importResponse(http.StatusInternalServerError, "storage", err, report)
+	}
+
+	return user.ImportLinksResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: report,
+	}
+}
+
+// importRow проверяет строку файла так же, как ссылку, созданную через API.
+func (r *UserRepo) importRow(ctx context.Context, request user.ImportLinksRequest, row linkio.Row) (pendingImport, error) {
+	originalURL, err := urlnorm.Normalize(row.OriginalURL)
+	if err != nil {
+		return pendingImport{}, err
+	}
+
+	tags, err := validateShortenBody(user.ShortenLinkJSONRequestBody{
+		URL:          originalURL,
+		Title:        row.Title,
+		Note:         row.Note,
+		Tags:         row.Tags,
+		Collection:   row.Collection,
+		RedirectCode: row.RedirectCode,
+		MaxClicks:    row.MaxClicks,
+	})
+	if err != nil {
+		return pendingImport{}, err
+	}
+	if row.Clicks < 0 {
+		return pendingImport{}, errors.New("clicks must not be negative")
+	}
+
+	owner := request.UserID
+	if request.KeepOwners && row.UserID != "" {
+		owner = row.UserID
+	}
+	if owner == "" {
+		return pendingImport{}, errOwnerRequired
+	}
+
+	if err = r.screen(ctx, originalURL); err != nil {
+		return pendingImport{}, err
+	}
+
+	pending := pendingImport{
+		record: storage.Record{
+			Item: storage.Item{
+				Object:       originalURL,
+				UserID:       owner,
+				Title:        row.Title,
+				Note:         row.Note,
+				Tags:         tags,
+				Collection:   row.Collection,
+				RedirectCode: row.RedirectCode,
+				MaxClicks:    row.MaxClicks,
+				Clicks:       row.Clicks,
+			},
+		},
+		alias: row.Alias,
+		keep:  validAlias(row.Alias),
+	}
+	if row.CreatedAt != nil {
+		pending.record.CreatedAt = *row.CreatedAt
+	}
+
+	return pending, nil
+}
+
+// importBatch записывает пачку строк. Строкам без сокращения и с занятым сокращением выдаются
+// новые, пока они не будут записаны или попытки не закончатся.
+func (r *UserRepo) importBatch(ctx context.Context, baseURL string, batch []pendingImport, report *user.ImportReport) error {
+	for i := range batch {
+		batch[i].record.Alias = batch[i].alias
+		if !batch[i].keep {
+			batch[i].record.Alias = utils.RandomString(sizeOfAlias)
+		}
+	}
+
+	imported := make(map[string]storage.Item, len(batch))
+	for attempt := 0; len(batch) > 0; attempt++ {
+		records := make([]storage.Record, len(batch))
+		for i, pending := range batch {
+			records[i] = pending.record
+		}
+
+		results, err := r.storage.ImportLinks(ctx, records)
+		if err != nil {
+			return err
+		}
+
+		var retry []pendingImport
+		for i, pending := range batch {
+			switch {
+			case results[i] == nil:
+				imported[pending.record.Alias] = pending.record.Item
+				reportImported(report, pending, baseURL)
+			case errors.Is(results[i], models.ErrAliasTaken) && attempt < maxAliasAttempts:
+				pending.record.Alias = utils.RandomString(sizeOfAlias)
+				retry = append(retry, pending)
+			default:
+				reportFailure(report, pending.line, pending.alias, results[i])
+			}
+		}
+		batch = retry
+	}
+
+	if r.storage.File != nil && len(imported) > 0 {
+		return r.storage.File.SaveToFile(imported)
+	}
+	return nil
+}
+
+// importResponse - ответ на прерванный импорт: уже записанные строки остаются в отчете.
+func (r *UserRepo) importResponse(code int, source string, err error, report *user.ImportReport) user.ImportLinksResponse {
+	return user.ImportLinksResponse{
+		Code:   code,
+		Status: fail,
+		Error: &models.Err{
+			Source:  source,
+			Message: err.Error(),
+		},
+		Response: report,
+	}
+}
+
+// ExportLinks - потоковая выгрузка неудаленных ссылок постранично, без загрузки всех ссылок в память
+func (r *UserRepo) ExportLinks(ctx context.Context, request user.ExportLinksRequest) user.ExportLinksResponse {
+	report := &user.ExportReport{}
+
+	query := storage.BatchQuery{Limit: exportPageSize, Deleted: storage.DeletedExclude}
+	var after string
+	for {
+		var (
+			page []storage.Record
+			err  error
+		)
+		if request.UserID == "" {
+			page, err = r.storage.ScanLinks(ctx, after, exportPageSize)
+		} else {
+			page, err = r.storage.GetBatchByUserID(ctx, request.UserID, query)
+		}
+		if err != nil {
+			return r.exportResponse(http.StatusInternalServerError, "storage", err, report)
+		}
+
+		for _, record := range page {
+			if err = request.Rows.Write(exportRow(record)); err != nil {
+				return r.exportResponse(http.StatusInternalServerError, "writer", err, report)
+			}
+			report.Exported++
+		}
+
+		if len(page) < exportPageSize {
+			break
+		}
+		last := page[len(page)-1]
+		after = last.Alias
+		query.After = &storage.Cursor{CreatedAt: last.CreatedAt, Alias: last.Alias}
+	}
+
+	if err := request.Rows.Flush(); err != nil {
+		return r.exportResponse(http.StatusInternalServerError, "writer", err, report)
+	}
+
+	return user.ExportLinksResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: report,
+	}
+}
+
+func (r *UserRepo) exportResponse(code int, source string, err error, report *user.ExportReport) user.ExportLinksResponse {
+	return user.ExportLinksResponse{
+		Code:   code,
+		Status: fail,
+		Error: &models.Err{
+			Source:  source,
+			Message: err.Error(),
+		},
+		Response: report,
+	}
+}
+
+// exportRow - ссылка в виде строки файла выгрузки.
+func exportRow(record storage.Record) linkio.Row {
+	row := linkio.Row{
+		Alias:        record.Alias,
+		OriginalURL:  record.Object,
+		UserID:       record.UserID,
+		Title:        record.Title,
+		Note:         record.Note,
+		Tags:         record.Tags,
+		Collection:   record.Collection,
+		RedirectCode: record.RedirectCode,
+		MaxClicks:    record.MaxClicks,
+		Clicks:       record.Clicks,
+	}
+	if !record.CreatedAt.IsZero() {
+		row.CreatedAt = utils.Ptr(record.CreatedAt)
+	}
+	return row
+}
+
+// validAlias - можно ли сохранить сокращение из файла импорта.
+func validAlias(alias string) bool {
+	return aliasPattern.MatchString(alias) && !slices.Contains(reservedAliases, alias)
+}
+
+// reportFailure учитывает строку, которую не удалось импортировать.
+func reportFailure(report *user.ImportReport, line int, alias string, err error) {
+	report.Failed++
+	addReportRow(report, user.ImportedRow{Line: line, Alias: alias, Error: err.Error()})
+}
+
+// reportImported учитывает записанную строку; в отчет попадает, только если сокращение отличается от файла.
+func reportImported(report *user.ImportReport, pending pendingImport, baseURL string) {
+	report.Imported++
+	if pending.record.Alias == pending.alias {
+		return
+	}
+	if pending.alias != "" {
+		report.Renamed++
+	}
+	addReportRow(report, user.ImportedRow{
+		Line:     pending.line,
+		Alias:    pending.alias,
+		ShortURL: baseURL + "/" + pending.record.Alias,
+	})
+}
+
+func addReportRow(report *user.ImportReport, row user.ImportedRow) {
+	if len(report.Rows) >= maxImportReportRows {
+		report.RowsTruncated = true
+		return
+	}
+	report.Rows = append(report.Rows, row)
+}
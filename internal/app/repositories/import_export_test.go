@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/linkio"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_ImportLinks(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s, WithURLChecker(hostChecker{}))
+
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{"taken": {Object: "https://old.example", UserID: "other"}}))
+
+	data := "alias,original_url,user_id,tags,max_clicks,clicks,created_at\n" +
+		"keep,https://YA.ru/,ignored,\"go,news\",10,3,2020-01-02T03:04:05Z\n" +
+		"taken,https://example.com,,,,,\n" +
+		"api,https://example.org,,,,,\n" +
+		",https://example.net,,,,,\n" +
+		"dup,https://ya.ru,,,,,\n" +
+		"bad,ftp://example.com,,,,,\n" +
+		"evil,https://evil.example,,,,,\n" +
+		"clicks,https://example.info,,,,-1,\n" +
+		"short,https://example.biz\n"
+	rows, err := linkio.NewReader(strings.NewReader(data), linkio.FormatCSV)
+	require.NoError(t, err)
+
+	result := repo.ImportLinks(ctx, user.ImportLinksRequest{UserID: "user", BaseURL: "http://localhost", Rows: rows})
+	require.Equal(t, http.StatusOK, result.Code)
+
+	report := result.Response
+	require.Equal(t, 4, report.Imported)
+	require.Equal(t, 2, report.Renamed)
+	require.Equal(t, 5, report.Failed)
+
+	byLine := make(map[int]user.ImportedRow)
+	for _, row := range report.Rows {
+		byLine[row.Line] = row
+	}
+	require.Len(t, byLine, 8)
+	require.NotContains(t, byLine, 2)
+	require.Equal(t, "taken", byLine[3].Alias)
+	require.NotEqual(t, "http://localhost/taken", byLine[3].ShortURL)
+	require.NotEmpty(t, byLine[4].ShortURL)
+	require.NotEmpty(t, byLine[5].ShortURL)
+	require.Equal(t, models.ErrAlreadyExists.Error(), byLine[6].Error)
+	require.NotEmpty(t, byLine[7].Error)
+	require.Contains(t, byLine[8].Error, "test: phishing")
+	require.Contains(t, byLine[9].Error, "clicks")
+	require.Contains(t, byLine[10].Error, "expected 7 fields")
+
+	item, err := s.Get(ctx, "keep")
+	require.NoError(t, err)
+	require.Equal(t, "https://ya.ru", item.Object)
+	require.Equal(t, "user", item.UserID)
+	require.Equal(t, []string{"go", "news"}, item.Tags)
+	require.Equal(t, 3, item.Clicks)
+	require.Equal(t, 2020, item.CreatedAt.Year())
+	require.Zero(t, item.Expiration)
+
+	// Существующая ссылка не изменилась.
+	item, err = s.Get(ctx, "taken")
+	require.NoError(t, err)
+	require.Equal(t, "other", item.UserID)
+
+	// Импорт без событий link.created: перенесенные ссылки не новые.
+	events, err := s.ClaimOutbox(ctx, item.CreatedAt.AddDate(1, 0, 0), 0, 100)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "taken", events[0].Link.Alias)
+}
+
+func TestUserRepo_ImportLinks_owners(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage(storage.WithDedupScope(storage.DedupNone))
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	data := `{"alias":"a1","original_url":"https://ya.ru","user_id":"u1"}` + "\n" +
+		`{"alias":"a2","original_url":"https://ya.ru"}` + "\n"
+
+	rows, err := linkio.NewReader(strings.NewReader(data), linkio.FormatJSONL)
+	require.NoError(t, err)
+	result := repo.ImportLinks(ctx, user.ImportLinksRequest{KeepOwners: true, Rows: rows})
+	require.Equal(t, http.StatusOK, result.Code)
+	require.Equal(t, 1, result.Response.Imported)
+	require.Equal(t, errOwnerRequired.Error(), result.Response.Rows[0].Error)
+
+	rows, err = linkio.NewReader(strings.NewReader(data), linkio.FormatJSONL)
+	require.NoError(t, err)
+	result = repo.ImportLinks(ctx, user.ImportLinksRequest{UserID: "migrated", KeepOwners: true, Rows: rows})
+	require.Equal(t, 2, result.Response.Imported)
+	require.Equal(t, 1, result.Response.Renamed)
+
+	item, err := s.Get(ctx, "a1")
+	require.NoError(t, err)
+	require.Equal(t, "u1", item.UserID)
+	item, err = s.Get(ctx, "a2")
+	require.NoError(t, err)
+	require.Equal(t, "migrated", item.UserID)
+}
+
+func TestUserRepo_ExportLinks(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	var data strings.Builder
+	data.WriteString("alias,original_url,user_id\n")
+	for i := 0; i < exportPageSize+5; i++ {
+		owner := "u1"
+		if i%2 == 1 {
+			owner = "u2"
+		}
+		data.WriteString(strings.Join([]string{"", "https://example.com/" + string(rune('a'+i%26)) + strings.Repeat("x", i/26), owner}, ","))
+		data.WriteString("\n")
+	}
+	rows, err := linkio.NewReader(strings.NewReader(data.String()), linkio.FormatCSV)
+	require.NoError(t, err)
+	imported := repo.ImportLinks(ctx, user.ImportLinksRequest{KeepOwners: true, Rows: rows})
+	require.Equal(t, exportPageSize+5, imported.Response.Imported)
+
+	var buf bytes.Buffer
+	writer, err := linkio.NewWriter(&buf, linkio.FormatJSONL)
+	require.NoError(t, err)
+	result := repo.ExportLinks(ctx, user.ExportLinksRequest{Rows: writer})
+	require.Equal(t, http.StatusOK, result.Code)
+	require.Equal(t, exportPageSize+5, result.Response.Exported)
+	require.Equal(t, exportPageSize+5, strings.Count(buf.String(), "\n"))
+
+	buf.Reset()
+	writer, err = linkio.NewWriter(&buf, linkio.FormatCSV)
+	require.NoError(t, err)
+	result = repo.ExportLinks(ctx, user.ExportLinksRequest{UserID: "u2", Rows: writer})
+	require.Equal(t, http.StatusOK, result.Code)
+	require.Equal(t, (exportPageSize+5)/2, result.Response.Exported)
+	require.NotContains(t, buf.String(), ",u1,")
+
+	// Выгрузку можно загрузить обратно в другое хранилище.
+	target, err := storage.NewStorage()
+	require.NoError(t, err)
+	rows, err = linkio.NewReader(&buf, linkio.FormatCSV)
+	require.NoError(t, err)
+	imported = NewUserRepo(target).ImportLinks(ctx, user.ImportLinksRequest{UserID: "u3", Rows: rows})
+	require.Equal(t, (exportPageSize+5)/2, imported.Response.Imported)
+	require.Zero(t, imported.Response.Renamed)
+	require.Empty(t, imported.Response.Rows)
+}
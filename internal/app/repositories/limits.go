@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// validateMaxClicks запрещает отрицательный лимит переходов.
+func validateMaxClicks(maxClicks int) error {
+	if maxClicks < 0 {
+		return errors.New("max_clicks must not be negative")
+	}
+	return nil
+}
+
+// parseActiveFrom разбирает момент активации ссылки, пустая строка - ссылка активна сразу.
+func parseActiveFrom(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	activeFrom, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("active_from must be in RFC 3339 format")
+	}
+	return activeFrom, nil
+}
+
+// optionalTime - nil для нулевого времени, чтобы не отдавать его в ответах.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// consumeClick учитывает переход по ссылке с лимитом, сохраняет счетчик в файл и возвращает его.
+func (r *UserRepo) consumeClick(ctx context.Context, alias string) (int, error) {
+	item, err := r.storage.ConsumeClick(ctx, alias)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.storage.File != nil {
+		return item.Clicks, r.storage.File.SaveToFile(map[string]storage.Item{alias: item})
+	}
+	return item.Clicks, nil
+}
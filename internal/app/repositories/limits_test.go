@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_GetFullLinkByID_limits(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"once":   {Object: "https://example.com/invite", UserID: "user", MaxClicks: 1},
+		"future": {Object: "https://example.com/launch", UserID: "user", ActiveFrom: time.Now().Add(time.Hour)},
+		"past":   {Object: "https://example.com/sale", UserID: "user", ActiveFrom: time.Now().Add(-time.Hour)},
+	}))
+
+	expand := func(alias string) user.GetFullLinkByIDResponse {
+		return repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: alias})
+	}
+
+	result := expand("once")
+	require.Equal(t, http.StatusTemporaryRedirect, result.Code)
+	require.True(t, result.Limited)
+	require.Equal(t, http.StatusGone, expand("once").Code)
+
+	require.Equal(t, http.StatusForbidden, expand("future").Code)
+	require.Equal(t, http.StatusTemporaryRedirect, expand("past").Code)
+	require.Equal(t, http.StatusNotFound, expand("missing").Code)
+}
+
+func TestUserRepo_GetFullLinkByID_preview(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"once": {Object: "https://example.com/invite", UserID: "user", MaxClicks: 1, Title: "Приглашение"},
+		"ab": {Object: "https://example.com", UserID: "user", Interstitial: true, Sticky: true, Variants: []storage.Variant{
+			{Destination: "https://a.example.com", Weight: 1},
+			{Destination: "https://b.example.com", Weight: 1},
+		}},
+	}))
+
+	// Предпросмотр не расходует переходы и не меняет счетчики вариантов.
+	for i := 0; i < 3; i++ {
+		result := repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: "once", Preview: true})
+		require.Equal(t, http.StatusTemporaryRedirect, result.Code)
+		require.Equal(t, "Приглашение", result.Title)
+		require.False(t, result.CreatedAt.IsZero())
+	}
+	require.Equal(t, http.StatusTemporaryRedirect, repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: "once"}).Code)
+
+	result := repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: "ab", Preview: true})
+	require.True(t, result.Interstitial)
+	require.NotNil(t, result.Variant)
+	require.False(t, result.Sticky)
+
+	item, err := s.Get(ctx, "ab")
+	require.NoError(t, err)
+	for _, variant := range item.Variants {
+		require.Zero(t, variant.Clicks)
+	}
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+type cursorPayload struct {
+	CreatedAt int64  `json:"t"`
+	Alias     string `json:"a"`
+}
+
+// encodeCursor упаковывает позицию последней выданной ссылки в непрозрачную строку.
+func encodeCursor(record storage.Record) string {
+	data, _ := json.Marshal(cursorPayload{
+		CreatedAt: record.CreatedAt.UnixNano(),
+		Alias:     record.Alias,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (*storage.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err = json.Unmarshal(data, &payload); err != nil || payload.Alias == "" {
+		return nil, errInvalidCursor
+	}
+
+	return &storage.Cursor{
+		CreatedAt: time.Unix(0, payload.CreatedAt),
+		Alias:     payload.Alias,
+	}, nil
+}
+
+// batchQuery переводит параметры запроса списка ссылок в запрос к хранилищу.
+func batchQuery(request user.GetBatchByUserIDRequest) (storage.BatchQuery, error) {
+	query := storage.BatchQuery{
+		Limit:      request.Limit,
+		Search:     request.Search,
+		Tag:        normalizeTag(request.Tag),
+		Collection: request.Collection,
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = defaultPageSize
+	case query.Limit < 0 || query.Limit > maxPageSize:
+		return query, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+	}
+
+	switch request.Sort {
+	case "", "-created_at":
+		query.Desc = true
+	case "created_at":
+		query.Desc = false
+	default:
+		return query, fmt.Errorf("unknown sort: %q", request.Sort)
+	}
+
+	switch request.Deleted {
+	case "", "all":
+		query.Deleted = storage.DeletedInclude
+	case "active":
+		query.Deleted = storage.DeletedExclude
+	case "deleted":
+		query.Deleted = storage.DeletedOnly
+	default:
+		return query, fmt.Errorf("unknown deleted filter: %q", request.Deleted)
+	}
+
+	if request.Cursor != "" {
+		after, err := decodeCursor(request.Cursor)
+		if err != nil {
+			return query, err
+		}
+		query.After = after
+	}
+
+	return query, nil
+}
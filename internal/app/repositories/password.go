@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// validatePassword ограничивает длину пароля ссылки.
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("password must not exceed %d bytes", maxPasswordLength)
+	}
+	return nil
+}
+
+// hashPassword - bcrypt-хэш пароля для хранения вместе со ссылкой.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// checkPassword пропускает незащищенные ссылки, а для защищенных сверяет пароль.
+// После maxPasswordAttempts неудачных попыток alias блокируется до конца окна.
+func (r *UserRepo) checkPassword(alias, hash, password string) error {
+	if hash == "" {
+		return nil
+	}
+	if r.attempts.Blocked(alias) > 0 {
+		return models.ErrTooManyAttempts
+	}
+	if password == "" {
+		return models.ErrPasswordRequired
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		r.attempts.Fail(alias)
+		return models.ErrWrongPassword
+	}
+	if err != nil {
+		return err
+	}
+
+	r.attempts.Reset(alias)
+	return nil
+}
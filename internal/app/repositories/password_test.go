@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_GetFullLinkByID_password(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	hash, err := hashPassword("secret")
+	require.NoError(t, err)
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"locked": {Object: "https://example.com/doc", UserID: "user", PasswordHash: hash},
+	}))
+
+	expand := func(password string) user.GetFullLinkByIDResponse {
+		return repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: "locked", Password: password})
+	}
+
+	require.Equal(t, http.StatusUnauthorized, expand("").Code)
+
+	for i := 0; i < maxPasswordAttempts-1; i++ {
+		require.Equal(t, http.StatusUnauthorized, expand("wrong").Code)
+	}
+
+	// Верный пароль сбрасывает счетчик неудачных попыток.
+	result := expand("secret")
+	require.Equal(t, http.StatusTemporaryRedirect, result.Code)
+	require.Equal(t, "https://example.com/doc", *result.Response)
+
+	for i := 0; i < maxPasswordAttempts; i++ {
+		require.Equal(t, http.StatusUnauthorized, expand("wrong").Code)
+	}
+
+	result = expand("secret")
+	require.Equal(t, http.StatusTooManyRequests, result.Code)
+	require.Positive(t, result.RetryAfter)
+}
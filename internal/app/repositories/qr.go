@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/qr"
+)
+
+// GetLinkQR - QR-код полной сокращенной ссылки владельца
+func (r *UserRepo) GetLinkQR(ctx context.Context, request user.GetLinkQRRequest) user.GetLinkQRResponse {
+	format, opts, err := qrOptions(request)
+	if err != nil {
+		return user.GetLinkQRResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if _, err = r.ownedLink(ctx, request.ShortLinkID, request.UserID); err != nil {
+		return user.GetLinkQRResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if request.Logo {
+		opts.Logo, err = qr.LoadLogo(request.LogoPath)
+		if err != nil {
+			return user.GetLinkQRResponse{
+				Code:   http.StatusInternalServerError,
+				Status: fail,
+				Error: &models.Err{
+					Source:  "qr_logo",
+					Message: err.Error(),
+				},
+			}
+		}
+	}
+
+	image, err := qr.Render(request.BaseURL+"/"+request.ShortLinkID, format, opts)
+	if err != nil {
+		return user.GetLinkQRResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "qr",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	contentType := "image/png"
+	if format == qr.FormatSVG {
+		contentType = "image/svg+xml"
+	}
+
+	return user.GetLinkQRResponse{
+		Code:   http.StatusOK,
+		Status: success,
+		Response: &user.LinkQR{
+			ContentType: contentType,
+			Image:       image,
+		},
+	}
+}
+
+// qrOptions проверяет параметры QR-кода и подставляет значения по умолчанию.
+// Логотип закрывает часть кода, поэтому требует уровня коррекции Q или H (по умолчанию H).
+func qrOptions(request user.GetLinkQRRequest) (string, qr.Options, error) {
+	opts := qr.Options{
+		Size:   defaultQRSize,
+		Margin: defaultQRMargin,
+		Level:  qrcode.Medium,
+	}
+
+	format := request.Format
+	switch format {
+	case "":
+		format = qr.FormatPNG
+	case qr.FormatPNG, qr.FormatSVG:
+	default:
+		return "", opts, errors.New("format must be png or svg")
+	}
+
+	if request.Size != 0 {
+		if request.Size < minQRSize || request.Size > maxQRSize {
+			return "", opts, fmt.Errorf("size must be between %d and %d", minQRSize, maxQRSize)
+		}
+		opts.Size = request.Size
+	}
+
+	if request.Margin != nil {
+		if *request.Margin < 0 || *request.Margin > maxQRMargin {
+			return "", opts, fmt.Errorf("margin must be between 0 and %d", maxQRMargin)
+		}
+		opts.Margin = *request.Margin
+	}
+
+	if request.Logo {
+		if request.LogoPath == "" {
+			return "", opts, errors.New("logo is not configured")
+		}
+		opts.Level = qrcode.Highest
+	}
+
+	if request.Level != "" {
+		level, err := qr.ParseLevel(request.Level)
+		if err != nil {
+			return "", opts, err
+		}
+		if request.Logo && level < qrcode.High {
+			return "", opts, errors.New("logo requires error correction level Q or H")
+		}
+		opts.Level = level
+	}
+
+	return format, opts, nil
+}
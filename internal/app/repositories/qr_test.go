@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/utils"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_qrOptions(t *testing.T) {
+	format, opts, err := qrOptions(user.GetLinkQRRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "png", format)
+	require.Equal(t, defaultQRSize, opts.Size)
+	require.Equal(t, defaultQRMargin, opts.Margin)
+	require.Equal(t, qrcode.Medium, opts.Level)
+
+	_, opts, err = qrOptions(user.GetLinkQRRequest{Logo: true, LogoPath: "logo.png", Margin: utils.Ptr(0)})
+	require.NoError(t, err)
+	require.Equal(t, qrcode.Highest, opts.Level)
+	require.Zero(t, opts.Margin)
+
+	for _, request := range []user.GetLinkQRRequest{
+		{Format: "gif"},
+		{Size: 10},
+		{Margin: utils.Ptr(-1)},
+		{Level: "X"},
+		{Logo: true},
+		{Logo: true, LogoPath: "logo.png", Level: "M"},
+	} {
+		_, _, err = qrOptions(request)
+		require.Error(t, err, "%+v", request)
+	}
+}
+
+func TestUserRepo_GetLinkQR(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"abcdef": {Object: "https://example.com", UserID: "user"},
+	}))
+
+	result := repo.GetLinkQR(ctx, user.GetLinkQRRequest{
+		UserID:      "user",
+		ShortLinkID: "abcdef",
+		BaseURL:     "http://localhost:8080",
+		Format:      "svg",
+	})
+	require.Equal(t, http.StatusOK, result.Code)
+	require.Equal(t, "image/svg+xml", result.Response.ContentType)
+	require.NotEmpty(t, result.Response.Image)
+
+	result = repo.GetLinkQR(ctx, user.GetLinkQRRequest{UserID: "other", ShortLinkID: "abcdef"})
+	require.Equal(t, http.StatusForbidden, result.Code)
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// validateRedirectCode допускает коды редиректа 301, 302, 307 и 308, а также 0 - код по умолчанию.
+func validateRedirectCode(code int) error {
+	switch code {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return nil
+	default:
+		return errors.New("redirect_code must be one of 301, 302, 307 or 308")
+	}
+}
+
+// redirectCode - код редиректа ссылки с учетом кода по умолчанию.
+func redirectCode(linkCode, defaultCode int) int {
+	switch {
+	case linkCode != 0:
+		return linkCode
+	case defaultCode != 0:
+		return defaultCode
+	default:
+		return http.StatusTemporaryRedirect
+	}
+}
+
+// destination - адрес редиректа: original_url с хвостом пути и query входящего запроса,
+// если ссылка разрешает их передавать. Параметры original_url имеют приоритет над входящими.
+func destination(item storage.Item, pathSuffix, rawQuery string) (string, error) {
+	pathSuffix = strings.TrimPrefix(path.Clean("/"+pathSuffix), "/")
+	if pathSuffix != "" && !item.ForwardPath {
+		return "", errors.New("link does not accept path suffix")
+	}
+
+	forwardQuery := rawQuery != "" && item.ForwardQuery
+	if pathSuffix == "" && !forwardQuery {
+		return item.Object, nil
+	}
+
+	location, err := url.Parse(item.Object)
+	if err != nil {
+		return "", err
+	}
+
+	if pathSuffix != "" {
+		location.Path = strings.TrimSuffix(location.Path, "/") + "/" + pathSuffix
+		location.RawPath = ""
+	}
+
+	if forwardQuery {
+		// Некорректные пары входящего query отбрасываются, остальные передаются.
+		incoming, _ := url.ParseQuery(rawQuery)
+
+		query := location.Query()
+		for key, values := range incoming {
+			if _, found := query[key]; !found {
+				query[key] = values
+			}
+		}
+		location.RawQuery = query.Encode()
+	}
+
+	return location.String(), nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_destination(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       storage.Item
+		pathSuffix string
+		rawQuery   string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:     "passthrough disabled",
+			item:     storage.Item{Object: "https://example.com/docs?lang=ru"},
+			rawQuery: "utm_source=x",
+			want:     "https://example.com/docs?lang=ru",
+		},
+		{
+			name:       "path suffix rejected",
+			item:       storage.Item{Object: "https://example.com/docs"},
+			pathSuffix: "/guide",
+			wantErr:    true,
+		},
+		{
+			name:     "query merged, destination wins",
+			item:     storage.Item{Object: "https://example.com/docs?lang=ru", ForwardQuery: true},
+			rawQuery: "lang=en&utm_source=x",
+			want:     "https://example.com/docs?lang=ru&utm_source=x",
+		},
+		{
+			name:       "path suffix appended",
+			item:       storage.Item{Object: "https://example.com/docs/", ForwardPath: true},
+			pathSuffix: "/guide/intro",
+			want:       "https://example.com/docs/guide/intro",
+		},
+		{
+			name:       "dot segments cannot escape destination",
+			item:       storage.Item{Object: "https://example.com/docs", ForwardPath: true},
+			pathSuffix: "/../../admin",
+			want:       "https://example.com/docs/admin",
+		},
+		{
+			name:       "trailing slash is not a suffix",
+			item:       storage.Item{Object: "https://example.com/docs"},
+			pathSuffix: "/",
+			want:       "https://example.com/docs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := destination(tt.item, tt.pathSuffix, tt.rawQuery)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
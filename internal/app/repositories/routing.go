@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlnorm"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Платформы, которые распознаются по User-Agent.
+const (
+	platformIOS     = "ios"
+	platformAndroid = "android"
+	platformWindows = "windows"
+	platformMacOS   = "macos"
+	platformLinux   = "linux"
+)
+
+// normalizeRules проверяет правила маршрутизации и приводит условия к нижнему регистру.
+func normalizeRules(rules []user.RoutingRule) ([]storage.Rule, error) {
+	if len(rules) > maxRulesPerLink {
+		return nil, fmt.Errorf("link can have at most %d rules", maxRulesPerLink)
+	}
+
+	normalized := make([]storage.Rule, 0, len(rules))
+	for i, rule := range rules {
+		r := storage.Rule{
+			Platform: strings.ToLower(strings.TrimSpace(rule.Platform)),
+			Language: strings.ToLower(strings.TrimSpace(rule.Language)),
+			Referrer: strings.TrimPrefix(strings.ToLower(strings.TrimSpace(rule.Referrer)), "www."),
+		}
+
+		if r.Platform == "" && r.Language == "" && r.Referrer == "" {
+			return nil, fmt.Errorf("rule %d: at least one condition is required", i)
+		}
+		switch r.Platform {
+		case "", platformIOS, platformAndroid, platformWindows, platformMacOS, platformLinux:
+		default:
+			return nil, fmt.Errorf("rule %d: unknown platform %q", i, rule.Platform)
+		}
+		destination, err := urlnorm.Normalize(rule.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: destination: %w", i, err)
+		}
+		r.Destination = destination
+
+		normalized = append(normalized, r)
+	}
+
+	return normalized, nil
+}
+
+// routingRules - правила ссылки в представлении API.
+func routingRules(rules []storage.Rule) []user.RoutingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	result := make([]user.RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, user.RoutingRule(rule))
+	}
+	return result
+}
+
+// route выбирает адрес по первому подходящему правилу, false - ни одно правило не подошло.
+func route(item storage.Item, request user.GetFullLinkByIDRequest) (string, bool) {
+	if len(item.Rules) == 0 {
+		return "", false
+	}
+
+	platform := detectPlatform(request.UserAgent)
+	language := preferredLanguage(request.AcceptLanguage)
+	referrer := referrerHost(request.Referrer)
+
+	for _, rule := range item.Rules {
+		if rule.Platform != "" && rule.Platform != platform {
+			continue
+		}
+		if rule.Language != "" && !matchLanguage(rule.Language, language) {
+			continue
+		}
+		if rule.Referrer != "" && referrer != rule.Referrer && !strings.HasSuffix(referrer, "."+rule.Referrer) {
+			continue
+		}
+		return rule.Destination, true
+	}
+
+	return "", false
+}
+
+// detectPlatform определяет платформу клиента по User-Agent.
+func detectPlatform(userAgent string) string {
+	switch ua := strings.ToLower(userAgent); {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return platformIOS
+	case strings.Contains(ua, "android"):
+		return platformAndroid
+	case strings.Contains(ua, "windows"):
+		return platformWindows
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		return platformMacOS
+	case strings.Contains(ua, "linux"):
+		return platformLinux
+	default:
+		return ""
+	}
+}
+
+// preferredLanguage - язык с наибольшим весом q из Accept-Language.
+func preferredLanguage(acceptLanguage string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var languages []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			languages = append(languages, weighted{tag: strings.ToLower(strings.TrimSpace(tag)), q: q})
+		}
+	}
+	if len(languages) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].q > languages[j].q })
+	return languages[0].tag
+}
+
+// matchLanguage - подходит ли язык клиента под язык правила: ru подходит для ru-RU, но не наоборот.
+func matchLanguage(ruleLanguage, language string) bool {
+	return language == ruleLanguage || strings.HasPrefix(language, ruleLanguage+"-")
+}
+
+// referrerHost - домен из заголовка Referer без www.
+func referrerHost(referrer string) string {
+	location, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(location.Hostname()), "www.")
+}
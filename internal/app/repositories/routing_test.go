@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_route(t *testing.T) {
+	item := storage.Item{
+		Object: "https://example.com",
+		Rules: []storage.Rule{
+			{Platform: platformIOS, Destination: "https://apps.apple.com/app/id1"},
+			{Platform: platformAndroid, Destination: "https://play.google.com/store/apps/details?id=app"},
+			{Language: "ru", Referrer: "t.me", Destination: "https://example.com/ru/telegram"},
+			{Language: "ru", Destination: "https://example.com/ru"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		request user.GetFullLinkByIDRequest
+		want    string // пусто - ни одно правило не подошло
+	}{
+		{
+			name:    "ios",
+			request: user.GetFullLinkByIDRequest{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"},
+			want:    "https://apps.apple.com/app/id1",
+		},
+		{
+			name:    "android",
+			request: user.GetFullLinkByIDRequest{UserAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8)"},
+			want:    "https://play.google.com/store/apps/details?id=app",
+		},
+		{
+			name: "language and referrer",
+			request: user.GetFullLinkByIDRequest{
+				UserAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+				AcceptLanguage: "en;q=0.5, ru-RU",
+				Referrer:       "https://web.t.me/k/",
+			},
+			want: "https://example.com/ru/telegram",
+		},
+		{
+			name:    "language only",
+			request: user.GetFullLinkByIDRequest{AcceptLanguage: "ru-RU,ru;q=0.9,en;q=0.8"},
+			want:    "https://example.com/ru",
+		},
+		{
+			name:    "fallback",
+			request: user.GetFullLinkByIDRequest{UserAgent: "curl/8.0", AcceptLanguage: "en-US,ru;q=0.3"},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matched := route(item, tt.request)
+			require.Equal(t, tt.want != "", matched)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_normalizeRules(t *testing.T) {
+	rules, err := normalizeRules([]user.RoutingRule{
+		{Platform: " iOS ", Referrer: "www.Example.com", Destination: "https://apps.apple.com/app/id1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []storage.Rule{
+		{Platform: platformIOS, Referrer: "example.com", Destination: "https://apps.apple.com/app/id1"},
+	}, rules)
+
+	_, err = normalizeRules([]user.RoutingRule{{Destination: "https://example.com"}})
+	require.Error(t, err)
+
+	_, err = normalizeRules([]user.RoutingRule{{Platform: "symbian", Destination: "https://example.com"}})
+	require.Error(t, err)
+
+	_, err = normalizeRules([]user.RoutingRule{{Platform: platformAndroid, Destination: "market://details?id=app"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlcheck"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// OptionsUserRepo -
+type OptionsUserRepo func(r *UserRepo)
+
+// WithURLChecker - проверять адреса на вредоносность перед сохранением ссылок
+func WithURLChecker(checker urlcheck.URLChecker) OptionsUserRepo {
+	return func(r *UserRepo) {
+		r.checker = checker
+	}
+}
+
+// errScreeningUnavailable - проверку адреса не удалось выполнить, ссылка не сохраняется
+var errScreeningUnavailable = errors.New("url screening is unavailable")
+
+// screen проверяет адреса перед сохранением ссылки, без настроенной проверки пропускает все.
+func (r *UserRepo) screen(ctx context.Context, urls ...string) error {
+	if r.checker == nil {
+		return nil
+	}
+
+	for _, u := range urls {
+		verdict, err := r.checker.Check(ctx, u)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errScreeningUnavailable, err)
+		}
+		if verdict.Malicious {
+			return fmt.Errorf("%w: %s (%s)", models.ErrMaliciousURL, u, verdict.Reason)
+		}
+	}
+
+	return nil
+}
+
+// screeningErrorCode - 422 для вредоносного адреса, 503 если проверка недоступна.
+func screeningErrorCode(err error) int {
+	if errors.Is(err, models.ErrMaliciousURL) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusServiceUnavailable
+}
+
+// updateDestinations - новые адреса, которые задает изменение ссылки.
+func updateDestinations(update storage.LinkUpdate) []string {
+	var changed storage.Item
+	if update.Object != nil {
+		changed.Object = *update.Object
+	}
+	if update.Rules != nil {
+		changed.Rules = *update.Rules
+	}
+	if update.Variants != nil {
+		changed.Variants = *update.Variants
+	}
+	return changed.Destinations()
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlcheck"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// hostChecker признает вредоносными адреса на хосте evil.example и не работает для down.example.
+type hostChecker struct{}
+
+func (hostChecker) Check(_ context.Context, rawURL string) (urlcheck.Verdict, error) {
+	switch {
+	case strings.Contains(rawURL, "down.example"):
+		return urlcheck.Verdict{}, errors.New("checker is down")
+	case strings.Contains(rawURL, "evil.example"):
+		return urlcheck.Verdict{Malicious: true, Reason: "test: phishing"}, nil
+	default:
+		return urlcheck.Verdict{}, nil
+	}
+}
+
+func TestUserRepo_screening(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s, WithURLChecker(hostChecker{}))
+
+	shorten := func(body user.ShortenLinkJSONRequestBody) user.ShorteningLinkJSONResponse {
+		return repo.ShorteningLinkJSON(ctx, user.ShorteningLinkJSONRequest{UserID: "user", ShorteningLink: body})
+	}
+
+	result := shorten(user.ShortenLinkJSONRequestBody{URL: "https://evil.example/login"})
+	require.Equal(t, http.StatusUnprocessableEntity, result.Code)
+	require.Contains(t, result.Error.Message, "test: phishing")
+
+	result = shorten(user.ShortenLinkJSONRequestBody{URL: "https://example.com", Variants: []user.Variant{
+		{Destination: "https://a.example.com", Weight: 1},
+		{Destination: "https://evil.example", Weight: 1},
+	}})
+	require.Equal(t, http.StatusUnprocessableEntity, result.Code)
+
+	require.Equal(t, http.StatusServiceUnavailable, shorten(user.ShortenLinkJSONRequestBody{URL: "https://down.example"}).Code)
+
+	plain := repo.ShorteningLink(ctx, user.ShorteningLinkRequest{UserID: "user", ShorteningLink: "https://sub.evil.example"})
+	require.Equal(t, http.StatusUnprocessableEntity, plain.Code)
+
+	batch := repo.ShorteningBatchLinks(ctx, user.ShorteningBatchLinksRequest{UserID: "user", Body: []user.BatchUrlsInput{
+		{CorrelationID: "1", OriginalURL: "https://ya.ru"},
+		{CorrelationID: "2", OriginalURL: "https://evil.example"},
+	}})
+	require.Equal(t, http.StatusUnprocessableEntity, batch.Code)
+
+	result = shorten(user.ShortenLinkJSONRequestBody{URL: "https://example.com"})
+	require.Equal(t, http.StatusCreated, result.Code)
+	alias := result.Response.Result[strings.LastIndex(result.Response.Result, "/")+1:]
+
+	evil := "https://evil.example"
+	update := repo.UpdateLink(ctx, user.UpdateLinkRequest{UserID: "user", ShortLinkID: alias, Body: user.UpdateLinkRequestBody{
+		Rules: &[]user.RoutingRule{{Platform: "ios", Destination: evil}},
+	}})
+	require.Equal(t, http.StatusUnprocessableEntity, update.Code)
+
+	// Ссылка, заблокированная при перепроверке, больше не открывается.
+	_, err = s.SetBlocked(ctx, alias, "test: phishing")
+	require.NoError(t, err)
+	expand := repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: alias})
+	require.Equal(t, http.StatusForbidden, expand.Code)
+
+	links := repo.GetBatchByUserID(ctx, user.GetBatchByUserIDRequest{UserID: "user"})
+	require.Len(t, links.Response, 1)
+	require.Equal(t, "test: phishing", links.Response[0].Blocked)
+}
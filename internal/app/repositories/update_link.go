@@ -0,0 +1,322 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlnorm"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// UpdateLink - изменение атрибутов ссылки ее владельцем
+func (r *UserRepo) UpdateLink(ctx context.Context, request user.UpdateLinkRequest) user.UpdateLinkResponse {
+	update, err := linkUpdate(request.Body)
+	if err != nil {
+		return user.UpdateLinkResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if err = r.screen(ctx, updateDestinations(update)...); err != nil {
+		return user.UpdateLinkResponse{
+			Code:   screeningErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "url_checker",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return r.updateLink(ctx, request.UserID, request.ShortLinkID, request.BaseURL, update)
+}
+
+// GetLinkRevisions - история изменений original_url ссылки
+func (r *UserRepo) GetLinkRevisions(ctx context.Context, request user.GetLinkRevisionsRequest) user.GetLinkRevisionsResponse {
+	revisions, err := r.storage.GetRevisions(ctx, request.ShortLinkID, request.UserID)
+	if err != nil {
+		return user.GetLinkRevisionsResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	result := make([]user.LinkRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		result = append(result, user.LinkRevision{
+			ID:          revision.ID,
+			OriginalURL: revision.Object,
+			ReplacedAt:  revision.CreatedAt,
+		})
+	}
+
+	return user.GetLinkRevisionsResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: result,
+	}
+}
+
+// RestoreLinkRevision - возврат ссылки к одному из прежних original_url
+func (r *UserRepo) RestoreLinkRevision(ctx context.Context, request user.RestoreLinkRevisionRequest) user.UpdateLinkResponse {
+	revisions, err := r.storage.GetRevisions(ctx, request.ShortLinkID, request.UserID)
+	if err != nil {
+		return user.UpdateLinkResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	for _, revision := range revisions {
+		if revision.ID == request.RevisionID {
+			return r.updateLink(ctx, request.UserID, request.ShortLinkID, request.BaseURL, storage.LinkUpdate{
+				Object: &revision.Object,
+			})
+		}
+	}
+
+	return user.UpdateLinkResponse{
+		Code:   http.StatusNotFound,
+		Status: fail,
+		Error: &models.Err{
+			Source:  "storage",
+			Message: "revision not found",
+		},
+	}
+}
+
+func (r *UserRepo) updateLink(ctx context.Context, userID, alias, baseURL string, update storage.LinkUpdate) user.UpdateLinkResponse {
+	item, err := r.storage.UpdateLink(ctx, alias, userID, update)
+	if err != nil {
+		return user.UpdateLinkResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if r.storage.File != nil {
+		err = r.storage.File.SaveToFile(map[string]storage.Item{alias: item})
+		if err != nil {
+			return user.UpdateLinkResponse{
+				Code:   http.StatusInternalServerError,
+				Status: fail,
+				Error: &models.Err{
+					Source:  "file_storage",
+					Message: err.Error(),
+				},
+			}
+		}
+	}
+
+	return user.UpdateLinkResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: updatedLink(baseURL, alias, item),
+	}
+}
+
+func updatedLink(baseURL, alias string, item storage.Item) *user.UpdatedLink {
+	return &user.UpdatedLink{
+		ShortURL:     baseURL + "/" + alias,
+		OriginalURL:  item.Object,
+		Title:        item.Title,
+		Note:         item.Note,
+		Tags:         item.Tags,
+		Collection:   item.Collection,
+		RedirectCode: item.RedirectCode,
+		ForwardQuery: item.ForwardQuery,
+		ForwardPath:  item.ForwardPath,
+		Protected:    item.PasswordHash != "",
+		MaxClicks:    item.MaxClicks,
+		Clicks:       item.Clicks,
+		ActiveFrom:   optionalTime(item.ActiveFrom),
+		Rules:        routingRules(item.Rules),
+		Variants:     linkVariants(item.Variants),
+		Sticky:       item.Sticky,
+		Interstitial: item.Interstitial,
+		Blocked:      item.BlockedReason,
+		UpdatedAt:    item.UpdatedAt,
+	}
+}
+
+// linkUpdate проверяет, что запрос что-то меняет и новые значения допустимы.
+func linkUpdate(body user.UpdateLinkRequestBody) (storage.LinkUpdate, error) {
+	update := storage.LinkUpdate{
+		Object:       body.URL,
+		Title:        body.Title,
+		Note:         body.Note,
+		Collection:   body.Collection,
+		RedirectCode: body.RedirectCode,
+		ForwardQuery: body.ForwardQuery,
+		ForwardPath:  body.ForwardPath,
+		MaxClicks:    body.MaxClicks,
+		Sticky:       body.Sticky,
+		Interstitial: body.Interstitial,
+	}
+
+	if body.URL == nil && body.Title == nil && body.Note == nil && body.Tags == nil && body.Collection == nil &&
+		body.RedirectCode == nil && body.ForwardQuery == nil && body.ForwardPath == nil && body.Password == nil &&
+		body.MaxClicks == nil && body.ActiveFrom == nil && body.Rules == nil &&
+		body.Variants == nil && body.Sticky == nil && body.Interstitial == nil {
+		return update, errors.New("nothing to update")
+	}
+	if body.URL != nil {
+		originalURL, err := urlnorm.Normalize(*body.URL)
+		if err != nil {
+			return update, err
+		}
+		update.Object = &originalURL
+	}
+
+	var title, note string
+	if body.Title != nil {
+		title = *body.Title
+	}
+	if body.Note != nil {
+		note = *body.Note
+	}
+	if err := validateMetadata(title, note); err != nil {
+		return update, err
+	}
+
+	if body.Tags != nil {
+		tags, err := normalizeTags(*body.Tags)
+		if err != nil {
+			return update, err
+		}
+		update.Tags = &tags
+	}
+
+	if body.RedirectCode != nil {
+		if err := validateRedirectCode(*body.RedirectCode); err != nil {
+			return update, err
+		}
+	}
+
+	if body.MaxClicks != nil {
+		if err := validateMaxClicks(*body.MaxClicks); err != nil {
+			return update, err
+		}
+	}
+	if body.ActiveFrom != nil {
+		activeFrom, err := parseActiveFrom(*body.ActiveFrom)
+		if err != nil {
+			return update, err
+		}
+		update.ActiveFrom = &activeFrom
+	}
+
+	if body.Rules != nil {
+		rules, err := normalizeRules(*body.Rules)
+		if err != nil {
+			return update, err
+		}
+		update.Rules = &rules
+	}
+	if body.Variants != nil {
+		variants, err := normalizeVariants(*body.Variants)
+		if err != nil {
+			return update, err
+		}
+		update.Variants = &variants
+	}
+
+	// Пустой пароль снимает защиту со ссылки.
+	if body.Password != nil {
+		var hash string
+		if *body.Password != "" {
+			if err := validatePassword(*body.Password); err != nil {
+				return update, err
+			}
+			var err error
+			if hash, err = hashPassword(*body.Password); err != nil {
+				return update, err
+			}
+		}
+		update.PasswordHash = &hash
+	}
+
+	// Пустое имя убирает ссылку из коллекции.
+	if body.Collection != nil && *body.Collection != "" {
+		if err := validateGroupName("collection", *body.Collection); err != nil {
+			return update, err
+		}
+	}
+
+	return update, nil
+}
+
+// validateMetadata ограничивает длину названия и заметки ссылки.
+func validateMetadata(title, note string) error {
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return fmt.Errorf("title must not exceed %d characters", maxTitleLength)
+	}
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		return fmt.Errorf("note must not exceed %d characters", maxNoteLength)
+	}
+	return nil
+}
+
+// validateShortenBody проверяет атрибуты новой ссылки и возвращает нормализованные теги.
+func validateShortenBody(body user.ShortenLinkJSONRequestBody) ([]string, error) {
+	if err := validateMetadata(body.Title, body.Note); err != nil {
+		return nil, err
+	}
+
+	if err := validateRedirectCode(body.RedirectCode); err != nil {
+		return nil, err
+	}
+
+	if body.Collection != "" {
+		if err := validateGroupName("collection", body.Collection); err != nil {
+			return nil, err
+		}
+	}
+
+	if body.Password != "" {
+		if err := validatePassword(body.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateMaxClicks(body.MaxClicks); err != nil {
+		return nil, err
+	}
+	if _, err := parseActiveFrom(body.ActiveFrom); err != nil {
+		return nil, err
+	}
+	if _, err := normalizeRules(body.Rules); err != nil {
+		return nil, err
+	}
+	if _, err := normalizeVariants(body.Variants); err != nil {
+		return nil, err
+	}
+
+	if len(body.Tags) == 0 {
+		return nil, nil
+	}
+
+	return normalizeTags(body.Tags)
+}
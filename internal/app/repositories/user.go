@@ -3,6 +3,11 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/sonikq/url-shortener/internal/app/admin"
+	"github.com/sonikq/url-shortener/internal/app/pkg/throttle"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlcheck"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlnorm"
 	"github.com/sonikq/url-shortener/internal/app/pkg/utils"
 	"net/http"
 	"time"
@@ -14,27 +19,59 @@ import (
 
 // UserRepo -
 type UserRepo struct {
-	storage *storage.Storage
+	storage  *storage.Storage
+	attempts *throttle.Limiter   // неудачные вводы пароля по alias
+	checker  urlcheck.URLChecker // nil - адреса не проверяются
 }
 
 // NewUserRepo -
-func NewUserRepo(storage *storage.Storage) *UserRepo {
-	return &UserRepo{
-		storage: storage,
+func NewUserRepo(storage *storage.Storage, opts ...OptionsUserRepo) *UserRepo {
+	r := &UserRepo{
+		storage:  storage,
+		attempts: throttle.New(maxPasswordAttempts, passwordAttemptsWindow),
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // ShorteningLink -
 func (r *UserRepo) ShorteningLink(ctx context.Context, request user.ShorteningLinkRequest) user.ShorteningLinkResponse {
+	originalURL, err := urlnorm.Normalize(request.ShorteningLink)
+	if err != nil {
+		return user.ShorteningLinkResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+			Response: nil,
+		}
+	}
+
 	alias := utils.RandomString(sizeOfAlias)
 	result := request.BaseURL + "/" + alias
 
-	mapToStore := utils.ConvertDataToStore(alias, request.ShorteningLink, request.UserID)
+	if err = r.screen(ctx, originalURL); err != nil {
+		return user.ShorteningLinkResponse{
+			Code:   screeningErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "url_checker",
+				Message: err.Error(),
+			},
+			Response: nil,
+		}
+	}
+
+	mapToStore := utils.ConvertDataToStore(alias, originalURL, request.UserID)
 
-	err := r.storage.Set(ctx, mapToStore)
+	err = r.storage.Set(ctx, mapToStore)
 	if err != nil {
 		if errors.Is(err, models.ErrAlreadyExists) {
-			conflictShortURL, noShortURLErr := r.storage.GetShortURL(ctx, request.ShorteningLink)
+			conflictShortURL, noShortURLErr := r.storage.GetShortURL(ctx, originalURL, request.UserID)
 			if noShortURLErr != nil {
 				return user.ShorteningLinkResponse{
 					Code:   http.StatusInternalServerError,
@@ -90,16 +127,88 @@ func (r *UserRepo) ShorteningLink(ctx context.Context, request user.ShorteningLi
 
 // ShorteningLinkJSON -
 func (r *UserRepo) ShorteningLinkJSON(ctx context.Context, request user.ShorteningLinkJSONRequest) user.ShorteningLinkJSONResponse {
+	// Дедупликация через GetShortURL работает с канонической формой адреса.
+	var err error
+	request.ShorteningLink.URL, err = urlnorm.Normalize(request.ShorteningLink.URL)
+	if err != nil {
+		return user.ShorteningLinkJSONResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+			Response: user.ShortenLinkJSONResponseBody{},
+		}
+	}
+
+	tags, err := validateShortenBody(request.ShorteningLink)
+	if err != nil {
+		return user.ShorteningLinkJSONResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+			Response: user.ShortenLinkJSONResponseBody{},
+		}
+	}
+
 	alias := utils.RandomString(sizeOfAlias)
 
 	result := request.BaseURL + "/" + alias
 
 	mapToStore := utils.ConvertDataToStore(alias, request.ShorteningLink.URL, request.UserID)
+	item := mapToStore[alias]
+	item.Title = request.ShorteningLink.Title
+	item.Note = request.ShorteningLink.Note
+	item.Tags = tags
+	item.Collection = request.ShorteningLink.Collection
+	item.RedirectCode = request.ShorteningLink.RedirectCode
+	item.ForwardQuery = request.ShorteningLink.ForwardQuery
+	item.ForwardPath = request.ShorteningLink.ForwardPath
+	item.MaxClicks = request.ShorteningLink.MaxClicks
+	// Формат active_from, правила и варианты уже проверены в validateShortenBody.
+	item.ActiveFrom, _ = parseActiveFrom(request.ShorteningLink.ActiveFrom)
+	item.Rules, _ = normalizeRules(request.ShorteningLink.Rules)
+	item.Variants, _ = normalizeVariants(request.ShorteningLink.Variants)
+	item.Sticky = request.ShorteningLink.Sticky
+	item.Interstitial = request.ShorteningLink.Interstitial
+	mapToStore[alias] = item
+
+	if request.ShorteningLink.Password != "" {
+		item.PasswordHash, err = hashPassword(request.ShorteningLink.Password)
+		if err != nil {
+			return user.ShorteningLinkJSONResponse{
+				Code:   http.StatusInternalServerError,
+				Status: fail,
+				Error: &models.Err{
+					Source:  "password",
+					Message: err.Error(),
+				},
+				Response: user.ShortenLinkJSONResponseBody{},
+			}
+		}
+		mapToStore[alias] = item
+	}
+
+	if err = r.screen(ctx, item.Destinations()...); err != nil {
+		return user.ShorteningLinkJSONResponse{
+			Code:   screeningErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "url_checker",
+				Message: err.Error(),
+			},
+			Response: user.ShortenLinkJSONResponseBody{},
+		}
+	}
 
-	err := r.storage.Set(ctx, mapToStore)
+	err = r.storage.Set(ctx, mapToStore)
 	if err != nil {
 		if errors.Is(err, models.ErrAlreadyExists) {
-			conflictShortURL, noShortURLErr := r.storage.GetShortURL(ctx, request.ShorteningLink.URL)
+			conflictShortURL, noShortURLErr := r.storage.GetShortURL(ctx, request.ShorteningLink.URL, request.UserID)
 			if noShortURLErr != nil {
 				return user.ShorteningLinkJSONResponse{
 					Code:   http.StatusInternalServerError,
@@ -155,7 +264,7 @@ func (r *UserRepo) ShorteningLinkJSON(ctx context.Context, request user.Shorteni
 
 // GetFullLinkByID -
 func (r *UserRepo) GetFullLinkByID(ctx context.Context, request user.GetFullLinkByIDRequest) user.GetFullLinkByIDResponse {
-	fullLink, err := r.storage.Get(ctx, request.ShortLinkID)
+	item, err := r.storage.Get(ctx, request.ShortLinkID)
 	if err != nil {
 		if errors.Is(err, models.ErrGetDeletedLink) {
 			msg := "cant get deleted link"
@@ -167,7 +276,7 @@ func (r *UserRepo) GetFullLinkByID(ctx context.Context, request user.GetFullLink
 			}
 		}
 		return user.GetFullLinkByIDResponse{
-			Code:   http.StatusInternalServerError,
+			Code:   linkErrorCode(err),
 			Status: fail,
 			Error: &models.Err{
 				Source:  "storage",
@@ -177,11 +286,142 @@ func (r *UserRepo) GetFullLinkByID(ctx context.Context, request user.GetFullLink
 		}
 	}
 
+	if item.BlockedReason != "" {
+		return user.GetFullLinkByIDResponse{
+			Code:   http.StatusForbidden,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "url_checker",
+				Message: models.ErrLinkBlocked.Error(),
+			},
+			Response: nil,
+		}
+	}
+
+	if !item.ActiveFrom.IsZero() && time.Now().Before(item.ActiveFrom) {
+		return user.GetFullLinkByIDResponse{
+			Code:   http.StatusForbidden,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: models.ErrLinkNotActive.Error(),
+			},
+			Response: nil,
+		}
+	}
+
+	// Исчерпанная ссылка не требует пароля; окончательная проверка - в ConsumeClick.
+	if item.ClicksExhausted() {
+		return user.GetFullLinkByIDResponse{
+			Code:   http.StatusGone,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: models.ErrClicksExhausted.Error(),
+			},
+			Response: nil,
+		}
+	}
+
+	if err = r.checkPassword(request.ShortLinkID, item.PasswordHash, request.Password); err != nil {
+		response := user.GetFullLinkByIDResponse{
+			Code:   http.StatusUnauthorized,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "password",
+				Message: err.Error(),
+			},
+			Response:  nil,
+			Protected: true,
+		}
+		if errors.Is(err, models.ErrTooManyAttempts) {
+			response.Code = http.StatusTooManyRequests
+			response.RetryAfter = r.attempts.Blocked(request.ShortLinkID)
+		}
+		return response
+	}
+
+	// Правила маршрутизации, а за ними варианты A/B-теста подменяют original_url,
+	// хвост пути и query дописываются к выбранному адресу.
+	originalURL := item.Object
+	var variant *int
+	if target, matched := route(item, request); matched {
+		item.Object = target
+	} else if len(item.Variants) > 0 {
+		variant = utils.Ptr(pickVariant(item.Variants, request.Variant))
+		item.Object = item.Variants[*variant].Destination
+	}
+
+	location, err := destination(item, request.PathSuffix, request.RawQuery)
+	if err != nil {
+		return user.GetFullLinkByIDResponse{
+			Code:   http.StatusNotFound,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+			Response: nil,
+		}
+	}
+
+	// Предпросмотр не считается переходом: счетчики не меняются.
+	if item.MaxClicks > 0 && !request.Preview {
+		if item.Clicks, err = r.consumeClick(ctx, request.ShortLinkID); err != nil {
+			return user.GetFullLinkByIDResponse{
+				Code:   linkErrorCode(err),
+				Status: fail,
+				Error: &models.Err{
+					Source:  "storage",
+					Message: err.Error(),
+				},
+				Response: nil,
+			}
+		}
+	}
+
+	if variant != nil && !request.Preview {
+		if err = r.recordVariant(ctx, request.ShortLinkID, *variant); err != nil {
+			return user.GetFullLinkByIDResponse{
+				Code:   http.StatusInternalServerError,
+				Status: fail,
+				Error: &models.Err{
+					Source:  "storage",
+					Message: err.Error(),
+				},
+				Response: nil,
+			}
+		}
+	}
+
+	if !request.Preview {
+		link := storage.EventLink{
+			Alias:       request.ShortLinkID,
+			OriginalURL: originalURL,
+			Destination: location,
+			Clicks:      item.Clicks,
+			MaxClicks:   item.MaxClicks,
+		}
+		r.emit(ctx, item.UserID, storage.EventLinkClicked, link)
+		if item.ClicksExhausted() {
+			r.emit(ctx, item.UserID, storage.EventLinkExpired, link)
+		}
+	}
+
 	return user.GetFullLinkByIDResponse{
-		Code:     http.StatusTemporaryRedirect,
-		Status:   success,
-		Error:    nil,
-		Response: &fullLink,
+		Code:      redirectCode(item.RedirectCode, request.DefaultRedirectCode),
+		Status:    success,
+		Error:     nil,
+		Response:  &location,
+		Protected: item.PasswordHash != "",
+		Limited:   item.MaxClicks > 0,
+		Routed:    len(item.Rules) > 0,
+		Variant:   variant,
+		Sticky:    variant != nil && item.Sticky && !request.Preview,
+
+		Interstitial: item.Interstitial,
+		Title:        item.Title,
+		CreatedAt:    item.CreatedAt,
 	}
 }
 
@@ -189,7 +429,24 @@ func (r *UserRepo) GetFullLinkByID(ctx context.Context, request user.GetFullLink
 func (r *UserRepo) GetBatchByUserID(ctx context.Context, request user.GetBatchByUserIDRequest) user.GetBatchByUserIDResponse {
 	var result []user.BatchByUserID
 
-	batch, err := r.storage.GetBatchByUserID(ctx, request.UserID)
+	query, err := batchQuery(request)
+	if err != nil {
+		return user.GetBatchByUserIDResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+			Response: nil,
+		}
+	}
+
+	// Запрашиваем на одну ссылку больше, чтобы понять, есть ли следующая страница.
+	limit := query.Limit
+	query.Limit++
+
+	batch, err := r.storage.GetBatchByUserID(ctx, request.UserID, query)
 	if err != nil {
 		return user.GetBatchByUserIDResponse{
 			Code:   http.StatusInternalServerError,
@@ -210,18 +467,48 @@ func (r *UserRepo) GetBatchByUserID(ctx context.Context, request user.GetBatchBy
 		}
 	}
 
-	for key, value := range batch {
-		result = append(result, user.BatchByUserID{
-			ShortURL:    request.BaseURL + "/" + key,
-			OriginalURL: value.Object,
-		})
+	var nextCursor string
+	if len(batch) > limit {
+		batch = batch[:limit]
+		nextCursor = encodeCursor(batch[limit-1])
+	}
+
+	for _, record := range batch {
+		link := user.BatchByUserID{
+			ShortURL:     request.BaseURL + "/" + record.Alias,
+			OriginalURL:  record.Object,
+			Title:        record.Title,
+			Note:         record.Note,
+			Tags:         record.Tags,
+			Collection:   record.Collection,
+			RedirectCode: record.RedirectCode,
+			ForwardQuery: record.ForwardQuery,
+			ForwardPath:  record.ForwardPath,
+			Protected:    record.PasswordHash != "",
+			MaxClicks:    record.MaxClicks,
+			Clicks:       record.Clicks,
+			ActiveFrom:   optionalTime(record.ActiveFrom),
+			Rules:        routingRules(record.Rules),
+			Variants:     linkVariants(record.Variants),
+			Sticky:       record.Sticky,
+			Interstitial: record.Interstitial,
+			Blocked:      record.BlockedReason,
+			CreatedAt:    record.CreatedAt,
+			UpdatedAt:    record.UpdatedAt,
+			IsDeleted:    record.IsDeleted,
+		}
+		if record.IsDeleted {
+			link.DeletedAt = utils.Ptr(record.DeletedAt)
+		}
+		result = append(result, link)
 	}
 
 	return user.GetBatchByUserIDResponse{
-		Code:     http.StatusOK,
-		Status:   success,
-		Error:    nil,
-		Response: result,
+		Code:       http.StatusOK,
+		Status:     success,
+		Error:      nil,
+		Response:   result,
+		NextCursor: nextCursor,
 	}
 }
 
@@ -235,11 +522,34 @@ func (r *UserRepo) ShorteningBatchLinks(ctx context.Context, request user.Shorte
 	storageMap := make(map[string]storage.Item)
 	var result []user.BatchUrlsOutput
 	for _, itemOfBatch := range request.Body {
+		originalURL, err := urlnorm.Normalize(itemOfBatch.OriginalURL)
+		if err != nil {
+			return user.ShorteningBatchLinksResponse{
+				Code:   http.StatusBadRequest,
+				Status: fail,
+				Error: &models.Err{
+					Source:  "request",
+					Message: fmt.Sprintf("correlation_id %q: %s", itemOfBatch.CorrelationID, err),
+				},
+				Response: nil,
+			}
+		}
+		if err = r.screen(ctx, originalURL); err != nil {
+			return user.ShorteningBatchLinksResponse{
+				Code:   screeningErrorCode(err),
+				Status: fail,
+				Error: &models.Err{
+					Source:  "url_checker",
+					Message: fmt.Sprintf("correlation_id %q: %s", itemOfBatch.CorrelationID, err),
+				},
+				Response: nil,
+			}
+		}
+
 		alias := utils.RandomString(sizeOfAlias)
 		itemToStoreInDB := storage.Item{
-			Object:     itemOfBatch.OriginalURL,
-			Expiration: time.Now().Add(10 * time.Minute).UnixNano(),
-			UserID:     request.UserID,
+			Object: originalURL,
+			UserID: request.UserID,
 		}
 		storageMap[alias] = itemToStoreInDB
 		result = append(result, user.BatchUrlsOutput{
@@ -283,6 +593,30 @@ func (r *UserRepo) ShorteningBatchLinks(ctx context.Context, request user.Shorte
 	}
 }
 
+// LookupLink - ссылка в любом состоянии в представлении администратора. В отличие от перехода
+// по ссылке счетчики, варианты A/B-теста и события не изменяются.
+func (r *UserRepo) LookupLink(ctx context.Context, request user.LookupLinkRequest) user.LookupLinkResponse {
+	link, err := admin.New(r.storage).Lookup(ctx, request.ShortLinkID)
+	if err != nil {
+		return user.LookupLinkResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+			Response: nil,
+		}
+	}
+
+	return user.LookupLinkResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Error:    nil,
+		Response: &link,
+	}
+}
+
 // GetStats - resolving count of urls and users in storage
 func (r *UserRepo) GetStats(ctx context.Context) user.GetStatsResponse {
 	urls, users, err := r.storage.GetStats(ctx)
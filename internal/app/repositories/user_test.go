@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/admin"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_ShorteningLink_canonical(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	shorten := func(url string) user.ShorteningLinkJSONResponse {
+		return repo.ShorteningLinkJSON(ctx, user.ShorteningLinkJSONRequest{
+			UserID:         "user",
+			ShorteningLink: user.ShortenLinkJSONRequestBody{URL: url},
+			BaseURL:        "http://localhost:8080",
+		})
+	}
+
+	created := shorten("HTTPS://Example.com:443/")
+	require.Equal(t, http.StatusCreated, created.Code)
+
+	// Тот же адрес в другой записи находит уже созданную ссылку.
+	conflict := shorten("https://example.com")
+	require.Equal(t, http.StatusConflict, conflict.Code)
+	require.Equal(t, created.Response.Result, conflict.Response.Result)
+
+	plain := repo.ShorteningLink(ctx, user.ShorteningLinkRequest{
+		UserID:         "user",
+		ShorteningLink: "https://EXAMPLE.com/\n",
+		BaseURL:        "http://localhost:8080",
+	})
+	require.Equal(t, http.StatusConflict, plain.Code)
+	require.Equal(t, created.Response.Result, *plain.Response)
+
+	for _, url := range []string{"", "javascript:alert(1)", "not a url", "https://"} {
+		require.Equal(t, http.StatusBadRequest, shorten(url).Code, url)
+		require.Equal(t, http.StatusBadRequest, repo.ShorteningLink(ctx, user.ShorteningLinkRequest{ShorteningLink: url}).Code, url)
+	}
+
+	batch := repo.ShorteningBatchLinks(ctx, user.ShorteningBatchLinksRequest{
+		UserID: "user",
+		Body: []user.BatchUrlsInput{
+			{CorrelationID: "1", OriginalURL: "https://ya.ru"},
+			{CorrelationID: "2", OriginalURL: "data:text/html,hi"},
+		},
+	})
+	require.Equal(t, http.StatusBadRequest, batch.Code)
+	require.Contains(t, batch.Error.Message, `correlation_id "2"`)
+
+	stats := repo.GetStats(ctx)
+	require.EqualValues(t, 1, stats.Response.URL)
+}
+
+func TestUserRepo_LookupLink(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"limited": {Object: "https://ya.ru", UserID: "user", MaxClicks: 1},
+		"later":   {Object: "https://example.com", UserID: "user", ActiveFrom: time.Now().Add(time.Hour)},
+		"blocked": {Object: "https://example.org", UserID: "user", BlockedReason: "malware"},
+	}))
+
+	// Просмотр администратором не расходует переходы.
+	for i := 0; i < 2; i++ {
+		result := repo.LookupLink(ctx, user.LookupLinkRequest{ShortLinkID: "limited"})
+		require.Equal(t, http.StatusOK, result.Code)
+		require.Equal(t, admin.StateActive, result.Response.State)
+		require.Equal(t, "https://ya.ru", result.Response.OriginalURL)
+		require.Zero(t, result.Response.Clicks)
+	}
+
+	require.Equal(t, admin.StatePending, repo.LookupLink(ctx, user.LookupLinkRequest{ShortLinkID: "later"}).Response.State)
+	require.Equal(t, admin.StateBlocked, repo.LookupLink(ctx, user.LookupLinkRequest{ShortLinkID: "blocked"}).Response.State)
+	require.Equal(t, http.StatusNotFound, repo.LookupLink(ctx, user.LookupLinkRequest{ShortLinkID: "missing"}).Code)
+}
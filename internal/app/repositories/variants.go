@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlnorm"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// GetLinkStats - счетчики переходов по ссылке владельца, в том числе по вариантам A/B-теста
+func (r *UserRepo) GetLinkStats(ctx context.Context, request user.GetLinkStatsRequest) user.GetLinkStatsResponse {
+	item, err := r.ownedLink(ctx, request.ShortLinkID, request.UserID)
+	if err != nil {
+		return user.GetLinkStatsResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return user.GetLinkStatsResponse{
+		Code:   http.StatusOK,
+		Status: success,
+		Response: &user.LinkStats{
+			ShortURL:  request.BaseURL + "/" + request.ShortLinkID,
+			MaxClicks: item.MaxClicks,
+			Clicks:    item.Clicks,
+			Variants:  linkVariants(item.Variants),
+		},
+	}
+}
+
+// ownedLink - неудаленная ссылка, принадлежащая пользователю.
+func (r *UserRepo) ownedLink(ctx context.Context, alias, userID string) (storage.Item, error) {
+	item, err := r.storage.Get(ctx, alias)
+	switch {
+	case err != nil:
+		return storage.Item{}, err
+	case item.Object == "":
+		return storage.Item{}, models.ErrLinkNotFound
+	case item.UserID != userID:
+		return storage.Item{}, models.ErrNotOwner
+	}
+	return item, nil
+}
+
+// normalizeVariants проверяет варианты A/B-теста, счетчики новых вариантов начинаются с нуля.
+func normalizeVariants(variants []user.Variant) ([]storage.Variant, error) {
+	if len(variants) == 0 {
+		return []storage.Variant{}, nil
+	}
+	if len(variants) < 2 || len(variants) > maxVariantsPerLink {
+		return nil, fmt.Errorf("link must have from 2 to %d variants", maxVariantsPerLink)
+	}
+
+	var total int
+	normalized := make([]storage.Variant, 0, len(variants))
+	for i, variant := range variants {
+		if variant.Weight < 0 || variant.Weight > maxVariantWeight {
+			return nil, fmt.Errorf("variant %d: weight must be between 0 and %d", i, maxVariantWeight)
+		}
+		destination, err := urlnorm.Normalize(variant.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("variant %d: destination: %w", i, err)
+		}
+
+		total += variant.Weight
+		normalized = append(normalized, storage.Variant{
+			Destination: destination,
+			Weight:      variant.Weight,
+		})
+	}
+	if total == 0 {
+		return nil, errors.New("at least one variant must have positive weight")
+	}
+
+	return normalized, nil
+}
+
+// linkVariants - варианты ссылки в представлении API.
+func linkVariants(variants []storage.Variant) []user.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	result := make([]user.Variant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, user.Variant(variant))
+	}
+	return result
+}
+
+// pickVariant выбирает вариант: закрепленный за посетителем, если он еще действует, иначе случайный по весам.
+func pickVariant(variants []storage.Variant, sticky *int) int {
+	if sticky != nil && *sticky >= 0 && *sticky < len(variants) && variants[*sticky].Weight > 0 {
+		return *sticky
+	}
+
+	var total int
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	return weightedVariant(variants, rand.Intn(total))
+}
+
+// weightedVariant - вариант, на отрезок которого приходится point из [0, сумма весов).
+func weightedVariant(variants []storage.Variant, point int) int {
+	for i, variant := range variants {
+		if point < variant.Weight {
+			return i
+		}
+		point -= variant.Weight
+	}
+	return len(variants) - 1
+}
+
+// recordVariant учитывает выбор варианта и сохраняет счетчики в файл.
+func (r *UserRepo) recordVariant(ctx context.Context, alias string, variant int) error {
+	item, err := r.storage.RecordVariant(ctx, alias, variant)
+	if err != nil {
+		return err
+	}
+
+	if r.storage.File != nil {
+		return r.storage.File.SaveToFile(map[string]storage.Item{alias: item})
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/utils"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_weightedVariant(t *testing.T) {
+	variants := []storage.Variant{{Weight: 3}, {Weight: 0}, {Weight: 1}}
+
+	require.Equal(t, 0, weightedVariant(variants, 0))
+	require.Equal(t, 0, weightedVariant(variants, 2))
+	require.Equal(t, 2, weightedVariant(variants, 3))
+}
+
+func Test_pickVariant(t *testing.T) {
+	variants := []storage.Variant{{Weight: 1}, {Weight: 0}}
+
+	require.Equal(t, 0, pickVariant(variants, utils.Ptr(1)), "variant without weight is not sticky")
+	require.Equal(t, 0, pickVariant(variants, utils.Ptr(5)))
+
+	variants[1].Weight = 1
+	require.Equal(t, 1, pickVariant(variants, utils.Ptr(1)))
+}
+
+func Test_normalizeVariants(t *testing.T) {
+	_, err := normalizeVariants([]user.Variant{{Destination: "https://a.example.com", Weight: 1}})
+	require.Error(t, err)
+
+	_, err = normalizeVariants([]user.Variant{
+		{Destination: "https://a.example.com"},
+		{Destination: "https://b.example.com"},
+	})
+	require.Error(t, err)
+
+	variants, err := normalizeVariants([]user.Variant{
+		{Destination: "https://a.example.com", Weight: 1, Clicks: 10},
+		{Destination: "https://b.example.com", Weight: 2},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []storage.Variant{
+		{Destination: "https://a.example.com", Weight: 1},
+		{Destination: "https://b.example.com", Weight: 2},
+	}, variants)
+}
+
+func TestUserRepo_GetFullLinkByID_variants(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	require.NoError(t, s.Set(ctx, map[string]storage.Item{
+		"abtest": {
+			Object: "https://example.com",
+			UserID: "user",
+			Sticky: true,
+			Variants: []storage.Variant{
+				{Destination: "https://a.example.com", Weight: 1},
+				{Destination: "https://b.example.com", Weight: 1},
+			},
+		},
+	}))
+
+	for i := 0; i < 3; i++ {
+		result := repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: "abtest", Variant: utils.Ptr(1)})
+		require.Equal(t, http.StatusTemporaryRedirect, result.Code)
+		require.Equal(t, "https://b.example.com", *result.Response)
+		require.Equal(t, 1, *result.Variant)
+		require.True(t, result.Sticky)
+	}
+
+	stats := repo.GetLinkStats(ctx, user.GetLinkStatsRequest{UserID: "user", ShortLinkID: "abtest"})
+	require.Equal(t, http.StatusOK, stats.Code)
+	require.Equal(t, []user.Variant{
+		{Destination: "https://a.example.com", Weight: 1},
+		{Destination: "https://b.example.com", Weight: 1, Clicks: 3},
+	}, stats.Response.Variants)
+
+	stats = repo.GetLinkStats(ctx, user.GetLinkStatsRequest{UserID: "other", ShortLinkID: "abtest"})
+	require.Equal(t, http.StatusForbidden, stats.Code)
+}
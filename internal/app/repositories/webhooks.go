@@ -0,0 +1,292 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlnorm"
+	"github.com/sonikq/url-shortener/internal/app/pkg/webhook"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// CreateWebhook - подписка пользователя на события его ссылок, ключ подписи отдается только здесь
+func (r *UserRepo) CreateWebhook(ctx context.Context, request user.CreateWebhookRequest) user.CreateWebhookResponse {
+	webhookURL, events, err := validateWebhookBody(request.Body)
+	if err != nil {
+		return user.CreateWebhookResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	existing, err := r.storage.GetWebhooks(ctx, request.UserID)
+	if err != nil {
+		return user.CreateWebhookResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return user.CreateWebhookResponse{
+			Code:   http.StatusConflict,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: fmt.Sprintf("at most %d webhooks per user", maxWebhooksPerUser),
+			},
+		}
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return user.CreateWebhookResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "secret",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	webhook := storage.Webhook{
+		ID:        uuid.NewString(),
+		UserID:    request.UserID,
+		URL:       webhookURL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now(),
+	}
+	if err = r.storage.CreateWebhook(ctx, webhook); err != nil {
+		return user.CreateWebhookResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	response := webhookResponse(webhook)
+	response.Secret = webhook.Secret
+
+	return user.CreateWebhookResponse{
+		Code:     http.StatusCreated,
+		Status:   success,
+		Response: &response,
+	}
+}
+
+// GetWebhooks - подписки пользователя без ключей подписи
+func (r *UserRepo) GetWebhooks(ctx context.Context, request user.GetWebhooksRequest) user.GetWebhooksResponse {
+	webhooks, err := r.storage.GetWebhooks(ctx, request.UserID)
+	if err != nil {
+		return user.GetWebhooksResponse{
+			Code:   http.StatusInternalServerError,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	result := make([]user.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		result = append(result, webhookResponse(webhook))
+	}
+
+	return user.GetWebhooksResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: result,
+	}
+}
+
+// DeleteWebhook - удаление подписки вместе с журналом доставок
+func (r *UserRepo) DeleteWebhook(ctx context.Context, request user.DeleteWebhookRequest) user.DeleteWebhookResponse {
+	if err := r.storage.DeleteWebhook(ctx, request.UserID, request.ID); err != nil {
+		return user.DeleteWebhookResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return user.DeleteWebhookResponse{
+		Code:   http.StatusNoContent,
+		Status: success,
+	}
+}
+
+// GetWebhookDeliveries - журнал доставок подписки, со статусом dead - список недоставленных событий
+func (r *UserRepo) GetWebhookDeliveries(ctx context.Context, request user.GetWebhookDeliveriesRequest) user.GetWebhookDeliveriesResponse {
+	limit, err := deliveriesLimit(request)
+	if err != nil {
+		return user.GetWebhookDeliveriesResponse{
+			Code:   http.StatusBadRequest,
+			Status: fail,
+			Error: &models.Err{
+				Source:  "request",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	deliveries, err := r.storage.GetDeliveries(ctx, request.UserID, request.WebhookID, request.Status, limit)
+	if err != nil {
+		return user.GetWebhookDeliveriesResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	result := make([]user.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, deliveryResponse(delivery))
+	}
+
+	return user.GetWebhookDeliveriesResponse{
+		Code:     http.StatusOK,
+		Status:   success,
+		Response: result,
+	}
+}
+
+// RetryWebhookDelivery - повторная отправка доставки, в том числе из списка недоставленных
+func (r *UserRepo) RetryWebhookDelivery(ctx context.Context, request user.RetryWebhookDeliveryRequest) user.RetryWebhookDeliveryResponse {
+	delivery, err := r.storage.RetryDelivery(ctx, request.UserID, request.WebhookID, request.DeliveryID)
+	if err != nil {
+		return user.RetryWebhookDeliveryResponse{
+			Code:   linkErrorCode(err),
+			Status: fail,
+			Error: &models.Err{
+				Source:  "storage",
+				Message: err.Error(),
+			},
+		}
+	}
+
+	response := deliveryResponse(delivery)
+
+	return user.RetryWebhookDeliveryResponse{
+		Code:     http.StatusAccepted,
+		Status:   success,
+		Response: &response,
+	}
+}
+
+// emit ставит в outbox событие, которое не сопровождает изменение ссылки в хранилище
+// (link.created и link.deleted хранилище пишет само). Событие - побочный эффект: ошибка
+// здесь не должна ломать переход по ссылке, поэтому не возвращается.
+func (r *UserRepo) emit(ctx context.Context, userID, eventType string, link storage.EventLink) {
+	_ = r.storage.AddOutbox(ctx, []storage.OutboxEvent{storage.NewOutboxEvent(eventType, userID, link)})
+}
+
+// validateWebhookBody нормализует адрес подписки и список событий, пустой список - все события.
+// Адреса во внутренней сети и локальные имена отклоняются.
+func validateWebhookBody(body user.CreateWebhookRequestBody) (string, []string, error) {
+	webhookURL, err := urlnorm.Normalize(body.URL)
+	if err != nil {
+		return "", nil, err
+	}
+	if err = webhook.CheckURL(webhookURL); err != nil {
+		return "", nil, err
+	}
+
+	if len(body.Events) == 0 {
+		return webhookURL, slices.Clone(storage.EventTypes), nil
+	}
+
+	for _, event := range body.Events {
+		if !slices.Contains(storage.EventTypes, event) {
+			return "", nil, fmt.Errorf("unknown event: %q", event)
+		}
+	}
+
+	events := make([]string, 0, len(body.Events))
+	for _, event := range storage.EventTypes {
+		if slices.Contains(body.Events, event) {
+			events = append(events, event)
+		}
+	}
+
+	return webhookURL, events, nil
+}
+
+// deliveriesLimit проверяет фильтр журнала доставок и возвращает размер выборки.
+func deliveriesLimit(request user.GetWebhookDeliveriesRequest) (int, error) {
+	switch request.Status {
+	case "", storage.DeliveryPending, storage.DeliveryDelivered, storage.DeliveryDead:
+	default:
+		return 0, fmt.Errorf("unknown delivery status: %q", request.Status)
+	}
+
+	switch {
+	case request.Limit == 0:
+		return defaultDeliveriesPageSize, nil
+	case request.Limit < 0 || request.Limit > maxDeliveriesPageSize:
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxDeliveriesPageSize)
+	}
+	return request.Limit, nil
+}
+
+// newWebhookSecret - случайный ключ подписи доставок.
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, webhookSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func webhookResponse(webhook storage.Webhook) user.Webhook {
+	return user.Webhook{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func deliveryResponse(delivery storage.Delivery) user.WebhookDelivery {
+	response := user.WebhookDelivery{
+		ID:           delivery.ID,
+		Event:        delivery.Event,
+		Status:       delivery.Status,
+		Attempts:     delivery.Attempts,
+		LastError:    delivery.LastError,
+		ResponseCode: delivery.ResponseCode,
+		CreatedAt:    delivery.CreatedAt,
+		UpdatedAt:    delivery.UpdatedAt,
+		Payload:      delivery.Payload,
+	}
+	if delivery.Status == storage.DeliveryPending {
+		response.NextAttemptAt = optionalTime(delivery.NextAttemptAt)
+	}
+	return response
+}
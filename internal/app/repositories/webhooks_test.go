@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	create := func(body user.CreateWebhookRequestBody) user.CreateWebhookResponse {
+		return repo.CreateWebhook(ctx, user.CreateWebhookRequest{UserID: "user", Body: body})
+	}
+
+	require.Equal(t, http.StatusBadRequest, create(user.CreateWebhookRequestBody{URL: "ftp://example.com"}).Code)
+	for _, internal := range []string{"http://127.0.0.1:8080/api", "http://localhost/hook", "http://10.0.0.5/hook",
+		"http://169.254.169.254/latest/meta-data", "http://[::1]/hook"} {
+		require.Equal(t, http.StatusBadRequest, create(user.CreateWebhookRequestBody{URL: internal}).Code, internal)
+	}
+	require.Equal(t, http.StatusBadRequest, create(user.CreateWebhookRequestBody{
+		URL: "https://example.com/hook", Events: []string{"link.renamed"},
+	}).Code)
+
+	result := create(user.CreateWebhookRequestBody{URL: "https://Example.com/hook"})
+	require.Equal(t, http.StatusCreated, result.Code)
+	require.Equal(t, "https://example.com/hook", result.Response.URL)
+	require.Equal(t, storage.EventTypes, result.Response.Events)
+	require.Len(t, result.Response.Secret, 2*webhookSecretSize)
+
+	result = create(user.CreateWebhookRequestBody{
+		URL: "https://example.com/hook", Events: []string{storage.EventLinkDeleted, storage.EventLinkCreated},
+	})
+	require.Equal(t, []string{storage.EventLinkCreated, storage.EventLinkDeleted}, result.Response.Events)
+
+	list := repo.GetWebhooks(ctx, user.GetWebhooksRequest{UserID: "user"})
+	require.Equal(t, http.StatusOK, list.Code)
+	require.Len(t, list.Response, 2)
+	for _, webhook := range list.Response {
+		require.Empty(t, webhook.Secret)
+	}
+
+	for i := len(list.Response); i < maxWebhooksPerUser; i++ {
+		require.Equal(t, http.StatusCreated, create(user.CreateWebhookRequestBody{URL: "https://example.com/hook"}).Code)
+	}
+	require.Equal(t, http.StatusConflict, create(user.CreateWebhookRequestBody{URL: "https://example.com/hook"}).Code)
+
+	deleted := repo.DeleteWebhook(ctx, user.DeleteWebhookRequest{UserID: "other", ID: result.Response.ID})
+	require.Equal(t, http.StatusNotFound, deleted.Code)
+	deleted = repo.DeleteWebhook(ctx, user.DeleteWebhookRequest{UserID: "user", ID: result.Response.ID})
+	require.Equal(t, http.StatusNoContent, deleted.Code)
+}
+
+func TestUserRepo_events(t *testing.T) {
+	ctx := context.Background()
+	s, err := storage.NewStorage()
+	require.NoError(t, err)
+	repo := NewUserRepo(s)
+
+	shortened := repo.ShorteningLinkJSON(ctx, user.ShorteningLinkJSONRequest{
+		UserID:         "user",
+		BaseURL:        "http://localhost:8080",
+		ShorteningLink: user.ShortenLinkJSONRequestBody{URL: "https://example.org", MaxClicks: 1},
+	})
+	require.Equal(t, http.StatusCreated, shortened.Code)
+	alias := strings.TrimPrefix(shortened.Response.Result, "http://localhost:8080/")
+
+	preview := repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: alias, Preview: true})
+	require.Equal(t, http.StatusTemporaryRedirect, preview.Code)
+
+	clicked := repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: alias})
+	require.Equal(t, http.StatusTemporaryRedirect, clicked.Code)
+
+	deleted := repo.DeleteBatchLinks(ctx, user.DeleteBatchLinksRequest{
+		UserID: "user",
+		Body:   []user.DeleteBatchBody{{URLS: []string{alias, "missing"}}},
+	})
+	require.Equal(t, http.StatusAccepted, deleted.Code)
+
+	outbox, err := s.ClaimOutbox(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, outbox, 4)
+
+	types := make([]string, 0, len(outbox))
+	for _, event := range outbox {
+		require.Equal(t, "user", event.UserID)
+		require.Equal(t, alias, event.Link.Alias)
+		types = append(types, event.Type)
+	}
+	require.Equal(t, []string{
+		storage.EventLinkCreated, storage.EventLinkClicked, storage.EventLinkExpired, storage.EventLinkDeleted,
+	}, types)
+	require.Equal(t, "https://example.org", outbox[0].Link.OriginalURL)
+	require.Equal(t, 1, outbox[1].Link.Clicks)
+}
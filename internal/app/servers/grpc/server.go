@@ -19,7 +19,12 @@ type Server struct {
 func NewServer(conf app.Config, repo repositories.IUserRepo) *Server {
 	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors.UnaryServerInterceptorOpts(conf.TrustedSubnet)))
 
-	pb.RegisterShortenerServer(server, &services.ServiceGrpc{Repo: repo, BaseURL: conf.BaseURL})
+	pb.RegisterShortenerServer(server, &services.ServiceGrpc{
+		Repo:                repo,
+		BaseURL:             conf.BaseURL,
+		DefaultRedirectCode: conf.DefaultRedirectCode,
+		QRLogoPath:          conf.QRLogoPath,
+	})
 	return &Server{server}
 }
 
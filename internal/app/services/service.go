@@ -16,6 +16,28 @@ type IUserService interface {
 	ShorteningBatchLinks(ctx context.Context, request user.ShorteningBatchLinksRequest) user.ShorteningBatchLinksResponse
 	GetBatchByUserID(ctx context.Context, request user.GetBatchByUserIDRequest) user.GetBatchByUserIDResponse
 	GetStats(ctx context.Context) user.GetStatsResponse
+	LookupLink(ctx context.Context, request user.LookupLinkRequest) user.LookupLinkResponse
+	UpdateLink(ctx context.Context, request user.UpdateLinkRequest) user.UpdateLinkResponse
+	GetLinkRevisions(ctx context.Context, request user.GetLinkRevisionsRequest) user.GetLinkRevisionsResponse
+	GetLinkStats(ctx context.Context, request user.GetLinkStatsRequest) user.GetLinkStatsResponse
+	GetLinkQR(ctx context.Context, request user.GetLinkQRRequest) user.GetLinkQRResponse
+	RestoreLinkRevision(ctx context.Context, request user.RestoreLinkRevisionRequest) user.UpdateLinkResponse
+	RestoreLink(ctx context.Context, request user.RestoreLinkRequest) user.UpdateLinkResponse
+	GetDeletedLinks(ctx context.Context, request user.GetDeletedLinksRequest) user.GetDeletedLinksResponse
+	CreateCollection(ctx context.Context, request user.CreateCollectionRequest) user.CreateCollectionResponse
+	GetCollections(ctx context.Context, request user.GetCollectionsRequest) user.GetCollectionsResponse
+	DeleteCollection(ctx context.Context, request user.DeleteCollectionRequest) user.DeleteCollectionResponse
+	ExportCollection(ctx context.Context, request user.ExportCollectionRequest) user.ExportCollectionResponse
+	DeleteBatchLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteBatchLinksResponse
+	EnqueueDeleteLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteJobResponse
+	GetDeleteJob(ctx context.Context, request user.GetDeleteJobRequest) user.DeleteJobResponse
+	ImportLinks(ctx context.Context, request user.ImportLinksRequest) user.ImportLinksResponse
+	ExportLinks(ctx context.Context, request user.ExportLinksRequest) user.ExportLinksResponse
+	CreateWebhook(ctx context.Context, request user.CreateWebhookRequest) user.CreateWebhookResponse
+	GetWebhooks(ctx context.Context, request user.GetWebhooksRequest) user.GetWebhooksResponse
+	DeleteWebhook(ctx context.Context, request user.DeleteWebhookRequest) user.DeleteWebhookResponse
+	GetWebhookDeliveries(ctx context.Context, request user.GetWebhookDeliveriesRequest) user.GetWebhookDeliveriesResponse
+	RetryWebhookDelivery(ctx context.Context, request user.RetryWebhookDeliveryRequest) user.RetryWebhookDeliveryResponse
 }
 
 // Service -
@@ -9,50 +9,94 @@ import (
 	"github.com/sonikq/url-shortener/internal/app/repositories"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"net/http"
 )
 
 // ServiceGrpc -
 type ServiceGrpc struct {
 	pb.UnimplementedShortenerServer
-	Repo    repositories.IUserRepo
-	BaseURL string
+	Repo                repositories.IUserRepo
+	BaseURL             string
+	DefaultRedirectCode int
+	QRLogoPath          string
 }
 
 func (s *ServiceGrpc) Shorten(ctx context.Context, req *pb.ShortenRequest) (*pb.ShortenResponse, error) {
 	var resp pb.ShortenResponse
 
-	result := s.Repo.ShorteningLink(ctx, user.ShorteningLinkRequest{
-		UserID:         req.UserId,
-		ShorteningLink: req.Url,
-		BaseURL:        s.BaseURL,
+	result := s.Repo.ShorteningLinkJSON(ctx, user.ShorteningLinkJSONRequest{
+		UserID: req.UserId,
+		ShorteningLink: user.ShortenLinkJSONRequestBody{
+			URL:          req.Url,
+			Title:        req.Title,
+			Note:         req.Note,
+			Tags:         req.Tags,
+			Collection:   req.Collection,
+			RedirectCode: int(req.RedirectCode),
+			ForwardQuery: req.ForwardQuery,
+			ForwardPath:  req.ForwardPath,
+			Password:     req.Password,
+			MaxClicks:    int(req.MaxClicks),
+			ActiveFrom:   req.ActiveFrom,
+			Rules:        rulesFromProto(req.Rules),
+			Variants:     variantsFromProto(req.Variants),
+			Sticky:       req.Sticky,
+			Interstitial: req.Interstitial,
+		},
+		BaseURL: s.BaseURL,
 	})
 	if result.Error != nil {
 		switch result.Code {
 		case http.StatusConflict:
 			return nil, status.Error(codes.AlreadyExists, models.ErrAlreadyExists.Error())
 		default:
-			return nil, status.Error(codes.Internal, result.Error.Message)
+			return nil, status.Error(grpcCode(result.Code), result.Error.Message)
 		}
 	}
-	resp.Shorten = *result.Response
+	resp.Shorten = result.Response.Result
 	return &resp, nil
 }
 
 func (s *ServiceGrpc) Expand(ctx context.Context, req *pb.ExpandRequest) (*pb.ExpandResponse, error) {
 	var resp pb.ExpandResponse
 
-	result := s.Repo.GetFullLinkByID(ctx, user.GetFullLinkByIDRequest{ShortLinkID: req.ShortUrl})
-	if result.Error != nil {
-		switch result.Code {
-		case http.StatusGone:
-			return nil, status.Error(codes.DataLoss, models.ErrGetDeletedLink.Error())
-		default:
-			return nil, status.Error(codes.Internal, result.Error.Message)
-		}
+	request := user.GetFullLinkByIDRequest{
+		ShortLinkID:         req.ShortUrl,
+		DefaultRedirectCode: s.DefaultRedirectCode,
+		PathSuffix:          req.PathSuffix,
+		RawQuery:            req.Query,
+		Password:            req.Password,
+		UserAgent:           req.UserAgent,
+		AcceptLanguage:      req.AcceptLanguage,
+		Referrer:            req.Referrer,
+		Preview:             req.Preview,
+	}
+	if req.Variant != nil {
+		variant := int(*req.Variant)
+		request.Variant = &variant
+	}
+
+	result := s.Repo.GetFullLinkByID(ctx, request)
+	switch {
+	case result.Code == http.StatusGone && result.Error == nil:
+		return nil, status.Error(codes.DataLoss, models.ErrGetDeletedLink.Error())
+	case result.Error != nil:
+		return nil, status.Error(grpcCode(result.Code), result.Error.Message)
 	}
 
 	resp.Url = *result.Response
+	resp.RedirectCode = int32(result.Code)
+	resp.Interstitial = result.Interstitial
+	resp.Title = result.Title
+	if !result.CreatedAt.IsZero() {
+		resp.CreatedAt = timestamppb.New(result.CreatedAt)
+	}
+	if result.Variant != nil {
+		variant := int32(*result.Variant)
+		resp.Variant = &variant
+		resp.Sticky = result.Sticky
+	}
 	return &resp, nil
 }
 
@@ -60,23 +104,56 @@ func (s *ServiceGrpc) GetBatch(ctx context.Context, req *pb.GetBatchRequest) (*p
 	var resp pb.GetBatchResponse
 
 	result := s.Repo.GetBatchByUserID(ctx, user.GetBatchByUserIDRequest{
-		BaseURL: s.BaseURL,
-		UserID:  req.UserId,
+		BaseURL:    s.BaseURL,
+		UserID:     req.UserId,
+		Limit:      int(req.Limit),
+		Cursor:     req.Cursor,
+		Sort:       req.Sort,
+		Search:     req.Search,
+		Tag:        req.Tag,
+		Collection: req.Collection,
+		Deleted:    req.Deleted,
 	})
 	if result.Error != nil {
 		switch result.Code {
 		case http.StatusNoContent:
 			return nil, status.Error(codes.NotFound, "there are no urls")
 		default:
-			return nil, status.Error(codes.Internal, result.Error.Message)
+			return nil, status.Error(grpcCode(result.Code), result.Error.Message)
 		}
 	}
 	for _, v := range result.Response {
-		resp.Rows = append(resp.Rows, &pb.UrlRow{
-			OriginalURL: v.OriginalURL,
-			ShortURL:    v.ShortURL,
-		})
+		row := &pb.UrlRow{
+			OriginalURL:  v.OriginalURL,
+			ShortURL:     v.ShortURL,
+			CreatedAt:    timestamppb.New(v.CreatedAt),
+			IsDeleted:    v.IsDeleted,
+			UpdatedAt:    timestamppb.New(v.UpdatedAt),
+			Title:        v.Title,
+			Note:         v.Note,
+			Tags:         v.Tags,
+			Collection:   v.Collection,
+			RedirectCode: int32(v.RedirectCode),
+			ForwardQuery: v.ForwardQuery,
+			ForwardPath:  v.ForwardPath,
+			Protected:    v.Protected,
+			MaxClicks:    int32(v.MaxClicks),
+			Clicks:       int32(v.Clicks),
+			Rules:        rulesToProto(v.Rules),
+			Variants:     variantsToProto(v.Variants),
+			Sticky:       v.Sticky,
+			Interstitial: v.Interstitial,
+			Blocked:      v.Blocked,
+		}
+		if v.DeletedAt != nil {
+			row.DeletedAt = timestamppb.New(*v.DeletedAt)
+		}
+		if v.ActiveFrom != nil {
+			row.ActiveFrom = timestamppb.New(*v.ActiveFrom)
+		}
+		resp.Rows = append(resp.Rows, row)
 	}
+	resp.NextCursor = result.NextCursor
 	return &resp, nil
 }
 
@@ -124,3 +201,174 @@ func (s *ServiceGrpc) GetStats(ctx context.Context, req *empty.Empty) (*pb.GetSt
 
 	return &resp, nil
 }
+
+func (s *ServiceGrpc) UpdateLink(ctx context.Context, req *pb.UpdateLinkRequest) (*pb.UpdateLinkResponse, error) {
+	body := user.UpdateLinkRequestBody{
+		URL:          req.Url,
+		Title:        req.Title,
+		Note:         req.Note,
+		Collection:   req.Collection,
+		ForwardQuery: req.ForwardQuery,
+		ForwardPath:  req.ForwardPath,
+		Password:     req.Password,
+		ActiveFrom:   req.ActiveFrom,
+		Sticky:       req.Sticky,
+		Interstitial: req.Interstitial,
+	}
+	if req.Tags != nil {
+		tags := req.Tags.GetTags()
+		body.Tags = &tags
+	}
+	if req.RedirectCode != nil {
+		code := int(*req.RedirectCode)
+		body.RedirectCode = &code
+	}
+	if req.Rules != nil {
+		rules := rulesFromProto(req.Rules.GetRules())
+		body.Rules = &rules
+	}
+	if req.Variants != nil {
+		variants := variantsFromProto(req.Variants.GetVariants())
+		body.Variants = &variants
+	}
+	if req.MaxClicks != nil {
+		maxClicks := int(*req.MaxClicks)
+		body.MaxClicks = &maxClicks
+	}
+
+	result := s.Repo.UpdateLink(ctx, user.UpdateLinkRequest{
+		UserID:      req.UserId,
+		ShortLinkID: req.ShortUrl,
+		BaseURL:     s.BaseURL,
+		Body:        body,
+	})
+	if result.Error != nil {
+		return nil, status.Error(grpcCode(result.Code), result.Error.Message)
+	}
+
+	resp := &pb.UpdateLinkResponse{
+		ShortUrl:     result.Response.ShortURL,
+		OriginalUrl:  result.Response.OriginalURL,
+		Title:        result.Response.Title,
+		Note:         result.Response.Note,
+		UpdatedAt:    timestamppb.New(result.Response.UpdatedAt),
+		Tags:         result.Response.Tags,
+		Collection:   result.Response.Collection,
+		RedirectCode: int32(result.Response.RedirectCode),
+		ForwardQuery: result.Response.ForwardQuery,
+		ForwardPath:  result.Response.ForwardPath,
+		Protected:    result.Response.Protected,
+		MaxClicks:    int32(result.Response.MaxClicks),
+		Clicks:       int32(result.Response.Clicks),
+		Rules:        rulesToProto(result.Response.Rules),
+		Variants:     variantsToProto(result.Response.Variants),
+		Sticky:       result.Response.Sticky,
+		Interstitial: result.Response.Interstitial,
+		Blocked:      result.Response.Blocked,
+	}
+	if result.Response.ActiveFrom != nil {
+		resp.ActiveFrom = timestamppb.New(*result.Response.ActiveFrom)
+	}
+
+	return resp, nil
+}
+
+func (s *ServiceGrpc) GetQR(ctx context.Context, req *pb.GetQRRequest) (*pb.GetQRResponse, error) {
+	request := user.GetLinkQRRequest{
+		UserID:      req.UserId,
+		ShortLinkID: req.ShortUrl,
+		BaseURL:     s.BaseURL,
+		LogoPath:    s.QRLogoPath,
+		Format:      req.Format,
+		Size:        int(req.Size),
+		Level:       req.Level,
+		Logo:        req.Logo,
+	}
+	if req.Margin != nil {
+		margin := int(*req.Margin)
+		request.Margin = &margin
+	}
+
+	result := s.Repo.GetLinkQR(ctx, request)
+	if result.Error != nil {
+		return nil, status.Error(grpcCode(result.Code), result.Error.Message)
+	}
+
+	return &pb.GetQRResponse{
+		ContentType: result.Response.ContentType,
+		Image:       result.Response.Image,
+	}, nil
+}
+
+func rulesFromProto(rules []*pb.RoutingRule) []user.RoutingRule {
+	result := make([]user.RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, user.RoutingRule{
+			Platform:    rule.GetPlatform(),
+			Language:    rule.GetLanguage(),
+			Referrer:    rule.GetReferrer(),
+			Destination: rule.GetDestination(),
+		})
+	}
+	return result
+}
+
+func rulesToProto(rules []user.RoutingRule) []*pb.RoutingRule {
+	result := make([]*pb.RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, &pb.RoutingRule{
+			Platform:    rule.Platform,
+			Language:    rule.Language,
+			Referrer:    rule.Referrer,
+			Destination: rule.Destination,
+		})
+	}
+	return result
+}
+
+func variantsFromProto(variants []*pb.Variant) []user.Variant {
+	result := make([]user.Variant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, user.Variant{
+			Destination: variant.GetDestination(),
+			Weight:      int(variant.GetWeight()),
+		})
+	}
+	return result
+}
+
+func variantsToProto(variants []user.Variant) []*pb.Variant {
+	result := make([]*pb.Variant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, &pb.Variant{
+			Destination: variant.Destination,
+			Weight:      int32(variant.Weight),
+			Clicks:      variant.Clicks,
+		})
+	}
+	return result
+}
+
+// grpcCode сопоставляет HTTP-код ответа репозитория с кодом gRPC.
+func grpcCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusGone:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
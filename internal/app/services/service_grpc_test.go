@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	pb "github.com/sonikq/url-shortener/internal/app/proto"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/stretchr/testify/require"
+)
+
+type expandRepo struct {
+	repositories.IUserRepo
+	request user.GetFullLinkByIDRequest
+}
+
+func (r *expandRepo) GetFullLinkByID(_ context.Context, request user.GetFullLinkByIDRequest) user.GetFullLinkByIDResponse {
+	r.request = request
+	location := "https://example.com/docs/guide?utm_source=x"
+	return user.GetFullLinkByIDResponse{
+		Code:     http.StatusPermanentRedirect,
+		Status:   "success",
+		Response: &location,
+	}
+}
+
+func TestServiceGrpc_Expand(t *testing.T) {
+	repo := &expandRepo{}
+	s := &ServiceGrpc{Repo: repo, DefaultRedirectCode: http.StatusTemporaryRedirect}
+
+	resp, err := s.Expand(context.Background(), &pb.ExpandRequest{
+		ShortUrl:   "abcdef",
+		PathSuffix: "/guide",
+		Query:      "utm_source=x",
+		Password:   "secret",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/docs/guide?utm_source=x", resp.Url)
+	require.Equal(t, int32(http.StatusPermanentRedirect), resp.RedirectCode)
+	require.Equal(t, user.GetFullLinkByIDRequest{
+		ShortLinkID:         "abcdef",
+		DefaultRedirectCode: http.StatusTemporaryRedirect,
+		PathSuffix:          "/guide",
+		RawQuery:            "utm_source=x",
+		Password:            "secret",
+	}, repo.request)
+}
@@ -50,7 +50,117 @@ func (s *UserService) GetStats(ctx context.Context) user.GetStatsResponse {
 	return s.repo.GetStats(ctx)
 }
 
+// LookupLink -
+func (s *UserService) LookupLink(ctx context.Context, request user.LookupLinkRequest) user.LookupLinkResponse {
+	return s.repo.LookupLink(ctx, request)
+}
+
+// UpdateLink -
+func (s *UserService) UpdateLink(ctx context.Context, request user.UpdateLinkRequest) user.UpdateLinkResponse {
+	return s.repo.UpdateLink(ctx, request)
+}
+
+// GetLinkRevisions -
+func (s *UserService) GetLinkRevisions(ctx context.Context, request user.GetLinkRevisionsRequest) user.GetLinkRevisionsResponse {
+	return s.repo.GetLinkRevisions(ctx, request)
+}
+
+// GetLinkStats -
+func (s *UserService) GetLinkStats(ctx context.Context, request user.GetLinkStatsRequest) user.GetLinkStatsResponse {
+	return s.repo.GetLinkStats(ctx, request)
+}
+
+// GetLinkQR -
+func (s *UserService) GetLinkQR(ctx context.Context, request user.GetLinkQRRequest) user.GetLinkQRResponse {
+	return s.repo.GetLinkQR(ctx, request)
+}
+
+// RestoreLinkRevision -
+func (s *UserService) RestoreLinkRevision(ctx context.Context, request user.RestoreLinkRevisionRequest) user.UpdateLinkResponse {
+	return s.repo.RestoreLinkRevision(ctx, request)
+}
+
+// RestoreLink -
+func (s *UserService) RestoreLink(ctx context.Context, request user.RestoreLinkRequest) user.UpdateLinkResponse {
+	return s.repo.RestoreLink(ctx, request)
+}
+
+// GetDeletedLinks -
+func (s *UserService) GetDeletedLinks(ctx context.Context, request user.GetDeletedLinksRequest) user.GetDeletedLinksResponse {
+	return s.repo.GetDeletedLinks(ctx, request)
+}
+
 // PingDB -
 func (s *UserService) PingDB(ctx context.Context) error {
 	return s.repo.PingDB(ctx)
 }
+
+// CreateCollection -
+func (s *UserService) CreateCollection(ctx context.Context, request user.CreateCollectionRequest) user.CreateCollectionResponse {
+	return s.repo.CreateCollection(ctx, request)
+}
+
+// GetCollections -
+func (s *UserService) GetCollections(ctx context.Context, request user.GetCollectionsRequest) user.GetCollectionsResponse {
+	return s.repo.GetCollections(ctx, request)
+}
+
+// DeleteCollection -
+func (s *UserService) DeleteCollection(ctx context.Context, request user.DeleteCollectionRequest) user.DeleteCollectionResponse {
+	return s.repo.DeleteCollection(ctx, request)
+}
+
+// ExportCollection -
+func (s *UserService) ExportCollection(ctx context.Context, request user.ExportCollectionRequest) user.ExportCollectionResponse {
+	return s.repo.ExportCollection(ctx, request)
+}
+
+// DeleteBatchLinks -
+func (s *UserService) DeleteBatchLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteBatchLinksResponse {
+	return s.repo.DeleteBatchLinks(ctx, request)
+}
+
+// EnqueueDeleteLinks -
+func (s *UserService) EnqueueDeleteLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteJobResponse {
+	return s.repo.EnqueueDeleteLinks(ctx, request)
+}
+
+// GetDeleteJob -
+func (s *UserService) GetDeleteJob(ctx context.Context, request user.GetDeleteJobRequest) user.DeleteJobResponse {
+	return s.repo.GetDeleteJob(ctx, request)
+}
+
+// ImportLinks -
+func (s *UserService) ImportLinks(ctx context.Context, request user.ImportLinksRequest) user.ImportLinksResponse {
+	return s.repo.ImportLinks(ctx, request)
+}
+
+// ExportLinks -
+func (s *UserService) ExportLinks(ctx context.Context, request user.ExportLinksRequest) user.ExportLinksResponse {
+	return s.repo.ExportLinks(ctx, request)
+}
+
+// CreateWebhook -
+func (s *UserService) CreateWebhook(ctx context.Context, request user.CreateWebhookRequest) user.CreateWebhookResponse {
+	return s.repo.CreateWebhook(ctx, request)
+}
+
+// GetWebhooks -
+func (s *UserService) GetWebhooks(ctx context.Context, request user.GetWebhooksRequest) user.GetWebhooksResponse {
+	return s.repo.GetWebhooks(ctx, request)
+}
+
+// DeleteWebhook -
+func (s *UserService) DeleteWebhook(ctx context.Context, request user.DeleteWebhookRequest) user.DeleteWebhookResponse {
+	return s.repo.DeleteWebhook(ctx, request)
+}
+
+// GetWebhookDeliveries -
+func (s *UserService) GetWebhookDeliveries(ctx context.Context, request user.GetWebhookDeliveriesRequest) user.GetWebhookDeliveriesResponse {
+	return s.repo.GetWebhookDeliveries(ctx, request)
+}
+
+// RetryWebhookDelivery -
+func (s *UserService) RetryWebhookDelivery(ctx context.Context, request user.RetryWebhookDeliveryRequest) user.RetryWebhookDeliveryResponse {
+	return s.repo.RetryWebhookDelivery(ctx, request)
+}
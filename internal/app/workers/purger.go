@@ -0,0 +1,44 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Purger - окончательно удаляет ссылки, помеченные удаленными дольше retention.
+// Запускается планировщиком (см. Scheduler).
+type Purger struct {
+	store     *storage.Storage
+	retention time.Duration
+}
+
+// NewPurger -
+func NewPurger(store *storage.Storage, retention time.Duration) *Purger {
+	return &Purger{
+		store:     store,
+		retention: retention,
+	}
+}
+
+// Purge - однократная очистка, возвращает количество удаленных ссылок
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	purged, err := p.store.PurgeDeleted(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if p.store.File != nil && len(purged) > 0 {
+		if err = p.store.File.RemoveFromFile(purged); err != nil {
+			return 0, err
+		}
+	}
+
+	// Вместе со ссылками вычищаются давно завершенные задания на удаление.
+	if _, err = p.store.PurgeDeleteJobs(ctx, time.Now().Add(-deleteJobRetention)); err != nil {
+		return 0, err
+	}
+
+	return len(purged), nil
+}
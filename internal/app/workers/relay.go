@@ -0,0 +1,102 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/pkg/events"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Параметры публикации outbox.
+const (
+	relayBatchSize = 100
+	// relayLease - на сколько откладывается взятое событие: если экземпляр упадет до
+	// подтверждения, событие опубликует другой
+	relayLease = time.Minute
+)
+
+// Relay - публикует события из outbox во все приемники и удаляет опубликованные.
+// Событие, которое не принял хотя бы один приемник, остается в outbox и публикуется
+// повторно во все приемники после истечения аренды.
+type Relay struct {
+	store    *storage.Storage
+	sinks    []events.Sink
+	log      logger.Logger
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewRelay -
+func NewRelay(store *storage.Storage, log logger.Logger, interval time.Duration, sinks ...events.Sink) *Relay {
+	return &Relay{
+		store:    store,
+		sinks:    sinks,
+		log:      log,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run - запускает публикацию по таймеру до отмены ctx
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Relay(ctx); err != nil {
+				r.log.Error("failed to relay outbox", logger.Error(err))
+			}
+		}
+	}
+}
+
+// Relay - однократная публикация накопленных событий, возвращает количество опубликованных.
+// Публикация пачки останавливается на первом непринятом событии, чтобы не нарушать порядок.
+func (r *Relay) Relay(ctx context.Context) (int, error) {
+	var published int
+	for {
+		batch, err := r.store.ClaimOutbox(ctx, r.now(), relayLease, relayBatchSize)
+		if err != nil || len(batch) == 0 {
+			return published, err
+		}
+
+		acked := make([]int64, 0, len(batch))
+		var publishErr error
+		for _, event := range batch {
+			if publishErr = r.publish(ctx, event); publishErr != nil {
+				break
+			}
+			acked = append(acked, event.ID)
+		}
+
+		if len(acked) > 0 {
+			if err = r.store.AckOutbox(ctx, acked); err != nil {
+				return published, err
+			}
+			published += len(acked)
+		}
+		if publishErr != nil {
+			return published, publishErr
+		}
+		if len(batch) < relayBatchSize {
+			return published, nil
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, event storage.OutboxEvent) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			r.log.Warn("failed to publish event", logger.String("event", event.EventID),
+				logger.String("type", event.Type), logger.Error(err))
+			return err
+		}
+	}
+	return nil
+}
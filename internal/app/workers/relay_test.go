@@ -0,0 +1,66 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/pkg/events"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelay_Relay(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, map[string]storage.Item{
+		"aaaaaa": {Object: "https://example.com/a", UserID: "user"},
+		"bbbbbb": {Object: "https://example.com/b", UserID: "user"},
+	}))
+	_, err = store.DeleteBatch(ctx, []string{"aaaaaa"}, "user")
+	require.NoError(t, err)
+
+	var received []storage.OutboxEvent
+	failing := true
+	bus := events.NewBus()
+	bus.Subscribe(func(_ context.Context, event storage.OutboxEvent) error {
+		if failing && event.Type == storage.EventLinkDeleted {
+			return errors.New("sink is down")
+		}
+		received = append(received, event)
+		return nil
+	})
+
+	now := time.Now()
+	relay := NewRelay(store, logger.New("info", "test_relay"), 0, bus)
+	relay.now = func() time.Time { return now }
+
+	// Пачка останавливается на непринятом событии, принятые подтверждаются.
+	published, err := relay.Relay(ctx)
+	require.Error(t, err)
+	require.Equal(t, 2, published)
+	require.Len(t, received, 2)
+	require.Equal(t, "aaaaaa", received[0].Link.Alias)
+	require.Equal(t, "bbbbbb", received[1].Link.Alias)
+
+	// Непринятое событие ждет окончания аренды.
+	failing = false
+	published, err = relay.Relay(ctx)
+	require.NoError(t, err)
+	require.Zero(t, published)
+
+	now = now.Add(relayLease)
+	published, err = relay.Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Equal(t, storage.EventLinkDeleted, received[2].Type)
+	require.Equal(t, "https://example.com/a", received[2].Link.OriginalURL)
+
+	published, err = relay.Relay(ctx)
+	require.NoError(t, err)
+	require.Zero(t, published)
+}
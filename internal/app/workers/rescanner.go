@@ -0,0 +1,86 @@
+package workers
+
+import (
+	"context"
+
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlcheck"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// rescanBatchSize - сколько ссылок читается из хранилища за один запрос
+const rescanBatchSize = 500
+
+// Rescanner - перепроверяет адреса существующих ссылок: ставшие вредоносными блокируются,
+// а заблокированные, которые проверка больше не находит, снова открываются.
+// Запускается планировщиком (см. Scheduler).
+type Rescanner struct {
+	store   *storage.Storage
+	checker urlcheck.URLChecker
+	log     logger.Logger
+}
+
+// NewRescanner -
+func NewRescanner(store *storage.Storage, checker urlcheck.URLChecker, log logger.Logger) *Rescanner {
+	return &Rescanner{
+		store:   store,
+		checker: checker,
+		log:     log,
+	}
+}
+
+// Rescan - однократная перепроверка всех неудаленных ссылок, возвращает количество
+// ссылок, у которых изменилась блокировка. Ошибка проверки прерывает проход, чтобы
+// недоступность проверки не снимала блокировки.
+func (s *Rescanner) Rescan(ctx context.Context) (int, error) {
+	var changed int
+	for after := ""; ; {
+		batch, err := s.store.ScanLinks(ctx, after, rescanBatchSize)
+		if err != nil || len(batch) == 0 {
+			return changed, err
+		}
+
+		for _, record := range batch {
+			reason, err := s.verdict(ctx, record.Item)
+			if err != nil {
+				return changed, err
+			}
+			if reason == record.BlockedReason {
+				continue
+			}
+
+			item, err := s.store.SetBlocked(ctx, record.Alias, reason)
+			if err != nil {
+				return changed, err
+			}
+			if s.store.File != nil {
+				if err = s.store.File.SaveToFile(map[string]storage.Item{record.Alias: item}); err != nil {
+					return changed, err
+				}
+			}
+
+			if reason != "" {
+				s.log.Warn("link blocked as malicious", logger.String("alias", record.Alias), logger.String("reason", reason))
+			} else {
+				s.log.Info("link unblocked", logger.String("alias", record.Alias))
+			}
+			changed++
+		}
+
+		after = batch[len(batch)-1].Alias
+	}
+}
+
+// verdict - причина блокировки по первому вредоносному адресу ссылки, пустая - ссылка чистая.
+func (s *Rescanner) verdict(ctx context.Context, item storage.Item) (string, error) {
+	for _, destination := range item.Destinations() {
+		verdict, err := s.checker.Check(ctx, destination)
+		if err != nil {
+			return "", err
+		}
+		if verdict.Malicious {
+			return verdict.Reason, nil
+		}
+	}
+	return "", nil
+}
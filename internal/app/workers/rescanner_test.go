@@ -0,0 +1,74 @@
+package workers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/urlcheck"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// listChecker признает вредоносными адреса, содержащие одну из строк списка.
+type listChecker struct {
+	blocked []string
+}
+
+func (c *listChecker) Check(_ context.Context, rawURL string) (urlcheck.Verdict, error) {
+	for _, blocked := range c.blocked {
+		if strings.Contains(rawURL, blocked) {
+			return urlcheck.Verdict{Malicious: true, Reason: "list: " + blocked}, nil
+		}
+	}
+	return urlcheck.Verdict{}, nil
+}
+
+func TestRescanner_Rescan(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, map[string]storage.Item{
+		"clean":  {Object: "https://example.com", UserID: "user"},
+		"direct": {Object: "https://phishing.example", UserID: "user"},
+		"rule": {Object: "https://example.org", UserID: "user", Rules: []storage.Rule{
+			{Platform: "ios", Destination: "https://malware.example/app"},
+		}},
+		"deleted": {Object: "https://phishing.example/old", UserID: "user", IsDeleted: true},
+	}))
+
+	checker := &listChecker{blocked: []string{"phishing.example", "malware.example"}}
+	rescanner := NewRescanner(store, checker, logger.New("info", "test_rescanner"))
+
+	changed, err := rescanner.Rescan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, changed)
+
+	blocked := func(alias string) string {
+		records, err := store.ScanLinks(ctx, "", 10)
+		require.NoError(t, err)
+		for _, record := range records {
+			if record.Alias == alias {
+				return record.BlockedReason
+			}
+		}
+		t.Fatalf("link %s not found", alias)
+		return ""
+	}
+	require.Equal(t, "", blocked("clean"))
+	require.Equal(t, "list: phishing.example", blocked("direct"))
+	require.Equal(t, "list: malware.example", blocked("rule"))
+
+	// Повторный проход ничего не меняет, а удаление из списка снимает блокировку.
+	changed, err = rescanner.Rescan(ctx)
+	require.NoError(t, err)
+	require.Zero(t, changed)
+
+	checker.blocked = []string{"phishing.example"}
+	changed, err = rescanner.Rescan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, changed)
+	require.Equal(t, "", blocked("rule"))
+}
@@ -0,0 +1,316 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/cron"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Способы запуска фоновой задачи.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Параметры планировщика.
+const (
+	schedulerTick = time.Second
+	// jobLockPrefix - префикс блокировки задачи в хранилище, общей для всех экземпляров сервиса.
+	jobLockPrefix = "scheduler:"
+)
+
+// ErrSchedulerStopped - задачу нельзя запустить вручную, пока планировщик не работает
+var ErrSchedulerStopped = errors.New("scheduler is not running")
+
+// JobFunc - однократное выполнение фоновой задачи, возвращает количество обработанных объектов
+type JobFunc func(ctx context.Context) (int, error)
+
+// scheduledJob - зарегистрированная задача и ее состояние в этом экземпляре сервиса.
+type scheduledJob struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	timeout  time.Duration
+	run      JobFunc
+
+	running bool
+	next    time.Time
+}
+
+// Scheduler - запускает фоновые задачи по расписанию и вручную. Задача выполняется не дольше
+// своего таймаута и одновременно только в одном экземпляре сервиса: перед запуском берется
+// блокировка в хранилище, у Postgres - advisory lock. Запуски записываются в историю.
+type Scheduler struct {
+	store *storage.Storage
+	log   logger.Logger
+	now   func() time.Time
+
+	mu   sync.Mutex
+	jobs []*scheduledJob
+	ctx  context.Context // контекст Run, nil - планировщик не запущен
+	wg   sync.WaitGroup
+}
+
+// NewScheduler -
+func NewScheduler(store *storage.Storage, log logger.Logger) *Scheduler {
+	return &Scheduler{
+		store: store,
+		log:   log,
+		now:   time.Now,
+	}
+}
+
+// Register - добавляет задачу с расписанием в формате cron (см. пакет cron)
+func (s *Scheduler) Register(name, spec string, timeout time.Duration, run JobFunc) error {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("job %s: timeout must be positive", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.find(name) != nil {
+		return fmt.Errorf("job %s is already registered", name)
+	}
+
+	job := &scheduledJob{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		timeout:  timeout,
+		run:      run,
+	}
+	if s.ctx != nil {
+		job.next = schedule.Next(s.now())
+	}
+	s.jobs = append(s.jobs, job)
+
+	return nil
+}
+
+// Run - запускает задачи по расписанию до отмены ctx, затем ждет завершения начатых запусков
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	now := s.now()
+	for _, job := range s.jobs {
+		job.next = job.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.ctx = nil
+			s.mu.Unlock()
+
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+			s.dispatch()
+		}
+	}
+}
+
+// dispatch запускает задачи, время которых наступило.
+func (s *Scheduler) dispatch() {
+	s.mu.Lock()
+	now := s.now()
+	var due []*scheduledJob
+	for _, job := range s.jobs {
+		if !job.next.IsZero() && !now.Before(job.next) {
+			job.next = job.schedule.Next(now)
+			due = append(due, job)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, job := range due {
+		err := s.start(job, TriggerSchedule)
+		switch {
+		case errors.Is(err, models.ErrJobRunning):
+			s.log.Debug("scheduled job skipped", logger.String("job", job.name), logger.Error(err))
+		case err != nil:
+			s.log.Error("failed to start scheduled job", logger.String("job", job.name), logger.Error(err))
+		}
+	}
+}
+
+// Trigger - внеочередной запуск задачи, не дожидается его завершения
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	job := s.find(name)
+	s.mu.Unlock()
+
+	if job == nil {
+		return models.ErrJobNotFound
+	}
+
+	return s.start(job, TriggerManual)
+}
+
+// start занимает задачу в этом экземпляре и в хранилище и запускает ее в отдельной горутине.
+func (s *Scheduler) start(job *scheduledJob, trigger string) error {
+	// Проверка s.ctx и wg.Add под одной блокировкой: после остановки Run новых запусков нет.
+	s.mu.Lock()
+	ctx := s.ctx
+	if ctx == nil {
+		s.mu.Unlock()
+		return ErrSchedulerStopped
+	}
+	if job.running {
+		s.mu.Unlock()
+		return models.ErrJobRunning
+	}
+	job.running = true
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		job.running = false
+		s.mu.Unlock()
+		s.wg.Done()
+	}
+
+	unlock, acquired, err := s.store.TryLock(ctx, jobLockPrefix+job.name)
+	if err != nil || !acquired {
+		release()
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w on another instance", models.ErrJobRunning)
+	}
+
+	go func() {
+		defer release()
+		defer unlock()
+
+		s.execute(ctx, job, trigger)
+	}()
+
+	return nil
+}
+
+// execute выполняет задачу с ее таймаутом и записывает запуск в историю.
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob, trigger string) {
+	runCtx, cancel := context.WithTimeout(ctx, job.timeout)
+	defer cancel()
+
+	run := storage.JobRun{
+		Job:       job.name,
+		Trigger:   trigger,
+		Status:    storage.JobRunSucceeded,
+		StartedAt: s.now(),
+	}
+
+	processed, err := job.run(runCtx)
+	run.FinishedAt = s.now()
+	run.Processed = processed
+	if err != nil {
+		run.Status = storage.JobRunFailed
+		run.Error = err.Error()
+		s.log.Error("scheduled job failed", logger.String("job", job.name), logger.Error(err))
+	} else if processed > 0 {
+		s.log.Info("scheduled job finished", logger.String("job", job.name), logger.Int("processed", processed))
+	}
+
+	// Запуск, прерванный остановкой сервиса, тоже попадает в историю.
+	if err = s.store.AddJobRun(context.WithoutCancel(ctx), run); err != nil {
+		s.log.Error("failed to save job run", logger.String("job", job.name), logger.Error(err))
+	}
+}
+
+// Jobs - зарегистрированные задачи в порядке регистрации с последним запуском каждой
+func (s *Scheduler) Jobs(ctx context.Context) ([]user.ScheduledJob, error) {
+	s.mu.Lock()
+	jobs := make([]user.ScheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		info := user.ScheduledJob{
+			Name:     job.name,
+			Schedule: job.spec,
+			Timeout:  job.timeout.String(),
+			Running:  job.running,
+		}
+		if !job.next.IsZero() {
+			next := job.next
+			info.NextRun = &next
+		}
+		jobs = append(jobs, info)
+	}
+	s.mu.Unlock()
+
+	for i := range jobs {
+		runs, err := s.store.GetJobRuns(ctx, jobs[i].Name, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) > 0 {
+			jobs[i].LastRun = jobRunResponse(runs[0])
+		}
+	}
+
+	return jobs, nil
+}
+
+// Runs - история запусков задачи от новых к старым
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]user.JobRun, error) {
+	s.mu.Lock()
+	job := s.find(name)
+	s.mu.Unlock()
+
+	if job == nil {
+		return nil, models.ErrJobNotFound
+	}
+
+	runs, err := s.store.GetJobRuns(ctx, name, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]user.JobRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, *jobRunResponse(run))
+	}
+	return result, nil
+}
+
+// find ищет задачу по имени; вызывается под s.mu.
+func (s *Scheduler) find(name string) *scheduledJob {
+	for _, job := range s.jobs {
+		if job.name == name {
+			return job
+		}
+	}
+	return nil
+}
+
+// jobRunResponse - запуск в виде, в котором его отдает API.
+func jobRunResponse(run storage.JobRun) *user.JobRun {
+	return &user.JobRun{
+		ID:         run.ID,
+		Trigger:    run.Trigger,
+		Status:     run.Status,
+		Processed:  run.Processed,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Duration:   run.FinishedAt.Sub(run.StartedAt).String(),
+	}
+}
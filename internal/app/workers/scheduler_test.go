@@ -0,0 +1,123 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduler(t *testing.T) (*Scheduler, *storage.Storage, context.CancelFunc) {
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	scheduler := NewScheduler(store, logger.New("info", "test_scheduler"))
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.ctx = ctx
+	return scheduler, store, cancel
+}
+
+func TestScheduler_Dispatch(t *testing.T) {
+	scheduler, store, cancel := newTestScheduler(t)
+	defer cancel()
+
+	now := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.UTC)
+	scheduler.now = func() time.Time { return now }
+
+	var calls int
+	require.NoError(t, scheduler.Register("hourly", "@hourly", time.Minute, func(context.Context) (int, error) {
+		calls++
+		return 3, nil
+	}))
+	require.Error(t, scheduler.Register("hourly", "@hourly", time.Minute, nil))
+	require.Error(t, scheduler.Register("broken", "61 * * * *", time.Minute, nil))
+
+	jobs, err := scheduler.Jobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, time.Date(2024, time.March, 15, 11, 0, 0, 0, time.UTC), *jobs[0].NextRun)
+
+	// До времени запуска задача не выполняется.
+	scheduler.dispatch()
+	scheduler.wg.Wait()
+	require.Zero(t, calls)
+
+	now = time.Date(2024, time.March, 15, 11, 0, 1, 0, time.UTC)
+	scheduler.dispatch()
+	scheduler.wg.Wait()
+	require.Equal(t, 1, calls)
+
+	runs, err := store.GetJobRuns(context.Background(), "hourly", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, TriggerSchedule, runs[0].Trigger)
+	require.Equal(t, storage.JobRunSucceeded, runs[0].Status)
+	require.Equal(t, 3, runs[0].Processed)
+
+	jobs, err = scheduler.Jobs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC), *jobs[0].NextRun)
+	require.Equal(t, storage.JobRunSucceeded, jobs[0].LastRun.Status)
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	scheduler, store, cancel := newTestScheduler(t)
+	defer cancel()
+
+	started, finish := make(chan struct{}), make(chan struct{})
+	require.NoError(t, scheduler.Register("slow", "@daily", time.Minute, func(context.Context) (int, error) {
+		started <- struct{}{}
+		<-finish
+		return 0, errors.New("partial failure")
+	}))
+
+	require.ErrorIs(t, scheduler.Trigger("missing"), models.ErrJobNotFound)
+
+	require.NoError(t, scheduler.Trigger("slow"))
+	<-started
+	require.ErrorIs(t, scheduler.Trigger("slow"), models.ErrJobRunning)
+	close(finish)
+	scheduler.wg.Wait()
+
+	runs, err := scheduler.Runs(context.Background(), "slow", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, TriggerManual, runs[0].Trigger)
+	require.Equal(t, storage.JobRunFailed, runs[0].Status)
+	require.Equal(t, "partial failure", runs[0].Error)
+
+	// Задачу, которую выполняет другой экземпляр, повторно не запустить.
+	unlock, acquired, err := store.TryLock(context.Background(), jobLockPrefix+"slow")
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.ErrorIs(t, scheduler.Trigger("slow"), models.ErrJobRunning)
+	unlock()
+
+	cancel()
+	scheduler.ctx = nil
+	require.ErrorIs(t, scheduler.Trigger("slow"), ErrSchedulerStopped)
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	scheduler, store, cancel := newTestScheduler(t)
+	defer cancel()
+
+	require.NoError(t, scheduler.Register("stuck", "@daily", 10*time.Millisecond, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}))
+
+	require.NoError(t, scheduler.Trigger("stuck"))
+	scheduler.wg.Wait()
+
+	runs, err := store.GetJobRuns(context.Background(), "stuck", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, storage.JobRunFailed, runs[0].Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), runs[0].Error)
+}
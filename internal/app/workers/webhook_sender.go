@@ -0,0 +1,173 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/webhook"
+	"github.com/sonikq/url-shortener/pkg/storage"
+)
+
+// Параметры доставки событий подписчикам.
+const (
+	webhookBatchSize   = 50
+	webhookConcurrency = 10
+	webhookTimeout     = 10 * time.Second
+	// webhookLease - на сколько откладывается взятая доставка: если экземпляр упадет до записи
+	// результата, доставку повторит другой. Должен покрывать отправку всей пачки.
+	webhookLease = 2 * time.Minute
+
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 10 * time.Second
+	webhookMaxBackoff  = time.Hour
+
+	webhookMaxErrorLength = 512
+)
+
+// WebhookSender - доставляет события подписчикам. Неудачная попытка повторяется с экспоненциально
+// растущей паузой; после webhookMaxAttempts попыток доставка попадает в список недоставленных.
+type WebhookSender struct {
+	store    *storage.Storage
+	log      logger.Logger
+	interval time.Duration
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewWebhookSender -
+func NewWebhookSender(store *storage.Storage, log logger.Logger, interval time.Duration) *WebhookSender {
+	return &WebhookSender{
+		store:    store,
+		log:      log,
+		interval: interval,
+		client:   webhook.NewClient(webhookTimeout),
+		now:      time.Now,
+	}
+}
+
+// Run - запускает отправку по таймеру до отмены ctx
+func (s *WebhookSender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Send(ctx); err != nil {
+				s.log.Error("failed to send webhooks", logger.Error(err))
+			}
+		}
+	}
+}
+
+// Send - однократная отправка всех доставок, время которых наступило, возвращает количество попыток
+func (s *WebhookSender) Send(ctx context.Context) (int, error) {
+	var attempted int
+	for {
+		deliveries, err := s.store.ClaimDeliveries(ctx, s.now(), webhookLease, webhookBatchSize)
+		if err != nil || len(deliveries) == 0 {
+			return attempted, err
+		}
+
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			first error
+		)
+		sem := make(chan struct{}, webhookConcurrency)
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(delivery storage.Delivery) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				if err := s.store.UpdateDelivery(ctx, s.attempt(ctx, delivery)); err != nil {
+					mu.Lock()
+					if first == nil {
+						first = err
+					}
+					mu.Unlock()
+				}
+			}(delivery)
+		}
+		wg.Wait()
+
+		attempted += len(deliveries)
+		if first != nil {
+			return attempted, first
+		}
+		if len(deliveries) < webhookBatchSize {
+			return attempted, nil
+		}
+	}
+}
+
+// attempt отправляет доставку и возвращает ее с результатом попытки.
+func (s *WebhookSender) attempt(ctx context.Context, delivery storage.Delivery) storage.Delivery {
+	delivery.Attempts++
+	delivery.ResponseCode, delivery.LastError = 0, ""
+
+	code, err := s.post(ctx, delivery)
+	delivery.ResponseCode = code
+	if err == nil {
+		delivery.Status = storage.DeliveryDelivered
+		return delivery
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > webhookMaxErrorLength {
+		delivery.LastError = delivery.LastError[:webhookMaxErrorLength]
+	}
+
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = storage.DeliveryDead
+		s.log.Warn("webhook delivery moved to dead letters",
+			logger.Any("delivery", delivery.ID), logger.String("webhook", delivery.WebhookID), logger.Error(err))
+		return delivery
+	}
+
+	delivery.Status = storage.DeliveryPending
+	delivery.NextAttemptAt = s.now().Add(webhookBackoff(delivery.Attempts))
+	return delivery
+}
+
+// post отправляет подписанное событие, успехом считается любой ответ 2xx.
+func (s *WebhookSender) post(ctx context.Context, delivery storage.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.EventHeader, delivery.Event)
+	req.Header.Set(webhook.DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.Secret, s.now(), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff - пауза перед следующей попыткой после attempts неудачных: 10s, 20s, 40s, ... до часа.
+func webhookBackoff(attempts int) time.Duration {
+	return backoff(attempts, webhookBaseBackoff, webhookMaxBackoff)
+}
@@ -0,0 +1,151 @@
+package workers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/pkg/webhook"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSender_Send(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte(`{"type":"link.created"}`)
+
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify("secret", r.Header.Get(webhook.SignatureHeader), body, 0, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(webhook.EventHeader) != "link.created" || failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateWebhook(ctx, storage.Webhook{
+		ID: "hook", UserID: "user", URL: server.URL, Secret: "secret", Events: []string{"link.created"},
+	}))
+
+	now := time.Now()
+	sender := NewWebhookSender(store, logger.New("info", "test_webhook_sender"), 0)
+	sender.now = func() time.Time { return now }
+	// Тестовый подписчик слушает loopback, куда обычный клиент доставок не подключается.
+	sender.client = &http.Client{Timeout: webhookTimeout}
+
+	deliveries := func() []storage.Delivery {
+		list, err := store.GetDeliveries(ctx, "user", "hook", "", 10)
+		require.NoError(t, err)
+		return list
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		require.NoError(t, store.AddDeliveries(ctx, []storage.Delivery{
+			{WebhookID: "hook", UserID: "user", Event: "link.created", Payload: payload, NextAttemptAt: now},
+		}))
+
+		sent, err := sender.Send(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, sent)
+
+		delivery := deliveries()[0]
+		require.Equal(t, storage.DeliveryDelivered, delivery.Status)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, http.StatusNoContent, delivery.ResponseCode)
+	})
+
+	t.Run("backoff and dead letter", func(t *testing.T) {
+		failing.Store(true)
+		require.NoError(t, store.AddDeliveries(ctx, []storage.Delivery{
+			{WebhookID: "hook", UserID: "user", Event: "link.created", Payload: payload, NextAttemptAt: now},
+		}))
+
+		for attempt := 1; attempt < webhookMaxAttempts; attempt++ {
+			sent, err := sender.Send(ctx)
+			require.NoError(t, err)
+			require.Equal(t, 1, sent)
+
+			delivery := deliveries()[0]
+			require.Equal(t, storage.DeliveryPending, delivery.Status)
+			require.Equal(t, attempt, delivery.Attempts)
+			require.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+			require.Equal(t, now.Add(webhookBackoff(attempt)), delivery.NextAttemptAt)
+
+			// До следующей попытки доставка не берется.
+			sent, err = sender.Send(ctx)
+			require.NoError(t, err)
+			require.Zero(t, sent)
+
+			now = delivery.NextAttemptAt
+		}
+
+		_, err := sender.Send(ctx)
+		require.NoError(t, err)
+
+		dead, err := store.GetDeliveries(ctx, "user", "hook", storage.DeliveryDead, 10)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		require.Equal(t, webhookMaxAttempts, dead[0].Attempts)
+		require.Equal(t, "unexpected status 500", dead[0].LastError)
+
+		failing.Store(false)
+		_, err = store.RetryDelivery(ctx, "user", "hook", dead[0].ID)
+		require.NoError(t, err)
+		sender.now = func() time.Time { return time.Now().Add(time.Second) }
+
+		sent, err := sender.Send(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, sent)
+		require.Equal(t, storage.DeliveryDelivered, deliveries()[0].Status)
+	})
+}
+
+func TestWebhookSender_ForbiddenHost(t *testing.T) {
+	ctx := context.Background()
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateWebhook(ctx, storage.Webhook{
+		ID: "hook", UserID: "user", URL: server.URL, Secret: "secret", Events: []string{"link.created"},
+	}))
+	require.NoError(t, store.AddDeliveries(ctx, []storage.Delivery{
+		{WebhookID: "hook", UserID: "user", Event: "link.created", Payload: []byte(`{}`), NextAttemptAt: time.Now()},
+	}))
+
+	sent, err := NewWebhookSender(store, logger.New("info", "test_webhook_sender"), 0).Send(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	require.Zero(t, hits.Load())
+
+	deliveries, err := store.GetDeliveries(ctx, "user", "hook", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, storage.DeliveryPending, deliveries[0].Status)
+	require.Zero(t, deliveries[0].ResponseCode)
+	require.Contains(t, deliveries[0].LastError, webhook.ErrForbiddenHost.Error())
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	require.Equal(t, 10*time.Second, webhookBackoff(1))
+	require.Equal(t, 20*time.Second, webhookBackoff(2))
+	require.Equal(t, 80*time.Second, webhookBackoff(4))
+	require.Equal(t, time.Hour, webhookBackoff(20))
+}
@@ -2,50 +2,188 @@ package workers
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
 
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
 	"github.com/sonikq/url-shortener/pkg/storage"
 )
 
-// Worker -
-type Worker struct {
-	pool  chan Pool
-	store *storage.Storage
-}
+// Параметры очереди удаления ссылок.
+const (
+	// deleteBatchSize - сколько заданий обработчик берет за раз; задания одного пользователя
+	// из пачки удаляются одним запросом к хранилищу.
+	deleteBatchSize = 100
+	// deleteLease - на сколько откладывается взятое задание: если обработчик упадет до записи
+	// результата, задание выполнит другой.
+	deleteLease = time.Minute
+
+	deleteMaxAttempts = 5
+	deleteBaseBackoff = time.Second
+	deleteMaxBackoff  = time.Minute
+
+	// deleteJobRetention - сколько хранится завершенное задание, чтобы можно было узнать его статус.
+	deleteJobRetention = 24 * time.Hour
+)
 
-// Pool -
-type Pool struct {
-	urls   []string
-	err    chan error
-	userID string
+// Worker - очередь удаления ссылок. Задания лежат в хранилище, поэтому невыполненные
+// не теряются при остановке сервиса; неудачная попытка повторяется с растущей паузой.
+type Worker struct {
+	store    *storage.Storage
+	repo     repositories.IUserRepo
+	log      logger.Logger
+	workers  int
+	interval time.Duration
+	wake     chan struct{}
+	now      func() time.Time
 }
 
 // NewWorker -
-func NewWorker(urlsChan chan Pool, store *storage.Storage) *Worker {
+func NewWorker(store *storage.Storage, repo repositories.IUserRepo, log logger.Logger, workers int,
+	interval time.Duration) *Worker {
 	return &Worker{
-		pool:  urlsChan,
-		store: store,
+		store:    store,
+		repo:     repo,
+		log:      log,
+		workers:  workers,
+		interval: interval,
+		wake:     make(chan struct{}, 1),
+		now:      time.Now,
+	}
+}
+
+// Notify - будит обработчиков после постановки задания, не дожидаясь таймера.
+// У nil очереди ничего не делает: задание подберет обработчик при запуске.
+func (w *Worker) Notify() {
+	if w == nil {
+		return
+	}
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run - запускает обработчиков и после отмены ctx ждет, пока они допишут результат начатых заданий
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop обрабатывает задания по таймеру и по Notify до отмены ctx.
+func (w *Worker) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.wake:
+		case <-ticker.C:
+		}
+
+		// Начатая пачка не прерывается остановкой сервиса.
+		if _, err := w.Process(context.WithoutCancel(ctx)); err != nil {
+			w.log.Error("failed to process delete jobs", logger.Error(err))
+		}
 	}
 }
 
-// DeleteURLs -
-func (w *Worker) DeleteURLs(urls []string, userID string) error {
-	errChan := make(chan error)
+// Process - однократная обработка всех заданий, время которых наступило, возвращает количество заданий
+func (w *Worker) Process(ctx context.Context) (int, error) {
+	var processed int
+	for {
+		jobs, err := w.store.ClaimDeleteJobs(ctx, w.now(), deleteLease, deleteBatchSize)
+		if err != nil || len(jobs) == 0 {
+			return processed, err
+		}
+
+		if err = w.store.UpdateDeleteJobs(ctx, w.execute(ctx, jobs)); err != nil {
+			return processed, err
+		}
 
-	// Создаем Pool и отправляем его в канал
-	w.pool <- Pool{
-		urls:   urls,
-		err:    errChan,
-		userID: userID,
+		processed += len(jobs)
+		if len(jobs) < deleteBatchSize {
+			return processed, nil
+		}
 	}
-	err := <-errChan
-	close(errChan)
-	return err
 }
 
-// Run -
-func (w *Worker) Run() {
-	for p := range w.pool {
-		err := w.store.DeleteBatch(context.Background(), p.urls, p.userID)
-		p.err <- err
+// execute удаляет ссылки заданий и возвращает задания с результатом попытки. Задания одного
+// пользователя объединяются в один вызов DeleteBatchLinks.
+func (w *Worker) execute(ctx context.Context, jobs []storage.DeleteJob) []storage.DeleteJob {
+	var users []string
+	byUser := make(map[string][]int)
+	for i, job := range jobs {
+		if _, found := byUser[job.UserID]; !found {
+			users = append(users, job.UserID)
+		}
+		byUser[job.UserID] = append(byUser[job.UserID], i)
+	}
+
+	for _, userID := range users {
+		body := make([]user.DeleteBatchBody, 0, len(byUser[userID]))
+		for _, i := range byUser[userID] {
+			body = append(body, user.DeleteBatchBody{URLS: jobs[i].URLs})
+		}
+
+		var err error
+		result := w.repo.DeleteBatchLinks(ctx, user.DeleteBatchLinksRequest{UserID: userID, Body: body})
+		if result.Code != http.StatusAccepted {
+			err = errors.New(result.Error.Message)
+		}
+
+		for _, i := range byUser[userID] {
+			jobs[i] = w.finish(jobs[i], err)
+		}
+	}
+
+	return jobs
+}
+
+// finish записывает в задание результат попытки.
+func (w *Worker) finish(job storage.DeleteJob, err error) storage.DeleteJob {
+	job.Attempts++
+	if err == nil {
+		job.Status = storage.JobDone
+		job.LastError = ""
+		return job
+	}
+
+	job.LastError = err.Error()
+	if job.Attempts >= deleteMaxAttempts {
+		job.Status = storage.JobFailed
+		w.log.Warn("delete job failed", logger.String("job", job.ID), logger.String("user", job.UserID),
+			logger.Error(err))
+		return job
+	}
+
+	job.Status = storage.JobPending
+	job.NextAttemptAt = w.now().Add(backoff(job.Attempts, deleteBaseBackoff, deleteMaxBackoff))
+	return job
+}
+
+// backoff - пауза перед следующей попыткой после attempts неудачных: base, удваиваясь, но не больше limit.
+func backoff(attempts int, base, limit time.Duration) time.Duration {
+	pause := base
+	for i := 1; i < attempts; i++ {
+		pause *= 2
+		if pause >= limit {
+			return limit
+		}
 	}
+	return pause
 }
@@ -0,0 +1,173 @@
+package workers
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// deleteRepo считает вызовы DeleteBatchLinks и по требованию отвечает ошибкой.
+type deleteRepo struct {
+	repositories.IUserRepo
+	calls   []user.DeleteBatchLinksRequest
+	failing bool
+}
+
+func (r *deleteRepo) DeleteBatchLinks(ctx context.Context, request user.DeleteBatchLinksRequest) user.DeleteBatchLinksResponse {
+	r.calls = append(r.calls, request)
+	if r.failing {
+		return user.DeleteBatchLinksResponse{
+			Code:  http.StatusInternalServerError,
+			Error: &models.Err{Source: "storage", Message: "storage is down"},
+		}
+	}
+	return r.IUserRepo.DeleteBatchLinks(ctx, request)
+}
+
+func TestWorker_Process(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, map[string]storage.Item{
+		"aaaaaa": {Object: "https://example.com/a", UserID: "user-a"},
+		"bbbbbb": {Object: "https://example.com/b", UserID: "user-a"},
+		"cccccc": {Object: "https://example.com/c", UserID: "user-b"},
+	}))
+
+	repo := &deleteRepo{IUserRepo: repositories.NewRepository(store)}
+	jobs := make(map[string]string) // задание - пользователь
+	for _, request := range []user.DeleteBatchLinksRequest{
+		{UserID: "user-a", Body: []user.DeleteBatchBody{{URLS: []string{"aaaaaa"}}}},
+		{UserID: "user-b", Body: []user.DeleteBatchBody{{URLS: []string{"cccccc"}}}},
+		{UserID: "user-a", Body: []user.DeleteBatchBody{{URLS: []string{"bbbbbb", "cccccc"}}}},
+	} {
+		result := repo.EnqueueDeleteLinks(ctx, request)
+		require.Equal(t, http.StatusAccepted, result.Code)
+		require.Equal(t, storage.JobPending, result.Response.Status)
+		jobs[result.Response.ID] = request.UserID
+	}
+
+	now := time.Now()
+	worker := NewWorker(store, repo, logger.New("info", "test_worker"), 1, time.Second)
+	worker.now = func() time.Time { return now }
+
+	// Задания одного пользователя удаляются одним запросом.
+	processed, err := worker.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, processed)
+	require.Len(t, repo.calls, 2)
+	require.Len(t, repo.calls[0].Body, 2)
+	require.Len(t, repo.calls[1].Body, 1)
+
+	for _, alias := range []string{"aaaaaa", "bbbbbb", "cccccc"} {
+		_, err = store.Get(ctx, alias)
+		require.ErrorIs(t, err, models.ErrGetDeletedLink, alias)
+	}
+	for id, userID := range jobs {
+		job, err := store.GetDeleteJob(ctx, userID, id)
+		require.NoError(t, err)
+		require.Equal(t, storage.JobDone, job.Status)
+		require.Equal(t, 1, job.Attempts)
+	}
+
+	processed, err = worker.Process(ctx)
+	require.NoError(t, err)
+	require.Zero(t, processed)
+}
+
+func TestWorker_ResumeAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+	open := func() *storage.Storage {
+		store, err := storage.NewStorage(storage.RestoreFile(ctx, path), storage.WithFileStorage(path))
+		require.NoError(t, err)
+		return store
+	}
+
+	store := open()
+	items := map[string]storage.Item{"aaaaaa": {Object: "https://example.com/a", UserID: "user-a"}}
+	require.NoError(t, store.Set(ctx, items))
+	require.NoError(t, store.File.SaveToFile(items))
+
+	result := repositories.NewRepository(store).EnqueueDeleteLinks(ctx, user.DeleteBatchLinksRequest{
+		UserID: "user-a", Body: []user.DeleteBatchBody{{URLS: []string{"aaaaaa"}}},
+	})
+	require.Equal(t, http.StatusAccepted, result.Code)
+	// Сервис остановлен до того, как очередь взяла задание.
+	store.Close()
+
+	store = open()
+	defer store.Close()
+
+	worker := NewWorker(store, repositories.NewRepository(store), logger.New("info", "test_worker"), 1, time.Second)
+	processed, err := worker.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+
+	_, err = store.Get(ctx, "aaaaaa")
+	require.ErrorIs(t, err, models.ErrGetDeletedLink)
+	job, err := store.GetDeleteJob(ctx, "user-a", result.Response.ID)
+	require.NoError(t, err)
+	require.Equal(t, storage.JobDone, job.Status)
+}
+
+func TestWorker_Retry(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	repo := &deleteRepo{IUserRepo: repositories.NewRepository(store), failing: true}
+	result := repo.EnqueueDeleteLinks(ctx, user.DeleteBatchLinksRequest{
+		UserID: "user",
+		Body:   []user.DeleteBatchBody{{URLS: []string{"aaaaaa"}}},
+	})
+	require.Equal(t, http.StatusAccepted, result.Code)
+
+	now := time.Now()
+	worker := NewWorker(store, repo, logger.New("info", "test_worker"), 1, time.Second)
+	worker.now = func() time.Time { return now }
+
+	for attempt := 1; attempt <= deleteMaxAttempts; attempt++ {
+		processed, err := worker.Process(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, processed)
+
+		job, err := store.GetDeleteJob(ctx, "user", result.Response.ID)
+		require.NoError(t, err)
+		require.Equal(t, attempt, job.Attempts)
+		require.Equal(t, "storage is down", job.LastError)
+
+		if attempt == deleteMaxAttempts {
+			require.Equal(t, storage.JobFailed, job.Status)
+			break
+		}
+		require.Equal(t, storage.JobPending, job.Status)
+		require.Equal(t, now.Add(backoff(attempt, deleteBaseBackoff, deleteMaxBackoff)), job.NextAttemptAt)
+
+		// До окончания паузы задание не берется.
+		processed, err = worker.Process(ctx)
+		require.NoError(t, err)
+		require.Zero(t, processed)
+		now = job.NextAttemptAt
+	}
+
+	processed, err := worker.Process(ctx)
+	require.NoError(t, err)
+	require.Zero(t, processed)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Second, backoff(1, time.Second, time.Minute))
+	require.Equal(t, 4*time.Second, backoff(3, time.Second, time.Minute))
+	require.Equal(t, time.Minute, backoff(10, time.Second, time.Minute))
+}
@@ -0,0 +1,309 @@
+// Package client - Go-клиент API сервиса сокращения ссылок.
+//
+// Client работает с HTTP API, GRPCClient - с gRPC-сервисом Shortener. Пользователя сервис
+// определяет по токену: HTTP-клиент сохраняет cookie, выданную при первом сокращении,
+// и отправляет ее в следующих запросах. Токен можно получить методом Token и передать
+// другому клиенту через WithToken - он уйдет заголовком Authorization: Bearer.
+//
+// Ответы в gzip распаковываются автоматически, тела JSON больше gzipThreshold сжимаются.
+// Запросы, на которые сервис ответил 5xx или 429, повторяются с экспоненциальной задержкой,
+// учитывающей Retry-After.
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sonikq/url-shortener/internal/app/pkg/auth"
+)
+
+// Значения по умолчанию.
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultRetries      = 3
+	defaultRetryWait    = 100 * time.Millisecond
+	defaultRetryMaxWait = 2 * time.Second
+
+	// gzipThreshold - тела запросов больше этого размера отправляются сжатыми
+	gzipThreshold = 1 << 10
+)
+
+// passwordHeader - заголовок с паролем защищенной ссылки
+const passwordHeader = "X-Link-Password"
+
+// Client - клиент HTTP API сервиса. Безопасен для одновременного использования.
+type Client struct {
+	baseURL string
+	http    *resty.Client
+}
+
+// Option -
+type Option func(c *Client)
+
+// WithToken - токен пользователя, выданный сервисом; отправляется как API-ключ
+// в заголовке Authorization: Bearer.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.http.SetAuthToken(token)
+	}
+}
+
+// WithHTTPClient - собственный http.Client, например с настроенным Transport.
+// Если у него нет CookieJar, cookie сервиса не сохраняются и нужен WithToken.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = configure(resty.NewWithClient(hc), c.baseURL)
+	}
+}
+
+// WithTimeout - ограничение времени одной попытки запроса
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.http.SetTimeout(timeout)
+	}
+}
+
+// WithRetries - количество повторов и границы задержки между ними, count 0 отключает повторы
+func WithRetries(count int, wait, maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.http.SetRetryCount(count).
+			SetRetryWaitTime(wait).
+			SetRetryMaxWaitTime(maxWait)
+	}
+}
+
+// WithRealIP - адрес клиента в X-Real-IP для служебных методов (/api/internal),
+// которые доступны только из доверенной подсети.
+func WithRealIP(ip string) Option {
+	return func(c *Client) {
+		c.http.SetHeader("X-Real-IP", ip)
+	}
+}
+
+// New - клиент сервиса с адресом baseURL, например http://localhost:8080.
+// WithHTTPClient указывается первым: он заменяет настройки, заданные до него.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+	c.http = configure(resty.New().SetTimeout(defaultTimeout), c.baseURL)
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func configure(r *resty.Client, baseURL string) *resty.Client {
+	return r.SetBaseURL(baseURL).
+		SetRetryCount(defaultRetries).
+		SetRetryWaitTime(defaultRetryWait).
+		SetRetryMaxWaitTime(defaultRetryMaxWait).
+		AddRetryCondition(retryable).
+		AddRetryHook(rewind).
+		SetRetryAfter(retryAfter).
+		// Редирект сокращенной ссылки - результат Expand, а не повод идти по адресу назначения.
+		SetRedirectPolicy(resty.RedirectPolicyFunc(func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}))
+}
+
+// Token - токен пользователя, выданный сервисом в cookie или заданный WithToken.
+// Пустой, пока клиент не выполнил ни одного запроса, создающего пользователя.
+func (c *Client) Token() string {
+	if c.http.Token != "" {
+		return c.http.Token
+	}
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil || c.http.GetClient().Jar == nil {
+		return ""
+	}
+	for _, cookie := range c.http.GetClient().Jar.Cookies(u) {
+		if cookie.Name == auth.CookieName {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// retryable - ответ, после которого запрос стоит повторить. Тело-поток, которое нельзя
+// перемотать на начало, второй раз не отправить.
+func retryable(resp *resty.Response, err error) bool {
+	if err != nil || resp == nil {
+		return false
+	}
+	if body, ok := resp.Request.Body.(io.Reader); ok {
+		if _, ok = body.(io.Seeker); !ok {
+			return false
+		}
+	}
+	return resp.StatusCode() >= http.StatusInternalServerError ||
+		resp.StatusCode() == http.StatusTooManyRequests
+}
+
+// rewind перематывает тело-поток на начало перед повтором запроса.
+func rewind(resp *resty.Response, _ error) {
+	if resp == nil {
+		return
+	}
+	if body, ok := resp.Request.Body.(io.Seeker); ok {
+		_, _ = body.Seek(0, io.SeekStart)
+	}
+}
+
+// retryAfter - задержка из Retry-After в секундах; 0 - задержка по экспоненте.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	seconds, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0, nil
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func (c *Client) request(ctx context.Context) *resty.Request {
+	return c.http.R().SetContext(ctx)
+}
+
+// setJSON кладет в запрос тело JSON, большое тело сжимается.
+func setJSON(req *resty.Request, body any) (*resty.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetHeader("Content-Type", "application/json")
+	return setBody(req, data)
+}
+
+func setBody(req *resty.Request, data []byte) (*resty.Request, error) {
+	if len(data) <= gzipThreshold {
+		return req.SetBody(data), nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return req.SetHeader("Content-Encoding", "gzip").SetBody(buf.Bytes()), nil
+}
+
+// do выполняет запрос и разбирает ответ с одним из ожидаемых кодов в result;
+// остальные коды возвращаются как *Error.
+func do(req *resty.Request, method, path string, result any, expected ...int) (*resty.Response, error) {
+	resp, err := req.Execute(method, path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range expected {
+		if resp.StatusCode() != code {
+			continue
+		}
+		if result != nil && len(resp.Body()) > 0 {
+			if err = json.Unmarshal(resp.Body(), result); err != nil {
+				return resp, fmt.Errorf("client: cant decode response of %s %s: %w", method, path, err)
+			}
+		}
+		return resp, nil
+	}
+	return resp, newError(resp)
+}
+
+// alias - сокращение из сокращенной ссылки или само сокращение.
+func alias(shortURL string) string {
+	if u, err := url.Parse(shortURL); err == nil && u.Scheme != "" {
+		shortURL = u.Path
+	}
+	return strings.Trim(shortURL, "/")
+}
+
+// segment - сокращение для подстановки в путь запроса.
+func segment(shortURL string) string {
+	return url.PathEscape(alias(shortURL))
+}
+
+// Ошибки ответов сервиса, сравниваются через errors.Is.
+var (
+	ErrBadRequest   = errors.New("client: bad request")
+	ErrUnauthorized = errors.New("client: unauthorized")
+	ErrForbidden    = errors.New("client: forbidden")
+	ErrNotFound     = errors.New("client: not found")
+	ErrConflict     = errors.New("client: already exists")
+	ErrGone         = errors.New("client: link is deleted or expired")
+	ErrRateLimited  = errors.New("client: too many requests")
+
+	// ErrInterstitial - ссылка открывается промежуточной страницей, адрес назначения
+	// можно узнать только в браузере или через gRPC Expand.
+	ErrInterstitial = errors.New("client: link opens an interstitial page")
+)
+
+// Error - ответ сервиса с неожиданным кодом
+type Error struct {
+	StatusCode int
+	Message    string // описание ошибки от сервиса, если оно есть
+}
+
+func newError(resp *resty.Response) *Error {
+	return newErrorFromBody(resp.StatusCode(), resp.Body())
+}
+
+// newErrorFromBody достает описание ошибки из ответа в формате сервиса.
+func newErrorFromBody(code int, data []byte) *Error {
+	e := &Error{StatusCode: code}
+
+	var body map[string]any
+	if json.Unmarshal(data, &body) == nil {
+		for _, key := range []string{"описание ошибки", "error"} {
+			if message, ok := body[key].(string); ok {
+				e.Message = message
+				break
+			}
+		}
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("client: unexpected response status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("client: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is сопоставляет код ответа с ошибками пакета.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrGone:
+		return e.StatusCode == http.StatusGone
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInterstitial:
+		return e.StatusCode == http.StatusOK
+	}
+	return false
+}
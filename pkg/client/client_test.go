@@ -0,0 +1,300 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cfg "github.com/sonikq/url-shortener/configs/app"
+	"github.com/sonikq/url-shortener/internal/app/handlers"
+	"github.com/sonikq/url-shortener/internal/app/pkg/logger"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/sonikq/url-shortener/internal/app/services"
+	"github.com/sonikq/url-shortener/internal/app/workers"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer - сервис на хранилище в памяти с очередью удаления.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	log := logger.New(logger.LevelError, "client-test")
+	repo := repositories.NewRepository(store)
+	worker := workers.NewWorker(store, repo, log, 1, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+
+	srv := httptest.NewUnstartedServer(nil)
+	srv.Config.Handler = handlers.NewRouter(handlers.Option{
+		Conf: cfg.Config{
+			BaseURL:           "http://" + srv.Listener.Addr().String(),
+			TrustedSubnet:     "192.168.1.0/24",
+			DeleteGracePeriod: time.Hour,
+		},
+		Service:   services.NewService(repo),
+		Logger:    log,
+		Cache:     store,
+		Worker:    worker,
+		Scheduler: workers.NewScheduler(store, log),
+	})
+	srv.Start()
+
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+		<-done
+	})
+	return srv
+}
+
+func TestClient_Shorten(t *testing.T) {
+	srv := newTestServer(t)
+	ctx := context.Background()
+	c := New(srv.URL)
+
+	shortURL, err := c.Shorten(ctx, "https://example.com/a")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(shortURL, srv.URL+"/"))
+	assert.NotEmpty(t, c.Token(), "cookie of the new user must be kept")
+
+	again, err := c.Shorten(ctx, "https://example.com/a")
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, shortURL, again)
+
+	jsonURL, err := c.ShortenJSON(ctx, ShortenRequest{URL: "https://example.com/b", Tags: []string{"docs"}})
+	require.NoError(t, err)
+
+	redirect, err := c.Expand(ctx, jsonURL)
+	require.NoError(t, err)
+	assert.Equal(t, Redirect{URL: "https://example.com/b", StatusCode: http.StatusTemporaryRedirect}, redirect)
+
+	page, err := c.Links(ctx, ListOptions{Tag: "docs"})
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, jsonURL, page.Links[0].ShortURL)
+
+	_, err = c.Expand(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestClient_Token(t *testing.T) {
+	srv := newTestServer(t)
+	ctx := context.Background()
+
+	owner := New(srv.URL)
+	shortURL, err := owner.Shorten(ctx, "https://example.com/token")
+	require.NoError(t, err)
+
+	// Токен из cookie работает как API-ключ у клиента без cookie.
+	page, err := New(srv.URL, WithToken(owner.Token())).Links(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, shortURL, page.Links[0].ShortURL)
+
+	_, err = New(srv.URL).Links(ctx, ListOptions{})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestClient_ShortenBatch(t *testing.T) {
+	srv := newTestServer(t)
+	ctx := context.Background()
+	c := New(srv.URL)
+
+	// Пакет больше gzipThreshold уходит сжатым.
+	links := make([]BatchRequest, 0, 50)
+	for i := 0; i < cap(links); i++ {
+		links = append(links, BatchRequest{
+			CorrelationID: fmt.Sprint(i),
+			OriginalURL:   fmt.Sprintf("https://example.com/batch/%d", i),
+		})
+	}
+
+	result, err := c.ShortenBatch(ctx, links)
+	require.NoError(t, err)
+	require.Len(t, result, len(links))
+
+	redirect, err := c.Expand(ctx, result[7].ShortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/batch/7", redirect.URL)
+}
+
+func TestClient_DeleteLinks(t *testing.T) {
+	srv := newTestServer(t)
+	ctx := context.Background()
+	c := New(srv.URL)
+
+	shortURL, err := c.Shorten(ctx, "https://example.com/delete")
+	require.NoError(t, err)
+
+	job, err := c.DeleteLinks(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.URLs)
+
+	require.Eventually(t, func() bool {
+		job, err = c.DeleteJob(ctx, job.ID)
+		return err == nil && job.Status == "done"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = c.Expand(ctx, shortURL)
+	assert.ErrorIs(t, err, ErrGone)
+
+	restored, err := c.RestoreLink(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/delete", restored.OriginalURL)
+}
+
+func TestClient_Stats(t *testing.T) {
+	srv := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := New(srv.URL).Shorten(ctx, "https://example.com/stats")
+	require.NoError(t, err)
+
+	stats, err := New(srv.URL, WithRealIP("192.168.1.10")).Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{URL: 1, Users: 1}, stats)
+
+	_, err = New(srv.URL).Stats(ctx)
+	assert.ErrorIs(t, err, ErrUnauthorized, "loopback is not in the trusted subnet")
+}
+
+func TestClient_ImportExport(t *testing.T) {
+	srv := newTestServer(t)
+	ctx := context.Background()
+	c := New(srv.URL)
+
+	// Импорт создает пользователя только по токену, поэтому сначала нужен запрос, выдающий cookie.
+	_, err := c.Shorten(ctx, "https://example.com/first")
+	require.NoError(t, err)
+
+	report, err := c.Import(ctx, strings.NewReader("alias,original_url\nimported,https://example.com/imported\n"), "csv")
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+
+	var out bytes.Buffer
+	require.NoError(t, c.Export(ctx, &out, "jsonl"))
+	assert.Equal(t, 2, strings.Count(out.String(), "\n"))
+	assert.Contains(t, out.String(), "https://example.com/imported")
+
+	err = c.Export(ctx, io.Discard, "xml")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		header   http.Header
+		want     int // код ошибки, 0 - успешный ответ
+		attempts int32
+	}{
+		{
+			name:     "retries 5xx",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusCreated},
+			attempts: 3,
+		},
+		{
+			name:     "retries 429 after Retry-After",
+			statuses: []int{http.StatusTooManyRequests, http.StatusCreated},
+			header:   http.Header{"Retry-After": {"1"}},
+			attempts: 2,
+		},
+		{
+			name:     "gives up",
+			statuses: []int{http.StatusInternalServerError},
+			want:     http.StatusInternalServerError,
+			attempts: 3,
+		},
+		{
+			name:     "does not retry 4xx",
+			statuses: []int{http.StatusBadRequest, http.StatusCreated},
+			want:     http.StatusBadRequest,
+			attempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "https://example.com", string(body), "body must be resent on retry")
+
+				n := int(attempts.Add(1)) - 1
+				code := tt.statuses[min(n, len(tt.statuses)-1)]
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(code)
+				_, _ = w.Write([]byte("http://short/abc"))
+			}))
+			defer srv.Close()
+
+			// Retry-After больше maxWait ограничивается им.
+			c := New(srv.URL, WithRetries(2, time.Millisecond, 20*time.Millisecond))
+			shortURL, err := c.Shorten(context.Background(), "https://example.com")
+			if tt.want != 0 {
+				var apiErr *Error
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.want, apiErr.StatusCode)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "http://short/abc", shortURL)
+			}
+			assert.Equal(t, tt.attempts, attempts.Load())
+		})
+	}
+}
+
+func TestClient_GzipRequest(t *testing.T) {
+	var encodings []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = zr
+		}
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	long := "https://example.com/" + strings.Repeat("a", gzipThreshold)
+	for _, originalURL := range []string{"https://example.com/short", long} {
+		got, err := c.Shorten(context.Background(), originalURL)
+		require.NoError(t, err)
+		assert.Equal(t, originalURL, got)
+	}
+	assert.Equal(t, []string{"", "gzip"}, encodings)
+}
+
+func TestError_Is(t *testing.T) {
+	err := error(&Error{StatusCode: http.StatusGone})
+	assert.ErrorIs(t, err, ErrGone)
+	assert.NotErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, fmt.Errorf("wrapped: %w", &Error{StatusCode: http.StatusConflict}), ErrConflict)
+}
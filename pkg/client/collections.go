@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateCollection создает коллекцию ссылок пользователя
+func (c *Client) CreateCollection(ctx context.Context, name string) (Collection, error) {
+	req, err := setJSON(c.request(ctx), map[string]string{"name": name})
+	if err != nil {
+		return Collection{}, err
+	}
+
+	var collection Collection
+	if _, err = do(req, http.MethodPost, "/api/user/collections", &collection, http.StatusCreated); err != nil {
+		return Collection{}, err
+	}
+	return collection, nil
+}
+
+// Collections - коллекции пользователя с количеством ссылок в них
+func (c *Client) Collections(ctx context.Context) ([]Collection, error) {
+	var collections []Collection
+	if _, err := do(c.request(ctx), http.MethodGet, "/api/user/collections", &collections, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// DeleteCollection удаляет коллекцию вместе с ее ссылками. Ссылки удаляются заданием
+// в очереди, для пустой коллекции задания нет и возвращается nil.
+func (c *Client) DeleteCollection(ctx context.Context, name string) (*DeleteJob, error) {
+	var job DeleteJob
+	resp, err := do(c.request(ctx), http.MethodDelete, "/api/user/collections/"+url.PathEscape(name), &job, http.StatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Body()) == 0 {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// ExportCollection - ссылки коллекции
+func (c *Client) ExportCollection(ctx context.Context, name string) ([]Link, error) {
+	var links []Link
+	_, err := do(c.request(ctx), http.MethodGet, "/api/user/collections/"+url.PathEscape(name)+"/export", &links, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// CreateWebhook подписывает адрес на события ссылок пользователя. Secret для проверки
+// подписи доставок возвращается только здесь.
+func (c *Client) CreateWebhook(ctx context.Context, webhook WebhookRequest) (Webhook, error) {
+	req, err := setJSON(c.request(ctx), webhook)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	var created Webhook
+	if _, err = do(req, http.MethodPost, "/api/user/webhooks", &created, http.StatusCreated); err != nil {
+		return Webhook{}, err
+	}
+	return created, nil
+}
+
+// Webhooks - подписки пользователя
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if _, err := do(c.request(ctx), http.MethodGet, "/api/user/webhooks", &webhooks, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook удаляет подписку
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := do(c.request(ctx), http.MethodDelete, "/api/user/webhooks/"+url.PathEscape(id), nil, http.StatusNoContent)
+	return err
+}
+
+// WebhookDeliveries - журнал доставок подписки; пустой status - доставки в любом состоянии,
+// limit 0 - ограничение сервиса по умолчанию.
+func (c *Client) WebhookDeliveries(ctx context.Context, id, status string, limit int) ([]WebhookDelivery, error) {
+	req := c.request(ctx)
+	if status != "" {
+		req.SetQueryParam("status", status)
+	}
+	if limit > 0 {
+		req.SetQueryParam("limit", strconv.Itoa(limit))
+	}
+
+	var deliveries []WebhookDelivery
+	_, err := do(req, http.MethodGet, "/api/user/webhooks/"+url.PathEscape(id)+"/deliveries", &deliveries, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery ставит неудавшуюся доставку на немедленный повтор
+func (c *Client) RetryWebhookDelivery(ctx context.Context, id string, delivery int64) (WebhookDelivery, error) {
+	path := "/api/user/webhooks/" + url.PathEscape(id) + "/deliveries/" + strconv.FormatInt(delivery, 10) + "/retry"
+
+	var result WebhookDelivery
+	if _, err := do(c.request(ctx), http.MethodPost, path, &result, http.StatusAccepted); err != nil {
+		return WebhookDelivery{}, err
+	}
+	return result, nil
+}
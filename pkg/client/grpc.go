@@ -0,0 +1,505 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/google/uuid"
+	"github.com/sonikq/url-shortener/internal/app/models/user"
+	pb "github.com/sonikq/url-shortener/internal/app/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCClient - клиент gRPC-сервиса Shortener с теми же типами, что и Client.
+// В gRPC пользователь передается в каждом запросе, а не токеном.
+type GRPCClient struct {
+	conn    *grpc.ClientConn // только у клиента, созданного DialGRPC
+	client  pb.ShortenerClient
+	userID  string
+	realIP  string
+	retries int
+	wait    time.Duration
+	maxWait time.Duration
+}
+
+// GRPCOption -
+type GRPCOption func(c *GRPCClient)
+
+// WithUserID - пользователь, от имени которого выполняются запросы.
+// По умолчанию клиент создает нового пользователя.
+func WithUserID(userID string) GRPCOption {
+	return func(c *GRPCClient) {
+		c.userID = userID
+	}
+}
+
+// WithGRPCRealIP - адрес клиента в метаданных x-real-ip для Stats, который доступен
+// только из доверенной подсети. Сервис ожидает адрес с портом, например 10.0.0.1:0.
+func WithGRPCRealIP(addr string) GRPCOption {
+	return func(c *GRPCClient) {
+		c.realIP = addr
+	}
+}
+
+// WithGRPCRetries - количество повторов при Unavailable и ResourceExhausted и границы
+// задержки между ними, count 0 отключает повторы.
+func WithGRPCRetries(count int, wait, maxWait time.Duration) GRPCOption {
+	return func(c *GRPCClient) {
+		c.retries = count
+		c.wait = wait
+		c.maxWait = maxWait
+	}
+}
+
+// NewGRPC - клиент поверх готового соединения
+func NewGRPC(conn grpc.ClientConnInterface, opts ...GRPCOption) *GRPCClient {
+	c := &GRPCClient{
+		client:  pb.NewShortenerClient(conn),
+		retries: defaultRetries,
+		wait:    defaultRetryWait,
+		maxWait: defaultRetryMaxWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.userID == "" {
+		c.userID = uuid.NewString()
+	}
+	return c
+}
+
+// DialGRPC подключается к сервису по адресу target, например localhost:3200.
+// Без grpc.DialOption соединение не шифруется. Соединение закрывает Close.
+func DialGRPC(target string, dialOpts []grpc.DialOption, opts ...GRPCOption) (*GRPCClient, error) {
+	if len(dialOpts) == 0 {
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewGRPC(conn, opts...)
+	c.conn = conn
+	return c, nil
+}
+
+// Close закрывает соединение, созданное DialGRPC
+func (c *GRPCClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// UserID - пользователь, от имени которого выполняются запросы
+func (c *GRPCClient) UserID() string {
+	return c.userID
+}
+
+// Shorten сокращает ссылку. Если адрес уже сокращен, сервис возвращает прежнюю ссылку
+// без ошибки: в отличие от HTTP API gRPC не отличает ее от новой.
+func (c *GRPCClient) Shorten(ctx context.Context, link ShortenRequest) (string, error) {
+	var resp *pb.ShortenResponse
+	err := c.invoke(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.Shorten(ctx, &pb.ShortenRequest{
+			UserId:       c.userID,
+			Url:          link.URL,
+			Title:        link.Title,
+			Note:         link.Note,
+			Tags:         link.Tags,
+			Collection:   link.Collection,
+			RedirectCode: int32(link.RedirectCode),
+			ForwardQuery: link.ForwardQuery,
+			ForwardPath:  link.ForwardPath,
+			Password:     link.Password,
+			MaxClicks:    int32(link.MaxClicks),
+			ActiveFrom:   link.ActiveFrom,
+			Rules:        rulesToProto(link.Rules),
+			Variants:     variantsToProto(link.Variants),
+			Sticky:       link.Sticky,
+			Interstitial: link.Interstitial,
+		})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetShorten(), nil
+}
+
+// ShortenBatch сокращает несколько ссылок одним запросом
+func (c *GRPCClient) ShortenBatch(ctx context.Context, links []BatchRequest) ([]BatchResult, error) {
+	req := &pb.ShortBatchRequest{UserId: c.userID}
+	for _, link := range links {
+		req.Original = append(req.Original, &pb.CorrelatedOriginalURL{
+			CorrelationId: link.CorrelationID,
+			OriginalUrl:   link.OriginalURL,
+		})
+	}
+
+	var resp *pb.ShortBatchResponse
+	err := c.invoke(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.Batch(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]BatchResult, 0, len(resp.GetOriginal()))
+	for _, row := range resp.GetOriginal() {
+		result = append(result, BatchResult{
+			CorrelationID: row.GetCorrelationId(),
+			ShortURL:      row.GetShortUrl(),
+		})
+	}
+	return result, nil
+}
+
+// Expand - куда ведет сокращенная ссылка; переход засчитывается, как у посетителя.
+// Для ссылки с промежуточной страницей возвращается адрес назначения и Interstitial.
+func (c *GRPCClient) Expand(ctx context.Context, shortURL string) (Redirect, error) {
+	return c.expand(ctx, &pb.ExpandRequest{ShortUrl: alias(shortURL)})
+}
+
+// ExpandProtected - Expand для ссылки, защищенной паролем
+func (c *GRPCClient) ExpandProtected(ctx context.Context, shortURL, password string) (Redirect, error) {
+	return c.expand(ctx, &pb.ExpandRequest{ShortUrl: alias(shortURL), Password: password})
+}
+
+func (c *GRPCClient) expand(ctx context.Context, req *pb.ExpandRequest) (Redirect, error) {
+	var resp *pb.ExpandResponse
+	err := c.invoke(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.Expand(ctx, req)
+		return err
+	})
+	if err != nil {
+		return Redirect{}, err
+	}
+	return Redirect{
+		URL:          resp.GetUrl(),
+		StatusCode:   int(resp.GetRedirectCode()),
+		Interstitial: resp.GetInterstitial(),
+	}, nil
+}
+
+// Links - страница ссылок пользователя
+func (c *GRPCClient) Links(ctx context.Context, opts ListOptions) (LinkPage, error) {
+	var resp *pb.GetBatchResponse
+	err := c.invoke(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.GetBatch(ctx, &pb.GetBatchRequest{
+			UserId:     c.userID,
+			Limit:      int32(opts.Limit),
+			Cursor:     opts.Cursor,
+			Sort:       opts.Sort,
+			Search:     opts.Search,
+			Deleted:    opts.Deleted,
+			Tag:        opts.Tag,
+			Collection: opts.Collection,
+		})
+		return err
+	})
+	// Пустой список сервис возвращает как NotFound, HTTP API - как 204.
+	if status.Code(err) == codes.NotFound {
+		return LinkPage{}, nil
+	}
+	if err != nil {
+		return LinkPage{}, err
+	}
+
+	page := LinkPage{
+		Links:      make([]Link, 0, len(resp.GetRows())),
+		NextCursor: resp.GetNextCursor(),
+	}
+	for _, row := range resp.GetRows() {
+		page.Links = append(page.Links, Link{
+			ShortURL:     row.GetShortURL(),
+			OriginalURL:  row.GetOriginalURL(),
+			Title:        row.GetTitle(),
+			Note:         row.GetNote(),
+			Tags:         row.GetTags(),
+			Collection:   row.GetCollection(),
+			RedirectCode: int(row.GetRedirectCode()),
+			ForwardQuery: row.GetForwardQuery(),
+			ForwardPath:  row.GetForwardPath(),
+			Protected:    row.GetProtected(),
+			MaxClicks:    int(row.GetMaxClicks()),
+			Clicks:       int(row.GetClicks()),
+			ActiveFrom:   timePtr(row.GetActiveFrom()),
+			Rules:        rulesFromProto(row.GetRules()),
+			Variants:     variantsFromProto(row.GetVariants()),
+			Sticky:       row.GetSticky(),
+			Interstitial: row.GetInterstitial(),
+			Blocked:      row.GetBlocked(),
+			CreatedAt:    row.GetCreatedAt().AsTime(),
+			UpdatedAt:    row.GetUpdatedAt().AsTime(),
+			IsDeleted:    row.GetIsDeleted(),
+			DeletedAt:    timePtr(row.GetDeletedAt()),
+		})
+	}
+	return page, nil
+}
+
+// UpdateLink меняет заданные поля ссылки
+func (c *GRPCClient) UpdateLink(ctx context.Context, shortURL string, update UpdateRequest) (UpdatedLink, error) {
+	req := &pb.UpdateLinkRequest{
+		UserId:       c.userID,
+		ShortUrl:     alias(shortURL),
+		Url:          update.URL,
+		Title:        update.Title,
+		Note:         update.Note,
+		Collection:   update.Collection,
+		ForwardQuery: update.ForwardQuery,
+		ForwardPath:  update.ForwardPath,
+		Password:     update.Password,
+		ActiveFrom:   update.ActiveFrom,
+		Sticky:       update.Sticky,
+		Interstitial: update.Interstitial,
+	}
+	if update.Tags != nil {
+		req.Tags = &pb.TagList{Tags: *update.Tags}
+	}
+	if update.RedirectCode != nil {
+		code := int32(*update.RedirectCode)
+		req.RedirectCode = &code
+	}
+	if update.MaxClicks != nil {
+		maxClicks := int32(*update.MaxClicks)
+		req.MaxClicks = &maxClicks
+	}
+	if update.Rules != nil {
+		req.Rules = &pb.RuleList{Rules: rulesToProto(*update.Rules)}
+	}
+	if update.Variants != nil {
+		req.Variants = &pb.VariantList{Variants: variantsToProto(*update.Variants)}
+	}
+
+	var resp *pb.UpdateLinkResponse
+	err := c.invoke(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.UpdateLink(ctx, req)
+		return err
+	})
+	if err != nil {
+		return UpdatedLink{}, err
+	}
+
+	return UpdatedLink{
+		ShortURL:     resp.GetShortUrl(),
+		OriginalURL:  resp.GetOriginalUrl(),
+		Title:        resp.GetTitle(),
+		Note:         resp.GetNote(),
+		Tags:         resp.GetTags(),
+		Collection:   resp.GetCollection(),
+		RedirectCode: int(resp.GetRedirectCode()),
+		ForwardQuery: resp.GetForwardQuery(),
+		ForwardPath:  resp.GetForwardPath(),
+		Protected:    resp.GetProtected(),
+		MaxClicks:    int(resp.GetMaxClicks()),
+		Clicks:       int(resp.GetClicks()),
+		ActiveFrom:   timePtr(resp.GetActiveFrom()),
+		Rules:        rulesFromProto(resp.GetRules()),
+		Variants:     variantsFromProto(resp.GetVariants()),
+		Sticky:       resp.GetSticky(),
+		Interstitial: resp.GetInterstitial(),
+		Blocked:      resp.GetBlocked(),
+		UpdatedAt:    resp.GetUpdatedAt().AsTime(),
+	}, nil
+}
+
+// QR - QR-код сокращенной ссылки
+func (c *GRPCClient) QR(ctx context.Context, shortURL string, opts QROptions) (QRCode, error) {
+	req := &pb.GetQRRequest{
+		UserId:   c.userID,
+		ShortUrl: alias(shortURL),
+		Format:   opts.Format,
+		Size:     int32(opts.Size),
+		Level:    opts.Level,
+		Logo:     opts.Logo,
+	}
+	if opts.Margin != nil {
+		margin := int32(*opts.Margin)
+		req.Margin = &margin
+	}
+
+	var resp *pb.GetQRResponse
+	err := c.invoke(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.GetQR(ctx, req)
+		return err
+	})
+	if err != nil {
+		return QRCode{}, err
+	}
+	return QRCode{ContentType: resp.GetContentType(), Image: resp.GetImage()}, nil
+}
+
+// Ping проверяет доступность хранилища сервиса
+func (c *GRPCClient) Ping(ctx context.Context) error {
+	return c.invoke(ctx, func(ctx context.Context) error {
+		_, err := c.client.Ping(ctx, &empty.Empty{})
+		return err
+	})
+}
+
+// Stats - количество ссылок и пользователей сервиса
+func (c *GRPCClient) Stats(ctx context.Context) (Stats, error) {
+	if c.realIP != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-real-ip", c.realIP)
+	}
+
+	var resp *pb.GetStatsResponse
+	err := c.invoke(ctx, func(ctx context.Context) (err error) {
+		resp, err = c.client.GetStats(ctx, &empty.Empty{})
+		return err
+	})
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{URL: int64(resp.GetUrls()), Users: int64(resp.GetUsers())}, nil
+}
+
+// invoke выполняет вызов, повторяя его при Unavailable и ResourceExhausted с экспоненциальной задержкой.
+func (c *GRPCClient) invoke(ctx context.Context, call func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := call(ctx)
+		if err == nil {
+			return nil
+		}
+
+		code := status.Code(err)
+		if attempt >= c.retries || (code != codes.Unavailable && code != codes.ResourceExhausted) {
+			return &grpcError{err: err}
+		}
+
+		timer := time.NewTimer(backoff(c.wait, c.maxWait, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &grpcError{err: err}
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff - задержка перед повтором: удваивается с каждой попыткой, не больше maxWait,
+// со случайной добавкой, чтобы клиенты не повторяли запросы одновременно.
+func backoff(wait, maxWait time.Duration, attempt int) time.Duration {
+	d := wait << attempt
+	if d <= 0 || d > maxWait {
+		d = maxWait
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
+
+// grpcError - ошибка вызова gRPC, которая сравнивается с ошибками пакета через errors.Is
+// и сохраняет статус для status.FromError.
+type grpcError struct {
+	err error
+}
+
+func (e *grpcError) Error() string {
+	return "client: " + e.err.Error()
+}
+
+func (e *grpcError) Unwrap() error {
+	return e.err
+}
+
+func (e *grpcError) GRPCStatus() *status.Status {
+	return status.Convert(e.err)
+}
+
+func (e *grpcError) Is(target error) bool {
+	code := status.Code(e.err)
+	switch target {
+	case ErrBadRequest:
+		return code == codes.InvalidArgument
+	case ErrUnauthorized:
+		return code == codes.Unauthenticated
+	case ErrForbidden:
+		return code == codes.PermissionDenied
+	case ErrNotFound:
+		return code == codes.NotFound
+	case ErrConflict:
+		return code == codes.AlreadyExists
+	case ErrGone:
+		return code == codes.DataLoss || code == codes.FailedPrecondition
+	case ErrRateLimited:
+		return code == codes.ResourceExhausted
+	}
+	return errors.Is(e.err, target)
+}
+
+func timePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func rulesToProto(rules []RoutingRule) []*pb.RoutingRule {
+	result := make([]*pb.RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, &pb.RoutingRule{
+			Platform:    rule.Platform,
+			Language:    rule.Language,
+			Referrer:    rule.Referrer,
+			Destination: rule.Destination,
+		})
+	}
+	return result
+}
+
+func rulesFromProto(rules []*pb.RoutingRule) []user.RoutingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	result := make([]user.RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, user.RoutingRule{
+			Platform:    rule.GetPlatform(),
+			Language:    rule.GetLanguage(),
+			Referrer:    rule.GetReferrer(),
+			Destination: rule.GetDestination(),
+		})
+	}
+	return result
+}
+
+func variantsToProto(variants []Variant) []*pb.Variant {
+	result := make([]*pb.Variant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, &pb.Variant{
+			Destination: variant.Destination,
+			Weight:      int32(variant.Weight),
+		})
+	}
+	return result
+}
+
+func variantsFromProto(variants []*pb.Variant) []user.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+	result := make([]user.Variant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, user.Variant{
+			Destination: variant.GetDestination(),
+			Weight:      int(variant.GetWeight()),
+			Clicks:      variant.GetClicks(),
+		})
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/sonikq/url-shortener/internal/app/proto"
+	"github.com/sonikq/url-shortener/internal/app/repositories"
+	"github.com/sonikq/url-shortener/internal/app/services"
+	"github.com/sonikq/url-shortener/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testBaseURL = "http://localhost:8080"
+
+// newTestConn - соединение с gRPC-сервисом на хранилище в памяти.
+func newTestConn(t *testing.T, interceptors ...grpc.UnaryServerInterceptor) *grpc.ClientConn {
+	t.Helper()
+
+	store, err := storage.NewStorage()
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	pb.RegisterShortenerServer(server, &services.ServiceGrpc{
+		Repo:    repositories.NewRepository(store),
+		BaseURL: testBaseURL,
+	})
+
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+	return conn
+}
+
+func TestGRPCClient(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestConn(t)
+	c := NewGRPC(conn)
+	require.NotEmpty(t, c.UserID())
+
+	link := ShortenRequest{URL: "https://example.com/grpc", Interstitial: true}
+	shortURL, err := c.Shorten(ctx, link)
+	require.NoError(t, err)
+
+	again, err := c.Shorten(ctx, link)
+	require.NoError(t, err)
+	assert.Equal(t, shortURL, again)
+
+	_, err = c.Shorten(ctx, ShortenRequest{URL: "not a url"})
+	assert.ErrorIs(t, err, ErrBadRequest)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	redirect, err := c.Expand(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, Redirect{URL: "https://example.com/grpc", StatusCode: 307, Interstitial: true}, redirect)
+
+	title := "Docs"
+	updated, err := c.UpdateLink(ctx, shortURL, UpdateRequest{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, "Docs", updated.Title)
+
+	page, err := c.Links(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, shortURL, page.Links[0].ShortURL)
+	assert.Equal(t, "Docs", page.Links[0].Title)
+
+	// Пустой список ссылок - не ошибка, как и в HTTP API.
+	page, err = NewGRPC(conn).Links(ctx, ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Links)
+
+	other := NewGRPC(conn, WithUserID(c.UserID()))
+	page, err = other.Links(ctx, ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, page.Links, 1)
+}
+
+func TestGRPCClient_Retry(t *testing.T) {
+	var attempts int
+	unavailable := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, status.Error(codes.Unavailable, "warming up")
+		}
+		return handler(ctx, req)
+	}
+	conn := newTestConn(t, unavailable)
+
+	c := NewGRPC(conn, WithGRPCRetries(2, time.Millisecond, 10*time.Millisecond))
+	_, err := c.Links(context.Background(), ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	c = NewGRPC(conn, WithGRPCRetries(1, time.Millisecond, 10*time.Millisecond))
+	_, err = c.Links(context.Background(), ListOptions{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 2, attempts)
+}
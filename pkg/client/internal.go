@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Служебные методы /api/internal доступны только из доверенной подсети сервиса,
+// адрес клиента задается WithRealIP.
+
+// Stats - количество ссылок и пользователей сервиса
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	if _, err := do(c.request(ctx), http.MethodGet, "/api/internal/stats", &stats, http.StatusOK); err != nil {
+		return Stats{}, err
+	}
+	return stats, nil
+}
+
+// ScheduledJobs - фоновые задачи планировщика с последним запуском
+func (c *Client) ScheduledJobs(ctx context.Context) ([]ScheduledJob, error) {
+	var jobs []ScheduledJob
+	if _, err := do(c.request(ctx), http.MethodGet, "/api/internal/jobs", &jobs, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// JobRuns - журнал запусков фоновой задачи, limit 0 - ограничение сервиса по умолчанию
+func (c *Client) JobRuns(ctx context.Context, name string, limit int) ([]JobRun, error) {
+	req := c.request(ctx)
+	if limit > 0 {
+		req.SetQueryParam("limit", strconv.Itoa(limit))
+	}
+
+	var runs []JobRun
+	if _, err := do(req, http.MethodGet, "/api/internal/jobs/"+url.PathEscape(name)+"/runs", &runs, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// RunJob запускает фоновую задачу вне расписания; результат запуска появится в JobRuns
+func (c *Client) RunJob(ctx context.Context, name string) (ManualRun, error) {
+	var run ManualRun
+	if _, err := do(c.request(ctx), http.MethodPost, "/api/internal/jobs/"+url.PathEscape(name)+"/run", &run, http.StatusAccepted); err != nil {
+		return ManualRun{}, err
+	}
+	return run, nil
+}
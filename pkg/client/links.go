@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Links - страница ссылок пользователя, GET /api/user/urls. Следующую страницу
+// возвращает запрос с Cursor из LinkPage.NextCursor.
+func (c *Client) Links(ctx context.Context, opts ListOptions) (LinkPage, error) {
+	req := c.request(ctx)
+	if opts.Limit > 0 {
+		req.SetQueryParam("limit", strconv.Itoa(opts.Limit))
+	}
+	for key, value := range map[string]string{
+		"cursor":     opts.Cursor,
+		"sort":       opts.Sort,
+		"q":          opts.Search,
+		"tag":        opts.Tag,
+		"collection": opts.Collection,
+		"deleted":    opts.Deleted,
+	} {
+		if value != "" {
+			req.SetQueryParam(key, value)
+		}
+	}
+
+	var page LinkPage
+	resp, err := do(req, http.MethodGet, "/api/user/urls", &page.Links, http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return LinkPage{}, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return LinkPage{}, nil
+	}
+	page.NextCursor = resp.Header().Get("X-Next-Cursor")
+	return page, nil
+}
+
+// DeleteLinks ставит ссылки пользователя в очередь на удаление, DELETE /api/user/urls.
+// За выполнением задания можно следить методом DeleteJob.
+func (c *Client) DeleteLinks(ctx context.Context, shortURLs ...string) (DeleteJob, error) {
+	aliases := make([]string, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		aliases = append(aliases, alias(shortURL))
+	}
+
+	req, err := setJSON(c.request(ctx), aliases)
+	if err != nil {
+		return DeleteJob{}, err
+	}
+
+	var job DeleteJob
+	if _, err = do(req, http.MethodDelete, "/api/user/urls", &job, http.StatusAccepted); err != nil {
+		return DeleteJob{}, err
+	}
+	return job, nil
+}
+
+// DeleteJob - состояние задания на удаление
+func (c *Client) DeleteJob(ctx context.Context, id string) (DeleteJob, error) {
+	var job DeleteJob
+	if _, err := do(c.request(ctx), http.MethodGet, "/api/user/jobs/"+url.PathEscape(id), &job, http.StatusOK); err != nil {
+		return DeleteJob{}, err
+	}
+	return job, nil
+}
+
+// UpdateLink меняет заданные поля ссылки, PATCH /api/user/urls/:id
+func (c *Client) UpdateLink(ctx context.Context, shortURL string, update UpdateRequest) (UpdatedLink, error) {
+	req, err := setJSON(c.request(ctx), update)
+	if err != nil {
+		return UpdatedLink{}, err
+	}
+	return c.updatedLink(req, http.MethodPatch, "/api/user/urls/"+segment(shortURL))
+}
+
+// Revisions - прежние адреса ссылки
+func (c *Client) Revisions(ctx context.Context, shortURL string) ([]Revision, error) {
+	var revisions []Revision
+	_, err := do(c.request(ctx), http.MethodGet, "/api/user/urls/"+segment(shortURL)+"/revisions", &revisions, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// RestoreRevision возвращает ссылке адрес из ревизии
+func (c *Client) RestoreRevision(ctx context.Context, shortURL string, revision int64) (UpdatedLink, error) {
+	path := fmt.Sprintf("/api/user/urls/%s/revisions/%d/restore", segment(shortURL), revision)
+	return c.updatedLink(c.request(ctx), http.MethodPost, path)
+}
+
+// DeletedLinks - удаленные ссылки пользователя, которые еще можно восстановить
+func (c *Client) DeletedLinks(ctx context.Context) ([]DeletedLink, error) {
+	var links []DeletedLink
+	if _, err := do(c.request(ctx), http.MethodGet, "/api/user/urls/deleted", &links, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// RestoreLink восстанавливает удаленную ссылку
+func (c *Client) RestoreLink(ctx context.Context, shortURL string) (UpdatedLink, error) {
+	return c.updatedLink(c.request(ctx), http.MethodPost, "/api/user/urls/"+segment(shortURL)+"/restore")
+}
+
+func (c *Client) updatedLink(req *resty.Request, method, path string) (UpdatedLink, error) {
+	var link UpdatedLink
+	if _, err := do(req, method, path, &link, http.StatusOK); err != nil {
+		return UpdatedLink{}, err
+	}
+	return link, nil
+}
+
+// LinkStats - счетчики переходов по ссылке
+func (c *Client) LinkStats(ctx context.Context, shortURL string) (LinkStats, error) {
+	var stats LinkStats
+	_, err := do(c.request(ctx), http.MethodGet, "/api/user/urls/"+segment(shortURL)+"/stats", &stats, http.StatusOK)
+	if err != nil {
+		return LinkStats{}, err
+	}
+	return stats, nil
+}
+
+// QR - QR-код сокращенной ссылки
+func (c *Client) QR(ctx context.Context, shortURL string, opts QROptions) (QRCode, error) {
+	req := c.request(ctx)
+	if opts.Format != "" {
+		req.SetQueryParam("format", opts.Format)
+	}
+	if opts.Size > 0 {
+		req.SetQueryParam("size", strconv.Itoa(opts.Size))
+	}
+	if opts.Margin != nil {
+		req.SetQueryParam("margin", strconv.Itoa(*opts.Margin))
+	}
+	if opts.Level != "" {
+		req.SetQueryParam("level", opts.Level)
+	}
+	if opts.Logo {
+		req.SetQueryParam("logo", "true")
+	}
+
+	resp, err := do(req, http.MethodGet, "/api/user/urls/"+segment(shortURL)+"/qr", nil, http.StatusOK)
+	if err != nil {
+		return QRCode{}, err
+	}
+	return QRCode{
+		ContentType: resp.Header().Get("Content-Type"),
+		Image:       resp.Body(),
+	}, nil
+}
+
+// Import загружает ссылки из CSV или JSON Lines (format csv или jsonl), POST /api/user/import.
+// Тело читается из r потоком, поэтому запрос повторяется, только если r поддерживает io.Seeker.
+func (c *Client) Import(ctx context.Context, r io.Reader, format string) (ImportReport, error) {
+	req := c.request(ctx).
+		SetQueryParam("format", format).
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody(r)
+
+	var report ImportReport
+	if _, err := do(req, http.MethodPost, "/api/user/import", &report, http.StatusOK); err != nil {
+		return ImportReport{}, err
+	}
+	return report, nil
+}
+
+// Export выгружает все ссылки пользователя в w в формате csv или jsonl, GET /api/user/export.
+// Выгрузка не буферизуется в памяти.
+func (c *Client) Export(ctx context.Context, w io.Writer, format string) error {
+	resp, err := c.request(ctx).
+		SetQueryParam("format", format).
+		SetDoNotParseResponse(true).
+		Get("/api/user/export")
+	if err != nil {
+		return err
+	}
+	body := resp.RawBody()
+	defer body.Close()
+
+	if resp.StatusCode() != http.StatusOK {
+		data, errRead := io.ReadAll(body)
+		if errRead != nil {
+			return errRead
+		}
+		return newErrorFromBody(resp.StatusCode(), data)
+	}
+
+	_, err = io.Copy(w, body)
+	return err
+}
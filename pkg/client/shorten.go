@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Shorten сокращает ссылку через POST /. Если адрес уже сокращен, возвращает
+// прежнюю сокращенную ссылку вместе с ошибкой ErrConflict.
+func (c *Client) Shorten(ctx context.Context, originalURL string) (string, error) {
+	req, err := setBody(c.request(ctx).SetHeader("Content-Type", "text/plain"), []byte(originalURL))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := do(req, http.MethodPost, "/", nil, http.StatusCreated, http.StatusConflict)
+	if err != nil {
+		return "", err
+	}
+
+	shortURL := strings.TrimSpace(resp.String())
+	if resp.StatusCode() == http.StatusConflict {
+		return shortURL, &Error{StatusCode: resp.StatusCode(), Message: "url already shortened"}
+	}
+	return shortURL, nil
+}
+
+// ShortenJSON сокращает ссылку с дополнительными полями через POST /api/shorten.
+// Если адрес уже сокращен, возвращает прежнюю сокращенную ссылку вместе с ошибкой ErrConflict.
+func (c *Client) ShortenJSON(ctx context.Context, link ShortenRequest) (string, error) {
+	req, err := setJSON(c.request(ctx), link)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Result string `json:"result"`
+	}
+	resp, err := do(req, http.MethodPost, "/api/shorten", &result, http.StatusCreated, http.StatusConflict)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode() == http.StatusConflict {
+		return result.Result, &Error{StatusCode: resp.StatusCode(), Message: "url already shortened"}
+	}
+	return result.Result, nil
+}
+
+// ShortenBatch сокращает несколько ссылок одним запросом POST /api/shorten/batch
+func (c *Client) ShortenBatch(ctx context.Context, links []BatchRequest) ([]BatchResult, error) {
+	req, err := setJSON(c.request(ctx), links)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []BatchResult
+	if _, err = do(req, http.MethodPost, "/api/shorten/batch", &result, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Expand - куда ведет сокращенная ссылка; переход засчитывается, как у посетителя.
+// shortURL - сокращенная ссылка или только сокращение. Удаленная или истекшая ссылка
+// возвращает ErrGone, защищенная паролем - ErrUnauthorized, ссылка с промежуточной
+// страницей - ErrInterstitial.
+func (c *Client) Expand(ctx context.Context, shortURL string) (Redirect, error) {
+	return c.expand(c.request(ctx), shortURL)
+}
+
+// ExpandProtected - Expand для ссылки, защищенной паролем. Неверный пароль возвращает
+// ErrUnauthorized, частые ошибки - ErrRateLimited.
+func (c *Client) ExpandProtected(ctx context.Context, shortURL, password string) (Redirect, error) {
+	return c.expand(c.request(ctx).SetHeader(passwordHeader, password), shortURL)
+}
+
+func (c *Client) expand(req *resty.Request, shortURL string) (Redirect, error) {
+	resp, err := do(req, http.MethodGet, "/"+segment(shortURL), nil,
+		http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect)
+	if err != nil {
+		return Redirect{}, err
+	}
+
+	return Redirect{
+		URL:        resp.Header().Get("Location"),
+		StatusCode: resp.StatusCode(),
+	}, nil
+}
+
+// Ping проверяет доступность хранилища сервиса.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := do(c.request(ctx), http.MethodGet, "/ping", nil, http.StatusOK)
+	return err
+}
@@ -0,0 +1,96 @@
+package client
+
+import "github.com/sonikq/url-shortener/internal/app/models/user"
+
+// Типы запросов и ответов API. Это псевдонимы моделей сервиса, поэтому клиент не расходится
+// с сервером при добавлении полей.
+type (
+	// ShortenRequest - ссылка для POST /api/shorten
+	ShortenRequest = user.ShortenLinkJSONRequestBody
+	// RoutingRule - правило выбора адреса по платформе, языку или источнику перехода
+	RoutingRule = user.RoutingRule
+	// Variant - вариант A/B-теста
+	Variant = user.Variant
+	// BatchRequest - ссылка пакетного сокращения
+	BatchRequest = user.BatchUrlsInput
+	// BatchResult - результат пакетного сокращения
+	BatchResult = user.BatchUrlsOutput
+	// Link - ссылка пользователя
+	Link = user.BatchByUserID
+	// UpdateRequest - изменяемые поля ссылки, nil - поле не меняется
+	UpdateRequest = user.UpdateLinkRequestBody
+	// UpdatedLink - ссылка после изменения или восстановления
+	UpdatedLink = user.UpdatedLink
+	// Revision - прежний original_url ссылки
+	Revision = user.LinkRevision
+	// LinkStats - счетчики переходов по ссылке
+	LinkStats = user.LinkStats
+	// DeletedLink - удаленная ссылка, которую еще можно восстановить
+	DeletedLink = user.DeletedLink
+	// DeleteJob - задание на удаление ссылок
+	DeleteJob = user.DeleteJob
+	// Collection - коллекция ссылок
+	Collection = user.Collection
+	// Webhook - подписка на события ссылок
+	Webhook = user.Webhook
+	// WebhookRequest - новая подписка на события ссылок
+	WebhookRequest = user.CreateWebhookRequestBody
+	// WebhookDelivery - запись журнала доставок подписки
+	WebhookDelivery = user.WebhookDelivery
+	// ImportReport - итог импорта ссылок
+	ImportReport = user.ImportReport
+	// Stats - количество ссылок и пользователей сервиса
+	Stats = user.StatsBody
+	// ScheduledJob - фоновая задача планировщика
+	ScheduledJob = user.ScheduledJob
+	// JobRun - запуск фоновой задачи
+	JobRun = user.JobRun
+)
+
+// Redirect - куда ведет сокращенная ссылка
+type Redirect struct {
+	URL        string
+	StatusCode int // 301, 302, 303, 307 или 308
+
+	// Interstitial - посетитель увидит промежуточную страницу. Заполняет только GRPCClient,
+	// Client для таких ссылок возвращает ErrInterstitial.
+	Interstitial bool
+}
+
+// ListOptions - параметры выборки ссылок пользователя, пустые поля не передаются
+type ListOptions struct {
+	Limit      int
+	Cursor     string
+	Sort       string // created_at или -created_at
+	Search     string // подстрока original_url
+	Tag        string
+	Collection string
+	Deleted    string // all, active или deleted
+}
+
+// LinkPage - страница ссылок пользователя
+type LinkPage struct {
+	Links      []Link
+	NextCursor string // пустой - страница последняя
+}
+
+// QROptions - параметры QR-кода, пустые поля заменяются значениями сервиса по умолчанию
+type QROptions struct {
+	Format string // png или svg
+	Size   int
+	Margin *int
+	Level  string // L, M, Q или H
+	Logo   bool
+}
+
+// QRCode - изображение QR-кода
+type QRCode struct {
+	ContentType string
+	Image       []byte
+}
+
+// ManualRun - принятый внеочередной запуск фоновой задачи
+type ManualRun struct {
+	Job     string `json:"job"`
+	Trigger string `json:"trigger"`
+}
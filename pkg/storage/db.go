@@ -7,18 +7,24 @@ import (
 	"fmt"
 	"github.com/sonikq/url-shortener/internal/app/models"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type dbStorage struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	dedup DedupScope
 }
 
-func newDB(ctx context.Context, dsn string, dbPoolWorkers int) (*dbStorage, error) {
+// newDB подключается к БД и обновляет схему до текущей версии (см. Migrate). Данные не удаляются:
+// ссылки, очереди заданий и событий и история задач переживают перезапуск и общие для всех экземпляров.
+func newDB(ctx context.Context, dsn string, dbPoolWorkers int, dedup DedupScope) (*dbStorage, error) {
 	t1 := time.Now()
 	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
@@ -31,23 +37,112 @@ func newDB(ctx context.Context, dsn string, dbPoolWorkers int) (*dbStorage, erro
 		return nil, err
 	}
 
-	if err = dropTable(ctx, pool); err != nil {
+	migration, err := migrate(ctx, pool, dedup)
+	if err != nil {
 		return nil, err
 	}
+	if migration.Applied() {
+		log.Printf("database schema migrated from version %d to %d\n", migration.From, migration.To)
+	}
 
-	if err = createTable(ctx, pool); err != nil {
-		return nil, err
+	log.Printf("connection to database took: %v\n", time.Since(t1))
+
+	return &dbStorage{pool: pool, dedup: dedup}, nil
+}
+
+// migrations - шаги обновления схемы БД: migrations[i] переводит схему из версии i в i+1.
+// Изменение схемы добавляется новым шагом в конец списка.
+var migrations = []func(ctx context.Context, tx execer) error{
+	// 1: таблицы, колонки и индексы, в том числе для таблицы urls первых версий сервиса.
+	createTable,
+	// 2: уникальный индекс original_url первых версий учитывает и удаленные ссылки, а под тем
+	// же именем частичный индекс не создается; createIndex пересоздаст его после удаления.
+	func(ctx context.Context, tx execer) error {
+		_, err := tx.Exec(ctx, dropOriginalURLIndexQuery)
+		return err
+	},
+}
+
+// schemaVersion - версия схемы БД после всех шагов migrations
+var schemaVersion = len(migrations)
+
+// ErrSchemaTooNew - схема БД обновлена более новой версией сервиса
+var ErrSchemaTooNew = errors.New("database schema is newer than this version supports")
+
+// Migration - результат обновления схемы БД
+type Migration struct {
+	From int // версия до обновления, 0 - схема не создавалась или создана до учета версий
+	To   int
+}
+
+// Applied - схема была создана или обновлена
+func (m Migration) Applied() bool {
+	return m.From != m.To
+}
+
+// Migrate подключается к БД, выполняет недостающие шаги migrations и создает индекс области
+// дедупликации dedup. Сервис делает то же при подключении, Migrate позволяет обновить схему
+// заранее, до запуска новой версии.
+func Migrate(ctx context.Context, dsn string, dedup DedupScope) (Migration, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return Migration{}, err
 	}
+	defer pool.Close()
 
-	if err = createIndex(ctx, pool); err != nil {
-		return nil, err
+	return migrate(ctx, pool, dedup)
+}
+
+// migrate обновляет схему в одной транзакции под advisory-блокировкой, поэтому одновременно
+// запущенные экземпляры сервиса не изменяют схему параллельно.
+func migrate(ctx context.Context, pool *pgxpool.Pool, dedup DedupScope) (Migration, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return Migration{}, fmt.Errorf("error while begin transaction: %w", err)
 	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	log.Printf("connection to database took: %v\n", time.Since(t1))
+	for _, query := range []string{lockSchemaQuery, createSchemaVersionTableQuery} {
+		if _, err = tx.Exec(ctx, query); err != nil {
+			return Migration{}, err
+		}
+	}
 
-	return &dbStorage{pool: pool}, nil
+	var migration Migration
+	if err = tx.QueryRow(ctx, getSchemaVersion).Scan(&migration.From); err != nil {
+		return Migration{}, err
+	}
+	if migration.From > schemaVersion {
+		return Migration{}, fmt.Errorf("%w: version %d, supported %d", ErrSchemaTooNew, migration.From, schemaVersion)
+	}
+	migration.To = schemaVersion
+
+	for _, step := range migrations[migration.From:] {
+		if err = step(ctx, tx); err != nil {
+			return Migration{}, err
+		}
+	}
+	if migration.Applied() {
+		if _, err = tx.Exec(ctx, setSchemaVersion, schemaVersion); err != nil {
+			return Migration{}, err
+		}
+	}
+
+	if err = createIndex(ctx, tx, dedup); err != nil {
+		return Migration{}, err
+	}
+
+	return migration, tx.Commit(ctx)
+}
+
+// execer - пул соединений или транзакция
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 }
 
+// dropTable удаляет все таблицы вместе с данными; нужна тестам для чистой схемы.
 func dropTable(ctx context.Context, pool *pgxpool.Pool) error {
 	_, err := pool.Exec(ctx, preCreateTableQuery)
 	if err != nil {
@@ -57,26 +152,67 @@ func dropTable(ctx context.Context, pool *pgxpool.Pool) error {
 	return nil
 }
 
-func createTable(ctx context.Context, pool *pgxpool.Pool) error {
-	_, err := pool.Exec(ctx, createTableQuery)
-	if err != nil {
-		return err
+func createTable(ctx context.Context, pool execer) error {
+	for _, query := range []string{
+		createTableQuery,
+		createRevisionsTableQuery,
+		createTagsTableQuery,
+		createCollectionsTableQuery,
+		createWebhooksTableQuery,
+		createDeliveriesTableQuery,
+		createOutboxTableQuery,
+		createDeleteJobsTableQuery,
+		createJobRunsTableQuery,
+		upgradeTableQuery,
+		createUserCreatedIndexQuery,
+		createUserCollectionIndexQuery,
+		createTagIndexQuery,
+		createWebhookUserIndexQuery,
+		createDeliveryDueIndexQuery,
+		createDeliveryWebhookIndexQuery,
+		createDeleteJobDueIndexQuery,
+		createJobRunsIndexQuery,
+	} {
+		_, err := pool.Exec(ctx, query)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func createIndex(ctx context.Context, pool *pgxpool.Pool) error {
-	_, err := pool.Exec(ctx, createOriginalURLIndexQuery)
-	if err != nil {
-		return err
+// createIndex создает уникальный индекс области дедупликации и удаляет индексы других областей,
+// оставшиеся от запуска с другой настройкой.
+func createIndex(ctx context.Context, pool execer, dedup DedupScope) error {
+	queries := []string{dropUserOriginalURLIndexQuery, createOriginalURLIndexQuery}
+	switch dedup {
+	case DedupPerUser:
+		queries = []string{dropOriginalURLIndexQuery, createUserOriginalURLIndexQuery}
+	case DedupNone:
+		queries = []string{dropOriginalURLIndexQuery, dropUserOriginalURLIndexQuery}
+	}
+
+	for _, query := range queries {
+		_, err := pool.Exec(ctx, query)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// Set -
+// Set - сохраняет ссылки, для новых в той же транзакции пишет в outbox link.created
 func (c *dbStorage) Set(ctx context.Context, data map[string]Item) error {
+	return c.set(ctx, data, true)
+}
+
+func (c *dbStorage) restore(ctx context.Context, data map[string]Item) error {
+	return c.set(ctx, data, false)
+}
+
+func (c *dbStorage) set(ctx context.Context, data map[string]Item, emit bool) error {
 	if len(data) == 0 {
 		return nil
 	}
@@ -91,8 +227,15 @@ func (c *dbStorage) Set(ctx context.Context, data map[string]Item) error {
 		}
 	}()
 
-	for key, item := range data {
-		_, err = tx.Exec(ctx, setNewValueInDB, item.Object, key, item.UserID)
+	for _, key := range sortedKeys(data) {
+		item := data[key]
+
+		// xmax = 0 только у вставленной строки: для уже существующего alias событие не пишется.
+		var inserted bool
+		err = tx.QueryRow(ctx, setNewValueInDB, item.Object, key, item.UserID, nullTime(item.CreatedAt),
+			item.Title, item.Note, item.Collection, item.RedirectCode, item.ForwardQuery, item.ForwardPath, item.PasswordHash,
+			item.MaxClicks, item.Clicks, nullTime(item.ActiveFrom), rulesJSON(item.Rules),
+			variantsJSON(item.Variants), item.Sticky, item.Interstitial, item.BlockedReason).Scan(&inserted)
 		if err != nil {
 			var pgErr *pgconn.PgError
 			if errors.As(err, &pgErr) {
@@ -102,17 +245,78 @@ func (c *dbStorage) Set(ctx context.Context, data map[string]Item) error {
 			}
 			return err
 		}
+
+		if err = setLinkGroups(ctx, tx, key, item); err != nil {
+			return err
+		}
+
+		if emit && inserted {
+			event := NewOutboxEvent(EventLinkCreated, item.UserID, EventLink{
+				Alias:       key,
+				OriginalURL: item.Object,
+				MaxClicks:   item.MaxClicks,
+			})
+			if err = insertOutbox(ctx, tx, event); err != nil {
+				return err
+			}
+		}
 	}
 
 	return tx.Commit(ctx)
 }
 
-// DeleteBatch -
-func (c *dbStorage) DeleteBatch(ctx context.Context, urls []string, userID string) error {
+// ImportLinks - добавляет перенесенные из другой системы ссылки одной транзакцией, не изменяя существующие
+func (c *dbStorage) ImportLinks(ctx context.Context, records []Record) ([]error, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
 	tx, err := c.pool.Begin(ctx)
 	if err != nil {
+		return nil, fmt.Errorf("error while begin transaction: %w", err)
+	}
+	defer func() {
+		if errRollBack := tx.Rollback(ctx); errRollBack != nil && !errors.Is(errRollBack, pgx.ErrTxClosed) {
+			fmt.Printf("rollback error: %v", errRollBack)
+		}
+	}()
+
+	results := make([]error, len(records))
+	for i, record := range records {
+		item := record.Item
 
-		return fmt.Errorf("error while begin transaction: %s", err.Error())
+		var inserted bool
+		err = tx.QueryRow(ctx, importLink, item.Object, record.Alias, item.UserID, nullTime(item.CreatedAt),
+			item.Title, item.Note, item.Collection, item.RedirectCode, item.MaxClicks, item.Clicks).Scan(&inserted)
+		if errors.Is(err, pgx.ErrNoRows) {
+			var taken bool
+			if err = tx.QueryRow(ctx, linkExists, record.Alias).Scan(&taken); err != nil {
+				return nil, err
+			}
+			results[i] = models.ErrAlreadyExists
+			if taken {
+				results[i] = models.ErrAliasTaken
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err = setLinkGroups(ctx, tx, record.Alias, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, tx.Commit(ctx)
+}
+
+// DeleteBatch - помечает удаленными ссылки пользователя, возвращает фактически удаленные
+func (c *dbStorage) DeleteBatch(ctx context.Context, urls []string, userID string) ([]string, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+
+		return nil, fmt.Errorf("error while begin transaction: %s", err.Error())
 	}
 	defer func() {
 		if errRollBack := tx.Rollback(ctx); errRollBack != nil {
@@ -120,61 +324,257 @@ func (c *dbStorage) DeleteBatch(ctx context.Context, urls []string, userID strin
 		}
 	}()
 
+	var deleted []string
 	for _, value := range urls {
-		_, err = tx.Exec(ctx, setDeleteBatch, value, userID)
+		var alias, originalURL string
+		err = tx.QueryRow(ctx, setDeleteBatch, value, userID).Scan(&alias, &originalURL)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("cant execute db command: %s", err.Error())
+			return nil, fmt.Errorf("cant execute db command: %s", err.Error())
+		}
+		deleted = append(deleted, alias)
+
+		event := NewOutboxEvent(EventLinkDeleted, userID, EventLink{Alias: alias, OriginalURL: originalURL})
+		if err = insertOutbox(ctx, tx, event); err != nil {
+			return nil, err
 		}
 	}
-	return tx.Commit(ctx)
+	return deleted, tx.Commit(ctx)
 }
 
 // GetBatchByUserID -
-func (c *dbStorage) GetBatchByUserID(ctx context.Context, userID string) (map[string]Item, error) {
-	batch := make(map[string]Item)
+func (c *dbStorage) GetBatchByUserID(ctx context.Context, userID string, query BatchQuery) ([]Record, error) {
+	sqlQuery, args := buildBatchQuery(userID, query)
 
-	rows, err := c.pool.Query(ctx, getBatchByUserID, userID)
+	rows, err := c.pool.Query(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
+	var batch []Record
 	for rows.Next() {
-		var originalURL, shortURL string
-		err = rows.Scan(&originalURL, &shortURL)
+		var record Record
+		record.Item, err = scanItem(rows, &record.Alias)
 		if err != nil {
 			return nil, err
 		}
-		batch[shortURL] = Item{
-			Object: originalURL,
-			UserID: userID,
-		}
+		batch = append(batch, record)
 	}
-	return batch, nil
+
+	return batch, rows.Err()
 }
 
+// buildBatchQuery собирает запрос выборки ссылок пользователя с keyset-пагинацией по (created_at, short_url).
+func buildBatchQuery(userID string, query BatchQuery) (string, []any) {
+	var sb strings.Builder
+	args := []any{userID}
+
+	sb.WriteString(getBatchByUserID)
+
+	switch query.Deleted {
+	case DeletedExclude:
+		sb.WriteString(" AND is_deleted = false")
+	case DeletedOnly:
+		sb.WriteString(" AND is_deleted = true")
+	}
+
+	if query.Search != "" {
+		args = append(args, "%"+likeEscaper.Replace(query.Search)+"%")
+		fmt.Fprintf(&sb, " AND original_url ILIKE $%d", len(args))
+	}
+
+	order, cmp := "ASC", ">"
+	if query.Desc {
+		order, cmp = "DESC", "<"
+	}
+
+	if query.Tag != "" {
+		args = append(args, query.Tag)
+		fmt.Fprintf(&sb, " AND EXISTS (SELECT 1 FROM url_tags WHERE url_tags.short_url = urls.short_url AND tag = $%d)", len(args))
+	}
+
+	if query.Collection != "" {
+		args = append(args, query.Collection)
+		fmt.Fprintf(&sb, " AND collection = $%d", len(args))
+	}
+
+	if query.After != nil {
+		args = append(args, query.After.CreatedAt, query.After.Alias)
+		fmt.Fprintf(&sb, " AND (created_at, short_url) %s ($%d, $%d)", cmp, len(args)-1, len(args))
+	}
+
+	fmt.Fprintf(&sb, " ORDER BY created_at %s, short_url %s", order, order)
+
+	if query.Limit > 0 {
+		args = append(args, query.Limit)
+		fmt.Fprintf(&sb, " LIMIT $%d", len(args))
+	}
+
+	return sb.String(), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // Get -
-func (c *dbStorage) Get(ctx context.Context, alias string) (string, error) {
-	var originalURL string
-	var isDeleted bool
-	if err := c.pool.QueryRow(ctx, getOriginalURL, alias).Scan(&originalURL, &isDeleted); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", nil
+func (c *dbStorage) Get(ctx context.Context, alias string) (Item, error) {
+	item, err := scanItem(c.pool.QueryRow(ctx, getLink, alias))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, models.ErrLinkNotFound
 		}
-		return "", err
+		return Item{}, err
+	}
+
+	if item.IsDeleted {
+		return Item{}, models.ErrGetDeletedLink
+	}
+
+	return item, nil
+}
+
+// ConsumeClick - атомарно учитывает переход; условие в UPDATE не дает превысить лимит
+// при одновременных переходах
+func (c *dbStorage) ConsumeClick(ctx context.Context, alias string) (Item, error) {
+	item, err := scanItem(c.pool.QueryRow(ctx, consumeClick, alias))
+	if err == nil {
+		return item, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Item{}, err
 	}
 
-	if isDeleted {
-		return "", models.ErrGetDeletedLink
+	// Ничего не обновлено: выясняем причину.
+	item, err = scanItem(c.pool.QueryRow(ctx, getLink, alias))
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return Item{}, models.ErrLinkNotFound
+	case err != nil:
+		return Item{}, err
+	case item.IsDeleted:
+		return Item{}, models.ErrGetDeletedLink
+	default:
+		return Item{}, models.ErrClicksExhausted
+	}
+}
+
+// RecordVariant - учитывает переход по варианту A/B-теста, счетчик хранится в variants
+func (c *dbStorage) RecordVariant(ctx context.Context, alias string, variant int) (Item, error) {
+	item, err := scanItem(c.pool.QueryRow(ctx, recordVariant, alias, variant))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, fmt.Errorf("link %s has no variant %d", alias, variant)
+		}
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// ScanLinks - неудаленные ссылки по возрастанию alias, начиная после after
+func (c *dbStorage) ScanLinks(ctx context.Context, after string, limit int) ([]Record, error) {
+	rows, err := c.pool.Query(ctx, scanLinks, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []Record
+	for rows.Next() {
+		var record Record
+		record.Item, err = scanItem(rows, &record.Alias)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, record)
 	}
 
-	return originalURL, nil
+	return batch, rows.Err()
+}
+
+// SetBlocked - блокирует ссылку с причиной reason, пустая причина снимает блокировку
+func (c *dbStorage) SetBlocked(ctx context.Context, alias, reason string) (Item, error) {
+	item, err := scanItem(c.pool.QueryRow(ctx, setBlocked, alias, reason))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, models.ErrLinkNotFound
+		}
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// Lookup - ссылка в любом состоянии, в том числе удаленная
+func (c *dbStorage) Lookup(ctx context.Context, alias string) (Item, error) {
+	item, err := scanItem(c.pool.QueryRow(ctx, getLink, alias))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, models.ErrLinkNotFound
+		}
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// TransferLinks - передает ссылки пользователя from пользователю to: перечисленные в aliases
+// или все, если список пуст. Возвращает переданные сокращения; чужие и несуществующие пропускаются.
+func (c *dbStorage) TransferLinks(ctx context.Context, from, to string, aliases []string) ([]string, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while begin transaction: %w", err)
+	}
+	defer func() {
+		if errRollBack := tx.Rollback(ctx); errRollBack != nil && !errors.Is(errRollBack, pgx.ErrTxClosed) {
+			fmt.Printf("rollback error: %v", errRollBack)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, transferLinks, from, to, aliases)
+	if err != nil {
+		return nil, err
+	}
+	var moved []string
+	for rows.Next() {
+		var alias string
+		if err = rows.Scan(&alias); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		moved = append(moved, alias)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return nil, models.ErrAlreadyExists
+		}
+		return nil, err
+	}
+
+	if _, err = tx.Exec(ctx, transferCollections, to, moved); err != nil {
+		return nil, err
+	}
+	sort.Strings(moved)
+
+	return moved, tx.Commit(ctx)
 }
 
 // GetShortURL -
-func (c *dbStorage) GetShortURL(ctx context.Context, originalURL string) (string, error) {
+func (c *dbStorage) GetShortURL(ctx context.Context, originalURL, userID string) (string, error) {
+	var row pgx.Row
+	switch c.dedup {
+	case DedupPerUser:
+		row = c.pool.QueryRow(ctx, getShortURLByUser, originalURL, userID)
+	case DedupNone:
+		return "", nil
+	default:
+		row = c.pool.QueryRow(ctx, getShortURL, originalURL)
+	}
+
 	var shortURL string
-	if err := c.pool.QueryRow(ctx, getShortURL, originalURL).Scan(&shortURL); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+	if err := row.Scan(&shortURL); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return "", nil
 		}
 		return "", err
@@ -183,6 +583,567 @@ func (c *dbStorage) GetShortURL(ctx context.Context, originalURL string) (string
 	return shortURL, nil
 }
 
+// UpdateLink - изменяет ссылку владельца, сохраняя прежний original_url в истории
+func (c *dbStorage) UpdateLink(ctx context.Context, alias, userID string, update LinkUpdate) (Item, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return Item{}, fmt.Errorf("error while begin transaction: %w", err)
+	}
+	defer func() {
+		if errRollBack := tx.Rollback(ctx); errRollBack != nil && !errors.Is(errRollBack, pgx.ErrTxClosed) {
+			fmt.Printf("rollback error: %v", errRollBack)
+		}
+	}()
+
+	item, err := scanItem(tx.QueryRow(ctx, getLinkForUpdate, alias))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, models.ErrLinkNotFound
+		}
+		return Item{}, err
+	}
+	if item.UserID != userID {
+		return Item{}, models.ErrNotOwner
+	}
+	if item.IsDeleted {
+		return Item{}, models.ErrGetDeletedLink
+	}
+
+	updated := update.Apply(item)
+	updated.UpdatedAt = time.Now()
+	if updated.Object != item.Object {
+		if _, err = tx.Exec(ctx, addRevision, alias, item.Object); err != nil {
+			return Item{}, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, updateLink, alias, updated.Object, updated.Title, updated.Note, updated.UpdatedAt,
+		updated.Collection, updated.RedirectCode, updated.ForwardQuery, updated.ForwardPath, updated.PasswordHash,
+		updated.MaxClicks, nullTime(updated.ActiveFrom), rulesJSON(updated.Rules),
+		variantsJSON(updated.Variants), updated.Sticky, updated.Interstitial)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return Item{}, models.ErrAlreadyExists
+		}
+		return Item{}, err
+	}
+
+	if update.Tags != nil {
+		if _, err = tx.Exec(ctx, removeTags, alias); err != nil {
+			return Item{}, err
+		}
+	}
+	if err = setLinkGroups(ctx, tx, alias, updated); err != nil {
+		return Item{}, err
+	}
+
+	return updated, tx.Commit(ctx)
+}
+
+// GetRevisions - история original_url ссылки, от новых к старым
+func (c *dbStorage) GetRevisions(ctx context.Context, alias, userID string) ([]Revision, error) {
+	var owner string
+	if err := c.pool.QueryRow(ctx, getLinkOwner, alias).Scan(&owner); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrLinkNotFound
+		}
+		return nil, err
+	}
+	if owner != userID {
+		return nil, models.ErrNotOwner
+	}
+
+	rows, err := c.pool.Query(ctx, getRevisions, alias)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]Revision, 0)
+	for rows.Next() {
+		var revision Revision
+		if err = rows.Scan(&revision.ID, &revision.Object, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// RestoreDeleted - снимает пометку удаления, если ссылка удалена не раньше deletedAfter
+func (c *dbStorage) RestoreDeleted(ctx context.Context, alias, userID string, deletedAfter time.Time) (Item, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return Item{}, fmt.Errorf("error while begin transaction: %w", err)
+	}
+	defer func() {
+		if errRollBack := tx.Rollback(ctx); errRollBack != nil && !errors.Is(errRollBack, pgx.ErrTxClosed) {
+			fmt.Printf("rollback error: %v", errRollBack)
+		}
+	}()
+
+	item, err := scanItem(tx.QueryRow(ctx, getLinkForUpdate, alias))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Item{}, models.ErrLinkNotFound
+		}
+		return Item{}, err
+	}
+	if item.UserID != userID {
+		return Item{}, models.ErrNotOwner
+	}
+	if !item.IsDeleted {
+		return item, nil
+	}
+	if item.DeletedAt.Before(deletedAfter) {
+		return Item{}, models.ErrRestorePeriodExpired
+	}
+
+	item.IsDeleted = false
+	item.DeletedAt = time.Time{}
+	item.UpdatedAt = time.Now()
+	if _, err = tx.Exec(ctx, restoreDeleted, alias, item.UpdatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return Item{}, models.ErrAlreadyExists
+		}
+		return Item{}, err
+	}
+
+	if item.Collection != "" {
+		if _, err = tx.Exec(ctx, upsertCollection, item.UserID, item.Collection, nil); err != nil {
+			return Item{}, err
+		}
+	}
+
+	return item, tx.Commit(ctx)
+}
+
+// PurgeDeleted - окончательно удаляет ссылки, помеченные удаленными раньше deletedBefore
+func (c *dbStorage) PurgeDeleted(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while begin transaction: %w", err)
+	}
+	defer func() {
+		if errRollBack := tx.Rollback(ctx); errRollBack != nil && !errors.Is(errRollBack, pgx.ErrTxClosed) {
+			fmt.Printf("rollback error: %v", errRollBack)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, purgeDeleted, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	purged, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(purged) > 0 {
+		for _, query := range []string{purgeRevisions, purgeTags} {
+			if _, err = tx.Exec(ctx, query, purged); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return purged, tx.Commit(ctx)
+}
+
+// CreateCollection - создает пустую коллекцию пользователя
+func (c *dbStorage) CreateCollection(ctx context.Context, collection Collection) error {
+	_, err := c.pool.Exec(ctx, createCollection, collection.UserID, collection.Name, nullTime(collection.CreatedAt))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return models.ErrAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetCollections - коллекции пользователя с количеством неудаленных ссылок, по имени
+func (c *dbStorage) GetCollections(ctx context.Context, userID string) ([]Collection, error) {
+	rows, err := c.pool.Query(ctx, getCollections, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := make([]Collection, 0)
+	for rows.Next() {
+		collection := Collection{UserID: userID}
+		if err = rows.Scan(&collection.Name, &collection.CreatedAt, &collection.Links); err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, rows.Err()
+}
+
+// DeleteCollection - удаляет коллекцию, сами ссылки не затрагиваются
+func (c *dbStorage) DeleteCollection(ctx context.Context, userID, name string) error {
+	tag, err := c.pool.Exec(ctx, deleteCollection, userID, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+// CreateWebhook - сохраняет подписку пользователя
+func (c *dbStorage) CreateWebhook(ctx context.Context, webhook Webhook) error {
+	_, err := c.pool.Exec(ctx, createWebhook, webhook.ID, webhook.UserID, webhook.URL, webhook.Secret, webhook.Events,
+		nullTime(webhook.CreatedAt))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return models.ErrAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhooks - подписки пользователя в порядке создания
+func (c *dbStorage) GetWebhooks(ctx context.Context, userID string) ([]Webhook, error) {
+	rows, err := c.pool.Query(ctx, getWebhooks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]Webhook, 0)
+	for rows.Next() {
+		webhook := Webhook{UserID: userID}
+		if err = rows.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &webhook.Events, &webhook.CreatedAt); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook - удаляет подписку, журнал ее доставок удаляется каскадно
+func (c *dbStorage) DeleteWebhook(ctx context.Context, userID, id string) error {
+	tag, err := c.pool.Exec(ctx, deleteWebhook, userID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// AddDeliveries - ставит доставки в очередь, без NextAttemptAt первая попытка - сразу
+func (c *dbStorage) AddDeliveries(ctx context.Context, deliveries []Delivery) error {
+	batch := &pgx.Batch{}
+	for _, delivery := range deliveries {
+		batch.Queue(addDelivery, delivery.WebhookID, delivery.UserID, delivery.Event, delivery.Payload,
+			nullTime(delivery.NextAttemptAt))
+	}
+
+	return c.pool.SendBatch(ctx, batch).Close()
+}
+
+// ClaimDeliveries - забирает до limit доставок, время попытки которых наступило, и откладывает их
+// на lease, чтобы их не взял другой экземпляр сервиса
+func (c *dbStorage) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	rows, err := c.pool.Query(ctx, claimDeliveries, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.URL, delivery.Secret = url, secret
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery - сохраняет результат попытки доставки
+func (c *dbStorage) UpdateDelivery(ctx context.Context, delivery Delivery) error {
+	tag, err := c.pool.Exec(ctx, updateDelivery, delivery.ID, delivery.Status, delivery.Attempts, delivery.LastError,
+		delivery.ResponseCode, delivery.NextAttemptAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// GetDeliveries - журнал доставок подписки от новых к старым, status фильтрует по статусу
+func (c *dbStorage) GetDeliveries(ctx context.Context, userID, webhookID, status string, limit int) ([]Delivery, error) {
+	var owner string
+	err := c.pool.QueryRow(ctx, getWebhookOwner, webhookID).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && owner != userID) {
+		return nil, models.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.pool.Query(ctx, getDeliveries, userID, webhookID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]Delivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RetryDelivery - возвращает доставку в очередь с обнуленным счетчиком попыток
+func (c *dbStorage) RetryDelivery(ctx context.Context, userID, webhookID string, id int64) (Delivery, error) {
+	delivery, err := scanDelivery(c.pool.QueryRow(ctx, retryDelivery, id, webhookID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Delivery{}, models.ErrDeliveryNotFound
+	}
+
+	return delivery, err
+}
+
+// AddOutbox - ставит события в очередь на публикацию
+func (c *dbStorage) AddOutbox(ctx context.Context, events []OutboxEvent) error {
+	batch := &pgx.Batch{}
+	for _, event := range events {
+		batch.Queue(addOutbox, event.EventID, event.Type, event.UserID, event.Link, event.CreatedAt)
+	}
+
+	return c.pool.SendBatch(ctx, batch).Close()
+}
+
+// ClaimOutbox - забирает до limit событий по порядку и откладывает их на lease,
+// чтобы их не взял другой экземпляр сервиса
+func (c *dbStorage) ClaimOutbox(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxEvent, error) {
+	rows, err := c.pool.Query(ctx, claimOutbox, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []OutboxEvent
+	for rows.Next() {
+		var event OutboxEvent
+		if err = rows.Scan(&event.ID, &event.EventID, &event.Type, &event.UserID, &event.Link, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса.
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+
+	return events, nil
+}
+
+// AckOutbox - удаляет опубликованные события
+func (c *dbStorage) AckOutbox(ctx context.Context, ids []int64) error {
+	_, err := c.pool.Exec(ctx, ackOutbox, ids)
+	return err
+}
+
+// AddDeleteJob - ставит задание на удаление в очередь, без NextAttemptAt первая попытка - сразу
+func (c *dbStorage) AddDeleteJob(ctx context.Context, job DeleteJob) error {
+	_, err := c.pool.Exec(ctx, addDeleteJob, job.ID, job.UserID, job.URLs, nullTime(job.CreatedAt),
+		nullTime(job.NextAttemptAt))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return models.ErrAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ClaimDeleteJobs - забирает до limit заданий, время попытки которых наступило, и откладывает их
+// на lease, чтобы их не взял другой экземпляр сервиса
+func (c *dbStorage) ClaimDeleteJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DeleteJob, error) {
+	rows, err := c.pool.Query(ctx, claimDeleteJobs, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []DeleteJob
+	for rows.Next() {
+		job, err := scanDeleteJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса.
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+
+	return jobs, nil
+}
+
+// UpdateDeleteJobs - сохраняет результат попытки выполнения заданий
+func (c *dbStorage) UpdateDeleteJobs(ctx context.Context, jobs []DeleteJob) error {
+	batch := &pgx.Batch{}
+	for _, job := range jobs {
+		batch.Queue(updateDeleteJob, job.ID, job.Status, job.Attempts, job.LastError, job.NextAttemptAt)
+	}
+
+	results := c.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range jobs {
+		tag, err := results.Exec()
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return models.ErrJobNotFound
+		}
+	}
+
+	return results.Close()
+}
+
+// GetDeleteJob - задание на удаление, созданное пользователем
+func (c *dbStorage) GetDeleteJob(ctx context.Context, userID, id string) (DeleteJob, error) {
+	job, err := scanDeleteJob(c.pool.QueryRow(ctx, getDeleteJob, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DeleteJob{}, models.ErrJobNotFound
+	}
+
+	return job, err
+}
+
+// PurgeDeleteJobs - удаляет завершенные задания, последний раз изменявшиеся раньше finishedBefore
+func (c *dbStorage) PurgeDeleteJobs(ctx context.Context, finishedBefore time.Time) (int, error) {
+	tag, err := c.pool.Exec(ctx, purgeDeleteJobs, finishedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// TryLock - берет advisory-блокировку Postgres без ожидания, так что задачу выполняет только
+// один экземпляр сервиса. Блокировка держится на отдельном соединении до вызова unlock.
+func (c *dbStorage) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired bool
+	if err = conn.QueryRow(ctx, tryAdvisoryLock, name).Scan(&acquired); err != nil || !acquired {
+		conn.Release()
+		return nil, false, err
+	}
+
+	return func() {
+		if _, err := conn.Exec(context.Background(), advisoryUnlock, name); err != nil {
+			// Блокировка снимается вместе с сессией, соединение в пул не возвращается.
+			_ = conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}, true, nil
+}
+
+// AddJobRun - сохраняет запуск фоновой задачи, хранятся последние maxJobRuns запусков задачи
+func (c *dbStorage) AddJobRun(ctx context.Context, run JobRun) error {
+	batch := &pgx.Batch{}
+	batch.Queue(addJobRun, run.Job, run.Trigger, run.Status, run.Processed, run.Error, run.StartedAt, run.FinishedAt)
+	batch.Queue(trimJobRuns, run.Job, maxJobRuns)
+
+	return c.pool.SendBatch(ctx, batch).Close()
+}
+
+// GetJobRuns - последние запуски фоновой задачи от новых к старым
+func (c *dbStorage) GetJobRuns(ctx context.Context, job string, limit int) ([]JobRun, error) {
+	rows, err := c.pool.Query(ctx, getJobRuns, job, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]JobRun, 0)
+	for rows.Next() {
+		var run JobRun
+		err = rows.Scan(&run.ID, &run.Job, &run.Trigger, &run.Status, &run.Processed, &run.Error, &run.StartedAt,
+			&run.FinishedAt)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// insertOutbox пишет событие в транзакции изменения ссылки.
+func insertOutbox(ctx context.Context, tx pgx.Tx, event OutboxEvent) error {
+	_, err := tx.Exec(ctx, addOutbox, event.EventID, event.Type, event.UserID, event.Link, event.CreatedAt)
+	return err
+}
+
+// setLinkGroups сохраняет теги ссылки и заводит ее коллекцию, если той еще нет.
+func setLinkGroups(ctx context.Context, tx pgx.Tx, alias string, item Item) error {
+	if len(item.Tags) > 0 {
+		if _, err := tx.Exec(ctx, addTags, alias, item.Tags); err != nil {
+			return err
+		}
+	}
+
+	if item.Collection != "" {
+		if _, err := tx.Exec(ctx, upsertCollection, item.UserID, item.Collection, nullTime(item.CreatedAt)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // GetStats - returning distinct urls and users from storage
 func (c *dbStorage) GetStats(ctx context.Context) (int64, int64, error) {
 	var countOfURLs, countOfUsers sql.NullInt64
@@ -205,3 +1166,83 @@ func (c *dbStorage) Ping(ctx context.Context) error {
 func (c *dbStorage) Close() {
 	c.pool.Close()
 }
+
+// nullTime - NULL вместо нулевого времени, чтобы сработал DEFAULT колонки.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// rulesJSON - правила для колонки rules: пустой список вместо null.
+func rulesJSON(rules []Rule) []Rule {
+	if rules == nil {
+		return []Rule{}
+	}
+	return rules
+}
+
+// variantsJSON - варианты для колонки variants: пустой список вместо null.
+func variantsJSON(variants []Variant) []Variant {
+	if variants == nil {
+		return []Variant{}
+	}
+	return variants
+}
+
+// scanItem читает Item из строки с колонками itemColumns, перед которыми идут колонки prefix.
+func scanItem(row pgx.Row, prefix ...any) (Item, error) {
+	var (
+		item       Item
+		deletedAt  *time.Time
+		activeFrom *time.Time
+	)
+
+	dest := append(prefix,
+		&item.Object, &item.UserID, &item.IsDeleted, &item.CreatedAt, &item.UpdatedAt, &deletedAt,
+		&item.Title, &item.Note, &item.Collection, &item.RedirectCode, &item.ForwardQuery, &item.ForwardPath,
+		&item.PasswordHash, &item.MaxClicks, &item.Clicks, &activeFrom, &item.Rules,
+		&item.Variants, &item.Sticky, &item.Interstitial, &item.BlockedReason, &item.Tags,
+	)
+	if err := row.Scan(dest...); err != nil {
+		return Item{}, err
+	}
+
+	if deletedAt != nil {
+		item.DeletedAt = *deletedAt
+	}
+	if activeFrom != nil {
+		item.ActiveFrom = *activeFrom
+	}
+
+	return item, nil
+}
+
+// scanDelivery собирает Delivery из колонок deliveryColumns, suffix - цели для колонок после них.
+func scanDelivery(row pgx.Row, suffix ...any) (Delivery, error) {
+	var delivery Delivery
+
+	dest := append([]any{
+		&delivery.ID, &delivery.WebhookID, &delivery.UserID, &delivery.Event, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &delivery.LastError, &delivery.ResponseCode, &delivery.NextAttemptAt,
+		&delivery.CreatedAt, &delivery.UpdatedAt,
+	}, suffix...)
+	if err := row.Scan(dest...); err != nil {
+		return Delivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// scanDeleteJob собирает DeleteJob из строки с колонками deleteJobColumns.
+func scanDeleteJob(row pgx.Row) (DeleteJob, error) {
+	var job DeleteJob
+	err := row.Scan(&job.ID, &job.UserID, &job.URLs, &job.Status, &job.Attempts, &job.LastError,
+		&job.NextAttemptAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return DeleteJob{}, err
+	}
+
+	return job, nil
+}
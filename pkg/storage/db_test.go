@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sonikq/url-shortener/internal/app/models"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -73,11 +74,7 @@ func newTestDB() (db testDB, err error) {
 		return db, err
 	}
 
-	if err = createTable(ctx, pool); err != nil {
-		return db, err
-	}
-
-	if err = createIndex(ctx, pool); err != nil {
+	if _, err = migrate(ctx, pool, DedupGlobal); err != nil {
 		return db, err
 	}
 
@@ -131,7 +128,7 @@ func Test_newDB(t *testing.T) {
 			defer cancel()
 
 			var dbs *dbStorage
-			dbs, err = newDB(ctx, tt.dsn, tt.dbPoolWorkers)
+			dbs, err = newDB(ctx, tt.dsn, tt.dbPoolWorkers, DedupGlobal)
 			if tt.wantErr {
 				require.Error(t, err)
 				t.Log(err)
@@ -144,6 +141,112 @@ func Test_newDB(t *testing.T) {
 	}
 }
 
+func Test_newDB_upgradesSchema(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+	ctx := context.Background()
+
+	// Таблица urls в том виде, в котором ее создавали первые версии сервиса.
+	require.NoError(t, dropTable(ctx, db.pool))
+	_, err = db.pool.Exec(ctx, `CREATE TABLE urls (id SERIAL PRIMARY KEY, original_url TEXT NOT NULL,
+		short_url TEXT NOT NULL UNIQUE, user_id TEXT NOT NULL, is_deleted BOOLEAN DEFAULT False);`)
+	require.NoError(t, err)
+	_, err = db.pool.Exec(ctx, `INSERT INTO urls (original_url, short_url, user_id) VALUES ('https://yandex.ru', 'iuhpj31', '3pjojojngf');`)
+	require.NoError(t, err)
+	require.NoError(t, createIndex(ctx, db.pool, DedupGlobal))
+
+	c, err := newDB(ctx, db.dsn, 2, DedupPerUser)
+	require.NoError(t, err)
+	defer c.Close()
+
+	item, err := c.Get(ctx, "iuhpj31")
+	require.NoError(t, err)
+	require.Equal(t, "https://yandex.ru", item.Object)
+	require.Empty(t, item.Tags)
+
+	// Индекс прежней области дедупликации удален: тот же адрес может сократить другой пользователь.
+	require.NoError(t, c.Set(ctx, map[string]Item{"iuhpj32": {Object: "https://yandex.ru", UserID: "other"}}))
+}
+
+func Test_Migrate(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+	ctx := context.Background()
+
+	migration, err := Migrate(ctx, db.dsn, DedupGlobal)
+	require.NoError(t, err)
+	require.Equal(t, Migration{From: schemaVersion, To: schemaVersion}, migration)
+	require.False(t, migration.Applied())
+
+	require.NoError(t, dropTable(ctx, db.pool))
+	migration, err = Migrate(ctx, db.dsn, DedupGlobal)
+	require.NoError(t, err)
+	require.Equal(t, Migration{From: 0, To: schemaVersion}, migration)
+	require.True(t, migration.Applied())
+
+	c := &dbStorage{pool: db.pool, dedup: DedupGlobal}
+	require.NoError(t, c.Set(ctx, map[string]Item{"iuhpj31": {Object: "https://yandex.ru", UserID: "3pjojojngf"}}))
+
+	// Схему обновила более новая версия сервиса: старая не запускается поверх нее.
+	_, err = db.pool.Exec(ctx, setSchemaVersion, schemaVersion+1)
+	require.NoError(t, err)
+	_, err = Migrate(ctx, db.dsn, DedupGlobal)
+	require.ErrorIs(t, err, ErrSchemaTooNew)
+	_, err = newDB(ctx, db.dsn, 2, DedupGlobal)
+	require.ErrorIs(t, err, ErrSchemaTooNew)
+}
+
+func Test_newDB_upgradesOriginalURLIndex(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		version int // версия схемы, с которой БД осталась с индексом первых версий
+	}{
+		{name: "baseline", version: 0},
+		{name: "version_1", version: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, dropTable(ctx, db.pool))
+			_, err = migrate(ctx, db.pool, DedupNone)
+			require.NoError(t, err)
+			_, err = db.pool.Exec(ctx, `CREATE UNIQUE INDEX original_url_idx ON urls (original_url);`)
+			require.NoError(t, err)
+			_, err = db.pool.Exec(ctx, `DELETE FROM schema_version;`)
+			require.NoError(t, err)
+			if tt.version > 0 {
+				_, err = db.pool.Exec(ctx, setSchemaVersion, tt.version)
+				require.NoError(t, err)
+			}
+
+			_, err = db.pool.Exec(ctx, `INSERT INTO urls (original_url, short_url, user_id, is_deleted)
+				VALUES ('https://yandex.ru', 'iuhpj31', '3pjojojngf', true);`)
+			require.NoError(t, err)
+
+			c, err := newDB(ctx, db.dsn, 2, DedupGlobal)
+			require.NoError(t, err)
+			defer c.Close()
+
+			// Адрес, сокращение которого удалено, сокращается заново.
+			require.NoError(t, c.Set(ctx, map[string]Item{"iuhpj32": {Object: "https://yandex.ru", UserID: "3pjojojngf"}}))
+			alias, err := c.GetShortURL(ctx, "https://yandex.ru", "3pjojojngf")
+			require.NoError(t, err)
+			require.Equal(t, "iuhpj32", alias)
+			require.ErrorIs(t, c.Set(ctx, map[string]Item{"iuhpj33": {Object: "https://yandex.ru", UserID: "other"}}),
+				models.ErrAlreadyExists)
+		})
+	}
+}
+
 func Test_createTable(t *testing.T) {
 	db, err := newTestDB()
 	defer db.close()
@@ -184,7 +287,7 @@ func Test_createIndex(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createIndex(context.Background(), db.pool); (err != nil) != tt.wantErr {
+			if err := createIndex(context.Background(), db.pool, DedupGlobal); (err != nil) != tt.wantErr {
 				t.Errorf("createIndex() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -211,7 +314,8 @@ func Test_dbStorage_DeleteBatch(t *testing.T) {
 	require.NotNil(t, db.pool)
 
 	c := &dbStorage{
-		pool: db.pool,
+		pool:  db.pool,
+		dedup: DedupGlobal,
 	}
 
 	tests := []struct {
@@ -239,7 +343,7 @@ func Test_dbStorage_DeleteBatch(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := c.DeleteBatch(context.Background(), tt.urls, tt.userID); (err != nil) != tt.wantErr {
+			if _, err := c.DeleteBatch(context.Background(), tt.urls, tt.userID); (err != nil) != tt.wantErr {
 				t.Errorf("DeleteBatch() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -253,7 +357,8 @@ func Test_dbStorage_Get(t *testing.T) {
 	require.NotNil(t, db.pool)
 
 	c := &dbStorage{
-		pool: db.pool,
+		pool:  db.pool,
+		dedup: DedupGlobal,
 	}
 
 	_ = c.Set(context.Background(), map[string]Item{
@@ -277,6 +382,12 @@ func Test_dbStorage_Get(t *testing.T) {
 			want:    "https://yandex.ru",
 			wantErr: false,
 		},
+		{
+			name:    "unknown-alias",
+			alias:   "missing",
+			want:    "",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -285,8 +396,8 @@ func Test_dbStorage_Get(t *testing.T) {
 				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
-				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			if got.Object != tt.want {
+				t.Errorf("Get() got = %v, want %v", got.Object, tt.want)
 			}
 		})
 	}
@@ -299,7 +410,8 @@ func Test_dbStorage_GetBatchByUserID(t *testing.T) {
 	require.NotNil(t, db.pool)
 
 	c := &dbStorage{
-		pool: db.pool,
+		pool:  db.pool,
+		dedup: DedupGlobal,
 	}
 
 	_ = c.Set(context.Background(), map[string]Item{
@@ -314,32 +426,37 @@ func Test_dbStorage_GetBatchByUserID(t *testing.T) {
 	tests := []struct {
 		name    string
 		userID  string
-		want    map[string]Item
+		query   BatchQuery
+		want    []string
 		wantErr bool
 	}{
 		{
-			name:   "valid-batch",
-			userID: "3pjojojngf",
-			want: map[string]Item{
-				"iuhpj21": {
-					Object:     "https://yandex.ru",
-					UserID:     "3pjojojngf",
-					IsDeleted:  false,
-					Expiration: 0,
-				},
-			},
+			name:    "valid-batch",
+			userID:  "3pjojojngf",
+			want:    []string{"iuhpj21"},
+			wantErr: false,
+		},
+		{
+			name:    "search-miss",
+			userID:  "3pjojojngf",
+			query:   BatchQuery{Search: "google"},
+			want:    nil,
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := c.GetBatchByUserID(context.Background(), tt.userID)
+			got, err := c.GetBatchByUserID(context.Background(), tt.userID, tt.query)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetBatchByUserID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("GetBatchByUserID() got = %v, want %v", got, tt.want)
+			var aliases []string
+			for _, record := range got {
+				aliases = append(aliases, record.Alias)
+			}
+			if !reflect.DeepEqual(aliases, tt.want) {
+				t.Errorf("GetBatchByUserID() got = %v, want %v", aliases, tt.want)
 			}
 		})
 	}
@@ -352,7 +469,8 @@ func Test_dbStorage_GetShortURL(t *testing.T) {
 	require.NotNil(t, db.pool)
 
 	c := &dbStorage{
-		pool: db.pool,
+		pool:  db.pool,
+		dedup: DedupGlobal,
 	}
 
 	_ = c.Set(context.Background(), map[string]Item{
@@ -379,7 +497,7 @@ func Test_dbStorage_GetShortURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := c.GetShortURL(context.Background(), tt.originalURL)
+			got, err := c.GetShortURL(context.Background(), tt.originalURL, "3pjojojngf")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetShortURL() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -412,7 +530,8 @@ func Test_dbStorage_Set(t *testing.T) {
 	require.NotNil(t, db.pool)
 
 	c := &dbStorage{
-		pool: db.pool,
+		pool:  db.pool,
+		dedup: DedupGlobal,
 	}
 	tests := []struct {
 		name    string
@@ -464,3 +583,549 @@ func Test_dropTable(t *testing.T) {
 		})
 	}
 }
+
+func Test_dbStorage_UpdateLink(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+
+	_ = c.Set(context.Background(), map[string]Item{
+		"iuhpj21": {
+			Object: "https://yandex.ru",
+			UserID: "3pjojojngf",
+		},
+	})
+
+	object, title, interstitial := "https://ya.ru", "Ya", true
+	_, err = c.UpdateLink(context.Background(), "iuhpj21", "another", LinkUpdate{Object: &object})
+	require.Error(t, err)
+
+	item, err := c.UpdateLink(context.Background(), "iuhpj21", "3pjojojngf", LinkUpdate{
+		Object: &object, Title: &title, Interstitial: &interstitial,
+	})
+	require.NoError(t, err)
+	require.Equal(t, object, item.Object)
+	require.Equal(t, title, item.Title)
+	require.True(t, item.Interstitial)
+	require.True(t, item.UpdatedAt.After(item.CreatedAt))
+
+	revisions, err := c.GetRevisions(context.Background(), "iuhpj21", "3pjojojngf")
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, "https://yandex.ru", revisions[0].Object)
+}
+
+func Test_dbStorage_TagsAndCollections(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	err = c.Set(ctx, map[string]Item{
+		"iuhpj21": {Object: "https://yandex.ru", UserID: "3pjojojngf", Tags: []string{"promo"}, Collection: "spring"},
+		"iuhpj22": {Object: "https://ya.ru", UserID: "3pjojojngf", Tags: []string{"mail", "promo"}},
+	})
+	require.NoError(t, err)
+
+	tagged, err := c.GetBatchByUserID(ctx, "3pjojojngf", BatchQuery{Tag: "promo"})
+	require.NoError(t, err)
+	require.Len(t, tagged, 2)
+
+	tags := []string{"mail"}
+	item, err := c.UpdateLink(ctx, "iuhpj22", "3pjojojngf", LinkUpdate{Tags: &tags})
+	require.NoError(t, err)
+	require.Equal(t, tags, item.Tags)
+
+	inCollection, err := c.GetBatchByUserID(ctx, "3pjojojngf", BatchQuery{Collection: "spring"})
+	require.NoError(t, err)
+	require.Len(t, inCollection, 1)
+	require.Equal(t, []string{"promo"}, inCollection[0].Tags)
+
+	require.NoError(t, c.CreateCollection(ctx, Collection{Name: "autumn", UserID: "3pjojojngf"}))
+	require.ErrorIs(t, c.CreateCollection(ctx, Collection{Name: "spring", UserID: "3pjojojngf"}), models.ErrAlreadyExists)
+
+	collections, err := c.GetCollections(ctx, "3pjojojngf")
+	require.NoError(t, err)
+	require.Len(t, collections, 2)
+	require.Equal(t, 1, collections[1].Links)
+
+	require.NoError(t, c.DeleteCollection(ctx, "3pjojojngf", "autumn"))
+	require.ErrorIs(t, c.DeleteCollection(ctx, "3pjojojngf", "autumn"), models.ErrCollectionNotFound)
+}
+
+func Test_dbStorage_ConsumeClick(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	err = c.Set(ctx, map[string]Item{
+		"iuhpj21": {Object: "https://yandex.ru", UserID: "3pjojojngf", MaxClicks: 1},
+	})
+	require.NoError(t, err)
+
+	item, err := c.ConsumeClick(ctx, "iuhpj21")
+	require.NoError(t, err)
+	require.Equal(t, 1, item.Clicks)
+
+	_, err = c.ConsumeClick(ctx, "iuhpj21")
+	require.ErrorIs(t, err, models.ErrClicksExhausted)
+
+	_, err = c.ConsumeClick(ctx, "missing")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+}
+
+func Test_dbStorage_Rules(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	rules := []Rule{{Platform: "ios", Destination: "https://apps.apple.com/app/id1"}}
+	err = c.Set(ctx, map[string]Item{
+		"iuhpj21": {Object: "https://yandex.ru", UserID: "3pjojojngf", Rules: rules},
+	})
+	require.NoError(t, err)
+
+	item, err := c.Get(ctx, "iuhpj21")
+	require.NoError(t, err)
+	require.Equal(t, rules, item.Rules)
+
+	item, err = c.UpdateLink(ctx, "iuhpj21", "3pjojojngf", LinkUpdate{Rules: &[]Rule{}})
+	require.NoError(t, err)
+	require.Empty(t, item.Rules)
+}
+
+func Test_dbStorage_RecordVariant(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	err = c.Set(ctx, map[string]Item{
+		"iuhpj21": {Object: "https://yandex.ru", UserID: "3pjojojngf", Variants: []Variant{
+			{Destination: "https://a.example.com", Weight: 1},
+			{Destination: "https://b.example.com", Weight: 1},
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = c.RecordVariant(ctx, "iuhpj21", 1)
+	require.NoError(t, err)
+	item, err := c.RecordVariant(ctx, "iuhpj21", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), item.Variants[1].Clicks)
+
+	_, err = c.RecordVariant(ctx, "iuhpj21", 2)
+	require.Error(t, err)
+}
+
+func Test_dbStorage_ScanLinksAndSetBlocked(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	err = c.Set(ctx, map[string]Item{
+		"iuhpj21": {Object: "https://yandex.ru", UserID: "3pjojojngf"},
+		"iuhpj22": {Object: "https://ya.ru", UserID: "3pjojojngf"},
+	})
+	require.NoError(t, err)
+
+	page, err := c.ScanLinks(ctx, "iuhpj21", 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "iuhpj22", page[0].Alias)
+
+	item, err := c.SetBlocked(ctx, "iuhpj22", "blocklist: ya.ru")
+	require.NoError(t, err)
+	require.Equal(t, "blocklist: ya.ru", item.BlockedReason)
+
+	_, err = c.SetBlocked(ctx, "missing", "blocklist: ya.ru")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+}
+
+func Test_dbStorage_Webhooks(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, c.CreateWebhook(ctx, Webhook{ID: "hook-a", UserID: "3pjojojngf", URL: "https://example.com/hook",
+		Secret: "secret", Events: []string{"link.created"}}))
+	require.ErrorIs(t, c.CreateWebhook(ctx, Webhook{ID: "hook-a", UserID: "3pjojojngf", Events: []string{}}),
+		models.ErrAlreadyExists)
+
+	require.NoError(t, c.AddDeliveries(ctx, []Delivery{
+		{WebhookID: "hook-a", UserID: "3pjojojngf", Event: "link.created", Payload: []byte(`{"a":1}`)},
+	}))
+
+	claimed, err := c.ClaimDeliveries(ctx, time.Now().Add(time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "secret", claimed[0].Secret)
+	require.Equal(t, `{"a":1}`, string(claimed[0].Payload))
+
+	claimed[0].Status, claimed[0].Attempts = DeliveryDead, 8
+	require.NoError(t, c.UpdateDelivery(ctx, claimed[0]))
+
+	dead, err := c.GetDeliveries(ctx, "3pjojojngf", "hook-a", DeliveryDead, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+
+	retried, err := c.RetryDelivery(ctx, "3pjojojngf", "hook-a", dead[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, DeliveryPending, retried.Status)
+
+	require.NoError(t, c.DeleteWebhook(ctx, "3pjojojngf", "hook-a"))
+	_, err = c.GetDeliveries(ctx, "3pjojojngf", "hook-a", "", 10)
+	require.ErrorIs(t, err, models.ErrWebhookNotFound)
+}
+
+func Test_dbStorage_WebhooksSurviveRestart(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, c.CreateWebhook(ctx, Webhook{ID: "hook-a", UserID: "3pjojojngf", URL: "https://example.com/hook",
+		Secret: "secret", Events: []string{"link.created"}}))
+	require.NoError(t, c.AddDeliveries(ctx, []Delivery{
+		{WebhookID: "hook-a", UserID: "3pjojojngf", Event: "link.created", Payload: []byte(`{"a":1}`)},
+	}))
+
+	// Подключение при запуске сервиса не удаляет подписки и недоставленные события.
+	restarted, err := newDB(ctx, db.dsn, 2, DedupGlobal)
+	require.NoError(t, err)
+	defer restarted.Close()
+
+	webhooks, err := restarted.GetWebhooks(ctx, "3pjojojngf")
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+
+	pending, err := restarted.GetDeliveries(ctx, "3pjojojngf", "hook-a", DeliveryPending, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+}
+
+func Test_dbStorage_Outbox(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, map[string]Item{"iuhpj31": {Object: "https://yandex.ru", UserID: "3pjojojngf"}}))
+	require.NoError(t, c.Set(ctx, map[string]Item{"iuhpj31": {Object: "https://yandex.ru", UserID: "3pjojojngf"}}))
+	_, err = c.DeleteBatch(ctx, []string{"iuhpj31"}, "3pjojojngf")
+	require.NoError(t, err)
+
+	now := time.Now()
+	events, err := c.ClaimOutbox(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, EventLinkCreated, events[0].Type)
+	require.Equal(t, "https://yandex.ru", events[0].Link.OriginalURL)
+	require.Equal(t, EventLinkDeleted, events[1].Type)
+
+	again, err := c.ClaimOutbox(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	// Неподтвержденные события переживают перезапуск и снова выдаются после истечения аренды.
+	restarted, err := newDB(ctx, db.dsn, 2, DedupGlobal)
+	require.NoError(t, err)
+	defer restarted.Close()
+	again, err = restarted.ClaimOutbox(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, again, 2)
+
+	require.NoError(t, c.AckOutbox(ctx, []int64{events[0].ID, events[1].ID}))
+	again, err = c.ClaimOutbox(ctx, now.Add(time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+}
+
+func Test_dbStorage_DeleteJobsSurviveRestart(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, c.Set(ctx, map[string]Item{"iuhpj31": {Object: "https://yandex.ru", UserID: "3pjojojngf"}}))
+	require.NoError(t, c.AddDeleteJob(ctx, DeleteJob{ID: "job-1", UserID: "3pjojojngf", URLs: []string{"iuhpj31"}}))
+	require.NoError(t, c.AddDeleteJob(ctx, DeleteJob{ID: "job-2", UserID: "3pjojojngf", URLs: []string{"iuhpj31"},
+		NextAttemptAt: now.Add(time.Hour)}))
+
+	// Экземпляр взял задание и упал, не записав результат.
+	claimed, err := c.ClaimDeleteJobs(ctx, now, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	restarted, err := newDB(ctx, db.dsn, 2, DedupGlobal)
+	require.NoError(t, err)
+	defer restarted.Close()
+
+	item, err := restarted.Get(ctx, "iuhpj31")
+	require.NoError(t, err)
+	require.Equal(t, "https://yandex.ru", item.Object)
+
+	// После перезапуска задание выдается снова, когда истекает аренда, отложенное - в свой срок.
+	resumed, err := restarted.ClaimDeleteJobs(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, resumed, 1)
+	require.Equal(t, "job-1", resumed[0].ID)
+	require.Equal(t, JobPending, resumed[0].Status)
+
+	resumed, err = restarted.ClaimDeleteJobs(ctx, now.Add(2*time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, resumed, 1)
+	require.Equal(t, "job-2", resumed[0].ID)
+}
+
+func Test_dbStorage_DeleteJobs(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, c.AddDeleteJob(ctx, DeleteJob{ID: "job-1", UserID: "3pjojojngf", URLs: []string{"iuhpj31"},
+		CreatedAt: now.Add(-time.Minute)}))
+	require.ErrorIs(t, c.AddDeleteJob(ctx, DeleteJob{ID: "job-1", UserID: "3pjojojngf", URLs: []string{}}),
+		models.ErrAlreadyExists)
+
+	jobs, err := c.ClaimDeleteJobs(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, []string{"iuhpj31"}, jobs[0].URLs)
+
+	again, err := c.ClaimDeleteJobs(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	jobs[0].Status, jobs[0].Attempts = JobDone, 1
+	require.NoError(t, c.UpdateDeleteJobs(ctx, jobs))
+	require.ErrorIs(t, c.UpdateDeleteJobs(ctx, []DeleteJob{{ID: "missing"}}), models.ErrJobNotFound)
+
+	job, err := c.GetDeleteJob(ctx, "3pjojojngf", "job-1")
+	require.NoError(t, err)
+	require.Equal(t, JobDone, job.Status)
+	_, err = c.GetDeleteJob(ctx, "other", "job-1")
+	require.ErrorIs(t, err, models.ErrJobNotFound)
+
+	purged, err := c.PurgeDeleteJobs(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+}
+
+func Test_dbStorage_Scheduler(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	unlock, acquired, err := c.TryLock(ctx, "scheduler:purge")
+	require.NoError(t, err)
+	require.True(t, acquired)
+	_, acquired, err = c.TryLock(ctx, "scheduler:purge")
+	require.NoError(t, err)
+	require.False(t, acquired)
+	unlock()
+
+	now := time.Now()
+	for i := 0; i < maxJobRuns+2; i++ {
+		require.NoError(t, c.AddJobRun(ctx, JobRun{Job: "purge", Trigger: "schedule", Status: JobRunSucceeded,
+			Processed: i, StartedAt: now, FinishedAt: now}))
+	}
+
+	runs, err := c.GetJobRuns(ctx, "purge", 1000)
+	require.NoError(t, err)
+	require.Len(t, runs, maxJobRuns)
+	require.Equal(t, maxJobRuns+1, runs[0].Processed)
+}
+
+func Test_dbStorage_JobRunsSurviveRestart(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, c.AddJobRun(ctx, JobRun{Job: "purge", Trigger: "schedule", Status: JobRunSucceeded,
+		Processed: 7, StartedAt: now, FinishedAt: now}))
+
+	unlock, acquired, err := c.TryLock(ctx, "scheduler:purge")
+	require.NoError(t, err)
+	require.True(t, acquired)
+	defer unlock()
+
+	// Вторая реплика поднимается, пока первая выполняет задачу: история и блокировка сохраняются.
+	replica, err := newDB(ctx, db.dsn, 2, DedupGlobal)
+	require.NoError(t, err)
+	defer replica.Close()
+
+	runs, err := replica.GetJobRuns(ctx, "purge", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, 7, runs[0].Processed)
+
+	_, acquired, err = replica.TryLock(ctx, "scheduler:purge")
+	require.NoError(t, err)
+	require.False(t, acquired)
+}
+
+func Test_dbStorage_ImportLinks(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, map[string]Item{"taken": {Object: "https://old.example", UserID: "u1"}}))
+
+	createdAt := time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)
+	results, err := c.ImportLinks(ctx, []Record{
+		{Alias: "new", Item: Item{Object: "https://ya.ru", UserID: "u2", Tags: []string{"go"}, Clicks: 3, CreatedAt: createdAt}},
+		{Alias: "taken", Item: Item{Object: "https://example.com", UserID: "u2"}},
+		{Alias: "dup", Item: Item{Object: "https://old.example", UserID: "u2"}},
+		{Alias: "new", Item: Item{Object: "https://example.org", UserID: "u2"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, models.ErrAliasTaken, models.ErrAlreadyExists, models.ErrAliasTaken}, results)
+
+	item, err := c.Get(ctx, "new")
+	require.NoError(t, err)
+	require.True(t, createdAt.Equal(item.CreatedAt))
+	require.Equal(t, []string{"go"}, item.Tags)
+	require.Equal(t, 3, item.Clicks)
+
+	item, err = c.Get(ctx, "taken")
+	require.NoError(t, err)
+	require.Equal(t, "https://old.example", item.Object)
+}
+
+func Test_dbStorage_LookupAndTransfer(t *testing.T) {
+	db, err := newTestDB()
+	defer db.close()
+	require.NoError(t, err)
+	require.NotNil(t, db.pool)
+
+	c := &dbStorage{
+		pool:  db.pool,
+		dedup: DedupGlobal,
+	}
+	ctx := context.Background()
+	require.NoError(t, c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://ya.ru", UserID: "user-a", Collection: "work"},
+		"bbbbbb": {Object: "https://google.com", UserID: "user-a"},
+		"cccccc": {Object: "https://mail.ru", UserID: "user-c"},
+	}))
+	_, err = c.DeleteBatch(ctx, []string{"bbbbbb"}, "user-a")
+	require.NoError(t, err)
+
+	item, err := c.Lookup(ctx, "bbbbbb")
+	require.NoError(t, err)
+	require.True(t, item.IsDeleted)
+
+	_, err = c.Lookup(ctx, "zzzzzz")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+
+	moved, err := c.TransferLinks(ctx, "user-a", "user-b", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaaaaa", "bbbbbb"}, moved)
+
+	item, err = c.Lookup(ctx, "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, "user-b", item.UserID)
+
+	collections, err := c.GetCollections(ctx, "user-b")
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+
+	moved, err = c.TransferLinks(ctx, "user-c", "user-b", []string{"aaaaaa"})
+	require.NoError(t, err)
+	require.Empty(t, moved)
+}
@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 type fileStorage struct {
@@ -11,7 +13,7 @@ type fileStorage struct {
 }
 
 func newFileStorage(path string) (*fileStorage, error) {
-	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
 		return nil, err
 	}
@@ -39,3 +41,120 @@ func (f *fileStorage) SaveToFile(items map[string]Item) error {
 	}
 	return nil
 }
+
+// RemoveFromFile - дописывает в журнал null для окончательно удаленных ссылок
+func (f *fileStorage) RemoveFromFile(aliases []string) error {
+	for _, alias := range aliases {
+		data, err := json.Marshal(map[string]*Item{alias: nil})
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+
+		_, err = f.file.Write(data)
+		if err != nil {
+			return fmt.Errorf("error in saving file: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// readFile читает журнал ссылок и возвращает их актуальное состояние и число прочитанных записей.
+func readFile(filename string) (map[string]Item, int, error) {
+	file, err := os.OpenFile(filename, os.O_RDONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cant open file: %s", err.Error())
+	}
+	defer func(file *os.File) {
+		if err = file.Close(); err != nil {
+			fmt.Printf("cant close file: %s", err.Error())
+		}
+	}(file)
+
+	// Файл - журнал: каждая строка содержит актуальное состояние ссылок,
+	// поэтому более поздние строки перекрывают более ранние, а null удаляет ссылку.
+	itemsMap := make(map[string]Item)
+
+	var entries int
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		err = json.Unmarshal(data, &itemsMap)
+		if err != nil {
+			return nil, 0, fmt.Errorf("cant unmarshal objects from file: %s", err.Error())
+		}
+		entries++
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("cant read file: %s", err.Error())
+	}
+
+	for key, item := range itemsMap {
+		if item.Object == "" {
+			delete(itemsMap, key)
+			continue
+		}
+		// Прежние версии записывали каждой ссылке срок жизни 10 минут; ссылки действуют бессрочно.
+		item.Expiration = 0
+		itemsMap[key] = item
+	}
+
+	return itemsMap, entries, nil
+}
+
+// CompactResult - итог сжатия файла ссылок
+type CompactResult struct {
+	Entries int `json:"entries"` // записей в журнале до сжатия
+	Links   int `json:"links"`   // записей после сжатия, по одной на ссылку
+}
+
+// CompactFile переписывает журнал ссылок, оставляя по одной записи с актуальным состоянием
+// каждой ссылки; окончательно удаленные ссылки из него исчезают. Новый файл пишется рядом
+// и заменяет старый переименованием, поэтому прерванное сжатие журнал не портит.
+// Сервис, пишущий в файл, на время сжатия должен быть остановлен.
+func CompactFile(filename string) (CompactResult, error) {
+	itemsMap, entries, err := readFile(filename)
+	if err != nil {
+		return CompactResult{}, err
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return CompactResult{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return CompactResult{}, err
+	}
+	defer os.Remove(tmp.Name())
+	if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return CompactResult{}, err
+	}
+
+	compacted := &fileStorage{file: tmp}
+	for _, key := range sortedKeys(itemsMap) {
+		if err = compacted.SaveToFile(map[string]Item{key: itemsMap[key]}); err != nil {
+			tmp.Close()
+			return CompactResult{}, err
+		}
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return CompactResult{}, err
+	}
+	if err = tmp.Close(); err != nil {
+		return CompactResult{}, err
+	}
+
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return CompactResult{}, err
+	}
+
+	return CompactResult{Entries: entries, Links: len(itemsMap)}, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// jobJournalSuffix - журнал очереди удаления хранилища в памяти лежит рядом с файлом ссылок
+const jobJournalSuffix = ".jobs"
+
+// jobJournal - журнал заданий на удаление для хранилища в памяти: каждая строка - актуальное
+// состояние задания. При открытии журнал переписывается, в нем остаются только невыполненные задания.
+type jobJournal struct {
+	file *os.File
+}
+
+// openJobJournal открывает журнал и возвращает невыполненные задания в порядке создания.
+func openJobJournal(path string) (*jobJournal, []DeleteJob, error) {
+	pending, err := readJobJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cant open jobs journal: %w", err)
+	}
+
+	journal := &jobJournal{file: file}
+	if err = journal.write(pending); err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	return journal, pending, nil
+}
+
+func readJobJournal(path string) ([]DeleteJob, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cant open jobs journal: %w", err)
+	}
+	defer file.Close()
+
+	jobs := make(map[string]DeleteJob)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16<<20) // в задании может быть много ссылок
+	for scanner.Scan() {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var job DeleteJob
+		if err = json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("cant unmarshal jobs journal: %w", err)
+		}
+		if job.Status != JobPending {
+			delete(jobs, job.ID)
+			continue
+		}
+		jobs[job.ID] = job
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cant read jobs journal: %w", err)
+	}
+
+	pending := make([]DeleteJob, 0, len(jobs))
+	for _, job := range jobs {
+		pending = append(pending, job)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+
+	return pending, nil
+}
+
+// write дописывает состояния заданий одной записью.
+func (j *jobJournal) write(jobs []DeleteJob) error {
+	var data []byte
+	for _, job := range jobs {
+		line, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("error in saving jobs journal: %s", err.Error())
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/sonikq/url-shortener/internal/app/models"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 )
@@ -13,6 +15,32 @@ type Item struct {
 	Object     string
 	UserID     string
 	IsDeleted  bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  time.Time
+	Title      string
+	Note       string
+	Tags       []string
+	Collection string
+
+	RedirectCode int    // 0 - код по умолчанию из конфигурации
+	ForwardQuery bool   // дописывать query входящего запроса к original_url
+	ForwardPath  bool   // дописывать хвост пути после сокращения к original_url
+	PasswordHash string // bcrypt-хэш пароля, пустой - ссылка не защищена
+
+	MaxClicks  int       // 0 - число переходов не ограничено
+	Clicks     int       // учтенные переходы по ссылке с ограничением
+	ActiveFrom time.Time // до этого момента ссылка неактивна, нулевое значение - активна сразу
+
+	Rules []Rule // проверяются по порядку, original_url - адрес по умолчанию
+
+	Variants []Variant // A/B-тест: если заданы, заменяют original_url
+	Sticky   bool      // закреплять выбранный вариант за посетителем
+
+	Interstitial bool // вместо редиректа показывать страницу "Вы покидаете сайт"
+
+	BlockedReason string // почему ссылка признана вредоносной, пустая - ссылка не заблокирована
+
 	Expiration int64
 }
 
@@ -24,9 +52,57 @@ func (item Item) Expired() bool {
 	return time.Now().UnixNano() > item.Expiration
 }
 
+// Destinations - все адреса, на которые может вести ссылка: original_url, правила и варианты
+func (item Item) Destinations() []string {
+	destinations := make([]string, 0, 1+len(item.Rules)+len(item.Variants))
+	if item.Object != "" {
+		destinations = append(destinations, item.Object)
+	}
+	for _, rule := range item.Rules {
+		destinations = append(destinations, rule.Destination)
+	}
+	for _, variant := range item.Variants {
+		destinations = append(destinations, variant.Destination)
+	}
+	return destinations
+}
+
+// ClicksExhausted - исчерпан ли лимит переходов по ссылке
+func (item Item) ClicksExhausted() bool {
+	return item.MaxClicks > 0 && item.Clicks >= item.MaxClicks
+}
+
 type memoryStorage struct {
-	items map[string]Item
-	mu    sync.RWMutex
+	items       map[string]Item
+	originals   map[dedupKey]map[string]struct{} // индекс неудаленных ссылок для проверки дубликатов
+	revisions   map[string][]Revision
+	collections map[string]map[string]Collection
+	webhooks    map[string]Webhook
+	deliveries  map[int64]Delivery
+	outbox      []outboxEntry
+	journal     *outboxJournal // nil - outbox не переживает перезапуск
+	deleteJobs  map[string]DeleteJob
+	jobJournal  *jobJournal // nil - очередь удаления не переживает перезапуск
+	locks       map[string]bool
+	jobRuns     map[string][]JobRun
+	runSeq      int64
+	revSeq      int64
+	deliverySeq int64
+	outboxSeq   int64
+	dedup       DedupScope
+	mu          sync.RWMutex
+}
+
+// dedupKey - original_url в области дедупликации: при DedupPerUser вместе с владельцем.
+type dedupKey struct {
+	userID string
+	object string
+}
+
+// outboxEntry - событие outbox и срок, до которого оно взято на публикацию.
+type outboxEntry struct {
+	OutboxEvent
+	lockedUntil time.Time
 }
 
 // OptionsMemoryStorage -
@@ -34,12 +110,22 @@ type OptionsMemoryStorage func(m *memoryStorage)
 
 func newMemoryStorage(opts ...OptionsMemoryStorage) *memoryStorage {
 	c := &memoryStorage{
-		items: make(map[string]Item),
+		items:       make(map[string]Item),
+		originals:   make(map[dedupKey]map[string]struct{}),
+		revisions:   make(map[string][]Revision),
+		collections: make(map[string]map[string]Collection),
+		webhooks:    make(map[string]Webhook),
+		deliveries:  make(map[int64]Delivery),
+		deleteJobs:  make(map[string]DeleteJob),
+		locks:       make(map[string]bool),
+		jobRuns:     make(map[string][]JobRun),
+		dedup:       DedupGlobal,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.reindex()
 
 	return c
 }
@@ -51,92 +137,1054 @@ func WithMemoryStorage(items map[string]Item) OptionsMemoryStorage {
 	}
 }
 
-// Set -
+// setDedup меняет область дедупликации и перестраивает индекс под нее.
+func (c *memoryStorage) setDedup(scope DedupScope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.dedup = scope
+	c.reindex()
+}
+
+// Set - сохраняет ссылки, для новых пишет в outbox link.created
 func (c *memoryStorage) Set(_ context.Context, data map[string]Item) error {
+	return c.set(data, true)
+}
+
+func (c *memoryStorage) restore(_ context.Context, data map[string]Item) error {
+	return c.set(data, false)
+}
+
+func (c *memoryStorage) set(data map[string]Item, emit bool) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// Дубликаты ищутся и среди уже сохраненных ссылок, и внутри самой пачки.
+	batch := make(map[dedupKey]struct{}, len(data))
+	for key, value := range data {
+		if _, found := c.findDuplicate(key, value); found {
+			return models.ErrAlreadyExists
+		}
+		if c.dedup == DedupNone || value.IsDeleted || value.Expired() {
+			continue
+		}
+		if _, found := batch[c.dedupKey(value)]; found {
+			return models.ErrAlreadyExists
+		}
+		batch[c.dedupKey(value)] = struct{}{}
+	}
+
+	if emit {
+		var events []OutboxEvent
+		for _, key := range sortedKeys(data) {
+			if _, found := c.items[key]; found {
+				continue
+			}
+			events = append(events, NewOutboxEvent(EventLinkCreated, data[key].UserID, EventLink{
+				Alias:       key,
+				OriginalURL: data[key].Object,
+				MaxClicks:   data[key].MaxClicks,
+			}))
+		}
+		if err := c.enqueue(events); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
 	for key, value := range data {
-		c.items[key] = value
+		if value.CreatedAt.IsZero() {
+			value.CreatedAt = now
+		}
+		if value.UpdatedAt.IsZero() {
+			value.UpdatedAt = value.CreatedAt
+		}
+		c.ensureCollection(value.UserID, value.Collection, now)
+		c.put(key, value)
 	}
 
 	return nil
 }
 
+// importChunkSize - сколько записей импорта обрабатывается под одной блокировкой: между частями
+// хранилище доступно для переходов и остальных запросов.
+const importChunkSize = 500
+
+// ImportLinks - добавляет перенесенные из другой системы ссылки, не изменяя существующие.
+// Для каждой записи возвращает nil, ErrAliasTaken, если сокращение занято, или ErrAlreadyExists,
+// если original_url уже сокращен. Как и при restore, события link.created не пишутся.
+// Записи обрабатываются частями по importChunkSize, дубликаты ищутся по индексу original_url.
+func (c *memoryStorage) ImportLinks(ctx context.Context, records []Record) ([]error, error) {
+	results := make([]error, len(records))
+	for start := 0; start < len(records); start += importChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+importChunkSize, len(records))
+		c.importChunk(records[start:end], results[start:end])
+	}
+
+	return results, nil
+}
+
+func (c *memoryStorage) importChunk(records []Record, results []error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for i, record := range records {
+		if _, found := c.items[record.Alias]; found {
+			results[i] = models.ErrAliasTaken
+			continue
+		}
+		if _, found := c.findDuplicate(record.Alias, record.Item); found {
+			results[i] = models.ErrAlreadyExists
+			continue
+		}
+
+		item := record.Item
+		if item.CreatedAt.IsZero() {
+			item.CreatedAt = now
+		}
+		if item.UpdatedAt.IsZero() {
+			item.UpdatedAt = item.CreatedAt
+		}
+		c.ensureCollection(item.UserID, item.Collection, now)
+		c.put(record.Alias, item)
+	}
+}
+
+// findDuplicate ищет другую живую ссылку на тот же original_url в рамках области дедупликации.
+func (c *memoryStorage) findDuplicate(alias string, item Item) (string, bool) {
+	if c.dedup == DedupNone {
+		return "", false
+	}
+
+	for key := range c.originals[c.dedupKey(item)] {
+		if key != alias && !c.items[key].Expired() {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+func (c *memoryStorage) dedupKey(item Item) dedupKey {
+	if c.dedup == DedupPerUser {
+		return dedupKey{userID: item.UserID, object: item.Object}
+	}
+	return dedupKey{object: item.Object}
+}
+
+// put сохраняет ссылку и обновляет индекс дубликатов. Все изменения items идут через put и remove.
+func (c *memoryStorage) put(alias string, item Item) {
+	c.unindex(alias)
+	c.items[alias] = item
+	c.index(alias, item)
+}
+
+// remove удаляет ссылку вместе с ее записью в индексе дубликатов.
+func (c *memoryStorage) remove(alias string) {
+	c.unindex(alias)
+	delete(c.items, alias)
+}
+
+func (c *memoryStorage) index(alias string, item Item) {
+	if c.dedup == DedupNone || item.IsDeleted {
+		return
+	}
+
+	key := c.dedupKey(item)
+	if c.originals[key] == nil {
+		c.originals[key] = make(map[string]struct{}, 1)
+	}
+	c.originals[key][alias] = struct{}{}
+}
+
+func (c *memoryStorage) unindex(alias string) {
+	item, found := c.items[alias]
+	if !found {
+		return
+	}
+
+	key := c.dedupKey(item)
+	delete(c.originals[key], alias)
+	if len(c.originals[key]) == 0 {
+		delete(c.originals, key)
+	}
+}
+
+// reindex строит индекс дубликатов заново, например после смены области дедупликации.
+func (c *memoryStorage) reindex() {
+	c.originals = make(map[dedupKey]map[string]struct{})
+	for alias, item := range c.items {
+		c.index(alias, item)
+	}
+}
+
 // Get -
-func (c *memoryStorage) Get(_ context.Context, alias string) (string, error) {
+func (c *memoryStorage) Get(_ context.Context, alias string) (Item, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	item, found := c.items[alias]
 	if !found {
-		return "", fmt.Errorf("access denied")
+		return Item{}, models.ErrLinkNotFound
+	}
+
+	if err := usable(item); err != nil {
+		return Item{}, err
 	}
+	return item, nil
+}
 
+// usable - ошибка, если по ссылке нельзя перейти: она удалена или истек ее срок.
+// Get и ConsumeClick проверяют ссылку одинаково.
+func usable(item Item) error {
 	if item.IsDeleted {
-		return "", models.ErrGetDeletedLink
+		return models.ErrGetDeletedLink
 	}
+	if item.Expired() {
+		return models.ErrLinkExpired
+	}
+	return nil
+}
+
+// ConsumeClick - атомарно учитывает переход по ссылке, если лимит переходов не исчерпан
+func (c *memoryStorage) ConsumeClick(_ context.Context, alias string) (Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.items[alias]
+	if !found {
+		return Item{}, models.ErrLinkNotFound
+	}
+	if err := usable(item); err != nil {
+		return Item{}, err
+	}
+	if item.ClicksExhausted() {
+		return Item{}, models.ErrClicksExhausted
+	}
+
+	item.Clicks++
+	c.put(alias, item)
+
+	return item, nil
+}
+
+// RecordVariant - учитывает переход по варианту A/B-теста
+func (c *memoryStorage) RecordVariant(_ context.Context, alias string, variant int) (Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.items[alias]
+	if !found {
+		return Item{}, models.ErrLinkNotFound
+	}
+	if variant < 0 || variant >= len(item.Variants) {
+		return Item{}, fmt.Errorf("link has no variant %d", variant)
+	}
+
+	// Копия среза, чтобы не менять варианты у ранее выданных Item.
+	item.Variants = append([]Variant(nil), item.Variants...)
+	item.Variants[variant].Clicks++
+	c.put(alias, item)
+
+	return item, nil
+}
+
+// ScanLinks - неудаленные ссылки по возрастанию alias, начиная после after
+func (c *memoryStorage) ScanLinks(_ context.Context, after string, limit int) ([]Record, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-	if item.Expiration > 0 {
-		if time.Now().UnixNano() > item.Expiration {
-			return "", fmt.Errorf("memoryStorage time expired")
+	var batch []Record
+	for key, item := range c.items {
+		if key > after && !item.IsDeleted && !item.Expired() {
+			batch = append(batch, Record{Alias: key, Item: item})
 		}
 	}
-	return item.Object, nil
+	sort.Slice(batch, func(i, j int) bool {
+		return batch[i].Alias < batch[j].Alias
+	})
+	if len(batch) > limit {
+		batch = batch[:limit]
+	}
+
+	return batch, nil
 }
 
-// GetShortURL -
-func (c *memoryStorage) GetShortURL(_ context.Context, originalURL string) (string, error) {
+// SetBlocked - блокирует ссылку с причиной reason, пустая причина снимает блокировку
+func (c *memoryStorage) SetBlocked(_ context.Context, alias, reason string) (Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, found := c.items[alias]
+	if !found {
+		return Item{}, models.ErrLinkNotFound
+	}
+
+	item.BlockedReason = reason
+	item.UpdatedAt = time.Now()
+	c.put(alias, item)
+
+	return item, nil
+}
+
+// Lookup - ссылка в любом состоянии, в том числе удаленная и истекшая
+func (c *memoryStorage) Lookup(_ context.Context, alias string) (Item, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	for key, value := range c.items {
-		if value.Object == originalURL {
-			if value.Expiration > 0 {
-				if time.Now().UnixNano() > value.Expiration {
-					return "", fmt.Errorf("memoryStorage time expired")
-				}
-			}
-			return key, nil
+	item, found := c.items[alias]
+	if !found {
+		return Item{}, models.ErrLinkNotFound
+	}
+
+	return item, nil
+}
+
+// TransferLinks - передает ссылки пользователя from пользователю to: перечисленные в aliases
+// или все, если список пуст. Возвращает переданные сокращения; чужие и несуществующие пропускаются.
+func (c *memoryStorage) TransferLinks(_ context.Context, from, to string, aliases []string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var moved []string
+	for key, item := range c.items {
+		if item.UserID == from && (len(aliases) == 0 || slices.Contains(aliases, key)) {
+			moved = append(moved, key)
+		}
+	}
+	sort.Strings(moved)
+
+	// Конфликты проверяются до изменений, чтобы не передать ссылки частично.
+	for _, key := range moved {
+		item := c.items[key]
+		if item.IsDeleted {
+			continue
 		}
+		item.UserID = to
+		if _, found := c.findDuplicate(key, item); found {
+			return nil, models.ErrAlreadyExists
+		}
+	}
+
+	now := time.Now()
+	for _, key := range moved {
+		item := c.items[key]
+		item.UserID = to
+		item.UpdatedAt = now
+		c.ensureCollection(to, item.Collection, now)
+		c.put(key, item)
 	}
 
-	return "", nil
+	return moved, nil
 }
 
-// DeleteBatch -
-func (c *memoryStorage) DeleteBatch(_ context.Context, urls []string, userID string) error {
+// GetShortURL -
+func (c *memoryStorage) GetShortURL(_ context.Context, originalURL, userID string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	key, _ := c.findDuplicate("", Item{Object: originalURL, UserID: userID})
+
+	return key, nil
+}
+
+// DeleteBatch - помечает удаленными ссылки пользователя, возвращает фактически удаленные
+func (c *memoryStorage) DeleteBatch(_ context.Context, urls []string, userID string) ([]string, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	var (
+		deleted []string
+		events  []OutboxEvent
+	)
 	for _, value := range urls {
-		if c.items[value].UserID == userID {
-			c.items[value] = Item{
-				Object:     c.items[value].Object,
-				Expiration: time.Now().Add(10 * time.Minute).UnixNano(),
-				UserID:     userID,
-				IsDeleted:  true,
-			}
+		item, found := c.items[value]
+		if !found || item.UserID != userID || item.IsDeleted || slices.Contains(deleted, value) {
+			continue
 		}
+		deleted = append(deleted, value)
+		events = append(events, NewOutboxEvent(EventLinkDeleted, userID, EventLink{Alias: value, OriginalURL: item.Object}))
 	}
 
-	return nil
+	// События пишутся до изменения ссылок: если журнал недоступен, удаление не происходит.
+	if err := c.enqueue(events); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, value := range deleted {
+		item := c.items[value]
+		item.IsDeleted = true
+		item.DeletedAt = now
+		item.UpdatedAt = now
+		c.put(value, item)
+	}
+
+	return deleted, nil
 }
 
 // GetBatchByUserID -
-func (c *memoryStorage) GetBatchByUserID(_ context.Context, userID string) (map[string]Item, error) {
+func (c *memoryStorage) GetBatchByUserID(_ context.Context, userID string, query BatchQuery) ([]Record, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var batch []Record
+
+	for key, item := range c.items {
+		if item.UserID == userID && query.match(item) {
+			batch = append(batch, Record{Alias: key, Item: item})
+		}
+	}
+
+	return query.page(batch), nil
+}
+
+// UpdateLink - изменяет ссылку владельца, сохраняя прежний original_url в истории
+func (c *memoryStorage) UpdateLink(_ context.Context, alias, userID string, update LinkUpdate) (Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, err := c.ownedItem(alias, userID)
+	if err != nil {
+		return Item{}, err
+	}
+	if item.IsDeleted {
+		return Item{}, models.ErrGetDeletedLink
+	}
+
+	updated := update.Apply(item)
+	updated.UpdatedAt = time.Now()
+	if updated.Object != item.Object {
+		if _, found := c.findDuplicate(alias, updated); found {
+			return Item{}, models.ErrAlreadyExists
+		}
+
+		c.revSeq++
+		c.revisions[alias] = append(c.revisions[alias], Revision{
+			ID:        c.revSeq,
+			Object:    item.Object,
+			CreatedAt: updated.UpdatedAt,
+		})
+	}
+
+	c.ensureCollection(userID, updated.Collection, updated.UpdatedAt)
+	c.put(alias, updated)
+
+	return updated, nil
+}
+
+// GetRevisions - история original_url ссылки, от новых к старым
+func (c *memoryStorage) GetRevisions(_ context.Context, alias, userID string) ([]Revision, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, err := c.ownedItem(alias, userID); err != nil {
+		return nil, err
+	}
+
+	history := c.revisions[alias]
+	revisions := make([]Revision, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		revisions = append(revisions, history[i])
+	}
+
+	return revisions, nil
+}
+
+// RestoreDeleted - снимает пометку удаления, если ссылка удалена не раньше deletedAfter
+func (c *memoryStorage) RestoreDeleted(_ context.Context, alias, userID string, deletedAfter time.Time) (Item, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	batch := make(map[string]Item)
+	item, err := c.ownedItem(alias, userID)
+	if err != nil {
+		return Item{}, err
+	}
+	if !item.IsDeleted {
+		return item, nil
+	}
+	if item.DeletedAt.Before(deletedAfter) {
+		return Item{}, models.ErrRestorePeriodExpired
+	}
+
+	item.IsDeleted = false
+	item.DeletedAt = time.Time{}
+	item.UpdatedAt = time.Now()
+	if _, found := c.findDuplicate(alias, item); found {
+		return Item{}, models.ErrAlreadyExists
+	}
+
+	c.ensureCollection(userID, item.Collection, item.UpdatedAt)
+	c.put(alias, item)
+
+	return item, nil
+}
+
+// PurgeDeleted - окончательно удаляет ссылки, помеченные удаленными раньше deletedBefore
+func (c *memoryStorage) PurgeDeleted(_ context.Context, deletedBefore time.Time) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
+	var purged []string
 	for key, item := range c.items {
-		if item.UserID == userID {
-			batch[key] = item
+		if item.IsDeleted && item.DeletedAt.Before(deletedBefore) {
+			c.remove(key)
+			delete(c.revisions, key)
+			purged = append(purged, key)
 		}
 	}
 
-	return batch, nil
+	return purged, nil
+}
+
+// CreateCollection - создает пустую коллекцию пользователя
+func (c *memoryStorage) CreateCollection(_ context.Context, collection Collection) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.collections[collection.UserID][collection.Name]; found {
+		return models.ErrAlreadyExists
+	}
+
+	if collection.CreatedAt.IsZero() {
+		collection.CreatedAt = time.Now()
+	}
+	c.ensureCollection(collection.UserID, collection.Name, collection.CreatedAt)
+
+	return nil
+}
+
+// GetCollections - коллекции пользователя с количеством неудаленных ссылок, по имени
+func (c *memoryStorage) GetCollections(_ context.Context, userID string) ([]Collection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, item := range c.items {
+		if item.UserID == userID && item.Collection != "" && !item.IsDeleted {
+			counts[item.Collection]++
+		}
+	}
+
+	collections := make([]Collection, 0, len(c.collections[userID]))
+	for name, collection := range c.collections[userID] {
+		collection.Links = counts[name]
+		collections = append(collections, collection)
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Name < collections[j].Name
+	})
+
+	return collections, nil
+}
+
+// DeleteCollection - удаляет коллекцию, сами ссылки не затрагиваются
+func (c *memoryStorage) DeleteCollection(_ context.Context, userID, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.collections[userID][name]; !found {
+		return models.ErrCollectionNotFound
+	}
+	delete(c.collections[userID], name)
+
+	return nil
+}
+
+// CreateWebhook - сохраняет подписку пользователя
+func (c *memoryStorage) CreateWebhook(_ context.Context, webhook Webhook) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.webhooks[webhook.ID]; found {
+		return models.ErrAlreadyExists
+	}
+	if webhook.CreatedAt.IsZero() {
+		webhook.CreatedAt = time.Now()
+	}
+	webhook.Events = append([]string(nil), webhook.Events...)
+	c.webhooks[webhook.ID] = webhook
+
+	return nil
+}
+
+// GetWebhooks - подписки пользователя в порядке создания
+func (c *memoryStorage) GetWebhooks(_ context.Context, userID string) ([]Webhook, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	webhooks := make([]Webhook, 0)
+	for _, webhook := range c.webhooks {
+		if webhook.UserID == userID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+// DeleteWebhook - удаляет подписку вместе с журналом ее доставок
+func (c *memoryStorage) DeleteWebhook(_ context.Context, userID, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if webhook, found := c.webhooks[id]; !found || webhook.UserID != userID {
+		return models.ErrWebhookNotFound
+	}
+	delete(c.webhooks, id)
+	for key, delivery := range c.deliveries {
+		if delivery.WebhookID == id {
+			delete(c.deliveries, key)
+		}
+	}
+
+	return nil
+}
+
+// AddDeliveries - ставит доставки в очередь, без NextAttemptAt первая попытка - сразу
+func (c *memoryStorage) AddDeliveries(_ context.Context, deliveries []Delivery) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, delivery := range deliveries {
+		c.deliverySeq++
+		delivery.ID = c.deliverySeq
+		delivery.Status = DeliveryPending
+		delivery.CreatedAt, delivery.UpdatedAt = now, now
+		if delivery.NextAttemptAt.IsZero() {
+			delivery.NextAttemptAt = now
+		}
+		c.deliveries[delivery.ID] = delivery
+	}
+
+	return nil
+}
+
+// ClaimDeliveries - забирает до limit доставок, время попытки которых наступило, и откладывает их
+// на lease, чтобы их не взял другой обработчик
+func (c *memoryStorage) ClaimDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var due []Delivery
+	for _, delivery := range c.deliveries {
+		if delivery.Status == DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i, delivery := range due {
+		delivery.NextAttemptAt = now.Add(lease)
+		c.deliveries[delivery.ID] = delivery
+
+		webhook := c.webhooks[delivery.WebhookID]
+		delivery.URL, delivery.Secret = webhook.URL, webhook.Secret
+		due[i] = delivery
+	}
+
+	return due, nil
+}
+
+// UpdateDelivery - сохраняет результат попытки доставки
+func (c *memoryStorage) UpdateDelivery(_ context.Context, delivery Delivery) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored, found := c.deliveries[delivery.ID]
+	if !found {
+		return models.ErrDeliveryNotFound
+	}
+
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.LastError = delivery.LastError
+	stored.ResponseCode = delivery.ResponseCode
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.UpdatedAt = time.Now()
+	c.deliveries[delivery.ID] = stored
+
+	return nil
+}
+
+// GetDeliveries - журнал доставок подписки от новых к старым, status фильтрует по статусу
+func (c *memoryStorage) GetDeliveries(_ context.Context, userID, webhookID, status string, limit int) ([]Delivery, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if webhook, found := c.webhooks[webhookID]; !found || webhook.UserID != userID {
+		return nil, models.ErrWebhookNotFound
+	}
+
+	deliveries := make([]Delivery, 0)
+	for _, delivery := range c.deliveries {
+		if delivery.WebhookID == webhookID && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// RetryDelivery - возвращает доставку в очередь с обнуленным счетчиком попыток
+func (c *memoryStorage) RetryDelivery(_ context.Context, userID, webhookID string, id int64) (Delivery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delivery, found := c.deliveries[id]
+	if !found || delivery.WebhookID != webhookID || delivery.UserID != userID {
+		return Delivery{}, models.ErrDeliveryNotFound
+	}
+
+	now := time.Now()
+	delivery.Status = DeliveryPending
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.ResponseCode = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	c.deliveries[id] = delivery
+
+	return delivery, nil
+}
+
+// AddOutbox - ставит события в очередь на публикацию
+func (c *memoryStorage) AddOutbox(_ context.Context, events []OutboxEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.enqueue(events)
+}
+
+// ClaimOutbox - забирает до limit событий по порядку и откладывает их на lease,
+// чтобы их не взял другой публикатор
+func (c *memoryStorage) ClaimOutbox(_ context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxEvent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var claimed []OutboxEvent
+	for i := range c.outbox {
+		if len(claimed) == limit {
+			break
+		}
+		if c.outbox[i].lockedUntil.After(now) {
+			continue
+		}
+		c.outbox[i].lockedUntil = now.Add(lease)
+		claimed = append(claimed, c.outbox[i].OutboxEvent)
+	}
+
+	return claimed, nil
+}
+
+// AckOutbox - удаляет опубликованные события
+func (c *memoryStorage) AckOutbox(_ context.Context, ids []int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.journal != nil {
+		if err := c.journal.ack(ids); err != nil {
+			return err
+		}
+	}
+
+	c.outbox = slices.DeleteFunc(c.outbox, func(entry outboxEntry) bool {
+		return slices.Contains(ids, entry.ID)
+	})
+
+	return nil
+}
+
+// enqueue нумерует события и добавляет их в outbox; вызывается под c.mu.
+func (c *memoryStorage) enqueue(events []OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	for i := range events {
+		events[i].ID = c.outboxSeq + int64(i) + 1
+	}
+	if c.journal != nil {
+		if err := c.journal.append(events); err != nil {
+			return err
+		}
+	}
+
+	c.outboxSeq += int64(len(events))
+	for _, event := range events {
+		c.outbox = append(c.outbox, outboxEntry{OutboxEvent: event})
+	}
+
+	return nil
+}
+
+// attachJournal подключает журнал outbox и загружает из него неопубликованные события.
+func (c *memoryStorage) attachJournal(path string) error {
+	journal, pending, err := openOutboxJournal(path)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.journal = journal
+	for _, event := range pending {
+		c.outbox = append(c.outbox, outboxEntry{OutboxEvent: event})
+		c.outboxSeq = max(c.outboxSeq, event.ID)
+	}
+
+	return nil
+}
+
+// AddDeleteJob - ставит задание на удаление в очередь, без NextAttemptAt первая попытка - сразу
+func (c *memoryStorage) AddDeleteJob(_ context.Context, job DeleteJob) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.deleteJobs[job.ID]; found {
+		return models.ErrAlreadyExists
+	}
+
+	now := time.Now()
+	job.URLs = slices.Clone(job.URLs)
+	job.Status = JobPending
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	job.UpdatedAt = now
+	if job.NextAttemptAt.IsZero() {
+		job.NextAttemptAt = now
+	}
+
+	if c.jobJournal != nil {
+		if err := c.jobJournal.write([]DeleteJob{job}); err != nil {
+			return err
+		}
+	}
+	c.deleteJobs[job.ID] = job
+
+	return nil
+}
+
+// ClaimDeleteJobs - забирает до limit заданий, время попытки которых наступило, и откладывает их
+// на lease, чтобы их не взял другой обработчик
+func (c *memoryStorage) ClaimDeleteJobs(_ context.Context, now time.Time, lease time.Duration, limit int) ([]DeleteJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var due []DeleteJob
+	for _, job := range c.deleteJobs {
+		if job.Status == JobPending && !job.NextAttemptAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i, job := range due {
+		job.NextAttemptAt = now.Add(lease)
+		c.deleteJobs[job.ID] = job
+
+		job.URLs = slices.Clone(job.URLs)
+		due[i] = job
+	}
+
+	return due, nil
+}
+
+// UpdateDeleteJobs - сохраняет результат попытки выполнения заданий
+func (c *memoryStorage) UpdateDeleteJobs(_ context.Context, jobs []DeleteJob) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	updated := make([]DeleteJob, 0, len(jobs))
+	for _, job := range jobs {
+		stored, found := c.deleteJobs[job.ID]
+		if !found {
+			return models.ErrJobNotFound
+		}
+
+		stored.Status = job.Status
+		stored.Attempts = job.Attempts
+		stored.LastError = job.LastError
+		stored.NextAttemptAt = job.NextAttemptAt
+		stored.UpdatedAt = now
+		updated = append(updated, stored)
+	}
+
+	if c.jobJournal != nil {
+		if err := c.jobJournal.write(updated); err != nil {
+			return err
+		}
+	}
+	for _, job := range updated {
+		c.deleteJobs[job.ID] = job
+	}
+
+	return nil
+}
+
+// GetDeleteJob - задание на удаление, созданное пользователем
+func (c *memoryStorage) GetDeleteJob(_ context.Context, userID, id string) (DeleteJob, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	job, found := c.deleteJobs[id]
+	if !found || job.UserID != userID {
+		return DeleteJob{}, models.ErrJobNotFound
+	}
+
+	job.URLs = slices.Clone(job.URLs)
+	return job, nil
+}
+
+// PurgeDeleteJobs - удаляет завершенные задания, последний раз изменявшиеся раньше finishedBefore
+func (c *memoryStorage) PurgeDeleteJobs(_ context.Context, finishedBefore time.Time) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var purged int
+	for id, job := range c.deleteJobs {
+		if job.Status != JobPending && job.UpdatedAt.Before(finishedBefore) {
+			delete(c.deleteJobs, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// TryLock - берет именованную блокировку без ожидания. Хранилище в памяти не разделяется
+// между экземплярами сервиса, поэтому блокировка действует только внутри процесса.
+func (c *memoryStorage) TryLock(_ context.Context, name string) (func(), bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.locks[name] {
+		return nil, false, nil
+	}
+	c.locks[name] = true
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.locks, name)
+	}, true, nil
+}
+
+// AddJobRun - сохраняет запуск фоновой задачи, хранятся последние maxJobRuns запусков задачи
+func (c *memoryStorage) AddJobRun(_ context.Context, run JobRun) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.runSeq++
+	run.ID = c.runSeq
+	runs := append(c.jobRuns[run.Job], run)
+	if len(runs) > maxJobRuns {
+		runs = slices.Clone(runs[len(runs)-maxJobRuns:])
+	}
+	c.jobRuns[run.Job] = runs
+
+	return nil
+}
+
+// GetJobRuns - последние запуски фоновой задачи от новых к старым
+func (c *memoryStorage) GetJobRuns(_ context.Context, job string, limit int) ([]JobRun, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	runs := make([]JobRun, 0, min(limit, len(c.jobRuns[job])))
+	stored := c.jobRuns[job]
+	for i := len(stored) - 1; i >= 0 && len(runs) < limit; i-- {
+		runs = append(runs, stored[i])
+	}
+
+	return runs, nil
+}
+
+// attachJobJournal подключает журнал очереди удаления и загружает из него невыполненные задания.
+func (c *memoryStorage) attachJobJournal(path string) error {
+	journal, pending, err := openJobJournal(path)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.jobJournal = journal
+	for _, job := range pending {
+		c.deleteJobs[job.ID] = job
+	}
+
+	return nil
+}
+
+// sortedKeys - сокращения в порядке возрастания, чтобы события одного Set шли в предсказуемом порядке.
+func sortedKeys(data map[string]Item) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ensureCollection заводит коллекцию при первом упоминании в ссылке.
+func (c *memoryStorage) ensureCollection(userID, name string, createdAt time.Time) {
+	if name == "" {
+		return
+	}
+	if _, found := c.collections[userID][name]; found {
+		return
+	}
+	if c.collections[userID] == nil {
+		c.collections[userID] = make(map[string]Collection)
+	}
+	c.collections[userID][name] = Collection{Name: name, UserID: userID, CreatedAt: createdAt}
+}
+
+// ownedItem возвращает ссылку, если она существует и принадлежит пользователю.
+func (c *memoryStorage) ownedItem(alias, userID string) (Item, error) {
+	item, found := c.items[alias]
+	if !found {
+		return Item{}, models.ErrLinkNotFound
+	}
+	if item.UserID != userID {
+		return Item{}, models.ErrNotOwner
+	}
+	return item, nil
 }
 
 // GetStats - returning distinct urls and users from storage
@@ -164,4 +1212,15 @@ func (c *memoryStorage) Close() {
 	defer c.mu.Unlock()
 
 	c.items = make(map[string]Item)
+	c.originals = make(map[dedupKey]map[string]struct{})
+	c.revisions = make(map[string][]Revision)
+	c.collections = make(map[string]map[string]Collection)
+	if c.journal != nil {
+		_ = c.journal.file.Close()
+		c.journal = nil
+	}
+	if c.jobJournal != nil {
+		_ = c.jobJournal.file.Close()
+		c.jobJournal = nil
+	}
 }
@@ -0,0 +1,762 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_memoryStorage_Dedup(t *testing.T) {
+	tests := []struct {
+		name         string
+		dedup        DedupScope
+		userID       string
+		wantErr      error
+		wantShortURL string
+	}{
+		{
+			name:         "global scope, other user",
+			dedup:        DedupGlobal,
+			userID:       "user-b",
+			wantErr:      models.ErrAlreadyExists,
+			wantShortURL: "aaaaaa",
+		},
+		{
+			name:         "per-user scope, same user",
+			dedup:        DedupPerUser,
+			userID:       "user-a",
+			wantErr:      models.ErrAlreadyExists,
+			wantShortURL: "aaaaaa",
+		},
+		{
+			name:         "per-user scope, other user",
+			dedup:        DedupPerUser,
+			userID:       "user-b",
+			wantErr:      nil,
+			wantShortURL: "bbbbbb",
+		},
+		{
+			name:         "none scope, same user",
+			dedup:        DedupNone,
+			userID:       "user-a",
+			wantErr:      nil,
+			wantShortURL: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newMemoryStorage()
+			c.setDedup(tt.dedup)
+
+			err := c.Set(ctx, map[string]Item{
+				"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+			})
+			require.NoError(t, err)
+
+			err = c.Set(ctx, map[string]Item{
+				"bbbbbb": {Object: "https://yandex.ru", UserID: tt.userID},
+			})
+			require.ErrorIs(t, err, tt.wantErr)
+
+			got, err := c.GetShortURL(ctx, "https://yandex.ru", tt.userID)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantShortURL, got)
+		})
+	}
+}
+
+func Test_memoryStorage_DedupSkipsDeleted(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", IsDeleted: true},
+	})
+	require.NoError(t, err)
+
+	err = c.Set(ctx, map[string]Item{
+		"bbbbbb": {Object: "https://yandex.ru", UserID: "user-a"},
+	})
+	require.NoError(t, err)
+}
+
+func Test_memoryStorage_DedupBatch(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+	c.setDedup(DedupPerUser)
+
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://yandex.ru", UserID: "user-a"},
+	})
+	require.ErrorIs(t, err, models.ErrAlreadyExists)
+
+	err = c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://yandex.ru", UserID: "user-b"},
+		"cccccc": {Object: "https://yandex.ru", UserID: "user-c", IsDeleted: true},
+	})
+	require.NoError(t, err)
+}
+
+func Test_memoryStorage_DedupIndex(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	require.NoError(t, c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"expire": {Object: "https://mail.ru", UserID: "user-a", Expiration: time.Now().Add(-time.Minute).UnixNano()},
+	}))
+
+	// Истекшая ссылка не мешает сократить тот же адрес.
+	require.NoError(t, c.Set(ctx, map[string]Item{"bbbbbb": {Object: "https://mail.ru", UserID: "user-a"}}))
+
+	// После смены адреса прежний освобождается, а новый занят.
+	newURL := "https://ya.ru"
+	_, err := c.UpdateLink(ctx, "aaaaaa", "user-a", LinkUpdate{Object: &newURL})
+	require.NoError(t, err)
+	got, err := c.GetShortURL(ctx, "https://yandex.ru", "user-a")
+	require.NoError(t, err)
+	require.Empty(t, got)
+	got, err = c.GetShortURL(ctx, newURL, "user-b")
+	require.NoError(t, err)
+	require.Equal(t, "aaaaaa", got)
+
+	// Удаленная ссылка освобождает адрес, восстановление занимает его снова.
+	_, err = c.DeleteBatch(ctx, []string{"aaaaaa"}, "user-a")
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, map[string]Item{"cccccc": {Object: newURL, UserID: "user-b"}}))
+	_, err = c.RestoreDeleted(ctx, "aaaaaa", "user-a", time.Now().Add(-time.Hour))
+	require.ErrorIs(t, err, models.ErrAlreadyExists)
+
+	// Смена области перестраивает индекс.
+	c.setDedup(DedupPerUser)
+	_, err = c.RestoreDeleted(ctx, "aaaaaa", "user-a", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	got, err = c.GetShortURL(ctx, newURL, "user-a")
+	require.NoError(t, err)
+	require.Equal(t, "aaaaaa", got)
+
+	purged, err := c.PurgeDeleted(ctx, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, purged)
+	require.Len(t, c.originals, 3)
+}
+
+func Test_memoryStorage_UpdateLink(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		alias   string
+		userID  string
+		object  string
+		wantErr error
+	}{
+		{
+			name:    "not existing link",
+			alias:   "cccccc",
+			userID:  "user-a",
+			object:  "https://google.com",
+			wantErr: models.ErrLinkNotFound,
+		},
+		{
+			name:    "foreign link",
+			alias:   "aaaaaa",
+			userID:  "user-b",
+			object:  "https://google.com",
+			wantErr: models.ErrNotOwner,
+		},
+		{
+			name:    "duplicate destination",
+			alias:   "aaaaaa",
+			userID:  "user-a",
+			object:  "https://ya.ru",
+			wantErr: models.ErrAlreadyExists,
+		},
+		{
+			name:    "valid update",
+			alias:   "aaaaaa",
+			userID:  "user-a",
+			object:  "https://google.com",
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := c.UpdateLink(ctx, tt.alias, tt.userID, LinkUpdate{Object: &tt.object})
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				require.Equal(t, tt.object, item.Object)
+			}
+		})
+	}
+
+	revisions, err := c.GetRevisions(ctx, "aaaaaa", "user-a")
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, "https://yandex.ru", revisions[0].Object)
+
+	_, err = c.GetRevisions(ctx, "aaaaaa", "user-b")
+	require.ErrorIs(t, err, models.ErrNotOwner)
+}
+
+func Test_memoryStorage_RestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a"},
+	})
+	require.NoError(t, err)
+	removed, err := c.DeleteBatch(ctx, []string{"aaaaaa", "bbbbbb", "cccccc"}, "user-a")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"aaaaaa", "bbbbbb"}, removed)
+
+	removed, err = c.DeleteBatch(ctx, []string{"aaaaaa"}, "user-a")
+	require.NoError(t, err)
+	require.Empty(t, removed)
+
+	deleted, err := c.GetBatchByUserID(ctx, "user-a", BatchQuery{Deleted: DeletedOnly})
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+
+	_, err = c.RestoreDeleted(ctx, "aaaaaa", "user-b", time.Now().Add(-time.Hour))
+	require.ErrorIs(t, err, models.ErrNotOwner)
+
+	_, err = c.RestoreDeleted(ctx, "aaaaaa", "user-a", time.Now().Add(time.Hour))
+	require.ErrorIs(t, err, models.ErrRestorePeriodExpired)
+
+	item, err := c.RestoreDeleted(ctx, "aaaaaa", "user-a", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.False(t, item.IsDeleted)
+
+	purged, err := c.PurgeDeleted(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []string{"bbbbbb"}, purged)
+
+	_, err = c.Get(ctx, "aaaaaa")
+	require.NoError(t, err)
+}
+
+func Test_memoryStorage_GetBatchByUserIDPaging(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+	c.setDedup(DedupNone)
+
+	start := time.Now()
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", CreatedAt: start},
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a", CreatedAt: start.Add(time.Second)},
+		"cccccc": {Object: "https://google.com", UserID: "user-a", CreatedAt: start.Add(2 * time.Second)},
+		"dddddd": {Object: "https://yandex.ru/maps", UserID: "user-b", CreatedAt: start},
+	})
+	require.NoError(t, err)
+	_, err = c.DeleteBatch(ctx, []string{"bbbbbb"}, "user-a")
+	require.NoError(t, err)
+
+	aliases := func(records []Record) []string {
+		var result []string
+		for _, record := range records {
+			result = append(result, record.Alias)
+		}
+		return result
+	}
+
+	page, err := c.GetBatchByUserID(ctx, "user-a", BatchQuery{Limit: 2, Desc: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"cccccc", "bbbbbb"}, aliases(page))
+
+	page, err = c.GetBatchByUserID(ctx, "user-a", BatchQuery{
+		Limit: 2,
+		Desc:  true,
+		After: &Cursor{CreatedAt: page[1].CreatedAt, Alias: page[1].Alias},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaaaaa"}, aliases(page))
+
+	page, err = c.GetBatchByUserID(ctx, "user-a", BatchQuery{Deleted: DeletedExclude, Search: "YANDEX"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaaaaa"}, aliases(page))
+
+	page, err = c.GetBatchByUserID(ctx, "user-a", BatchQuery{Deleted: DeletedOnly})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bbbbbb"}, aliases(page))
+}
+
+func Test_memoryStorage_Metadata(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", Title: "Yandex"},
+	})
+	require.NoError(t, err)
+
+	batch, err := c.GetBatchByUserID(ctx, "user-a", BatchQuery{})
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	created := batch[0].Item
+	require.Equal(t, "Yandex", created.Title)
+	require.False(t, created.CreatedAt.IsZero())
+	require.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	note := "landing page"
+	item, err := c.UpdateLink(ctx, "aaaaaa", "user-a", LinkUpdate{Note: &note})
+	require.NoError(t, err)
+	require.Equal(t, "Yandex", item.Title)
+	require.Equal(t, note, item.Note)
+	require.Equal(t, created.CreatedAt, item.CreatedAt)
+	require.False(t, item.UpdatedAt.Before(created.UpdatedAt))
+
+	revisions, err := c.GetRevisions(ctx, "aaaaaa", "user-a")
+	require.NoError(t, err)
+	require.Empty(t, revisions)
+}
+
+func Test_memoryStorage_TagsAndCollections(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", Tags: []string{"promo"}, Collection: "spring"},
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a", Tags: []string{"promo", "mail"}},
+		"cccccc": {Object: "https://google.com", UserID: "user-b", Tags: []string{"promo"}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, c.CreateCollection(ctx, Collection{Name: "autumn", UserID: "user-a"}))
+	require.ErrorIs(t, c.CreateCollection(ctx, Collection{Name: "spring", UserID: "user-a"}), models.ErrAlreadyExists)
+
+	tagged, err := c.GetBatchByUserID(ctx, "user-a", BatchQuery{Tag: "promo"})
+	require.NoError(t, err)
+	require.Len(t, tagged, 2)
+
+	tags, collection := []string{"mail"}, "autumn"
+	_, err = c.UpdateLink(ctx, "bbbbbb", "user-a", LinkUpdate{Tags: &tags, Collection: &collection})
+	require.NoError(t, err)
+
+	tagged, err = c.GetBatchByUserID(ctx, "user-a", BatchQuery{Tag: "promo"})
+	require.NoError(t, err)
+	require.Len(t, tagged, 1)
+
+	collections, err := c.GetCollections(ctx, "user-a")
+	require.NoError(t, err)
+	require.Len(t, collections, 2)
+	require.Equal(t, "autumn", collections[0].Name)
+	require.Equal(t, 1, collections[0].Links)
+	require.Equal(t, "spring", collections[1].Name)
+	require.Equal(t, 1, collections[1].Links)
+
+	require.NoError(t, c.DeleteCollection(ctx, "user-a", "spring"))
+	require.ErrorIs(t, c.DeleteCollection(ctx, "user-a", "spring"), models.ErrCollectionNotFound)
+}
+
+func Test_memoryStorage_ConsumeClick(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	const maxClicks = 3
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", MaxClicks: maxClicks},
+	})
+	require.NoError(t, err)
+
+	var (
+		wg       sync.WaitGroup
+		consumed atomic.Int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.ConsumeClick(ctx, "aaaaaa")
+			switch {
+			case err == nil:
+				consumed.Add(1)
+			case !errors.Is(err, models.ErrClicksExhausted):
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(maxClicks), consumed.Load())
+
+	item, err := c.Get(ctx, "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, maxClicks, item.Clicks)
+	require.True(t, item.ClicksExhausted())
+
+	_, err = c.ConsumeClick(ctx, "missing")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+
+	// Истекшая ссылка не открывается ни через Get, ни через ConsumeClick, переход не учитывается.
+	require.NoError(t, c.Set(ctx, map[string]Item{
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a", MaxClicks: maxClicks, Expiration: time.Now().Add(-time.Minute).UnixNano()},
+	}))
+	_, err = c.Get(ctx, "bbbbbb")
+	require.ErrorIs(t, err, models.ErrLinkExpired)
+	_, err = c.ConsumeClick(ctx, "bbbbbb")
+	require.ErrorIs(t, err, models.ErrLinkExpired)
+
+	item, err = c.Lookup(ctx, "bbbbbb")
+	require.NoError(t, err)
+	require.Zero(t, item.Clicks)
+}
+
+func Test_memoryStorage_RecordVariant(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	err := c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", Variants: []Variant{
+			{Destination: "https://a.example.com", Weight: 1},
+			{Destination: "https://b.example.com", Weight: 1},
+		}},
+	})
+	require.NoError(t, err)
+
+	before, err := c.Get(ctx, "aaaaaa")
+	require.NoError(t, err)
+
+	item, err := c.RecordVariant(ctx, "aaaaaa", 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), item.Variants[1].Clicks)
+	require.Zero(t, before.Variants[1].Clicks)
+
+	_, err = c.RecordVariant(ctx, "aaaaaa", 2)
+	require.Error(t, err)
+}
+
+func Test_memoryStorage_ScanLinksAndSetBlocked(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	err := c.Set(ctx, map[string]Item{
+		"cccccc": {Object: "https://ya.ru", UserID: "user-b"},
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://google.com", UserID: "user-a", IsDeleted: true},
+		"dddddd": {Object: "https://mail.ru", UserID: "user-a"},
+	})
+	require.NoError(t, err)
+
+	page, err := c.ScanLinks(ctx, "", 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, "aaaaaa", page[0].Alias)
+	require.Equal(t, "cccccc", page[1].Alias)
+
+	page, err = c.ScanLinks(ctx, "cccccc", 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "dddddd", page[0].Alias)
+
+	item, err := c.SetBlocked(ctx, "aaaaaa", "blocklist: yandex.ru")
+	require.NoError(t, err)
+	require.Equal(t, "blocklist: yandex.ru", item.BlockedReason)
+
+	_, err = c.SetBlocked(ctx, "zzzzzz", "blocklist: yandex.ru")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+}
+
+func Test_memoryStorage_Webhooks(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	require.NoError(t, c.CreateWebhook(ctx, Webhook{ID: "hook-a", UserID: "user-a", URL: "https://example.com/hook",
+		Secret: "secret", Events: []string{"link.created"}}))
+	require.ErrorIs(t, c.CreateWebhook(ctx, Webhook{ID: "hook-a", UserID: "user-a"}), models.ErrAlreadyExists)
+
+	webhooks, err := c.GetWebhooks(ctx, "user-a")
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+
+	require.NoError(t, c.AddDeliveries(ctx, []Delivery{
+		{WebhookID: "hook-a", UserID: "user-a", Event: "link.created", Payload: []byte(`{}`)},
+		{WebhookID: "hook-a", UserID: "user-a", Event: "link.created", Payload: []byte(`{}`), NextAttemptAt: time.Now().Add(time.Hour)},
+	}))
+
+	now := time.Now()
+	claimed, err := c.ClaimDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "https://example.com/hook", claimed[0].URL)
+	require.Equal(t, "secret", claimed[0].Secret)
+
+	// Взятая доставка отложена до конца аренды.
+	again, err := c.ClaimDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	claimed[0].Status, claimed[0].Attempts = DeliveryDead, 8
+	require.NoError(t, c.UpdateDelivery(ctx, claimed[0]))
+
+	dead, err := c.GetDeliveries(ctx, "user-a", "hook-a", DeliveryDead, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+
+	_, err = c.GetDeliveries(ctx, "user-b", "hook-a", "", 10)
+	require.ErrorIs(t, err, models.ErrWebhookNotFound)
+
+	retried, err := c.RetryDelivery(ctx, "user-a", "hook-a", dead[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, DeliveryPending, retried.Status)
+	require.Zero(t, retried.Attempts)
+
+	_, err = c.RetryDelivery(ctx, "user-b", "hook-a", dead[0].ID)
+	require.ErrorIs(t, err, models.ErrDeliveryNotFound)
+
+	require.ErrorIs(t, c.DeleteWebhook(ctx, "user-b", "hook-a"), models.ErrWebhookNotFound)
+	require.NoError(t, c.DeleteWebhook(ctx, "user-a", "hook-a"))
+	require.Empty(t, c.deliveries)
+}
+
+func Test_memoryStorage_Outbox(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	require.NoError(t, c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", MaxClicks: 3},
+	}))
+	// Повторная запись существующей ссылки - не создание.
+	require.NoError(t, c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a", MaxClicks: 3},
+	}))
+	deleted, err := c.DeleteBatch(ctx, []string{"aaaaaa", "aaaaaa", "missing"}, "user-a")
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaaaaa"}, deleted)
+
+	now := time.Now()
+	events, err := c.ClaimOutbox(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, EventLinkCreated, events[0].Type)
+	require.Equal(t, EventLink{Alias: "aaaaaa", OriginalURL: "https://yandex.ru", MaxClicks: 3}, events[0].Link)
+	require.Equal(t, EventLinkDeleted, events[1].Type)
+	require.NotEqual(t, events[0].EventID, events[1].EventID)
+
+	again, err := c.ClaimOutbox(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	require.NoError(t, c.AckOutbox(ctx, []int64{events[0].ID}))
+	again, err = c.ClaimOutbox(ctx, now.Add(time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []OutboxEvent{events[1]}, again)
+}
+
+func Test_memoryStorage_DeleteJobs(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	now := time.Now()
+	require.NoError(t, c.AddDeleteJob(ctx, DeleteJob{ID: "job-1", UserID: "user-a", URLs: []string{"aaaaaa"}, CreatedAt: now}))
+	require.NoError(t, c.AddDeleteJob(ctx, DeleteJob{ID: "job-2", UserID: "user-b", URLs: []string{"bbbbbb"},
+		CreatedAt: now.Add(time.Second)}))
+	require.ErrorIs(t, c.AddDeleteJob(ctx, DeleteJob{ID: "job-1", UserID: "user-a"}), models.ErrAlreadyExists)
+
+	jobs, err := c.ClaimDeleteJobs(ctx, now.Add(time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	require.Equal(t, "job-1", jobs[0].ID)
+	require.Equal(t, JobPending, jobs[0].Status)
+
+	// Взятые задания не выдаются повторно до окончания аренды.
+	again, err := c.ClaimDeleteJobs(ctx, now.Add(time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, again)
+
+	jobs[0].Status, jobs[0].Attempts = JobDone, 1
+	jobs[1].Attempts, jobs[1].LastError, jobs[1].NextAttemptAt = 1, "storage is down", now.Add(time.Hour)
+	require.NoError(t, c.UpdateDeleteJobs(ctx, jobs))
+	require.ErrorIs(t, c.UpdateDeleteJobs(ctx, []DeleteJob{{ID: "missing"}}), models.ErrJobNotFound)
+
+	job, err := c.GetDeleteJob(ctx, "user-a", "job-1")
+	require.NoError(t, err)
+	require.Equal(t, JobDone, job.Status)
+	require.Equal(t, []string{"aaaaaa"}, job.URLs)
+	_, err = c.GetDeleteJob(ctx, "user-b", "job-1")
+	require.ErrorIs(t, err, models.ErrJobNotFound)
+
+	again, err = c.ClaimDeleteJobs(ctx, now.Add(2*time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	require.Equal(t, "job-2", again[0].ID)
+	require.Equal(t, "storage is down", again[0].LastError)
+
+	// Вычищаются только завершенные задания.
+	purged, err := c.PurgeDeleteJobs(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+	_, err = c.GetDeleteJob(ctx, "user-a", "job-1")
+	require.ErrorIs(t, err, models.ErrJobNotFound)
+	_, err = c.GetDeleteJob(ctx, "user-b", "job-2")
+	require.NoError(t, err)
+}
+
+func Test_memoryStorage_TryLock(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	unlock, acquired, err := c.TryLock(ctx, "job")
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	_, acquired, err = c.TryLock(ctx, "job")
+	require.NoError(t, err)
+	require.False(t, acquired)
+
+	unlock()
+	unlock, acquired, err = c.TryLock(ctx, "job")
+	require.NoError(t, err)
+	require.True(t, acquired)
+	unlock()
+}
+
+func Test_memoryStorage_JobRuns(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	for i := 0; i < maxJobRuns+5; i++ {
+		require.NoError(t, c.AddJobRun(ctx, JobRun{Job: "purge", Status: JobRunSucceeded, Processed: i}))
+	}
+	require.NoError(t, c.AddJobRun(ctx, JobRun{Job: "rescan", Status: JobRunFailed}))
+
+	runs, err := c.GetJobRuns(ctx, "purge", 2)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, maxJobRuns+4, runs[0].Processed)
+	require.Equal(t, maxJobRuns+3, runs[1].Processed)
+
+	// Хранятся только последние maxJobRuns запусков.
+	runs, err = c.GetJobRuns(ctx, "purge", 1000)
+	require.NoError(t, err)
+	require.Len(t, runs, maxJobRuns)
+	require.Equal(t, 5, runs[len(runs)-1].Processed)
+
+	runs, err = c.GetJobRuns(ctx, "missing", 10)
+	require.NoError(t, err)
+	require.Empty(t, runs)
+}
+
+func Test_memoryStorage_ImportLinks(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+	require.NoError(t, c.Set(ctx, map[string]Item{"taken": {Object: "https://old.example", UserID: "u1"}}))
+
+	createdAt := time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)
+	results, err := c.ImportLinks(ctx, []Record{
+		{Alias: "new", Item: Item{Object: "https://ya.ru", UserID: "u2", Collection: "imported", CreatedAt: createdAt}},
+		{Alias: "taken", Item: Item{Object: "https://example.com", UserID: "u2"}},
+		{Alias: "dup", Item: Item{Object: "https://old.example", UserID: "u2"}},
+		{Alias: "new", Item: Item{Object: "https://example.org", UserID: "u2"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, models.ErrAliasTaken, models.ErrAlreadyExists, models.ErrAliasTaken}, results)
+
+	item, err := c.Get(ctx, "new")
+	require.NoError(t, err)
+	require.Equal(t, createdAt, item.CreatedAt)
+	require.Equal(t, createdAt, item.UpdatedAt)
+
+	item, err = c.Get(ctx, "taken")
+	require.NoError(t, err)
+	require.Equal(t, "https://old.example", item.Object)
+
+	collections, err := c.GetCollections(ctx, "u2")
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+}
+
+func Test_memoryStorage_ImportLinksChunks(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+
+	// Записи больше нескольких частей, повтор original_url попадает в следующую часть.
+	records := make([]Record, 2*importChunkSize+10)
+	for i := range records {
+		records[i] = Record{Alias: fmt.Sprintf("a%d", i), Item: Item{Object: fmt.Sprintf("https://example.com/%d", i), UserID: "u1"}}
+	}
+	records[importChunkSize+1].Item.Object = records[0].Item.Object
+	records[len(records)-1].Alias = records[1].Alias
+
+	results, err := c.ImportLinks(ctx, records)
+	require.NoError(t, err)
+	require.Len(t, results, len(records))
+	for i, result := range results {
+		switch i {
+		case importChunkSize + 1:
+			require.ErrorIs(t, result, models.ErrAlreadyExists)
+		case len(records) - 1:
+			require.ErrorIs(t, result, models.ErrAliasTaken)
+		default:
+			require.NoError(t, result, i)
+		}
+	}
+
+	alias, err := c.GetShortURL(ctx, records[0].Item.Object, "u1")
+	require.NoError(t, err)
+	require.Equal(t, "a0", alias)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = c.ImportLinks(canceled, records[:1])
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_memoryStorage_LookupAndTransfer(t *testing.T) {
+	ctx := context.Background()
+	c := newMemoryStorage()
+	c.setDedup(DedupPerUser)
+
+	require.NoError(t, c.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://ya.ru", UserID: "user-a", Collection: "work"},
+		"bbbbbb": {Object: "https://google.com", UserID: "user-a", IsDeleted: true},
+		"cccccc": {Object: "https://mail.ru", UserID: "user-a"},
+		"dddddd": {Object: "https://mail.ru", UserID: "user-b"},
+	}))
+
+	item, err := c.Lookup(ctx, "bbbbbb")
+	require.NoError(t, err)
+	require.True(t, item.IsDeleted)
+
+	_, err = c.Lookup(ctx, "zzzzzz")
+	require.ErrorIs(t, err, models.ErrLinkNotFound)
+
+	// У user-b уже есть https://mail.ru: передача всех ссылок не выполняется целиком.
+	_, err = c.TransferLinks(ctx, "user-a", "user-b", nil)
+	require.ErrorIs(t, err, models.ErrAlreadyExists)
+	item, err = c.Lookup(ctx, "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, "user-a", item.UserID)
+
+	moved, err := c.TransferLinks(ctx, "user-a", "user-b", []string{"aaaaaa", "bbbbbb", "dddddd"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaaaaa", "bbbbbb"}, moved)
+
+	item, err = c.Lookup(ctx, "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, "user-b", item.UserID)
+
+	collections, err := c.GetCollections(ctx, "user-b")
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	require.Equal(t, "work", collections[0].Name)
+}
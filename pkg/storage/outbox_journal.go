@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// outboxJournalSuffix - журнал outbox хранилища в памяти лежит рядом с файлом ссылок
+const outboxJournalSuffix = ".outbox"
+
+// journalRecord - строка журнала: новое событие или подтверждение его публикации.
+type journalRecord struct {
+	Event *OutboxEvent `json:"event,omitempty"`
+	Ack   int64        `json:"ack,omitempty"`
+}
+
+// outboxJournal - журнал outbox для хранилища в памяти. При открытии журнал переписывается,
+// в нем остаются только неопубликованные события.
+type outboxJournal struct {
+	file *os.File
+}
+
+// openOutboxJournal открывает журнал и возвращает неопубликованные события по порядку.
+func openOutboxJournal(path string) (*outboxJournal, []OutboxEvent, error) {
+	pending, err := readOutboxJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cant open outbox journal: %w", err)
+	}
+
+	journal := &outboxJournal{file: file}
+	if err = journal.append(pending); err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	return journal, pending, nil
+}
+
+func readOutboxJournal(path string) ([]OutboxEvent, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cant open outbox journal: %w", err)
+	}
+	defer file.Close()
+
+	events := make(map[int64]OutboxEvent)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var record journalRecord
+		if err = json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("cant unmarshal outbox journal: %w", err)
+		}
+		if record.Event != nil {
+			events[record.Event.ID] = *record.Event
+		}
+		delete(events, record.Ack)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cant read outbox journal: %w", err)
+	}
+
+	pending := make([]OutboxEvent, 0, len(events))
+	for _, event := range events {
+		pending = append(pending, event)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+
+	return pending, nil
+}
+
+func (j *outboxJournal) append(events []OutboxEvent) error {
+	records := make([]journalRecord, 0, len(events))
+	for i := range events {
+		records = append(records, journalRecord{Event: &events[i]})
+	}
+	return j.write(records)
+}
+
+func (j *outboxJournal) ack(ids []int64) error {
+	records := make([]journalRecord, 0, len(ids))
+	for _, id := range ids {
+		records = append(records, journalRecord{Ack: id})
+	}
+	return j.write(records)
+}
+
+// write дописывает строки одной записью, чтобы события одного изменения не разделялись.
+func (j *outboxJournal) write(records []journalRecord) error {
+	var data []byte
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("error in saving outbox journal: %s", err.Error())
+	}
+	return nil
+}
@@ -1,25 +1,258 @@
 package storage
 
+// itemColumns - колонки urls, из которых собирается Item (см. scanItem)
+const itemColumns = `original_url, user_id, is_deleted, created_at, updated_at, deleted_at, title, note, collection, redirect_code,
+	forward_query, forward_path, password_hash, max_clicks, clicks, active_from, rules, variants, sticky, interstitial,
+	blocked_reason, ARRAY(SELECT tag FROM url_tags WHERE url_tags.short_url = urls.short_url ORDER BY tag)`
+
+// deliveryColumns - колонки webhook_deliveries, из которых собирается Delivery (см. scanDelivery)
+const deliveryColumns = `d.id, d.webhook_id, d.user_id, d.event, d.payload, d.status, d.attempts, d.last_error, d.response_code,
+	d.next_attempt_at, d.created_at, d.updated_at`
+
+// deleteJobColumns - колонки delete_jobs, из которых собирается DeleteJob (см. scanDeleteJob)
+const deleteJobColumns = `id, user_id, urls, status, attempts, last_error, next_attempt_at, created_at, updated_at`
+
+// Версия схемы и блокировка, под которой ее обновляет только один экземпляр сервиса.
+const (
+	createSchemaVersionTableQuery = `CREATE TABLE IF NOT EXISTS schema_version (
+						version INTEGER NOT NULL,
+						applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now());`
+	lockSchemaQuery  = `SELECT pg_advisory_xact_lock(hashtext('schema'));`
+	getSchemaVersion = `SELECT COALESCE(MAX(version), 0) FROM schema_version;`
+	setSchemaVersion = `INSERT INTO schema_version (version) VALUES ($1);`
+)
+
 // Все sql-запросы к БД
 const (
-	preCreateTableQuery = `drop table if exists urls;`
+	preCreateTableQuery = `drop table if exists urls, url_revisions, url_tags, collections, webhook_deliveries, webhooks, outbox, delete_jobs, job_runs, schema_version;`
 	createTableQuery    = `CREATE TABLE IF NOT EXISTS urls (
     					id SERIAL PRIMARY KEY,
     					original_url TEXT NOT NULL,
     					short_url TEXT NOT NULL UNIQUE,
     					user_id TEXT NOT NULL,
-                        is_deleted BOOLEAN DEFAULT False
+                        is_deleted BOOLEAN DEFAULT False,
+                        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                        deleted_at TIMESTAMPTZ,
+                        title TEXT NOT NULL DEFAULT '',
+                        note TEXT NOT NULL DEFAULT '',
+                        collection TEXT NOT NULL DEFAULT '',
+                        redirect_code INTEGER NOT NULL DEFAULT 0,
+                        forward_query BOOLEAN NOT NULL DEFAULT false,
+                        forward_path BOOLEAN NOT NULL DEFAULT false,
+                        password_hash TEXT NOT NULL DEFAULT '',
+                        max_clicks INTEGER NOT NULL DEFAULT 0,
+                        clicks INTEGER NOT NULL DEFAULT 0,
+                        active_from TIMESTAMPTZ,
+                        rules JSONB NOT NULL DEFAULT '[]',
+                        variants JSONB NOT NULL DEFAULT '[]',
+                        sticky BOOLEAN NOT NULL DEFAULT false,
+                        interstitial BOOLEAN NOT NULL DEFAULT false,
+                        blocked_reason TEXT NOT NULL DEFAULT ''
+													);`
+	// upgradeTableQuery дополняет таблицу urls, созданную прежними версиями сервиса, новыми колонками.
+	upgradeTableQuery = `ALTER TABLE urls
+						ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+						ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '',
+						ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '',
+						ADD COLUMN IF NOT EXISTS collection TEXT NOT NULL DEFAULT '',
+						ADD COLUMN IF NOT EXISTS redirect_code INTEGER NOT NULL DEFAULT 0,
+						ADD COLUMN IF NOT EXISTS forward_query BOOLEAN NOT NULL DEFAULT false,
+						ADD COLUMN IF NOT EXISTS forward_path BOOLEAN NOT NULL DEFAULT false,
+						ADD COLUMN IF NOT EXISTS password_hash TEXT NOT NULL DEFAULT '',
+						ADD COLUMN IF NOT EXISTS max_clicks INTEGER NOT NULL DEFAULT 0,
+						ADD COLUMN IF NOT EXISTS clicks INTEGER NOT NULL DEFAULT 0,
+						ADD COLUMN IF NOT EXISTS active_from TIMESTAMPTZ,
+						ADD COLUMN IF NOT EXISTS rules JSONB NOT NULL DEFAULT '[]',
+						ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]',
+						ADD COLUMN IF NOT EXISTS sticky BOOLEAN NOT NULL DEFAULT false,
+						ADD COLUMN IF NOT EXISTS interstitial BOOLEAN NOT NULL DEFAULT false,
+						ADD COLUMN IF NOT EXISTS blocked_reason TEXT NOT NULL DEFAULT '';`
+	createUserCreatedIndexQuery = `CREATE INDEX IF NOT EXISTS user_created_idx ON urls (user_id, created_at, short_url);`
+	createRevisionsTableQuery   = `CREATE TABLE IF NOT EXISTS url_revisions (
+						id BIGSERIAL PRIMARY KEY,
+						short_url TEXT NOT NULL,
+						original_url TEXT NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+													);`
+	createTagsTableQuery = `CREATE TABLE IF NOT EXISTS url_tags (
+						short_url TEXT NOT NULL,
+						tag TEXT NOT NULL,
+						PRIMARY KEY (short_url, tag)
+													);`
+	createTagIndexQuery         = `CREATE INDEX IF NOT EXISTS url_tags_tag_idx ON url_tags (tag);`
+	createCollectionsTableQuery = `CREATE TABLE IF NOT EXISTS collections (
+						user_id TEXT NOT NULL,
+						name TEXT NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						PRIMARY KEY (user_id, name)
+													);`
+	createWebhooksTableQuery = `CREATE TABLE IF NOT EXISTS webhooks (
+						id TEXT PRIMARY KEY,
+						user_id TEXT NOT NULL,
+						url TEXT NOT NULL,
+						secret TEXT NOT NULL,
+						events TEXT[] NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+													);`
+	createDeliveriesTableQuery = `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+						id BIGSERIAL PRIMARY KEY,
+						webhook_id TEXT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+						user_id TEXT NOT NULL,
+						event TEXT NOT NULL,
+						payload BYTEA NOT NULL,
+						status TEXT NOT NULL DEFAULT 'pending',
+						attempts INTEGER NOT NULL DEFAULT 0,
+						last_error TEXT NOT NULL DEFAULT '',
+						response_code INTEGER NOT NULL DEFAULT 0,
+						next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+													);`
+	createOutboxTableQuery = `CREATE TABLE IF NOT EXISTS outbox (
+						id BIGSERIAL PRIMARY KEY,
+						event_id TEXT NOT NULL,
+						type TEXT NOT NULL,
+						user_id TEXT NOT NULL,
+						link JSONB NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						locked_until TIMESTAMPTZ
+													);`
+	createDeleteJobsTableQuery = `CREATE TABLE IF NOT EXISTS delete_jobs (
+						id TEXT PRIMARY KEY,
+						user_id TEXT NOT NULL,
+						urls TEXT[] NOT NULL,
+						status TEXT NOT NULL DEFAULT 'pending',
+						attempts INTEGER NOT NULL DEFAULT 0,
+						last_error TEXT NOT NULL DEFAULT '',
+						next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+						updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+													);`
+	createJobRunsTableQuery = `CREATE TABLE IF NOT EXISTS job_runs (
+						id BIGSERIAL PRIMARY KEY,
+						job TEXT NOT NULL,
+						trigger TEXT NOT NULL,
+						status TEXT NOT NULL,
+						processed INTEGER NOT NULL DEFAULT 0,
+						error TEXT NOT NULL DEFAULT '',
+						started_at TIMESTAMPTZ NOT NULL,
+						finished_at TIMESTAMPTZ NOT NULL
 													);`
-	createOriginalURLIndexQuery = `CREATE UNIQUE INDEX IF NOT EXISTS original_url_idx ON urls (original_url);`
-	setNewValueInDB             = `INSERT INTO urls (original_url, short_url, user_id)
-						VALUES ($1, $2, $3)
+	createJobRunsIndexQuery         = `CREATE INDEX IF NOT EXISTS job_runs_job_idx ON job_runs (job, id);`
+	createDeleteJobDueIndexQuery    = `CREATE INDEX IF NOT EXISTS delete_jobs_due_idx ON delete_jobs (next_attempt_at) WHERE status = 'pending';`
+	createWebhookUserIndexQuery     = `CREATE INDEX IF NOT EXISTS webhooks_user_idx ON webhooks (user_id);`
+	createDeliveryDueIndexQuery     = `CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';`
+	createDeliveryWebhookIndexQuery = `CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id);`
+	createUserCollectionIndexQuery  = `CREATE INDEX IF NOT EXISTS user_collection_idx ON urls (user_id, collection);`
+	createOriginalURLIndexQuery     = `CREATE UNIQUE INDEX IF NOT EXISTS original_url_idx ON urls (original_url) WHERE is_deleted = false;`
+	createUserOriginalURLIndexQuery = `CREATE UNIQUE INDEX IF NOT EXISTS user_original_url_idx ON urls (user_id, original_url) WHERE is_deleted = false;`
+	dropOriginalURLIndexQuery       = `DROP INDEX IF EXISTS original_url_idx;`
+	dropUserOriginalURLIndexQuery   = `DROP INDEX IF EXISTS user_original_url_idx;`
+	setNewValueInDB                 = `INSERT INTO urls (original_url, short_url, user_id, created_at, updated_at, title, note, collection, redirect_code,
+						forward_query, forward_path, password_hash, max_clicks, clicks, active_from, rules, variants, sticky,
+						interstitial, blocked_reason)
+						VALUES ($1, $2, $3, COALESCE($4, now()), COALESCE($4, now()), $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+						$16, $17, $18, $19)
 						ON CONFLICT (short_url)
 						DO UPDATE
-						SET short_url = EXCLUDED.short_url;`
-	setDeleteBatch   = `UPDATE urls SET is_deleted=true WHERE short_url=$1 and user_id=$2;`
-	getBatchByUserID = `SELECT original_url, short_url from urls WHERE user_id = $1`
-	getOriginalURL   = `SELECT original_url, is_deleted FROM urls WHERE short_url = $1 LIMIT 1;`
-	getShortURL      = `SELECT short_url FROM urls WHERE original_url = $1 LIMIT 1;`
-	getCountOfURLs   = `select count(*) from urls;`
-	getCountOfUsers  = `select count(DISTINCT user_id) from urls`
+						SET short_url = EXCLUDED.short_url
+						RETURNING xmax = 0;`
+	// importLink пропускает и занятое сокращение, и дубликат original_url: причину различает linkExists.
+	importLink = `INSERT INTO urls (original_url, short_url, user_id, created_at, updated_at, title, note, collection, redirect_code,
+						max_clicks, clicks)
+						VALUES ($1, $2, $3, COALESCE($4, now()), COALESCE($4, now()), $5, $6, $7, $8, $9, $10)
+						ON CONFLICT DO NOTHING
+						RETURNING true;`
+	linkExists     = `SELECT EXISTS (SELECT 1 FROM urls WHERE short_url = $1);`
+	setDeleteBatch = `UPDATE urls SET is_deleted=true, deleted_at=now(), updated_at=now() WHERE short_url=$1 and user_id=$2 and is_deleted=false
+						RETURNING short_url, original_url;`
+	getBatchByUserID  = `SELECT short_url, ` + itemColumns + ` FROM urls WHERE user_id = $1`
+	getLink           = `SELECT ` + itemColumns + ` FROM urls WHERE short_url = $1 LIMIT 1;`
+	getShortURL       = `SELECT short_url FROM urls WHERE original_url = $1 AND is_deleted = false LIMIT 1;`
+	getShortURLByUser = `SELECT short_url FROM urls WHERE original_url = $1 AND user_id = $2 AND is_deleted = false LIMIT 1;`
+	getLinkForUpdate  = `SELECT ` + itemColumns + ` FROM urls WHERE short_url = $1 FOR UPDATE;`
+	getLinkOwner      = `SELECT user_id FROM urls WHERE short_url = $1;`
+	updateLink        = `UPDATE urls SET original_url = $2, title = $3, note = $4, updated_at = $5, collection = $6, redirect_code = $7,
+						forward_query = $8, forward_path = $9, password_hash = $10, max_clicks = $11, active_from = $12,
+						rules = $13, variants = $14, sticky = $15,
+						interstitial = $16 WHERE short_url = $1;`
+	recordVariant = `UPDATE urls SET variants = jsonb_set(variants, ARRAY[$2::int::text, 'clicks'],
+						to_jsonb(COALESCE((variants #>> ARRAY[$2::int::text, 'clicks'])::bigint, 0) + 1))
+						WHERE short_url = $1 AND $2::int >= 0 AND $2::int < jsonb_array_length(variants)
+						RETURNING ` + itemColumns + `;`
+	scanLinks = `SELECT short_url, ` + itemColumns + ` FROM urls WHERE is_deleted = false AND short_url > $1
+						ORDER BY short_url LIMIT $2;`
+	setBlocked = `UPDATE urls SET blocked_reason = $2, updated_at = now() WHERE short_url = $1
+						RETURNING ` + itemColumns + `;`
+	transferLinks = `UPDATE urls SET user_id = $2, updated_at = now()
+						WHERE user_id = $1 AND (COALESCE(cardinality($3::text[]), 0) = 0 OR short_url = ANY($3))
+						RETURNING short_url;`
+	transferCollections = `INSERT INTO collections (user_id, name)
+						SELECT DISTINCT $1::text, collection FROM urls WHERE short_url = ANY($2) AND collection <> ''
+						ON CONFLICT DO NOTHING;`
+	consumeClick = `UPDATE urls SET clicks = clicks + 1
+						WHERE short_url = $1 AND is_deleted = false AND (max_clicks = 0 OR clicks < max_clicks)
+						RETURNING ` + itemColumns + `;`
+	addRevision      = `INSERT INTO url_revisions (short_url, original_url) VALUES ($1, $2);`
+	getRevisions     = `SELECT id, original_url, created_at FROM url_revisions WHERE short_url = $1 ORDER BY id DESC;`
+	restoreDeleted   = `UPDATE urls SET is_deleted = false, deleted_at = NULL, updated_at = $2 WHERE short_url = $1;`
+	purgeDeleted     = `DELETE FROM urls WHERE is_deleted = true AND deleted_at < $1 RETURNING short_url;`
+	addTags          = `INSERT INTO url_tags (short_url, tag) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING;`
+	removeTags       = `DELETE FROM url_tags WHERE short_url = $1;`
+	purgeTags        = `DELETE FROM url_tags WHERE short_url = ANY($1);`
+	upsertCollection = `INSERT INTO collections (user_id, name, created_at) VALUES ($1, $2, COALESCE($3, now())) ON CONFLICT DO NOTHING;`
+	createCollection = `INSERT INTO collections (user_id, name, created_at) VALUES ($1, $2, COALESCE($3, now()));`
+	deleteCollection = `DELETE FROM collections WHERE user_id = $1 AND name = $2;`
+	getCollections   = `SELECT c.name, c.created_at, COUNT(u.short_url) FROM collections c
+						LEFT JOIN urls u ON u.user_id = c.user_id AND u.collection = c.name AND u.is_deleted = false
+						WHERE c.user_id = $1 GROUP BY c.name, c.created_at ORDER BY c.name;`
+	createWebhook   = `INSERT INTO webhooks (id, user_id, url, secret, events, created_at) VALUES ($1, $2, $3, $4, $5, COALESCE($6, now()));`
+	getWebhooks     = `SELECT id, url, secret, events, created_at FROM webhooks WHERE user_id = $1 ORDER BY created_at, id;`
+	getWebhookOwner = `SELECT user_id FROM webhooks WHERE id = $1;`
+	deleteWebhook   = `DELETE FROM webhooks WHERE user_id = $1 AND id = $2;`
+	addDelivery     = `INSERT INTO webhook_deliveries (webhook_id, user_id, event, payload, next_attempt_at)
+						VALUES ($1, $2, $3, $4, COALESCE($5, now()));`
+	claimDeliveries = `WITH d AS (
+						UPDATE webhook_deliveries SET next_attempt_at = $2 WHERE id IN (
+							SELECT id FROM webhook_deliveries WHERE status = 'pending' AND next_attempt_at <= $1
+							ORDER BY next_attempt_at, id LIMIT $3 FOR UPDATE SKIP LOCKED)
+						RETURNING *)
+						SELECT ` + deliveryColumns + `, w.url, w.secret FROM d JOIN webhooks w ON w.id = d.webhook_id ORDER BY d.id;`
+	updateDelivery = `UPDATE webhook_deliveries SET status = $2, attempts = $3, last_error = $4, response_code = $5,
+						next_attempt_at = $6, updated_at = now() WHERE id = $1;`
+	getDeliveries = `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d
+						WHERE d.user_id = $1 AND d.webhook_id = $2 AND ($3 = '' OR d.status = $3) ORDER BY d.id DESC LIMIT $4;`
+	retryDelivery = `UPDATE webhook_deliveries AS d SET status = 'pending', attempts = 0, last_error = '', response_code = 0,
+						next_attempt_at = now(), updated_at = now() WHERE d.id = $1 AND d.webhook_id = $2 AND d.user_id = $3
+						RETURNING ` + deliveryColumns + `;`
+	addOutbox   = `INSERT INTO outbox (event_id, type, user_id, link, created_at) VALUES ($1, $2, $3, $4, $5);`
+	claimOutbox = `UPDATE outbox SET locked_until = $2 WHERE id IN (
+						SELECT id FROM outbox WHERE locked_until IS NULL OR locked_until <= $1
+						ORDER BY id LIMIT $3 FOR UPDATE SKIP LOCKED)
+						RETURNING id, event_id, type, user_id, link, created_at;`
+	ackOutbox    = `DELETE FROM outbox WHERE id = ANY($1);`
+	addDeleteJob = `INSERT INTO delete_jobs (id, user_id, urls, created_at, next_attempt_at)
+						VALUES ($1, $2, $3, COALESCE($4, now()), COALESCE($5, now()));`
+	claimDeleteJobs = `UPDATE delete_jobs SET next_attempt_at = $2 WHERE id IN (
+						SELECT id FROM delete_jobs WHERE status = 'pending' AND next_attempt_at <= $1
+						ORDER BY next_attempt_at, created_at LIMIT $3 FOR UPDATE SKIP LOCKED)
+						RETURNING ` + deleteJobColumns + `;`
+	updateDeleteJob = `UPDATE delete_jobs SET status = $2, attempts = $3, last_error = $4, next_attempt_at = $5,
+						updated_at = now() WHERE id = $1;`
+	getDeleteJob    = `SELECT ` + deleteJobColumns + ` FROM delete_jobs WHERE id = $1 AND user_id = $2;`
+	purgeDeleteJobs = `DELETE FROM delete_jobs WHERE status <> 'pending' AND updated_at < $1;`
+	tryAdvisoryLock = `SELECT pg_try_advisory_lock(hashtext($1));`
+	advisoryUnlock  = `SELECT pg_advisory_unlock(hashtext($1));`
+	addJobRun       = `INSERT INTO job_runs (job, trigger, status, processed, error, started_at, finished_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7);`
+	trimJobRuns = `DELETE FROM job_runs WHERE job = $1 AND id <= (
+						SELECT id FROM job_runs WHERE job = $1 ORDER BY id DESC OFFSET $2 LIMIT 1);`
+	getJobRuns = `SELECT id, job, trigger, status, processed, error, started_at, finished_at FROM job_runs
+						WHERE job = $1 ORDER BY id DESC LIMIT $2;`
+	purgeRevisions  = `DELETE FROM url_revisions WHERE short_url = ANY($1);`
+	getCountOfURLs  = `select count(*) from urls;`
+	getCountOfUsers = `select count(DISTINCT user_id) from urls`
 )
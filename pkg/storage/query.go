@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeletedFilter - отбор ссылок по признаку удаления
+type DeletedFilter int
+
+// Варианты отбора ссылок по признаку удаления.
+const (
+	DeletedInclude DeletedFilter = iota
+	DeletedExclude
+	DeletedOnly
+)
+
+// Cursor - позиция последней выданной ссылки для постраничной выборки
+type Cursor struct {
+	CreatedAt time.Time
+	Alias     string
+}
+
+// BatchQuery - параметры выборки ссылок пользователя
+type BatchQuery struct {
+	Limit      int // 0 - без ограничения
+	After      *Cursor
+	Desc       bool
+	Search     string
+	Tag        string
+	Collection string
+	Deleted    DeletedFilter
+}
+
+// Record - ссылка вместе с ее сокращением
+type Record struct {
+	Alias string
+	Item
+}
+
+// match - подходит ли ссылка под фильтры запроса (без учета курсора)
+func (q BatchQuery) match(item Item) bool {
+	switch q.Deleted {
+	case DeletedExclude:
+		if item.IsDeleted {
+			return false
+		}
+	case DeletedOnly:
+		if !item.IsDeleted {
+			return false
+		}
+	}
+
+	if q.Search != "" && !strings.Contains(strings.ToLower(item.Object), strings.ToLower(q.Search)) {
+		return false
+	}
+
+	if q.Tag != "" && !slices.Contains(item.Tags, q.Tag) {
+		return false
+	}
+
+	if q.Collection != "" && item.Collection != q.Collection {
+		return false
+	}
+
+	return true
+}
+
+// less - порядок выдачи: по created_at, при равенстве по сокращению
+func (q BatchQuery) less(a, b Record) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		if q.Desc {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	if q.Desc {
+		return a.Alias > b.Alias
+	}
+	return a.Alias < b.Alias
+}
+
+// page - сортирует записи и вырезает страницу после курсора
+func (q BatchQuery) page(records []Record) []Record {
+	sort.Slice(records, func(i, j int) bool {
+		return q.less(records[i], records[j])
+	})
+
+	if q.After != nil {
+		after := Record{Alias: q.After.Alias, Item: Item{CreatedAt: q.After.CreatedAt}}
+		start := sort.Search(len(records), func(i int) bool {
+			return q.less(after, records[i])
+		})
+		records = records[start:]
+	}
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+	}
+
+	return records
+}
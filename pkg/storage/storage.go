@@ -1,34 +1,306 @@
 package storage
 
 import (
-	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // FileStorage -
 type FileStorage interface {
 	SaveToFile(items map[string]Item) error
+	RemoveFromFile(aliases []string) error
 }
 
 // IStorage -
 type IStorage interface {
 	Set(ctx context.Context, data map[string]Item) error
-	Get(ctx context.Context, alias string) (string, error)
-	GetShortURL(ctx context.Context, originalURL string) (string, error)
+	ImportLinks(ctx context.Context, records []Record) ([]error, error)
+	Get(ctx context.Context, alias string) (Item, error)
+	ConsumeClick(ctx context.Context, alias string) (Item, error)
+	RecordVariant(ctx context.Context, alias string, variant int) (Item, error)
+	ScanLinks(ctx context.Context, after string, limit int) ([]Record, error)
+	SetBlocked(ctx context.Context, alias, reason string) (Item, error)
+	Lookup(ctx context.Context, alias string) (Item, error)
+	TransferLinks(ctx context.Context, from, to string, aliases []string) ([]string, error)
+	GetShortURL(ctx context.Context, originalURL, userID string) (string, error)
 	Ping(ctx context.Context) error
-	GetBatchByUserID(ctx context.Context, userID string) (map[string]Item, error)
-	DeleteBatch(ctx context.Context, urls []string, userID string) error
+	GetBatchByUserID(ctx context.Context, userID string, query BatchQuery) ([]Record, error)
+	DeleteBatch(ctx context.Context, urls []string, userID string) ([]string, error)
 	GetStats(ctx context.Context) (int64, int64, error)
+	UpdateLink(ctx context.Context, alias, userID string, update LinkUpdate) (Item, error)
+	GetRevisions(ctx context.Context, alias, userID string) ([]Revision, error)
+	RestoreDeleted(ctx context.Context, alias, userID string, deletedAfter time.Time) (Item, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) ([]string, error)
+	CreateCollection(ctx context.Context, collection Collection) error
+	GetCollections(ctx context.Context, userID string) ([]Collection, error)
+	DeleteCollection(ctx context.Context, userID, name string) error
+	CreateWebhook(ctx context.Context, webhook Webhook) error
+	GetWebhooks(ctx context.Context, userID string) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, id string) error
+	AddDeliveries(ctx context.Context, deliveries []Delivery) error
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery Delivery) error
+	GetDeliveries(ctx context.Context, userID, webhookID, status string, limit int) ([]Delivery, error)
+	RetryDelivery(ctx context.Context, userID, webhookID string, id int64) (Delivery, error)
+	AddOutbox(ctx context.Context, events []OutboxEvent) error
+	ClaimOutbox(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxEvent, error)
+	AckOutbox(ctx context.Context, ids []int64) error
+	AddDeleteJob(ctx context.Context, job DeleteJob) error
+	ClaimDeleteJobs(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DeleteJob, error)
+	UpdateDeleteJobs(ctx context.Context, jobs []DeleteJob) error
+	GetDeleteJob(ctx context.Context, userID, id string) (DeleteJob, error)
+	PurgeDeleteJobs(ctx context.Context, finishedBefore time.Time) (int, error)
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+	AddJobRun(ctx context.Context, run JobRun) error
+	GetJobRuns(ctx context.Context, job string, limit int) ([]JobRun, error)
 	Close()
 }
 
+// LinkUpdate - изменяемые атрибуты ссылки, nil означает "не менять"
+type LinkUpdate struct {
+	Object       *string
+	Title        *string
+	Note         *string
+	Tags         *[]string
+	Collection   *string
+	RedirectCode *int
+	ForwardQuery *bool
+	ForwardPath  *bool
+	PasswordHash *string
+	MaxClicks    *int
+	ActiveFrom   *time.Time
+	Rules        *[]Rule
+	Variants     *[]Variant
+	Sticky       *bool
+	Interstitial *bool
+}
+
+// Apply - возвращает копию item с примененными изменениями
+func (u LinkUpdate) Apply(item Item) Item {
+	if u.Object != nil {
+		item.Object = *u.Object
+	}
+	if u.Title != nil {
+		item.Title = *u.Title
+	}
+	if u.Note != nil {
+		item.Note = *u.Note
+	}
+	if u.Tags != nil {
+		item.Tags = append([]string(nil), *u.Tags...)
+	}
+	if u.Collection != nil {
+		item.Collection = *u.Collection
+	}
+	if u.RedirectCode != nil {
+		item.RedirectCode = *u.RedirectCode
+	}
+	if u.ForwardQuery != nil {
+		item.ForwardQuery = *u.ForwardQuery
+	}
+	if u.ForwardPath != nil {
+		item.ForwardPath = *u.ForwardPath
+	}
+	if u.PasswordHash != nil {
+		item.PasswordHash = *u.PasswordHash
+	}
+	if u.MaxClicks != nil {
+		item.MaxClicks = *u.MaxClicks
+	}
+	if u.ActiveFrom != nil {
+		item.ActiveFrom = *u.ActiveFrom
+	}
+	if u.Rules != nil {
+		item.Rules = append([]Rule(nil), *u.Rules...)
+	}
+	if u.Variants != nil {
+		item.Variants = append([]Variant(nil), *u.Variants...)
+	}
+	if u.Sticky != nil {
+		item.Sticky = *u.Sticky
+	}
+	if u.Interstitial != nil {
+		item.Interstitial = *u.Interstitial
+	}
+	return item
+}
+
+// Rule - правило маршрутизации: при совпадении всех заданных условий
+// редирект ведет на Destination вместо original_url
+type Rule struct {
+	Platform    string `json:"platform,omitempty"` // ios, android, windows, macos, linux
+	Language    string `json:"language,omitempty"` // языковой тег, например ru или pt-BR
+	Referrer    string `json:"referrer,omitempty"` // домен источника перехода
+	Destination string `json:"destination"`
+}
+
+// Variant - вариант адреса для A/B-теста, выбирается пропорционально весу
+type Variant struct {
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+	Clicks      int64  `json:"clicks"` // сколько раз вариант был выбран
+}
+
+// Collection - именованная группа ссылок пользователя
+type Collection struct {
+	Name      string
+	UserID    string
+	CreatedAt time.Time
+	Links     int // количество неудаленных ссылок, заполняется при чтении
+}
+
+// Webhook - подписка пользователя на события его ссылок
+type Webhook struct {
+	ID        string
+	UserID    string
+	URL       string
+	Secret    string   // ключ HMAC-подписи доставок
+	Events    []string // типы событий, на которые оформлена подписка
+	CreatedAt time.Time
+}
+
+// Статусы доставки события подписчику.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead" // попытки исчерпаны, доставка в списке недоставленных
+)
+
+// Delivery - доставка события подписчику и журнал ее попыток
+type Delivery struct {
+	ID            int64
+	WebhookID     string
+	UserID        string
+	Event         string
+	Payload       []byte // JSON события
+	Status        string
+	Attempts      int
+	LastError     string
+	ResponseCode  int // код ответа подписчика на последнюю попытку, 0 - ответа не было
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Адрес и ключ подписки, заполняются только ClaimDeliveries.
+	URL    string
+	Secret string
+}
+
+// Статусы задания на удаление ссылок.
+const (
+	JobPending = "pending"
+	JobDone    = "done"
+	JobFailed  = "failed" // попытки исчерпаны
+)
+
+// DeleteJob - задание на удаление ссылок пользователя. Задания хранятся вместе со ссылками,
+// поэтому не выполненные до остановки сервиса удаления продолжаются после запуска.
+type DeleteJob struct {
+	ID            string
+	UserID        string
+	URLs          []string
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Результаты запуска фоновой задачи.
+const (
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// maxJobRuns - сколько последних запусков каждой фоновой задачи хранится в истории
+const maxJobRuns = 100
+
+// JobRun - запуск фоновой задачи планировщика
+type JobRun struct {
+	ID         int64
+	Job        string
+	Trigger    string // schedule - по расписанию, manual - вручную
+	Status     string
+	Processed  int // сколько объектов обработала задача
+	Error      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Типы событий жизненного цикла ссылки.
+const (
+	EventLinkCreated = "link.created"
+	EventLinkDeleted = "link.deleted"
+	EventLinkExpired = "link.expired" // исчерпан лимит переходов
+	EventLinkClicked = "link.clicked"
+)
+
+// EventTypes - все типы событий в порядке жизненного цикла ссылки
+var EventTypes = []string{EventLinkCreated, EventLinkClicked, EventLinkExpired, EventLinkDeleted}
+
+// EventLink - ссылка, с которой произошло событие
+type EventLink struct {
+	Alias       string `json:"alias"`
+	OriginalURL string `json:"original_url,omitempty"`
+	Destination string `json:"destination,omitempty"` // адрес перехода для link.clicked
+	Clicks      int    `json:"clicks,omitempty"`
+	MaxClicks   int    `json:"max_clicks,omitempty"`
+}
+
+// OutboxEvent - событие, ожидающее публикации. Set и DeleteBatch записывают его вместе с самим
+// изменением ссылки, поэтому падение сервиса между изменением и публикацией не теряет событие.
+type OutboxEvent struct {
+	ID        int64  // порядок в outbox
+	EventID   string // не меняется при повторной публикации, по нему получатели отбрасывают повторы
+	Type      string
+	UserID    string
+	Link      EventLink
+	CreatedAt time.Time
+}
+
+// NewOutboxEvent -
+func NewOutboxEvent(eventType, userID string, link EventLink) OutboxEvent {
+	return OutboxEvent{
+		EventID:   uuid.NewString(),
+		Type:      eventType,
+		UserID:    userID,
+		Link:      link,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// restorer - загрузка ссылок из файла при запуске. В отличие от Set не пишет события:
+// ссылки существовали и до перезапуска.
+type restorer interface {
+	restore(ctx context.Context, data map[string]Item) error
+}
+
+// Revision - предыдущее значение original_url ссылки
+type Revision struct {
+	ID        int64
+	Object    string
+	CreatedAt time.Time
+}
+
+// DedupScope - область, в которой original_url должен быть уникальным
+type DedupScope string
+
+// Допустимые области дедупликации ссылок.
+const (
+	DedupGlobal  DedupScope = "global"
+	DedupPerUser DedupScope = "user"
+	DedupNone    DedupScope = "none"
+)
+
 // Storage -
 type Storage struct {
 	File FileStorage
 	IStorage
+	dedup DedupScope
 }
 
 // OptionsStorage -
@@ -36,7 +308,7 @@ type OptionsStorage func(s *Storage) error
 
 // NewStorage -
 func NewStorage(opts ...OptionsStorage) (*Storage, error) {
-	s := &Storage{}
+	s := &Storage{dedup: DedupGlobal}
 	s.IStorage = newMemoryStorage()
 	for _, opt := range opts {
 		err := opt(s)
@@ -47,11 +319,29 @@ func NewStorage(opts ...OptionsStorage) (*Storage, error) {
 	return s, nil
 }
 
+// WithDedupScope - задает область дедупликации original_url.
+// Должна передаваться раньше WithDB, так как схема БД зависит от области.
+func WithDedupScope(scope DedupScope) OptionsStorage {
+	return func(s *Storage) error {
+		switch scope {
+		case DedupGlobal, DedupPerUser, DedupNone:
+		default:
+			return fmt.Errorf("unknown dedup scope: %q", scope)
+		}
+
+		s.dedup = scope
+		if m, ok := s.IStorage.(*memoryStorage); ok {
+			m.setDedup(scope)
+		}
+		return nil
+	}
+}
+
 // WithDB -
 func WithDB(ctx context.Context, dsn string, dbPoolWorkers int) OptionsStorage {
 	return func(s *Storage) error {
 		var err error
-		s.IStorage, err = newDB(ctx, dsn, dbPoolWorkers)
+		s.IStorage, err = newDB(ctx, dsn, dbPoolWorkers, s.dedup)
 		return err
 	}
 }
@@ -64,6 +354,15 @@ func WithFileStorage(path string) OptionsStorage {
 		if err != nil {
 			return err
 		}
+
+		// У хранилища в памяти outbox и очередь удаления переживают перезапуск только
+		// в журналах рядом с файлом ссылок.
+		if m, ok := s.IStorage.(*memoryStorage); ok {
+			if err = m.attachJournal(path + outboxJournalSuffix); err != nil {
+				return err
+			}
+			return m.attachJobJournal(path + jobJournalSuffix)
+		}
 		return nil
 	}
 }
@@ -71,32 +370,18 @@ func WithFileStorage(path string) OptionsStorage {
 // RestoreFile -
 func RestoreFile(ctx context.Context, filename string) OptionsStorage {
 	return func(s *Storage) error {
-		file, err := os.OpenFile(filename, os.O_RDONLY|os.O_CREATE, 0666)
-		defer func(file *os.File) {
-			err = file.Close()
-			if err != nil {
-				fmt.Printf("cant close file: %s", err.Error())
-			}
-		}(file)
+		itemsMap, _, err := readFile(filename)
 		if err != nil {
-			return fmt.Errorf("cant open file: %s", err.Error())
+			return err
 		}
 
-		scanner := bufio.NewScanner(file)
-		scanner.Scan()
-		data := scanner.Bytes()
-
-		if data == nil {
+		if len(itemsMap) == 0 {
 			return nil
 		}
 
-		itemsMap := make(map[string]Item)
-
-		err = json.Unmarshal(data, &itemsMap)
-		if err != nil {
-			return fmt.Errorf("cant unmarshal objects from file: %s", err.Error())
+		if backend, ok := s.IStorage.(restorer); ok {
+			return backend.restore(ctx, itemsMap)
 		}
-
 		if s.IStorage != nil {
 			err = s.Set(ctx, itemsMap)
 			if err != nil {
@@ -1,17 +1,151 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/sonikq/url-shortener/internal/app/models"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewStorage(t *testing.T) {
 }
 
 func TestRestoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	file, err := newFileStorage(path)
+	require.NoError(t, err)
+	require.NoError(t, file.SaveToFile(map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a"},
+	}))
+	require.NoError(t, file.SaveToFile(map[string]Item{
+		"aaaaaa": {Object: "https://google.com", UserID: "user-a"},
+	}))
+	require.NoError(t, file.RemoveFromFile([]string{"bbbbbb"}))
+	// Срок жизни, записанный прежними версиями, при восстановлении не учитывается.
+	require.NoError(t, file.SaveToFile(map[string]Item{
+		"cccccc": {Object: "https://mail.ru", UserID: "user-a", Expiration: time.Now().Add(-time.Hour).UnixNano()},
+	}))
+
+	s, err := NewStorage(RestoreFile(context.Background(), path))
+	require.NoError(t, err)
+
+	got, err := s.Get(context.Background(), "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com", got.Object)
+
+	got, err = s.Get(context.Background(), "cccccc")
+	require.NoError(t, err)
+	require.Zero(t, got.Expiration)
+
+	_, err = s.Get(context.Background(), "bbbbbb")
+	require.Error(t, err)
+
+	// Восстановленные ссылки уже существовали, событий о создании нет.
+	events, err := s.ClaimOutbox(context.Background(), time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestCompactFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	file, err := newFileStorage(path)
+	require.NoError(t, err)
+	require.NoError(t, file.SaveToFile(map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a"},
+	}))
+	require.NoError(t, file.SaveToFile(map[string]Item{
+		"aaaaaa": {Object: "https://google.com", UserID: "user-a"},
+	}))
+	require.NoError(t, file.RemoveFromFile([]string{"bbbbbb"}))
+
+	result, err := CompactFile(path)
+	require.NoError(t, err)
+	require.Equal(t, CompactResult{Entries: 4, Links: 1}, result)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, bytes.Count(data, []byte("\n")))
+
+	s, err := NewStorage(RestoreFile(context.Background(), path))
+	require.NoError(t, err)
+
+	got, err := s.Get(context.Background(), "aaaaaa")
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com", got.Object)
 }
 
 func TestWithDB(t *testing.T) {
 }
 
 func TestWithFileStorage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	s, err := NewStorage(WithFileStorage(path))
+	require.NoError(t, err)
+	require.NoError(t, s.Set(ctx, map[string]Item{
+		"aaaaaa": {Object: "https://yandex.ru", UserID: "user-a"},
+		"bbbbbb": {Object: "https://ya.ru", UserID: "user-a"},
+	}))
+
+	events, err := s.ClaimOutbox(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.NoError(t, s.AckOutbox(ctx, []int64{events[0].ID}))
+	s.Close()
+
+	// Неподтвержденное событие переживает перезапуск, нумерация продолжается.
+	s, err = NewStorage(WithFileStorage(path))
+	require.NoError(t, err)
+	defer s.Close()
+
+	restored, err := s.ClaimOutbox(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Equal(t, []OutboxEvent{events[1]}, restored)
+
+	require.NoError(t, s.AddOutbox(ctx, []OutboxEvent{NewOutboxEvent(EventLinkClicked, "user-a", EventLink{Alias: "bbbbbb"})}))
+	restored, err = s.ClaimOutbox(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+	require.Equal(t, events[1].ID+1, restored[0].ID)
+}
+
+func TestWithFileStorage_DeleteJobs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	s, err := NewStorage(WithFileStorage(path))
+	require.NoError(t, err)
+	require.NoError(t, s.AddDeleteJob(ctx, DeleteJob{ID: "job-1", UserID: "user-a", URLs: []string{"aaaaaa"}}))
+	require.NoError(t, s.AddDeleteJob(ctx, DeleteJob{ID: "job-2", UserID: "user-a", URLs: []string{"bbbbbb"}}))
+
+	jobs, err := s.ClaimDeleteJobs(ctx, time.Now(), time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	jobs[0].Status, jobs[0].Attempts = JobDone, 1
+	require.NoError(t, s.UpdateDeleteJobs(ctx, jobs))
+	s.Close()
+
+	// Невыполненное задание переживает перезапуск, выполненное - нет.
+	s, err = NewStorage(WithFileStorage(path))
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.GetDeleteJob(ctx, "user-a", jobs[0].ID)
+	require.ErrorIs(t, err, models.ErrJobNotFound)
+
+	pending, err := s.ClaimDeleteJobs(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.NotEqual(t, jobs[0].ID, pending[0].ID)
+	require.Equal(t, JobPending, pending[0].Status)
 }